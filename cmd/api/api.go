@@ -1,17 +1,28 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/zmb3/spotify"
 	"music-service/internal/config"
 	"music-service/internal/handler"
+	"music-service/internal/models"
+	"music-service/internal/provider"
 	"music-service/internal/repository"
 	"music-service/internal/service"
 	"music-service/pkg/logging"
+	"music-service/pkg/scheduler"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+const shutdownTimeout = 30 * time.Second
+
 type Server struct {
 	db     *sql.DB
 	cfg    *config.Config
@@ -33,12 +44,116 @@ func NewServer(
 	}
 }
 
+// Run serves until the process is interrupted or terminated, then lets running
+// requests and background imports finish.
 func (s *Server) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	router := chi.NewRouter()
 	repo := repository.NewRepository(s.db, s.log)
-	services := service.NewService(repo, s.client, s.cfg.JWT.Expiration, s.cfg.JWT.Secret)
+	catalog, err := provider.New(s.cfg.Catalog.Provider, s.client)
+	if err != nil {
+		return err
+	}
+	services := service.NewService(ctx, repo, catalog, s.cfg.JWT.Expiration, s.cfg.JWT.Secret, s.cfg.Trash.Retention, models.QuotaLimits{
+		MaxPlaylists:         s.cfg.Quota.MaxPlaylists,
+		MaxTracksPerPlaylist: s.cfg.Quota.MaxTracksPerPlaylist,
+		MaxRequestsPerDay:    s.cfg.Quota.MaxRequestsPerDay,
+	}, models.CacheSettings{
+		Size:     s.cfg.CatalogCache.Size,
+		TTL:      s.cfg.CatalogCache.TTL,
+		StaleTTL: s.cfg.CatalogCache.StaleTTL,
+	}, models.RefreshSettings{
+		MaxAge:            s.cfg.MetadataRefresh.MaxAge,
+		BatchSize:         s.cfg.MetadataRefresh.BatchSize,
+		Batches:           s.cfg.MetadataRefresh.Batches,
+		RequestsPerSecond: s.cfg.MetadataRefresh.RequestsPerSecond,
+	})
+	go scheduler.Every(ctx, s.cfg.SmartPlaylists.RefreshInterval, func() {
+		refreshed, err := services.SmartPlaylist.RefreshScheduledPlaylists()
+		if err != nil {
+			s.log.Error("Smart playlist refresh failed: ", err)
+		}
+		if refreshed > 0 {
+			s.log.Info("Smart playlists refreshed: ", refreshed)
+		}
+	})
+	go scheduler.Every(ctx, s.cfg.Trash.PurgeInterval, func() {
+		purged, err := services.Trash.PurgeExpired()
+		if err != nil {
+			s.log.Error("Trash purge failed: ", err)
+		}
+		if purged > 0 {
+			s.log.Info("Trash purged playlists: ", purged)
+		}
+	})
+	go scheduler.Every(ctx, s.cfg.Quota.PurgeInterval, func() {
+		purged, err := services.Quota.PurgeRequestCounts()
+		if err != nil {
+			s.log.Error("Request count purge failed: ", err)
+		}
+		if purged > 0 {
+			s.log.Info("Request counts purged: ", purged)
+		}
+	})
+	backfillArtists := func() {
+		resolved, err := services.Song.BackfillSongArtists()
+		if err != nil {
+			s.log.Error("Artist backfill failed: ", err)
+		}
+		if resolved > 0 {
+			s.log.Info("Artist backfill resolved songs: ", resolved)
+		}
+	}
+	// The first run resolves the songs stored before artists were tracked.
+	go func() {
+		backfillArtists()
+		scheduler.Every(ctx, s.cfg.CatalogCache.BackfillInterval, backfillArtists)
+	}()
+	go scheduler.Every(ctx, s.cfg.AudioFeatures.BackfillInterval, func() {
+		stored, err := services.Feature.BackfillAudioFeatures()
+		if err != nil {
+			s.log.Error("Audio features backfill failed: ", err)
+		}
+		if stored > 0 {
+			s.log.Info("Audio features backfill analysed songs: ", stored)
+		}
+	})
+	go scheduler.Every(ctx, s.cfg.MetadataRefresh.Interval, func() {
+		report, err := services.Refresh.RefreshStaleSongs()
+		if errors.Is(err, service.ErrRefreshRunning) {
+			s.log.Info("Metadata refresh skipped: ", err)
+			return
+		}
+		if err != nil {
+			s.log.Error("Metadata refresh failed: ", err)
+			return
+		}
+		s.log.Info("Metadata refresh checked, updated, unavailable, rate limited: ",
+			report.Checked, report.Updated, len(report.Unavailable), report.RateLimited)
+	})
 	hand := handler.NewHandler(services, s.log)
 	hand.RegisterRoutes(router)
+
+	server := &http.Server{Addr: s.cfg.Server.Port, Handler: router}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			s.log.Error("Server shutdown error: ", err)
+		}
+	}()
+
 	s.log.Info("Server started on port: ", s.cfg.Server.Port)
-	return http.ListenAndServe(s.cfg.Server.Port, router)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-stopped
+	services.Import.WaitForImports()
+	s.log.Info("Server stopped")
+	return nil
 }
@@ -23,7 +23,15 @@ import (
 func main() {
 	cfg := config.GetConfig()
 	log := logging.NewLogger()
-	svc := client.NewSpotifyClient(cfg.Spotify.ClientID, cfg.Spotify.ClientSecret)
+	svc := client.NewSpotifyClient(cfg.Spotify.ClientID, cfg.Spotify.ClientSecret, client.Settings{
+		MaxRetries:        cfg.Spotify.MaxRetries,
+		BaseBackoff:       cfg.Spotify.BaseBackoff,
+		MaxBackoff:        cfg.Spotify.MaxBackoff,
+		RequestsPerSecond: cfg.Spotify.RequestsPerSecond,
+		Burst:             cfg.Spotify.Burst,
+		FailureThreshold:  cfg.Spotify.FailureThreshold,
+		OpenTimeout:       cfg.Spotify.OpenTimeout,
+	})
 	storage, err := db.NewStorage(mysql.Config{
 		User:                 cfg.MySql.DBUser,
 		Passwd:               cfg.MySql.DBPassword,
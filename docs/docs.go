@@ -15,6 +15,255 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/catalog/cache": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Hits, misses and stale answers of the track cache since the server started",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get catalog cache stats",
+                "responses": {
+                    "200": {
+                        "description": "Cache stats",
+                        "schema": {
+                            "$ref": "#/definitions/models.CacheStats"
+                        }
+                    },
+                    "403": {
+                        "description": "admin only",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/admin/catalog/refresh": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Runs the metadata refresher now instead of waiting for its schedule and reports what it did",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Refresh stale track metadata",
+                "responses": {
+                    "200": {
+                        "description": "Refresh report",
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshReport"
+                        }
+                    },
+                    "403": {
+                        "description": "admin only",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "a metadata refresh is already running",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/admin/users/{userId}/quota": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace the limits of a single user, omitted limits fall back to the configured defaults and 0 means unlimited",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Override user quota",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Quota override",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.QuotaOverride"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Effective limits",
+                        "schema": {
+                            "$ref": "#/definitions/models.QuotaLimits"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "admin only",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/albums/{albumId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the album with all its tracks in disc and track order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "catalog"
+                ],
+                "summary": "Get album",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spotify album ID",
+                        "name": "albumId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Album",
+                        "schema": {
+                            "$ref": "#/definitions/models.AlbumDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid album id",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "album not found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/albums/{albumId}/playlist/{playlistId}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Appends every track of the album in disc and track order the same way a single insert does. Tracks the playlist's duplicate policy rejects are listed instead of failing the request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracks"
+                ],
+                "summary": "Insert album",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spotify album ID",
+                        "name": "albumId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Added tracks",
+                        "schema": {
+                            "$ref": "#/definitions/models.AddTracksReport"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid album id",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "album not found",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
+                    }
+                }
+            }
+        },
         "/api/v1/login": {
             "post": {
                 "description": "User authentication",
@@ -134,70 +383,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/ping": {
-            "get": {
-                "description": "Send request to server",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "ping"
-                ],
-                "summary": "Ping server",
-                "responses": {
-                    "200": {
-                        "description": "pong",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/playlist": {
+        "/artists/{artistId}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get all playlists for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the artist with the top tracks in the market and the first page of albums and singles available there",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "playlist"
+                    "catalog"
+                ],
+                "summary": "Get artist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spotify artist ID",
+                        "name": "artistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 country code, defaults to US",
+                        "name": "market",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all playlists",
                 "responses": {
                     "200": {
-                        "description": "Playlists",
+                        "description": "Artist",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Playlist"
-                            }
+                            "$ref": "#/definitions/models.ArtistDetails"
                         }
                     },
+                    "400": {
+                        "description": "invalid artist id or market",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "artist not found",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "internal server error",
                         "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
                     }
                 }
-            },
+            }
+        },
+        "/folder": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates a new playlist for the authenticated user",
+                "description": "Creates a playlist folder, optionally inside another folder",
                 "consumes": [
                     "application/json"
                 ],
@@ -205,30 +454,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "playlist"
+                    "folders"
                 ],
-                "summary": "Create playlist",
+                "summary": "Create folder",
                 "parameters": [
                     {
-                        "description": "Playlist creation dto",
+                        "description": "Folder creation dto",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreatePlaylistDto"
+                            "$ref": "#/definitions/models.CreateFolderDto"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Playlist created",
+                        "description": "Folder created",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "invalid parsing JSON",
+                        "description": "invalid input",
                         "schema": {}
                     },
                     "500": {
@@ -238,14 +487,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/playlist/{id}": {
-            "get": {
+        "/folder/{folderId}": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get playlist by id",
+                "description": "Renames a playlist folder",
                 "consumes": [
                     "application/json"
                 ],
@@ -253,38 +502,52 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "playlist"
+                    "folders"
                 ],
-                "summary": "Get playlist by id",
+                "summary": "Rename folder",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Playlist id",
-                        "name": "id",
+                        "description": "Folder ID",
+                        "name": "folderId",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Playlist",
+                    },
+                    {
+                        "description": "New folder name",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Playlist"
+                            "$ref": "#/definitions/models.RenameFolderDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Folder renamed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "internal server error",
                         "schema": {}
                     }
                 }
             },
-            "put": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update playlist by id",
+                "description": "Deletes a folder. mode=move_up (default) moves its contents one level up, mode=cascade deletes them too",
                 "consumes": [
                     "application/json"
                 ],
@@ -292,37 +555,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "playlist"
+                    "folders"
                 ],
-                "summary": "Update playlist by id",
+                "summary": "Delete folder",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Playlist id",
-                        "name": "id",
+                        "description": "Folder ID",
+                        "name": "folderId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Playlist update dto",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdatePlaylistDto"
-                        }
+                        "type": "string",
+                        "description": "move_up or cascade",
+                        "name": "mode",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Playlist updated",
+                        "description": "Folder deleted",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "invalid parsing JSON",
+                        "description": "invalid input",
                         "schema": {}
                     },
                     "500": {
@@ -330,14 +590,16 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/folder/{folderId}/parent": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete playlist by id",
+                "description": "Moves a folder into another folder, or to the top level when parent_id is null",
                 "consumes": [
                     "application/json"
                 ],
@@ -345,28 +607,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "playlist"
+                    "folders"
                 ],
-                "summary": "Delete playlist by id",
+                "summary": "Move folder",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Playlist id",
-                        "name": "id",
+                        "description": "Folder ID",
+                        "name": "folderId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "New parent folder",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MoveFolderDto"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Playlist deleted",
+                        "description": "Folder moved",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "invalid playlist id",
+                        "description": "invalid input or folder cycle",
                         "schema": {}
                     },
                     "500": {
@@ -376,14 +647,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/playlist/{playlistId}/tracks": {
+        "/me/backup": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get tracks from playlist",
+                "description": "Downloads every playlist outside the trash with its ordered entries and all referenced song rows as one versioned JSON document. Smart playlists are saved with their rules and the tracks those match now",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "backup"
+                ],
+                "summary": "Back up the library",
+                "responses": {
+                    "200": {
+                        "description": "Backup",
+                        "schema": {
+                            "$ref": "#/definitions/backup.Document"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/me/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates the playlists of a backup document in this account under new ids. Tracks are looked up in the catalog by id, entries it doesn't know are left out and counted in missing_tracks. Restoring the same backup again skips the playlists it already created, unless they were deleted since. Smart playlists come back with their rules, matched against this library",
                 "consumes": [
                     "application/json"
                 ],
@@ -391,28 +691,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tracks"
+                    "backup"
                 ],
-                "summary": "Get tracks from playlist",
+                "summary": "Restore a backup",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Playlist ID",
-                        "name": "playlistId",
-                        "in": "path",
-                        "required": true
+                        "description": "Backup document",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/backup.Document"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tracks",
+                        "description": "Restore report",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Song"
-                            }
+                            "$ref": "#/definitions/models.RestoreReport"
                         }
                     },
+                    "400": {
+                        "description": "invalid or unsupported backup",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "internal server error",
                         "schema": {}
@@ -420,14 +727,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/tracks/{trackId}": {
+        "/me/usage": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get track from spotify",
+                "description": "Current consumption against the playlist, track and daily request limits, a limit of 0 means unlimited",
                 "consumes": [
                     "application/json"
                 ],
@@ -435,23 +742,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tracks"
-                ],
-                "summary": "Get track from spotify",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Track ID",
-                        "name": "trackId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "quota"
                 ],
+                "summary": "Get quota usage",
                 "responses": {
                     "200": {
-                        "description": "Track",
+                        "description": "Usage",
                         "schema": {
-                            "$ref": "#/definitions/models.Song"
+                            "$ref": "#/definitions/models.Usage"
                         }
                     },
                     "500": {
@@ -461,14 +759,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/tracks/{trackId}/playlist/{playlistId}": {
-            "post": {
+        "/ping": {
+            "get": {
+                "description": "Send request to server",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ping"
+                ],
+                "summary": "Ping server",
+                "responses": {
+                    "200": {
+                        "description": "pong",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlist": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Insert track to playlist",
+                "description": "Get all playlists for the authenticated user. view=tree groups them into folders",
                 "consumes": [
                     "application/json"
                 ],
@@ -476,45 +797,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tracks"
+                    "playlist"
                 ],
-                "summary": "Insert track",
+                "summary": "Get all playlists",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Playlist ID",
-                        "name": "playlistId",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "tree",
+                        "name": "view",
+                        "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Track ID",
-                        "name": "trackId",
-                        "in": "path",
-                        "required": true
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only playlists carrying all of these tags",
+                        "name": "tag",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Track",
+                        "description": "Playlists grouped into folders",
                         "schema": {
-                            "$ref": "#/definitions/models.Song"
+                            "$ref": "#/definitions/models.PlaylistTree"
                         }
                     },
+                    "400": {
+                        "description": "tag filter has only blank names",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "internal server error",
                         "schema": {}
                     }
                 }
             },
-            "delete": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete track from playlist",
+                "description": "Creates a new playlist for the authenticated user",
                 "consumes": [
                     "application/json"
                 ],
@@ -522,90 +849,3796 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tracks"
+                    "playlist"
                 ],
-                "summary": "Delete track from playlist",
+                "summary": "Create playlist",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Playlist ID",
-                        "name": "playlistId",
-                        "in": "path",
-                        "required": true
+                        "description": "Playlist creation dto",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePlaylistDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid parsing JSON",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
                     },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/import/csv": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Matches the rows of a CSV document against the catalog by Spotify id or url, or by artist and title through search. Runs as a dry run listing the rows that would fail unless commit is set, then creates the playlist in one transaction",
+                "consumes": [
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Import playlist from CSV",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Track ID",
-                        "name": "trackId",
-                        "in": "path",
-                        "required": true
+                        "description": "Playlist name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Header mapping as field:header, fields are id, url, title, artist, album, duration",
+                        "name": "map",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Create the playlist instead of a dry run",
+                        "name": "commit",
+                        "in": "query"
+                    },
+                    {
+                        "description": "CSV document with a header row",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Track removed from playlist",
+                        "description": "Import report",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ImportReport"
                         }
                     },
+                    "400": {
+                        "description": "invalid document or mapping",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "internal server error",
                         "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CreatePlaylistDto": {
-            "type": "object",
-            "properties": {
-                "name": {
-                    "type": "string"
-                }
-            }
         },
-        "models.LoginDto": {
-            "type": "object",
+        "/playlist/import/jobs/{jobId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Progress of a background import, playlist_id is set once it's done",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Get import job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "jobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import job",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid job id",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/import/spotify": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Clones a Spotify playlist with its tracks in order. Playlists of up to 100 tracks are imported right away, larger ones answer 202 with a job to poll for progress",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Import playlist from Spotify",
+                "parameters": [
+                    {
+                        "description": "Playlist URL, URI or id and an optional name",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SpotifyImportDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Finished import",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "202": {
+                        "description": "Import running in the background",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "spotify playlist not found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/import/text": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reads plain text with one \"Artist - Title\" per line, separated by -, –, —, ~, | or a tab, and searches the catalog for each line. Nothing is saved: the candidates come with a confidence from 0 to 1 and the chosen tracks are added with POST /playlist/{playlistId}/tracks",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Preview a pasted track list",
+                "parameters": [
+                    {
+                        "description": "Track list, at most 200 lines",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Candidates per line",
+                        "schema": {
+                            "$ref": "#/definitions/models.TracklistPreview"
+                        }
+                    },
+                    "400": {
+                        "description": "empty or too long track list",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/import/xspf": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a playlist from an XSPF document. Tracks with a Spotify identifier or location resolve directly, the others are matched through catalog search and reported back when nothing is found",
+                "consumes": [
+                    "application/xspf+xml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Import playlist from XSPF",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist name, defaults to the document title",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "description": "XSPF document",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import report",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid document",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/smart": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a playlist whose tracks are computed from a rule tree over the tracks in the user's own playlists",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "smart playlists"
+                ],
+                "summary": "Create smart playlist",
+                "parameters": [
+                    {
+                        "description": "Smart playlist creation dto",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSmartPlaylistDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Smart playlist created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid rules",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get playlist by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlist"
+                ],
+                "summary": "Get playlist by id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached playlist version",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist",
+                        "schema": {
+                            "$ref": "#/definitions/models.Playlist"
+                        }
+                    },
+                    "304": {
+                        "description": "playlist not modified"
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update playlist by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlist"
+                ],
+                "summary": "Update playlist by id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Playlist update dto",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdatePlaylistDto"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist updated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid parsing JSON",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Moves the playlist to the trash, from where it can be restored until the retention window ends",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlist"
+                ],
+                "summary": "Delete playlist by id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid playlist id",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List tracks that occur more than once in the playlist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Get duplicate tracks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Duplicates",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DuplicateGroup"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid playlist id",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Keep the first occurrence of every track and remove the rest",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Collapse duplicate tracks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Removed entries count",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid playlist id",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/entries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get ordered playlist entries, each with its own entry id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Get playlist entries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Entries",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PlaylistEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid playlist id",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/entries/{entryId}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Move a single playlist entry to a new 1-based position",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Move playlist entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target position",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MoveEntryDto"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Entry moved",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a single occurrence of a track from the playlist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Delete playlist entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Entry removed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/entries/{entryId}/note": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Set the note of a single playlist entry, an empty note removes it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Update playlist entry note",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Entry note",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EntryNoteDto"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Note updated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/export.csv": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streams the playlist as CSV with a header row. Columns are the song fields id, title, artist, album, album_cover, duration, release_date, popularity, preview_url, external_url and the entry fields position, entry_id, added_at, added_by, note",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Export playlist as CSV",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated columns, defaults to position,title,artist,album,duration,id,external_url,added_at,note",
+                        "name": "columns",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV playlist",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "unknown column",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/export.m3u8": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streams the playlist as extended M3U, tracks without a URL for the chosen source are left out",
+                "produces": [
+                    "audio/x-mpegurl"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Export playlist as M3U8",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "external (default) or preview",
+                        "name": "source",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "M3U8 playlist",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid source",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/export.xspf": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streams the playlist as XSPF with title, creator, album, duration in ms, cover image and the Spotify URL as location and identifier",
+                "produces": [
+                    "application/xspf+xml"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Export playlist as XSPF",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "XSPF playlist",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid playlist id",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/folder": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Moves a playlist into a folder, or to the top level when folder_id is null",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Move playlist to folder",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target folder",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MovePlaylistDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist moved",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/freeze": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Saves the current tracks of a smart playlist as a new normal playlist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "smart playlists"
+                ],
+                "summary": "Freeze smart playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Name of the new playlist",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FreezePlaylistDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist frozen",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get versioned change events of the playlist, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Get playlist history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "History",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PlaylistSnapshot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid playlist id",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/recommendations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Tracks like the playlist's, seeded by its most frequent artists and most popular tracks. Tracks already in the playlist are left out",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "Get recommendations for a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of tracks, 1 to 100, defaults to 20",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 country code, only content playable there is returned",
+                        "name": "market",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preferred tempo in BPM",
+                        "name": "target_tempo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preferred energy, 0 to 1",
+                        "name": "target_energy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preferred danceability, 0 to 1",
+                        "name": "target_danceability",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preferred valence, 0 to 1",
+                        "name": "target_valence",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preferred loudness in dB",
+                        "name": "target_loudness",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recommended tracks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Song"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query or playlist without Spotify tracks",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Appends the top recommendations to the playlist, limit sets how many",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "Append recommendations to a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of tracks to append, 1 to 100, defaults to 20",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 country code, only content playable there is returned",
+                        "name": "market",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preferred tempo in BPM",
+                        "name": "target_tempo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preferred energy, 0 to 1",
+                        "name": "target_energy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preferred danceability, 0 to 1",
+                        "name": "target_danceability",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preferred valence, 0 to 1",
+                        "name": "target_valence",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preferred loudness in dB",
+                        "name": "target_loudness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Added tracks",
+                        "schema": {
+                            "$ref": "#/definitions/models.AddTracksReport"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query or playlist without Spotify tracks",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/refresh": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Re-evaluates the rules and stores the resulting tracks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "smart playlists"
+                ],
+                "summary": "Refresh smart playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist refreshed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid playlist id",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restore the playlist name and tracks from an earlier snapshot",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Revert playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Snapshot to restore",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RevertPlaylistDto"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist reverted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/rules": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the rule tree of a smart playlist and refreshes its tracks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "smart playlists"
+                ],
+                "summary": "Update smart playlist rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Smart rules update dto",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSmartRulesDto"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rules updated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid rules",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/snapshots/{snapshotId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the playlist state recorded by a snapshot",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Get playlist snapshot",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Snapshot ID",
+                        "name": "snapshotId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Snapshot",
+                        "schema": {
+                            "$ref": "#/definitions/models.PlaylistSnapshot"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Track count, duration, popularity, top artists and albums and release years of a playlist. Smart playlists report their last refresh",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Get playlist statistics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist statistics",
+                        "schema": {
+                            "$ref": "#/definitions/models.PlaylistStats"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid playlist id",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/tags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the tags of a playlist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get playlist tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tags",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tag"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid playlist id",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds a tag to a playlist, creating the tag when it doesn't exist yet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Tag playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tag name",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TagDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist tagged",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/tags/{tagId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a tag from a playlist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Untag playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "tagId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag removed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/playlist/{playlistId}/tracks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the playlist entries with their track, added_at, added_by and note",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracks"
+                ],
+                "summary": "Get tracks from playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only tracks carrying all of these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "position (default), added_at, -added_at, or tempo, energy, danceability, valence, loudness with an optional - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries added at or after this RFC3339 time",
+                        "name": "added_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries added before this RFC3339 time",
+                        "name": "added_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tracks crediting this Spotify artist, featured artists included",
+                        "name": "artist_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Lowest tempo in BPM",
+                        "name": "tempo_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Highest tempo in BPM",
+                        "name": "tempo_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Lowest energy, 0 to 1",
+                        "name": "energy_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Highest energy, 0 to 1",
+                        "name": "energy_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Lowest danceability, 0 to 1",
+                        "name": "danceability_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Highest danceability, 0 to 1",
+                        "name": "danceability_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Lowest valence, 0 to 1",
+                        "name": "valence_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Highest valence, 0 to 1",
+                        "name": "valence_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Lowest loudness in dB",
+                        "name": "loudness_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Highest loudness in dB",
+                        "name": "loudness_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pitch class, 0 (C) to 11 (B)",
+                        "name": "key",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "1 for major, 0 for minor",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached playlist version",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tracks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PlaylistEntry"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "playlist not modified"
+                    },
+                    "400": {
+                        "description": "tag filter has only blank names",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "artist credits of the playlist are still being resolved",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Appends the tracks in order the same way a single insert does, for instance the candidates confirmed from a track list preview. Ids the playlist's duplicate policy rejects are listed instead of failing the request",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracks"
+                ],
+                "summary": "Insert several tracks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Spotify track ids or URLs",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddTracksDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Added tracks",
+                        "schema": {
+                            "$ref": "#/definitions/models.AddTracksReport"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Searches Spotify for tracks, albums and artists. Every requested type gets its own page, limit and offset apply to each of them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "catalog"
+                ],
+                "summary": "Search the catalog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query, Spotify field filters such as artist: or year: work too",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated track, album, artist, defaults to track",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per type, 1 to 50, defaults to 20",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Index of the first item, 0 to 1000",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 country code, only content playable there is returned",
+                        "name": "market",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Search result",
+                        "schema": {
+                            "$ref": "#/definitions/models.SearchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the user's tags with usage counts. With q set, returns up to 10 tags starting with it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag name prefix for autocomplete",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tags",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tag"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a tag, or returns the existing one with the same name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Create tag",
+                "parameters": [
+                    {
+                        "description": "Tag name",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TagDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/tags/{tagId}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Renames a tag everywhere it is used",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Rename tag",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "tagId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New tag name",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TagDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag renamed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "tag with this name already exists",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a tag and removes it from all playlists and tracks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Delete tag",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "tagId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid tag id",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/tracks/{trackId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get track from spotify",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracks"
+                ],
+                "summary": "Get track from spotify",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Track ID",
+                        "name": "trackId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Track",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/tracks/{trackId}/playlist/{playlistId}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Insert track to playlist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracks"
+                ],
+                "summary": "Insert track",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Track ID",
+                        "name": "trackId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Track",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "track is already in the playlist",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "catalog is unavailable",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete track from playlist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracks"
+                ],
+                "summary": "Delete track from playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Track ID",
+                        "name": "trackId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the playlist version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Track removed from playlist",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "track is not in the playlist",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "playlist was changed by another request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/tracks/{trackId}/tags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the user's tags on a track",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get track tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Track ID",
+                        "name": "trackId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tags",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tag"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds a tag to a track from one of the user's playlists",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Tag track",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Track ID",
+                        "name": "trackId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tag name",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TagDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Track tagged",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/tracks/{trackId}/tags/{tagId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a tag from a track",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Untag track",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Track ID",
+                        "name": "trackId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "tagId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag removed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid tag id",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/trash": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists deleted playlists together with the time they will be purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Get trash",
+                "responses": {
+                    "200": {
+                        "description": "Deleted playlists",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TrashedPlaylist"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/trash/{playlistId}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restores a deleted playlist with its tracks, history and tags",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Restore playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "playlistId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist restored",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid playlist id",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "quota exceeded",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {}
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "backup.Document": {
+            "type": "object",
+            "properties": {
+                "exported_at": {
+                    "type": "string"
+                },
+                "origin": {
+                    "type": "string"
+                },
+                "playlists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/backup.Playlist"
+                    }
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "backup.Entry": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "added_by": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "song_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "backup.Playlist": {
+            "type": "object",
+            "properties": {
+                "duplicate_policy": {
+                    "type": "string"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/backup.Entry"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "refresh_mode": {
+                    "type": "string"
+                },
+                "rules": {
+                    "$ref": "#/definitions/models.SmartRules"
+                }
+            }
+        },
+        "models.AddTracksDto": {
+            "type": "object",
+            "required": [
+                "track_ids"
+            ],
+            "properties": {
+                "track_ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.AddTracksReport": {
+            "type": "object",
+            "properties": {
+                "rejected": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "snapshot_id": {
+                    "type": "integer"
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                }
+            }
+        },
+        "models.Album": {
+            "type": "object",
+            "properties": {
+                "album_cover": {
+                    "type": "string"
+                },
+                "album_type": {
+                    "type": "string"
+                },
+                "artist": {
+                    "type": "string"
+                },
+                "external_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "release_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AlbumDetails": {
+            "type": "object",
+            "properties": {
+                "album_cover": {
+                    "type": "string"
+                },
+                "album_type": {
+                    "type": "string"
+                },
+                "artist": {
+                    "type": "string"
+                },
+                "external_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "total_tracks": {
+                    "type": "integer"
+                },
+                "tracks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                }
+            }
+        },
+        "models.AlbumPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Album"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Artist": {
+            "type": "object",
+            "properties": {
+                "external_url": {
+                    "type": "string"
+                },
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "popularity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ArtistDetails": {
+            "type": "object",
+            "properties": {
+                "albums": {
+                    "$ref": "#/definitions/models.AlbumPage"
+                },
+                "external_url": {
+                    "type": "string"
+                },
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "popularity": {
+                    "type": "integer"
+                },
+                "top_tracks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                }
+            }
+        },
+        "models.ArtistPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Artist"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AudioFeatureStats": {
+            "type": "object",
+            "properties": {
+                "danceability": {
+                    "$ref": "#/definitions/models.Distribution"
+                },
+                "energy": {
+                    "$ref": "#/definitions/models.Distribution"
+                },
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StatCount"
+                    }
+                },
+                "loudness": {
+                    "$ref": "#/definitions/models.Distribution"
+                },
+                "tempo": {
+                    "$ref": "#/definitions/models.Distribution"
+                },
+                "track_count": {
+                    "type": "integer"
+                },
+                "valence": {
+                    "$ref": "#/definitions/models.Distribution"
+                }
+            }
+        },
+        "models.AudioFeatures": {
+            "type": "object",
+            "properties": {
+                "danceability": {
+                    "type": "number"
+                },
+                "energy": {
+                    "type": "number"
+                },
+                "key": {
+                    "type": "integer"
+                },
+                "loudness": {
+                    "type": "number"
+                },
+                "mode": {
+                    "type": "integer"
+                },
+                "tempo": {
+                    "type": "number"
+                },
+                "valence": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Bucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CacheStats": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "stale": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateFolderDto": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "parent_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreatePlaylistDto": {
+            "type": "object",
+            "properties": {
+                "duplicate_policy": {
+                    "type": "string",
+                    "enum": [
+                        "allow",
+                        "reject",
+                        "skip"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateSmartPlaylistDto": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "refresh_mode": {
+                    "type": "string",
+                    "enum": [
+                        "on_read",
+                        "scheduled"
+                    ]
+                },
+                "rules": {
+                    "$ref": "#/definitions/models.SmartRules"
+                }
+            }
+        },
+        "models.Distribution": {
+            "type": "object",
+            "properties": {
+                "average": {
+                    "type": "number"
+                },
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Bucket"
+                    }
+                },
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.DuplicateGroup": {
+            "type": "object",
+            "properties": {
+                "entry_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "song": {
+                    "$ref": "#/definitions/models.Song"
+                }
+            }
+        },
+        "models.EntryNoteDto": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "models.Folder": {
+            "type": "object",
+            "properties": {
+                "folders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Folder"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "playlists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Playlist"
+                    }
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.FreezePlaylistDto": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportItem": {
+            "type": "object",
+            "properties": {
+                "album": {
+                    "type": "string"
+                },
+                "artist": {
+                    "type": "string"
+                },
+                "duration": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                },
+                "spotify_id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportJob": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "playlist_id": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportReport": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "playlist_id": {
+                    "type": "integer"
+                },
+                "snapshot_id": {
+                    "type": "integer"
+                },
+                "unmatched": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportItem"
+                    }
+                }
+            }
+        },
+        "models.LoginDto": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MoveEntryDto": {
+            "type": "object",
+            "required": [
+                "position"
+            ],
+            "properties": {
+                "position": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "models.MoveFolderDto": {
+            "type": "object",
+            "properties": {
+                "parent_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MovePlaylistDto": {
+            "type": "object",
+            "properties": {
+                "folder_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Playlist": {
+            "type": "object",
+            "properties": {
+                "duplicate_policy": {
+                    "type": "string"
+                },
+                "folder_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "refresh_mode": {
+                    "type": "string"
+                },
+                "rules": {
+                    "$ref": "#/definitions/models.SmartRules"
+                },
+                "snapshot_id": {
+                    "type": "integer"
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PlaylistEntry": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "added_by": {
+                    "type": "integer"
+                },
+                "album": {
+                    "type": "string"
+                },
+                "album_cover": {
+                    "type": "string"
+                },
+                "album_id": {
+                    "type": "string"
+                },
+                "artist": {
+                    "type": "string"
+                },
+                "artists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SongArtist"
+                    }
+                },
+                "audio_features": {
+                    "$ref": "#/definitions/models.AudioFeatures"
+                },
+                "disc_number": {
+                    "type": "integer"
+                },
+                "duration": {
+                    "type": "integer"
+                },
+                "entry_id": {
+                    "type": "integer"
+                },
+                "explicit": {
+                    "type": "boolean"
+                },
+                "external_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "popularity": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "preview_url": {
+                    "type": "string"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "release_date_precision": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "track_number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PlaylistSnapshot": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "playlist_id": {
+                    "type": "integer"
+                },
+                "snapshot_id": {
+                    "type": "integer"
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                },
+                "track_count": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PlaylistStats": {
+            "type": "object",
+            "properties": {
+                "audio_features": {
+                    "$ref": "#/definitions/models.AudioFeatureStats"
+                },
+                "average_popularity": {
+                    "type": "number"
+                },
+                "median_popularity": {
+                    "type": "number"
+                },
+                "missing_previews": {
+                    "type": "integer"
+                },
+                "playlist_id": {
+                    "type": "integer"
+                },
+                "release_years": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.YearCount"
+                    }
+                },
+                "top_albums": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StatCount"
+                    }
+                },
+                "top_artists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StatCount"
+                    }
+                },
+                "total_duration": {
+                    "type": "integer"
+                },
+                "track_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PlaylistTree": {
+            "type": "object",
+            "properties": {
+                "folders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Folder"
+                    }
+                },
+                "playlists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Playlist"
+                    }
+                }
+            }
+        },
+        "models.QuotaLimits": {
+            "type": "object",
+            "properties": {
+                "max_playlists": {
+                    "type": "integer"
+                },
+                "max_requests_per_day": {
+                    "type": "integer"
+                },
+                "max_tracks_per_playlist": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.QuotaOverride": {
+            "type": "object",
+            "properties": {
+                "max_playlists": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "max_requests_per_day": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "max_tracks_per_playlist": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.RefreshReport": {
+            "type": "object",
+            "properties": {
+                "checked": {
+                    "type": "integer"
+                },
+                "rate_limited": {
+                    "type": "boolean"
+                },
+                "unavailable": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RegisterDto": {
+            "type": "object",
             "properties": {
                 "email": {
                     "type": "string"
                 },
                 "password": {
                     "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "models.Playlist": {
+        "models.RenameFolderDto": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "id": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "models.RestoreReport": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "playlists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RestoredPlaylist"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RestoredPlaylist": {
+            "type": "object",
+            "properties": {
+                "missing_tracks": {
                     "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
-                "songs": {
+                "playlist_id": {
+                    "type": "integer"
+                },
+                "source_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RevertPlaylistDto": {
+            "type": "object",
+            "required": [
+                "snapshot_id"
+            ],
+            "properties": {
+                "snapshot_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RuleNode": {
+            "type": "object",
+            "properties": {
+                "all": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Song"
+                        "$ref": "#/definitions/models.RuleNode"
                     }
                 },
-                "user_id": {
-                    "type": "integer"
+                "any": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RuleNode"
+                    }
+                },
+                "field": {
+                    "type": "string"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "object"
                 }
             }
         },
-        "models.RegisterDto": {
+        "models.RuleSort": {
             "type": "object",
             "properties": {
-                "email": {
+                "field": {
                     "type": "string"
                 },
-                "password": {
+                "order": {
                     "type": "string"
+                }
+            }
+        },
+        "models.SearchResult": {
+            "type": "object",
+            "properties": {
+                "albums": {
+                    "$ref": "#/definitions/models.AlbumPage"
                 },
-                "username": {
+                "artists": {
+                    "$ref": "#/definitions/models.ArtistPage"
+                },
+                "tracks": {
+                    "$ref": "#/definitions/models.TrackPage"
+                }
+            }
+        },
+        "models.SmartRules": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "match": {
+                    "$ref": "#/definitions/models.RuleNode"
+                },
+                "sort": {
+                    "$ref": "#/definitions/models.RuleSort"
+                },
+                "source": {
                     "type": "string"
                 }
             }
@@ -619,12 +4652,30 @@ const docTemplate = `{
                 "album_cover": {
                     "type": "string"
                 },
+                "album_id": {
+                    "type": "string"
+                },
                 "artist": {
                     "type": "string"
                 },
+                "artists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SongArtist"
+                    }
+                },
+                "audio_features": {
+                    "$ref": "#/definitions/models.AudioFeatures"
+                },
+                "disc_number": {
+                    "type": "integer"
+                },
                 "duration": {
                     "type": "integer"
                 },
+                "explicit": {
+                    "type": "boolean"
+                },
                 "external_url": {
                     "type": "string"
                 },
@@ -640,18 +4691,235 @@ const docTemplate = `{
                 "release_date": {
                     "type": "string"
                 },
+                "release_date_precision": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "track_number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SongArtist": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SpotifyImportDto": {
+            "type": "object",
+            "required": [
+                "playlist"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "playlist": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "models.StatCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Tag": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "usage": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TagDto": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 50
+                }
+            }
+        },
+        "models.TrackCandidate": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "type": "number"
+                },
+                "song": {
+                    "$ref": "#/definitions/models.Song"
+                }
+            }
+        },
+        "models.TrackPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TracklistLine": {
+            "type": "object",
+            "properties": {
+                "artist": {
+                    "type": "string"
+                },
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TrackCandidate"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                },
                 "title": {
                     "type": "string"
                 }
             }
         },
+        "models.TracklistPreview": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TracklistLine"
+                    }
+                }
+            }
+        },
+        "models.TrashedPlaylist": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "purge_at": {
+                    "type": "string"
+                }
+            }
+        },
         "models.UpdatePlaylistDto": {
             "type": "object",
             "properties": {
+                "duplicate_policy": {
+                    "type": "string",
+                    "enum": [
+                        "allow",
+                        "reject",
+                        "skip"
+                    ]
+                },
                 "name": {
                     "type": "string"
                 }
             }
+        },
+        "models.UpdateSmartRulesDto": {
+            "type": "object",
+            "properties": {
+                "refresh_mode": {
+                    "type": "string",
+                    "enum": [
+                        "on_read",
+                        "scheduled"
+                    ]
+                },
+                "rules": {
+                    "$ref": "#/definitions/models.SmartRules"
+                }
+            }
+        },
+        "models.Usage": {
+            "type": "object",
+            "properties": {
+                "largest_playlist": {
+                    "$ref": "#/definitions/models.UsageCount"
+                },
+                "playlists": {
+                    "$ref": "#/definitions/models.UsageCount"
+                },
+                "requests_today": {
+                    "$ref": "#/definitions/models.UsageCount"
+                }
+            }
+        },
+        "models.UsageCount": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.YearCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,202 @@
+// Package backup reads and writes the JSON document holding a whole library:
+// every playlist with its ordered entries and each song row they reference. Smart
+// playlists also keep their rules.
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"music-service/internal/models"
+	"music-service/internal/rules"
+	"time"
+)
+
+const (
+	ContentType = "application/json; charset=utf-8"
+	Extension   = ".json"
+
+	// Version is the format written by Encode. Decode accepts every version from
+	// 1 up to it; bump it when a change would break older readers and teach
+	// Decode to upgrade the documents written before.
+	Version = 1
+)
+
+var (
+	ErrMissingVersion     = errors.New("backup has no version")
+	ErrUnsupportedVersion = errors.New("unsupported backup version")
+)
+
+// Document is one account's library. Origin identifies the exporting account, a
+// restore uses it together with the playlist ids to recognise playlists it has
+// brought in before.
+type Document struct {
+	Version    int           `json:"version"`
+	Origin     string        `json:"origin"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Playlists  []Playlist    `json:"playlists"`
+	Songs      []models.Song `json:"songs"`
+
+	songs map[string]bool
+}
+
+// Playlist keeps the id it had in the exporting account, entries are in order.
+// Smart playlists carry the tracks their rules held at export time.
+type Playlist struct {
+	ID              int                `json:"id"`
+	Name            string             `json:"name"`
+	DuplicatePolicy string             `json:"duplicate_policy,omitempty"`
+	Kind            string             `json:"kind,omitempty"`
+	Rules           *models.SmartRules `json:"rules,omitempty"`
+	RefreshMode     string             `json:"refresh_mode,omitempty"`
+	Entries         []Entry            `json:"entries"`
+}
+
+// Entry keeps added_by as a user id of the exporting server.
+type Entry struct {
+	SongId  string     `json:"song_id"`
+	AddedAt *time.Time `json:"added_at,omitempty"`
+	AddedBy *int       `json:"added_by,omitempty"`
+	Note    string     `json:"note,omitempty"`
+}
+
+func New(origin string, exportedAt time.Time) *Document {
+	return &Document{
+		Version:    Version,
+		Origin:     origin,
+		ExportedAt: exportedAt,
+		Playlists:  []Playlist{},
+		Songs:      []models.Song{},
+		songs:      make(map[string]bool),
+	}
+}
+
+// Add appends the playlist and every song it references that is not in the
+// document yet.
+func (d *Document) Add(playlist *models.Playlist, entries []*models.PlaylistEntry) {
+	backup := Playlist{
+		ID:              playlist.ID,
+		Name:            playlist.Name,
+		DuplicatePolicy: playlist.DuplicatePolicy,
+		Entries:         make([]Entry, 0, len(entries)),
+	}
+	if playlist.Kind == models.PlaylistKindSmart {
+		backup.Kind = playlist.Kind
+		backup.Rules = playlist.Rules
+		backup.RefreshMode = playlist.RefreshMode
+	}
+
+	for _, entry := range entries {
+		backup.Entries = append(backup.Entries, Entry{
+			SongId:  entry.ID,
+			AddedAt: entry.AddedAt,
+			AddedBy: entry.AddedBy,
+			Note:    entry.Note,
+		})
+		if !d.songs[entry.ID] {
+			d.songs[entry.ID] = true
+			d.Songs = append(d.Songs, entry.Song)
+		}
+	}
+
+	d.Playlists = append(d.Playlists, backup)
+}
+
+// Entries resolves the playlist's entries to the song rows of the document, in
+// order. Decode has made sure every reference resolves.
+func (d *Document) Entries(playlist Playlist) []*models.PlaylistEntry {
+	songs := make(map[string]models.Song, len(d.Songs))
+	for _, song := range d.Songs {
+		songs[song.ID] = song
+	}
+
+	entries := make([]*models.PlaylistEntry, 0, len(playlist.Entries))
+	for index, entry := range playlist.Entries {
+		entries = append(entries, &models.PlaylistEntry{
+			Position: index + 1,
+			AddedAt:  entry.AddedAt,
+			AddedBy:  entry.AddedBy,
+			Note:     entry.Note,
+			Song:     songs[entry.SongId],
+		})
+	}
+	return entries
+}
+
+func Encode(w io.Writer, document *Document) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// Decode reads a document and checks it before anything is written: the version
+// must be known, playlists need a name, smart ones valid rules, and every entry a
+// song of the document.
+func Decode(r io.Reader) (*Document, error) {
+	var document Document
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, err
+	}
+
+	if document.Version == 0 {
+		return nil, ErrMissingVersion
+	}
+	if document.Version < 0 || document.Version > Version {
+		return nil, fmt.Errorf("%w: %d, this server reads versions 1 to %d", ErrUnsupportedVersion, document.Version, Version)
+	}
+	if document.Origin == "" {
+		return nil, errors.New("backup has no origin")
+	}
+
+	document.songs = make(map[string]bool, len(document.Songs))
+	for _, song := range document.Songs {
+		if song.ID == "" {
+			return nil, errors.New("backup has a song without id")
+		}
+		document.songs[song.ID] = true
+	}
+
+	seen := make(map[int]bool, len(document.Playlists))
+	for _, playlist := range document.Playlists {
+		if playlist.Name == "" {
+			return nil, fmt.Errorf("playlist %d has no name", playlist.ID)
+		}
+		if seen[playlist.ID] {
+			return nil, fmt.Errorf("playlist %d appears twice", playlist.ID)
+		}
+		seen[playlist.ID] = true
+
+		switch playlist.DuplicatePolicy {
+		case "", models.DuplicatePolicyAllow, models.DuplicatePolicyReject, models.DuplicatePolicySkip:
+		default:
+			return nil, fmt.Errorf("playlist %d has unknown duplicate policy %q", playlist.ID, playlist.DuplicatePolicy)
+		}
+
+		switch playlist.Kind {
+		case "", models.PlaylistKindManual:
+		case models.PlaylistKindSmart:
+			if playlist.Rules == nil {
+				return nil, fmt.Errorf("smart playlist %d has no rules", playlist.ID)
+			}
+			if err := rules.Validate(playlist.Rules); err != nil {
+				return nil, fmt.Errorf("smart playlist %d: %w", playlist.ID, err)
+			}
+			switch playlist.RefreshMode {
+			case "", models.RefreshOnRead, models.RefreshScheduled:
+			default:
+				return nil, fmt.Errorf("smart playlist %d has unknown refresh mode %q", playlist.ID, playlist.RefreshMode)
+			}
+		default:
+			return nil, fmt.Errorf("playlist %d has unknown kind %q", playlist.ID, playlist.Kind)
+		}
+
+		for _, entry := range playlist.Entries {
+			if !document.songs[entry.SongId] {
+				return nil, fmt.Errorf("playlist %d references unknown song %q", playlist.ID, entry.SongId)
+			}
+		}
+	}
+
+	return &document, nil
+}
@@ -0,0 +1,133 @@
+package backup
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	addedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	addedBy := 3
+	songA := models.Song{ID: "a1", Title: "Song A", Artist: "Artist X", Duration: 215}
+	songB := models.Song{ID: "b2", Title: "Song B", Artist: "Artist Y", Duration: 180}
+
+	document := New("3-1790000000", time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+	document.Add(&models.Playlist{ID: 7, Name: "Road trip", DuplicatePolicy: models.DuplicatePolicySkip}, []*models.PlaylistEntry{
+		{EntryId: 10, Position: 1, AddedAt: &addedAt, AddedBy: &addedBy, Note: "opener", Song: songA},
+		{EntryId: 11, Position: 2, Song: songB},
+	})
+	document.Add(&models.Playlist{ID: 9, Name: "Empty"}, nil)
+	document.Add(&models.Playlist{ID: 12, Name: "Repeat", DuplicatePolicy: models.DuplicatePolicyAllow}, []*models.PlaylistEntry{
+		{EntryId: 20, Position: 1, Song: songB},
+		{EntryId: 21, Position: 2, Song: songB},
+	})
+	smartRules := &models.SmartRules{Match: models.RuleNode{Field: "popularity", Op: ">", Value: []byte(`60`)}, Limit: 10}
+	document.Add(&models.Playlist{ID: 14, Name: "Hits", Kind: models.PlaylistKindSmart, Rules: smartRules, RefreshMode: models.RefreshOnRead},
+		[]*models.PlaylistEntry{{Position: 1, Song: songA}})
+
+	assert.Equal(t, []models.Song{songA, songB}, document.Songs, "songs are stored once")
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, document))
+
+	decoded, err := Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, Version, decoded.Version)
+	assert.Equal(t, "3-1790000000", decoded.Origin)
+	require.Len(t, decoded.Playlists, 4)
+
+	assert.Equal(t, []*models.PlaylistEntry{
+		{Position: 1, AddedAt: &addedAt, AddedBy: &addedBy, Note: "opener", Song: songA},
+		{Position: 2, Song: songB},
+	}, decoded.Entries(decoded.Playlists[0]))
+	assert.Empty(t, decoded.Entries(decoded.Playlists[1]))
+	assert.Equal(t, []*models.PlaylistEntry{
+		{Position: 1, Song: songB},
+		{Position: 2, Song: songB},
+	}, decoded.Entries(decoded.Playlists[2]))
+	assert.Empty(t, decoded.Playlists[0].Kind, "manual playlists don't spell out their kind")
+	assert.Equal(t, models.PlaylistKindSmart, decoded.Playlists[3].Kind)
+	assert.Equal(t, smartRules, decoded.Playlists[3].Rules)
+	assert.Equal(t, models.RefreshOnRead, decoded.Playlists[3].RefreshMode)
+	assert.Len(t, decoded.Entries(decoded.Playlists[3]), 1)
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name        string
+		document    string
+		expectedErr string
+	}{
+		{
+			name: "minimal document of the first version",
+			document: `{"version":1, "origin":"3-1790000000", "songs":[{"id":"a1","title":"Song A"}],
+				"playlists":[{"id":7, "name":"Road trip", "entries":[{"song_id":"a1"}]}]}`,
+		},
+		{
+			name:        "no version",
+			document:    `{"origin":"3-1790000000", "playlists":[]}`,
+			expectedErr: "backup has no version",
+		},
+		{
+			name:        "newer version",
+			document:    `{"version":2, "origin":"3-1790000000", "playlists":[]}`,
+			expectedErr: "unsupported backup version: 2, this server reads versions 1 to 1",
+		},
+		{
+			name:        "no origin",
+			document:    `{"version":1, "playlists":[]}`,
+			expectedErr: "backup has no origin",
+		},
+		{
+			name:        "unknown song",
+			document:    `{"version":1, "origin":"3-1", "playlists":[{"id":7, "name":"Road trip", "entries":[{"song_id":"a1"}]}]}`,
+			expectedErr: `playlist 7 references unknown song "a1"`,
+		},
+		{
+			name:        "playlist twice",
+			document:    `{"version":1, "origin":"3-1", "playlists":[{"id":7, "name":"A"}, {"id":7, "name":"B"}]}`,
+			expectedErr: "playlist 7 appears twice",
+		},
+		{
+			name:        "unnamed playlist",
+			document:    `{"version":1, "origin":"3-1", "playlists":[{"id":7}]}`,
+			expectedErr: "playlist 7 has no name",
+		},
+		{
+			name:        "unknown duplicate policy",
+			document:    `{"version":1, "origin":"3-1", "playlists":[{"id":7, "name":"A", "duplicate_policy":"merge"}]}`,
+			expectedErr: `playlist 7 has unknown duplicate policy "merge"`,
+		},
+		{
+			name:        "unknown kind",
+			document:    `{"version":1, "origin":"3-1", "playlists":[{"id":7, "name":"A", "kind":"radio"}]}`,
+			expectedErr: `playlist 7 has unknown kind "radio"`,
+		},
+		{
+			name:        "smart playlist without rules",
+			document:    `{"version":1, "origin":"3-1", "playlists":[{"id":7, "name":"A", "kind":"smart"}]}`,
+			expectedErr: "smart playlist 7 has no rules",
+		},
+		{
+			name:        "smart playlist with invalid rules",
+			document:    `{"version":1, "origin":"3-1", "playlists":[{"id":7, "name":"A", "kind":"smart", "rules":{"match":{}}}]}`,
+			expectedErr: "smart playlist 7: rule must define a condition or a group",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tt.document))
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
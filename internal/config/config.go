@@ -5,6 +5,7 @@ import (
 	"github.com/joho/godotenv"
 	"log"
 	"sync"
+	"time"
 )
 
 const (
@@ -29,7 +30,52 @@ type Config struct {
 	Spotify struct {
 		ClientID     string `yaml:"client_id" env:"CLIENT_ID"`
 		ClientSecret string `yaml:"client_secret" env:"CLIENT_SECRET"`
+
+		MaxRetries        int           `yaml:"max_retries" env-default:"3"`
+		BaseBackoff       time.Duration `yaml:"base_backoff" env-default:"200ms"`
+		MaxBackoff        time.Duration `yaml:"max_backoff" env-default:"5s"`
+		RequestsPerSecond float64       `yaml:"requests_per_second" env-default:"10"`
+		Burst             int           `yaml:"burst" env-default:"20"`
+		FailureThreshold  int           `yaml:"failure_threshold" env-default:"5"`
+		OpenTimeout       time.Duration `yaml:"open_timeout" env-default:"30s"`
 	} `yaml:"spotify"`
+	Catalog struct {
+		// Provider is spotify, or fake to serve a small demo catalog from memory.
+		Provider string `yaml:"provider" env:"CATALOG_PROVIDER" env-default:"spotify"`
+	} `yaml:"catalog"`
+	SmartPlaylists struct {
+		RefreshInterval time.Duration `yaml:"refresh_interval" env-default:"15m"`
+	} `yaml:"smart_playlists"`
+	Trash struct {
+		Retention     time.Duration `yaml:"retention" env-default:"720h"`
+		PurgeInterval time.Duration `yaml:"purge_interval" env-default:"1h"`
+	} `yaml:"trash"`
+	Quota struct {
+		MaxPlaylists         int `yaml:"max_playlists" env-default:"500"`
+		MaxTracksPerPlaylist int `yaml:"max_tracks_per_playlist" env-default:"10000"`
+		MaxRequestsPerDay    int `yaml:"max_requests_per_day" env-default:"20000"`
+		// PurgeInterval is how often the request counts of past days are deleted.
+		PurgeInterval time.Duration `yaml:"purge_interval" env-default:"24h"`
+	} `yaml:"quota"`
+	CatalogCache struct {
+		Size     int           `yaml:"size" env-default:"10000"`
+		TTL      time.Duration `yaml:"ttl" env-default:"24h"`
+		StaleTTL time.Duration `yaml:"stale_ttl" env-default:"168h"`
+		// BackfillInterval is how often songs stored without artist credits are re-fetched.
+		BackfillInterval time.Duration `yaml:"backfill_interval" env-default:"10m"`
+	} `yaml:"catalog_cache"`
+	AudioFeatures struct {
+		// BackfillInterval is how often songs stored without audio features are analysed,
+		// added tracks included.
+		BackfillInterval time.Duration `yaml:"backfill_interval" env-default:"1m"`
+	} `yaml:"audio_features"`
+	MetadataRefresh struct {
+		Interval          time.Duration `yaml:"interval" env-default:"1h"`
+		MaxAge            time.Duration `yaml:"max_age" env-default:"168h"`
+		BatchSize         int           `yaml:"batch_size" env-default:"50"`
+		Batches           int           `yaml:"batches" env-default:"20"`
+		RequestsPerSecond float64       `yaml:"requests_per_second" env-default:"2"`
+	} `yaml:"metadata_refresh"`
 }
 
 var Instance *Config
@@ -0,0 +1,342 @@
+// Package csvfile reads and writes playlists as CSV for use in spreadsheets.
+package csvfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"music-service/internal/models"
+	"music-service/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ContentType = "text/csv; charset=utf-8"
+	Extension   = ".csv"
+
+	byteOrderMark = "\uFEFF"
+)
+
+// Export columns. Song columns use the JSON names of models.Song, the rest describe
+// the playlist entry.
+const (
+	ColumnPosition    = "position"
+	ColumnEntryId     = "entry_id"
+	ColumnAddedAt     = "added_at"
+	ColumnAddedBy     = "added_by"
+	ColumnNote        = "note"
+	ColumnId          = "id"
+	ColumnTitle       = "title"
+	ColumnArtist      = "artist"
+	ColumnAlbum       = "album"
+	ColumnAlbumCover  = "album_cover"
+	ColumnDuration    = "duration"
+	ColumnReleaseDate = "release_date"
+	ColumnPopularity  = "popularity"
+	ColumnPreviewURL  = "preview_url"
+	ColumnExternalURL = "external_url"
+)
+
+// Import fields a header can be mapped to. Without a mapping a header matches the
+// field of the same name, url also matches external_url.
+const (
+	FieldId       = "id"
+	FieldURL      = "url"
+	FieldTitle    = "title"
+	FieldArtist   = "artist"
+	FieldAlbum    = "album"
+	FieldDuration = "duration"
+)
+
+var (
+	DefaultColumns = []string{ColumnPosition, ColumnTitle, ColumnArtist, ColumnAlbum, ColumnDuration,
+		ColumnId, ColumnExternalURL, ColumnAddedAt, ColumnNote}
+
+	columns = map[string]bool{
+		ColumnPosition: true, ColumnEntryId: true, ColumnAddedAt: true, ColumnAddedBy: true, ColumnNote: true,
+		ColumnId: true, ColumnTitle: true, ColumnArtist: true, ColumnAlbum: true, ColumnAlbumCover: true,
+		ColumnDuration: true, ColumnReleaseDate: true, ColumnPopularity: true, ColumnPreviewURL: true,
+		ColumnExternalURL: true,
+	}
+
+	fields = []string{FieldId, FieldURL, FieldTitle, FieldArtist, FieldAlbum, FieldDuration}
+
+	ErrNoTrackColumn = errors.New("csv needs an id, url or title column")
+	ErrEmptyDocument = errors.New("csv has no header row")
+)
+
+// formulaPrefixes start a formula in common spreadsheets, cells starting with them are
+// written with a leading quote so a crafted title can't run in the analyst's sheet.
+const formulaPrefixes = "=+-@\t\r"
+
+// ParseColumns validates a comma separated column list. An empty value selects the
+// default columns.
+func ParseColumns(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultColumns, nil
+	}
+
+	var selected []string
+	for _, column := range strings.Split(value, ",") {
+		column = strings.TrimSpace(column)
+		if !columns[column] {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+		selected = append(selected, column)
+	}
+	return selected, nil
+}
+
+// ParseMapping reads field:header pairs. The header may contain colons, the field
+// can't.
+func ParseMapping(values []string) (map[string]string, error) {
+	mapping := make(map[string]string, len(values))
+	for _, value := range values {
+		field, header, ok := strings.Cut(value, ":")
+		field = strings.TrimSpace(field)
+		if !ok || strings.TrimSpace(header) == "" || !isField(field) {
+			return nil, fmt.Errorf("mapping %q must be field:header with field one of %s", value, strings.Join(fields, ", "))
+		}
+		mapping[field] = strings.TrimSpace(header)
+	}
+	return mapping, nil
+}
+
+func Encode(w io.Writer, selected []string, entries []*models.PlaylistEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(selected); err != nil {
+		return err
+	}
+
+	record := make([]string, len(selected))
+	for _, entry := range entries {
+		for i, column := range selected {
+			record[i] = escape(value(entry, column))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Decode reads the rows as import items. Rows that can't be read keep their place
+// with Error set, so the caller can report them next to the rows without a match.
+func Decode(r io.Reader, mapping map[string]string) ([]*models.ImportItem, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrEmptyDocument
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := fieldIndex(header, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*models.ImportItem
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		item := readItem(record, index)
+		item.Row = line
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func fieldIndex(header []string, mapping map[string]string) (map[string]int, error) {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, byteOrderMark)
+		}
+		positions[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	index := make(map[string]int)
+	for _, field := range fields {
+		if name, mapped := mapping[field]; mapped {
+			position, ok := positions[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("column %q mapped to %s not found", name, field)
+			}
+			index[field] = position
+			continue
+		}
+
+		for _, name := range defaultHeaders(field) {
+			if position, ok := positions[name]; ok {
+				index[field] = position
+				break
+			}
+		}
+	}
+
+	_, hasId := index[FieldId]
+	_, hasURL := index[FieldURL]
+	_, hasTitle := index[FieldTitle]
+	if !hasId && !hasURL && !hasTitle {
+		return nil, ErrNoTrackColumn
+	}
+	return index, nil
+}
+
+func readItem(record []string, index map[string]int) *models.ImportItem {
+	get := func(field string) string {
+		position, ok := index[field]
+		if !ok || position >= len(record) {
+			return ""
+		}
+		return unescape(strings.TrimSpace(record[position]))
+	}
+
+	item := &models.ImportItem{
+		Title:  get(FieldTitle),
+		Artist: get(FieldArtist),
+		Album:  get(FieldAlbum),
+	}
+
+	if id := get(FieldId); id != "" {
+		item.SpotifyId = spotifyID(id)
+		if item.SpotifyId == "" {
+			item.Error = fmt.Sprintf("%q is not a Spotify id or url", id)
+			return item
+		}
+	}
+	if url := get(FieldURL); url != "" && item.SpotifyId == "" {
+		item.SpotifyId = utils.ParseSpotifyTrackID(url)
+	}
+
+	if duration := get(FieldDuration); duration != "" {
+		seconds, err := parseDuration(duration)
+		if err != nil {
+			item.Error = fmt.Sprintf("duration %q must be seconds or m:ss", duration)
+			return item
+		}
+		item.Duration = seconds
+	}
+
+	if item.SpotifyId == "" && item.Title == "" {
+		item.Error = "row has neither a Spotify id nor a title"
+	}
+	return item
+}
+
+func spotifyID(value string) string {
+	if utils.IsSpotifyID(value) {
+		return value
+	}
+	return utils.ParseSpotifyTrackID(value)
+}
+
+// parseDuration accepts plain seconds as exported and m:ss as typed by people.
+func parseDuration(value string) (int, error) {
+	minutes, seconds, found := strings.Cut(value, ":")
+	if !found {
+		return strconv.Atoi(value)
+	}
+
+	m, err := strconv.Atoi(minutes)
+	if err != nil {
+		return 0, err
+	}
+	s, err := strconv.Atoi(seconds)
+	if err != nil || s >= 60 {
+		return 0, errors.New("invalid seconds")
+	}
+	return m*60 + s, nil
+}
+
+func value(entry *models.PlaylistEntry, column string) string {
+	song := entry.Song
+	switch column {
+	case ColumnPosition:
+		return strconv.Itoa(entry.Position)
+	case ColumnEntryId:
+		return strconv.Itoa(entry.EntryId)
+	case ColumnAddedAt:
+		if entry.AddedAt == nil {
+			return ""
+		}
+		return entry.AddedAt.UTC().Format(time.RFC3339)
+	case ColumnAddedBy:
+		if entry.AddedBy == nil {
+			return ""
+		}
+		return strconv.Itoa(*entry.AddedBy)
+	case ColumnNote:
+		return entry.Note
+	case ColumnId:
+		return song.ID
+	case ColumnTitle:
+		return song.Title
+	case ColumnArtist:
+		return song.Artist
+	case ColumnAlbum:
+		return song.Album
+	case ColumnAlbumCover:
+		return song.AlbumCover
+	case ColumnDuration:
+		return strconv.Itoa(song.Duration)
+	case ColumnReleaseDate:
+		return song.ReleaseDate
+	case ColumnPopularity:
+		return strconv.Itoa(song.Popularity)
+	case ColumnPreviewURL:
+		return song.PreviewURL
+	case ColumnExternalURL:
+		return song.ExternalURL
+	}
+	return ""
+}
+
+func escape(cell string) string {
+	if cell != "" && strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func unescape(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(cell[1])) {
+		return cell[1:]
+	}
+	return cell
+}
+
+// defaultHeaders lets an exported file be imported again without a mapping.
+func defaultHeaders(field string) []string {
+	if field == FieldURL {
+		return []string{FieldURL, ColumnExternalURL}
+	}
+	return []string{field}
+}
+
+func isField(field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package csvfile
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultColumns, columns)
+
+	columns, err = ParseColumns("title, artist,note")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title", "artist", "note"}, columns)
+
+	_, err = ParseColumns("title,password")
+	assert.EqualError(t, err, `unknown column "password"`)
+}
+
+func TestParseMapping(t *testing.T) {
+	mapping, err := ParseMapping([]string{"title:Track Name", "artist: Artist: Main"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"title": "Track Name", "artist": "Artist: Main"}, mapping)
+
+	_, err = ParseMapping([]string{"genre:Genre"})
+	assert.Error(t, err)
+
+	_, err = ParseMapping([]string{"title"})
+	assert.Error(t, err)
+}
+
+func TestEncode(t *testing.T) {
+	addedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	addedBy := 3
+	entries := []*models.PlaylistEntry{
+		{EntryId: 10, Position: 1, AddedAt: &addedAt, AddedBy: &addedBy, Note: "opener, loud",
+			Song: models.Song{ID: "a1", Title: "Song A", Artist: "Artist X", Duration: 215}},
+		{EntryId: 11, Position: 2, Song: models.Song{ID: "b2", Title: "=HYPERLINK(\"x\")", Artist: "Artist Y"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, []string{"position", "title", "artist", "duration", "added_at", "added_by", "note"}, entries))
+
+	expected := "position,title,artist,duration,added_at,added_by,note\n" +
+		"1,Song A,Artist X,215,2026-10-01T12:00:00Z,3,\"opener, loud\"\n" +
+		"2,\"'=HYPERLINK(\"\"x\"\")\",Artist Y,0,,,\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name          string
+		document      string
+		mapping       map[string]string
+		expectedItems []*models.ImportItem
+		expectedErr   string
+	}{
+		{
+			name: "ids, urls and search rows",
+			document: "\uFEFFTitle,Artist,Duration,ID,URL\n" +
+				"Song A,Artist X,215,4uLU6hMCjMI75M1A2tKUQC,\n" +
+				"Song B,Artist Y,3:05,,https://open.spotify.com/track/6rqhFgbbKwnb9MLmUQDhG6\n" +
+				"Song C,Artist Z,,,\n",
+			expectedItems: []*models.ImportItem{
+				{Row: 2, Title: "Song A", Artist: "Artist X", Duration: 215, SpotifyId: "4uLU6hMCjMI75M1A2tKUQC"},
+				{Row: 3, Title: "Song B", Artist: "Artist Y", Duration: 185, SpotifyId: "6rqhFgbbKwnb9MLmUQDhG6"},
+				{Row: 4, Title: "Song C", Artist: "Artist Z"},
+			},
+		},
+		{
+			name:     "header mapping",
+			document: "Track Name,Artist Name\nSong A,Artist X\n",
+			mapping:  map[string]string{"title": "track name", "artist": "Artist Name"},
+			expectedItems: []*models.ImportItem{
+				{Row: 2, Title: "Song A", Artist: "Artist X"},
+			},
+		},
+		{
+			name:     "broken rows keep their place",
+			document: "title,id,duration\nSong A,not-an-id,\nSong B,,long\n,,\n",
+			expectedItems: []*models.ImportItem{
+				{Row: 2, Title: "Song A", Error: `"not-an-id" is not a Spotify id or url`},
+				{Row: 3, Title: "Song B", Error: `duration "long" must be seconds or m:ss`},
+				{Row: 4, Error: "row has neither a Spotify id nor a title"},
+			},
+		},
+		{
+			name:        "mapped column missing",
+			document:    "Name\nSong A\n",
+			mapping:     map[string]string{"title": "Track Name"},
+			expectedErr: `column "Track Name" mapped to title not found`,
+		},
+		{
+			name:        "no track column",
+			document:    "artist\nArtist X\n",
+			expectedErr: "csv needs an id, url or title column",
+		},
+		{
+			name:        "empty document",
+			document:    "",
+			expectedErr: "csv has no header row",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := Decode(strings.NewReader(tt.document), tt.mapping)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedItems, items)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	entries := []*models.PlaylistEntry{
+		{Position: 1, Song: models.Song{ID: "4uLU6hMCjMI75M1A2tKUQC", Title: "-Intro-", Artist: "Artist X", Duration: 60}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, DefaultColumns, entries))
+
+	items, err := Decode(&buf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.ImportItem{
+		{Row: 2, Title: "-Intro-", Artist: "Artist X", Duration: 60, SpotifyId: "4uLU6hMCjMI75M1A2tKUQC"},
+	}, items)
+}
+
+func TestRoundTripExternalURL(t *testing.T) {
+	entries := []*models.PlaylistEntry{
+		{Position: 1, Song: models.Song{Title: "Song A", ExternalURL: "https://open.spotify.com/track/6rqhFgbbKwnb9MLmUQDhG6"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, []string{ColumnTitle, ColumnExternalURL}, entries))
+
+	items, err := Decode(&buf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.ImportItem{
+		{Row: 2, Title: "Song A", SpotifyId: "6rqhFgbbKwnb9MLmUQDhG6"},
+	}, items)
+}
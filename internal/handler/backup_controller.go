@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"music-service/internal/backup"
+	"music-service/pkg/utils"
+	"net/http"
+)
+
+const maxBackupSize = 50 << 20
+
+// HandleExportBackup
+// @Summary Back up the library
+// @Tags backup
+// @Description Downloads every playlist outside the trash with its ordered entries and all referenced song rows as one versioned JSON document. Smart playlists are saved with their rules and the tracks those match now
+// @Produce  json
+// @Success 200 {object} backup.Document "Backup"
+// @Failure 500 {object} error "internal server error"
+// @Router /me/backup [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleExportBackup(writer http.ResponseWriter, request *http.Request) {
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	document, err := h.services.Backup.ExportBackup(userId)
+	if err != nil {
+		h.log.Error("HANDLER: error exporting backup: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", backup.ContentType)
+	writer.Header().Set("Content-Disposition", attachment("backup-"+document.ExportedAt.Format("2006-01-02"), backup.Extension))
+	writer.WriteHeader(http.StatusOK)
+
+	if err := backup.Encode(writer, document); err != nil {
+		h.log.Error("HANDLER: error streaming backup: ", err)
+		return
+	}
+
+	h.log.Info("HANDLER: backup exported: ", userId, len(document.Playlists))
+}
+
+// HandleRestoreBackup
+// @Summary Restore a backup
+// @Tags backup
+// @Description Creates the playlists of a backup document in this account under new ids. Tracks are looked up in the catalog by id, entries it doesn't know are left out and counted in missing_tracks. Restoring the same backup again skips the playlists it already created, unless they were deleted since. Smart playlists come back with their rules, matched against this library
+// @Accept  json
+// @Produce  json
+// @Param input body backup.Document true "Backup document"
+// @Success 200 {object} models.RestoreReport "Restore report"
+// @Failure 400 {object} error "invalid or unsupported backup"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 500 {object} error "internal server error"
+// @Router /me/restore [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleRestoreBackup(writer http.ResponseWriter, request *http.Request) {
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	document, err := backup.Decode(http.MaxBytesReader(writer, request.Body, maxBackupSize))
+	if err != nil {
+		h.log.Error("HANDLER: error parsing backup: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := h.services.Backup.RestoreBackup(userId, document)
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error restoring backup: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: backup restored: ", userId, report.Created, report.Skipped)
+	utils.WriteJSON(writer, http.StatusOK, report)
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/backup"
+	"music-service/internal/models"
+	"music-service/internal/repository"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_HandleExportBackup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	backupService := mock_service.NewMockBackup(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Backup: backupService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	document := backup.New("1-1790000000", time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+	document.Add(&models.Playlist{ID: 7, Name: "Road trip", DuplicatePolicy: models.DuplicatePolicyAllow}, []*models.PlaylistEntry{
+		{EntryId: 10, Position: 1, Note: "opener", Song: models.Song{ID: "a1", Title: "Song A", Artist: "Artist X", Duration: 215}},
+	})
+	backupService.EXPECT().ExportBackup(1).Return(document, nil)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/me/backup", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleExportBackup).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.Equal(t, backup.ContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="backup-2026-10-18.json"`, rec.Header().Get("Content-Disposition"))
+	assert.JSONEq(t, `{"version":1, "origin":"1-1790000000", "exported_at":"2026-10-18T12:00:00Z",
+		"playlists":[{"id":7, "name":"Road trip", "duplicate_policy":"allow", "entries":[{"song_id":"a1", "note":"opener"}]}],
+		"songs":[{"id":"a1", "title":"Song A", "artist":"Artist X", "album":"", "album_cover":"", "duration":215,
+			"release_date":"", "popularity":0, "preview_url":"", "external_url":""}]}`, rec.Body.String())
+}
+
+func TestHandler_HandleRestoreBackup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	backupService := mock_service.NewMockBackup(ctrl)
+	quotaService := mock_service.NewMockQuota(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Backup: backupService,
+			Quota:  quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	document := `{"version":1, "origin":"1-1790000000", "songs":[{"id":"a1","title":"Song A"},{"id":"b2","title":"Song B"}],
+		"playlists":[{"id":7, "name":"Road trip", "entries":[{"song_id":"a1"},{"song_id":"b2"}]},
+			{"id":9, "name":"Calm", "entries":[{"song_id":"b2"}]}]}`
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "restored",
+			body: document,
+			mockSetup: func() {
+				backupService.EXPECT().RestoreBackup(1, gomock.Any()).Return(&models.RestoreReport{
+					Created: 1,
+					Skipped: 1,
+					Playlists: []*models.RestoredPlaylist{
+						{SourceId: 7, PlaylistId: 21, Name: "Road trip", Status: models.RestoreStatusSkipped},
+						{SourceId: 9, PlaylistId: 22, Name: "Calm", Status: models.RestoreStatusCreated},
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"created":1, "skipped":1, "playlists":[
+				{"source_id":7, "playlist_id":21, "name":"Road trip", "status":"skipped"},
+				{"source_id":9, "playlist_id":22, "name":"Calm", "status":"created"}]}`,
+		},
+		{
+			name:           "newer version",
+			body:           `{"version":2, "origin":"1-1790000000", "playlists":[]}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"unsupported backup version: 2, this server reads versions 1 to 1"}`,
+		},
+		{
+			name: "too many playlists",
+			body: document,
+			mockSetup: func() {
+				backupService.EXPECT().RestoreBackup(1, gomock.Any()).
+					Return(nil, fmt.Errorf("%w: at most 2 playlists allowed", repository.ErrQuotaExceeded))
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"quota exceeded: at most 2 playlists allowed"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/me/restore", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleRestoreBackup).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/pkg/utils"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	maxSearchOffset    = 1000
+
+	// defaultMarket is used for artist top tracks when no market is given, Spotify
+	// only ranks them per market.
+	defaultMarket = "US"
+)
+
+var (
+	errEmptySearchQuery   = errors.New("q is required")
+	errInvalidSearchType  = errors.New("type must be a comma separated list of track, album and artist")
+	errInvalidLimit       = fmt.Errorf("limit must be between 1 and %d", maxSearchLimit)
+	errInvalidOffset      = fmt.Errorf("offset must be between 0 and %d", maxSearchOffset)
+	errInvalidMarket      = errors.New("market must be an ISO 3166-1 alpha-2 country code")
+	errInvalidAlbumId     = errors.New("album id must be a Spotify id")
+	errInvalidArtistId    = errors.New("artist id must be a Spotify id")
+	errAlbumNotFound      = errors.New("album not found")
+	errArtistNotFound     = errors.New("artist not found")
+	errCatalogUnavailable = errors.New("catalog is unavailable, try again later")
+)
+
+// HandleSearch
+// @Summary Search the catalog
+// @Tags catalog
+// @Description Searches Spotify for tracks, albums and artists. Every requested type gets its own page, limit and offset apply to each of them
+// @Accept  json
+// @Produce  json
+// @Param q query string true "Search query, Spotify field filters such as artist: or year: work too"
+// @Param type query string false "Comma separated track, album, artist, defaults to track"
+// @Param limit query int false "Items per type, 1 to 50, defaults to 20"
+// @Param offset query int false "Index of the first item, 0 to 1000"
+// @Param market query string false "ISO 3166-1 alpha-2 country code, only content playable there is returned"
+// @Success 200 {object} models.SearchResult "Search result"
+// @Failure 400 {object} error "invalid query"
+// @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
+// @Router /search [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleSearch(writer http.ResponseWriter, request *http.Request) {
+	query, err := parseSearchQuery(request.URL.Query())
+	if err != nil {
+		h.log.Error("HANDLER: error parsing search query: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := h.services.Catalog.Search(query)
+	if errors.Is(err, provider.ErrUnavailable) {
+		h.log.Error("HANDLER: catalog unavailable: ", err)
+		utils.WriteError(writer, http.StatusServiceUnavailable, errCatalogUnavailable)
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error searching catalog: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: catalog searched: ", query.Query, query.Types)
+	utils.WriteJSON(writer, http.StatusOK, result)
+}
+
+func parseSearchQuery(values url.Values) (*models.SearchQuery, error) {
+	query := &models.SearchQuery{
+		Query: strings.TrimSpace(values.Get("q")),
+		Types: []string{models.SearchTypeTrack},
+		Limit: defaultSearchLimit,
+	}
+	if query.Query == "" {
+		return nil, errEmptySearchQuery
+	}
+
+	if types := values.Get("type"); types != "" {
+		query.Types = query.Types[:0]
+		seen := make(map[string]bool)
+		for _, name := range strings.Split(types, ",") {
+			name = strings.TrimSpace(name)
+			switch name {
+			case models.SearchTypeTrack, models.SearchTypeAlbum, models.SearchTypeArtist:
+			default:
+				return nil, errInvalidSearchType
+			}
+			if !seen[name] {
+				seen[name] = true
+				query.Types = append(query.Types, name)
+			}
+		}
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			return nil, errInvalidLimit
+		}
+		query.Limit = parsed
+	}
+
+	if offset := values.Get("offset"); offset != "" {
+		parsed, err := strconv.Atoi(offset)
+		if err != nil || parsed < 0 || parsed > maxSearchOffset {
+			return nil, errInvalidOffset
+		}
+		query.Offset = parsed
+	}
+
+	if market := values.Get("market"); market != "" {
+		market, err := parseMarket(market)
+		if err != nil {
+			return nil, err
+		}
+		query.Market = market
+	}
+
+	return query, nil
+}
+
+func parseMarket(market string) (string, error) {
+	market = strings.ToUpper(market)
+	if len(market) != 2 || strings.IndexFunc(market, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+		return "", errInvalidMarket
+	}
+	return market, nil
+}
+
+// HandleGetAlbum
+// @Summary Get album
+// @Tags catalog
+// @Description Get the album with all its tracks in disc and track order
+// @Produce  json
+// @Param albumId path string true "Spotify album ID"
+// @Success 200 {object} models.AlbumDetails "Album"
+// @Failure 400 {object} error "invalid album id"
+// @Failure 404 {object} error "album not found"
+// @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
+// @Router /albums/{albumId} [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetAlbum(writer http.ResponseWriter, request *http.Request) {
+	albumId := chi.URLParam(request, "albumId")
+	if !utils.IsSpotifyID(albumId) {
+		h.log.Error("HANDLER: error getting album id: ", albumId)
+		utils.WriteError(writer, http.StatusBadRequest, errInvalidAlbumId)
+		return
+	}
+
+	album, err := h.services.Song.GetAlbum(albumId)
+	if errors.Is(err, provider.ErrNotFound) {
+		h.log.Error("HANDLER: album not found: ", albumId)
+		utils.WriteError(writer, http.StatusNotFound, errAlbumNotFound)
+		return
+	}
+	if errors.Is(err, provider.ErrUnavailable) {
+		h.log.Error("HANDLER: catalog unavailable: ", err)
+		utils.WriteError(writer, http.StatusServiceUnavailable, errCatalogUnavailable)
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error getting album from spotify: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: album found: ", albumId)
+	utils.WriteJSON(writer, http.StatusOK, album)
+}
+
+// HandleGetArtist
+// @Summary Get artist
+// @Tags catalog
+// @Description Get the artist with the top tracks in the market and the first page of albums and singles available there
+// @Produce  json
+// @Param artistId path string true "Spotify artist ID"
+// @Param market query string false "ISO 3166-1 alpha-2 country code, defaults to US"
+// @Success 200 {object} models.ArtistDetails "Artist"
+// @Failure 400 {object} error "invalid artist id or market"
+// @Failure 404 {object} error "artist not found"
+// @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
+// @Router /artists/{artistId} [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetArtist(writer http.ResponseWriter, request *http.Request) {
+	artistId := chi.URLParam(request, "artistId")
+	if !utils.IsSpotifyID(artistId) {
+		h.log.Error("HANDLER: error getting artist id: ", artistId)
+		utils.WriteError(writer, http.StatusBadRequest, errInvalidArtistId)
+		return
+	}
+
+	market := defaultMarket
+	if value := request.URL.Query().Get("market"); value != "" {
+		parsed, err := parseMarket(value)
+		if err != nil {
+			h.log.Error("HANDLER: error parsing market: ", value)
+			utils.WriteError(writer, http.StatusBadRequest, err)
+			return
+		}
+		market = parsed
+	}
+
+	artist, err := h.services.Song.GetArtist(artistId, market)
+	if errors.Is(err, provider.ErrNotFound) {
+		h.log.Error("HANDLER: artist not found: ", artistId)
+		utils.WriteError(writer, http.StatusNotFound, errArtistNotFound)
+		return
+	}
+	if errors.Is(err, provider.ErrUnavailable) {
+		h.log.Error("HANDLER: catalog unavailable: ", err)
+		utils.WriteError(writer, http.StatusServiceUnavailable, errCatalogUnavailable)
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error getting artist from spotify: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: artist found: ", artistId, market)
+	utils.WriteJSON(writer, http.StatusOK, artist)
+}
+
+// HandleAddAlbumToPlaylist
+// @Summary Insert album
+// @Tags tracks
+// @Description Appends every track of the album in disc and track order the same way a single insert does. Tracks the playlist's duplicate policy rejects are listed instead of failing the request
+// @Produce  json
+// @Param albumId path string true "Spotify album ID"
+// @Param playlistId path int true "Playlist ID"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
+// @Success 200 {object} models.AddTracksReport "Added tracks"
+// @Failure 400 {object} error "invalid album id"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 404 {object} error "album not found"
+// @Failure 412 {object} error "playlist was changed by another request"
+// @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
+// @Router /albums/{albumId}/playlist/{playlistId} [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleAddAlbumToPlaylist(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	albumId := chi.URLParam(request, "albumId")
+	if !utils.IsSpotifyID(albumId) {
+		h.log.Error("HANDLER: error getting album id: ", albumId)
+		utils.WriteError(writer, http.StatusBadRequest, errInvalidAlbumId)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	album, err := h.services.Song.GetAlbum(albumId)
+	if errors.Is(err, provider.ErrNotFound) {
+		h.log.Error("HANDLER: album not found: ", albumId)
+		utils.WriteError(writer, http.StatusNotFound, errAlbumNotFound)
+		return
+	}
+	if errors.Is(err, provider.ErrUnavailable) {
+		h.log.Error("HANDLER: catalog unavailable: ", err)
+		utils.WriteError(writer, http.StatusServiceUnavailable, errCatalogUnavailable)
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error getting album from spotify: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	report, err := h.services.Song.CreateSongs(change, album.Tracks)
+	if h.versionMismatch(writer, err) {
+		return
+	}
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error inserting album to playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	setVersion(writer, change)
+	h.log.Info("HANDLER: album inserted to playlist: ", albumId, playlistId, len(report.Songs), len(report.Rejected))
+	utils.WriteJSON(writer, http.StatusOK, report)
+}
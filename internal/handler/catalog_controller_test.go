@@ -0,0 +1,424 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/repository"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_HandleSearch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	catalogService := mock_service.NewMockCatalog(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Catalog: catalogService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "tracks by default",
+			query: "?q=teardrop",
+			mockSetup: func() {
+				catalogService.EXPECT().Search(&models.SearchQuery{
+					Query: "teardrop", Types: []string{models.SearchTypeTrack}, Limit: 20,
+				}).Return(&models.SearchResult{Tracks: &models.TrackPage{
+					Items: []models.Song{{ID: "67Hna13dNDkZvBpTXRIaOJ", Title: "Teardrop", Artist: "Massive Attack"}},
+					Total: 31, Limit: 20,
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"tracks":{"items":[{"id":"67Hna13dNDkZvBpTXRIaOJ", "title":"Teardrop", "artist":"Massive Attack",
+				"album":"", "album_cover":"", "duration":0, "release_date":"", "popularity":0, "preview_url":"", "external_url":""}],
+				"total":31, "limit":20, "offset":0}}`,
+		},
+		{
+			name:  "several types, page and market",
+			query: "?q=massive+attack&type=artist,album,artist&limit=5&offset=10&market=gb",
+			mockSetup: func() {
+				catalogService.EXPECT().Search(&models.SearchQuery{
+					Query: "massive attack", Types: []string{models.SearchTypeArtist, models.SearchTypeAlbum},
+					Limit: 5, Offset: 10, Market: "GB",
+				}).Return(&models.SearchResult{
+					Albums:  &models.AlbumPage{Items: []models.Album{}, Total: 0, Limit: 5, Offset: 10},
+					Artists: &models.ArtistPage{Items: []models.Artist{}, Total: 0, Limit: 5, Offset: 10},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"albums":{"items":[], "total":0, "limit":5, "offset":10},
+				"artists":{"items":[], "total":0, "limit":5, "offset":10}}`,
+		},
+		{
+			name:           "missing query",
+			query:          "?q=+",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"q is required"}`,
+		},
+		{
+			name:           "unknown type",
+			query:          "?q=teardrop&type=track,playlist",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"type must be a comma separated list of track, album and artist"}`,
+		},
+		{
+			name:           "limit too large",
+			query:          "?q=teardrop&limit=51",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"limit must be between 1 and 50"}`,
+		},
+		{
+			name:           "negative offset",
+			query:          "?q=teardrop&offset=-1",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"offset must be between 0 and 1000"}`,
+		},
+		{
+			name:           "invalid market",
+			query:          "?q=teardrop&market=GBR",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"market must be an ISO 3166-1 alpha-2 country code"}`,
+		},
+		{
+			name:  "catalog unavailable",
+			query: "?q=teardrop",
+			mockSetup: func() {
+				catalogService.EXPECT().Search(gomock.Any()).Return(nil, errors.New("service unavailable"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"service unavailable"}`,
+		},
+		{
+			name:  "spotify rate limited",
+			query: "?q=teardrop",
+			mockSetup: func() {
+				catalogService.EXPECT().Search(gomock.Any()).Return(nil, errCatalogRateLimited)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"error":"catalog is unavailable, try again later"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/search"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleSearch).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+const mezzanineId = "49MNmJhZQewjt06rpwp6QR"
+
+var (
+	errCatalogNotFound    = fmt.Errorf("%w: non existing id", provider.ErrNotFound)
+	errCatalogRateLimited = fmt.Errorf("%w: API rate limit exceeded", provider.ErrRateLimited)
+)
+
+func TestHandler_HandleGetAlbum(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	songService := mock_service.NewMockSong(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Song: songService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		albumId        string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:    "album with tracks",
+			albumId: mezzanineId,
+			mockSetup: func() {
+				songService.EXPECT().GetAlbum(mezzanineId).Return(&models.AlbumDetails{
+					Album:       models.Album{ID: mezzanineId, Name: "Mezzanine", Artist: "Massive Attack", AlbumType: "album"},
+					TotalTracks: 1,
+					Tracks:      []models.Song{{ID: "67Hna13dNDkZvBpTXRIaOJ", Title: "Teardrop", Artist: "Massive Attack", Album: "Mezzanine"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"id":"` + mezzanineId + `", "name":"Mezzanine", "artist":"Massive Attack", "album_type":"album",
+				"album_cover":"", "release_date":"", "external_url":"", "total_tracks":1,
+				"tracks":[{"id":"67Hna13dNDkZvBpTXRIaOJ", "title":"Teardrop", "artist":"Massive Attack", "album":"Mezzanine",
+				"album_cover":"", "duration":0, "release_date":"", "popularity":0, "preview_url":"", "external_url":""}]}`,
+		},
+		{
+			name:           "invalid album id",
+			albumId:        "mezzanine",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"album id must be a Spotify id"}`,
+		},
+		{
+			name:    "unknown album",
+			albumId: mezzanineId,
+			mockSetup: func() {
+				songService.EXPECT().GetAlbum(mezzanineId).Return(nil, errCatalogNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"album not found"}`,
+		},
+		{
+			name:    "spotify error",
+			albumId: mezzanineId,
+			mockSetup: func() {
+				songService.EXPECT().GetAlbum(mezzanineId).Return(nil, errors.New("service unavailable"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"service unavailable"}`,
+		},
+		{
+			name:    "spotify unavailable",
+			albumId: mezzanineId,
+			mockSetup: func() {
+				songService.EXPECT().GetAlbum(mezzanineId).Return(nil, fmt.Errorf("get album: %w", provider.ErrUnavailable))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"error":"catalog is unavailable, try again later"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("albumId", tt.albumId)
+
+			req, _ := http.NewRequest(http.MethodGet, "/albums/"+tt.albumId, nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleGetAlbum).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_HandleGetArtist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	songService := mock_service.NewMockSong(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Song: songService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	const artistId = "6FXMGgJwohJLUSr5nVlf9X"
+	artist := &models.ArtistDetails{
+		Artist:    models.Artist{ID: artistId, Name: "Massive Attack", Genres: []string{"trip hop"}, Popularity: 70},
+		TopTracks: []models.Song{},
+		Albums:    &models.AlbumPage{Items: []models.Album{}, Total: 0, Limit: 50},
+	}
+	artistBody := `{"id":"` + artistId + `", "name":"Massive Attack", "genres":["trip hop"], "popularity":70, "image":"",
+		"external_url":"", "top_tracks":[], "albums":{"items":[], "total":0, "limit":50, "offset":0}}`
+
+	tests := []struct {
+		name           string
+		artistId       string
+		query          string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:     "default market",
+			artistId: artistId,
+			mockSetup: func() {
+				songService.EXPECT().GetArtist(artistId, "US").Return(artist, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   artistBody,
+		},
+		{
+			name:     "given market",
+			artistId: artistId,
+			query:    "?market=gb",
+			mockSetup: func() {
+				songService.EXPECT().GetArtist(artistId, "GB").Return(artist, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   artistBody,
+		},
+		{
+			name:           "invalid market",
+			artistId:       artistId,
+			query:          "?market=G1",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"market must be an ISO 3166-1 alpha-2 country code"}`,
+		},
+		{
+			name:           "invalid artist id",
+			artistId:       "massive-attack",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"artist id must be a Spotify id"}`,
+		},
+		{
+			name:     "unknown artist",
+			artistId: artistId,
+			mockSetup: func() {
+				songService.EXPECT().GetArtist(artistId, "US").Return(nil, errCatalogNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"artist not found"}`,
+		},
+		{
+			name:     "spotify failing",
+			artistId: artistId,
+			mockSetup: func() {
+				songService.EXPECT().GetArtist(artistId, "US").Return(nil, fmt.Errorf("%w: bad gateway", provider.ErrUnavailable))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"error":"catalog is unavailable, try again later"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("artistId", tt.artistId)
+
+			req, _ := http.NewRequest(http.MethodGet, "/artists/"+tt.artistId+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleGetArtist).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_HandleAddAlbumToPlaylist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	songService := mock_service.NewMockSong(ctrl)
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	quotaService := mock_service.NewMockQuota(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Song:     songService,
+			PlayList: playlistService,
+			Quota:    quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	const (
+		trackA = "4uLU6hMCjMI75M1A2tKUQC"
+		trackB = "6rqhFgbbKwnb9MLmUQDhG6"
+	)
+	album := &models.AlbumDetails{
+		Album:       models.Album{ID: mezzanineId, Name: "Mezzanine"},
+		TotalTracks: 2,
+		Tracks:      []models.Song{{ID: trackA, Title: "Angel"}, {ID: trackB, Title: "Risingson"}},
+	}
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "tracks added in album order",
+			mockSetup: func() {
+				songService.EXPECT().GetAlbum(mezzanineId).Return(album, nil)
+				songService.EXPECT().CreateSongs(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, album.Tracks).
+					Return(&models.AddTracksReport{Songs: album.Tracks[:1], Rejected: []string{trackB}, SnapshotId: 5}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"songs":[{"id":"` + trackA + `", "title":"Angel", "artist":"", "album":"", "album_cover":"",
+				"duration":0, "release_date":"", "popularity":0, "preview_url":"", "external_url":""}],
+				"rejected":["` + trackB + `"], "snapshot_id":5}`,
+		},
+		{
+			name: "unknown album",
+			mockSetup: func() {
+				songService.EXPECT().GetAlbum(mezzanineId).Return(nil, errCatalogNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"album not found"}`,
+		},
+		{
+			name: "track quota exceeded",
+			mockSetup: func() {
+				songService.EXPECT().GetAlbum(mezzanineId).Return(album, nil)
+				songService.EXPECT().CreateSongs(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, album.Tracks).
+					Return(nil, fmt.Errorf("%w: at most 3 tracks per playlist allowed", repository.ErrQuotaExceeded))
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"quota exceeded: at most 3 tracks per playlist allowed"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("albumId", mezzanineId)
+			chiCtx.URLParams.Add("playlistId", "1")
+
+			req, _ := http.NewRequest(http.MethodPost, "/albums/"+mezzanineId+"/playlist/1", nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleAddAlbumToPlaylist).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
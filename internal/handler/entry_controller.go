@@ -0,0 +1,439 @@
+package handler
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"music-service/internal/models"
+	"music-service/pkg/utils"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errInvalidEntrySort    = errors.New("sort must be one of position, added_at, -added_at or an audio feature such as tempo or -energy")
+	errInvalidFeatureBound = errors.New("audio feature bounds must be numbers")
+	errInvalidKey          = errors.New("key must be a pitch class from 0 (C) to 11 (B)")
+	errInvalidMode         = errors.New("mode must be 0 (minor) or 1 (major)")
+)
+
+// HandleGetPlaylistEntries
+// @Summary Get playlist entries
+// @Tags entries
+// @Description Get ordered playlist entries, each with its own entry id
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Success 200 {array} models.PlaylistEntry "Entries"
+// @Failure 400 {object} error "invalid playlist id"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/entries [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetPlaylistEntries(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	entries, err := h.services.Entry.GetPlaylistEntries(userId, playlistId)
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist entries: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist entries found: ", len(entries))
+	utils.WriteJSON(writer, http.StatusOK, entries)
+}
+
+// HandleMovePlaylistEntry
+// @Summary Move playlist entry
+// @Tags entries
+// @Description Move a single playlist entry to a new 1-based position
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param entryId path int true "Entry ID"
+// @Param input body models.MoveEntryDto true "Target position"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
+// @Success 200 {object} map[string]interface{} "Entry moved"
+// @Failure 400 {object} error "invalid input"
+// @Failure 412 {object} error "playlist was changed by another request"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/entries/{entryId} [put]
+// @Security ApiKeyAuth
+func (h *Handler) HandleMovePlaylistEntry(writer http.ResponseWriter, request *http.Request) {
+	var input models.MoveEntryDto
+
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	entryId, err := strconv.Atoi(chi.URLParam(request, "entryId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting entry id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	err = h.services.Entry.MovePlaylistEntry(change, entryId, input.Position)
+	if h.versionMismatch(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error moving playlist entry: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	setVersion(writer, change)
+	h.log.Info("HANDLER: playlist entry moved: ", entryId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":          entryId,
+		"position":    input.Position,
+		"snapshot_id": change.SnapshotId,
+	})
+}
+
+// HandleDeletePlaylistEntry
+// @Summary Delete playlist entry
+// @Tags entries
+// @Description Remove a single occurrence of a track from the playlist
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param entryId path int true "Entry ID"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
+// @Success 200 {object} map[string]interface{} "Entry removed"
+// @Failure 400 {object} error "invalid id"
+// @Failure 412 {object} error "playlist was changed by another request"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/entries/{entryId} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) HandleDeletePlaylistEntry(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	entryId, err := strconv.Atoi(chi.URLParam(request, "entryId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting entry id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	err = h.services.Entry.DeletePlaylistEntry(change, entryId)
+	if h.versionMismatch(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error deleting playlist entry: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	setVersion(writer, change)
+	h.log.Info("HANDLER: playlist entry removed: ", entryId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":          entryId,
+		"snapshot_id": change.SnapshotId,
+	})
+}
+
+// HandleUpdateEntryNote
+// @Summary Update playlist entry note
+// @Tags entries
+// @Description Set the note of a single playlist entry, an empty note removes it
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param entryId path int true "Entry ID"
+// @Param input body models.EntryNoteDto true "Entry note"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
+// @Success 200 {object} map[string]interface{} "Note updated"
+// @Failure 400 {object} error "invalid input"
+// @Failure 412 {object} error "playlist was changed by another request"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/entries/{entryId}/note [put]
+// @Security ApiKeyAuth
+func (h *Handler) HandleUpdateEntryNote(writer http.ResponseWriter, request *http.Request) {
+	var input models.EntryNoteDto
+
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	entryId, err := strconv.Atoi(chi.URLParam(request, "entryId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting entry id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	err = h.services.Entry.UpdateEntryNote(change, entryId, input.Note)
+	if h.versionMismatch(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error updating entry note: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	setVersion(writer, change)
+	h.log.Info("HANDLER: playlist entry note updated: ", entryId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":          entryId,
+		"note":        input.Note,
+		"snapshot_id": change.SnapshotId,
+	})
+}
+
+// HandleGetDuplicateEntries
+// @Summary Get duplicate tracks
+// @Tags entries
+// @Description List tracks that occur more than once in the playlist
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Success 200 {array} models.DuplicateGroup "Duplicates"
+// @Failure 400 {object} error "invalid playlist id"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/duplicates [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetDuplicateEntries(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	duplicates, err := h.services.Entry.GetDuplicateEntries(userId, playlistId)
+	if err != nil {
+		h.log.Error("HANDLER: error getting duplicate entries: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: duplicate entries found: ", len(duplicates))
+	utils.WriteJSON(writer, http.StatusOK, duplicates)
+}
+
+// HandleCollapseDuplicateEntries
+// @Summary Collapse duplicate tracks
+// @Tags entries
+// @Description Keep the first occurrence of every track and remove the rest
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
+// @Success 200 {object} map[string]interface{} "Removed entries count"
+// @Failure 400 {object} error "invalid playlist id"
+// @Failure 412 {object} error "playlist was changed by another request"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/duplicates [delete]
+// @Security ApiKeyAuth
+func (h *Handler) HandleCollapseDuplicateEntries(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	removed, err := h.services.Entry.CollapseDuplicateEntries(change)
+	if h.versionMismatch(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error collapsing duplicate entries: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	setVersion(writer, change)
+	h.log.Info("HANDLER: duplicate entries removed: ", removed)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":          playlistId,
+		"removed":     removed,
+		"snapshot_id": change.SnapshotId,
+	})
+}
+
+func parseEntryFilter(query url.Values) (*models.EntryFilter, error) {
+	filter := &models.EntryFilter{Sort: models.EntrySortPosition}
+	var err error
+
+	if sort := query.Get("sort"); sort != "" {
+		switch sort {
+		case models.EntrySortPosition, models.EntrySortAddedAt, models.EntrySortAddedAtDesc:
+			filter.Sort = sort
+		default:
+			if !slices.Contains(models.RangeFeatures, strings.TrimPrefix(sort, "-")) {
+				return nil, errInvalidEntrySort
+			}
+			filter.Sort = sort
+		}
+	}
+
+	if after := query.Get("added_after"); after != "" {
+		parsed, err := time.Parse(time.RFC3339, after)
+		if err != nil {
+			return nil, err
+		}
+		filter.AddedAfter = &parsed
+	}
+
+	if before := query.Get("added_before"); before != "" {
+		parsed, err := time.Parse(time.RFC3339, before)
+		if err != nil {
+			return nil, err
+		}
+		filter.AddedBefore = &parsed
+	}
+
+	filter.ArtistId = query.Get("artist_id")
+
+	for _, feature := range models.RangeFeatures {
+		bound := models.FeatureRange{Feature: feature}
+		if bound.Min, err = parseFeatureBound(query.Get(feature + "_min")); err != nil {
+			return nil, err
+		}
+		if bound.Max, err = parseFeatureBound(query.Get(feature + "_max")); err != nil {
+			return nil, err
+		}
+		if bound.Min != nil || bound.Max != nil {
+			filter.Features = append(filter.Features, bound)
+		}
+	}
+
+	if raw := query.Get("key"); raw != "" {
+		key, err := strconv.Atoi(raw)
+		if err != nil || key < 0 || key > 11 {
+			return nil, errInvalidKey
+		}
+		filter.Key = &key
+	}
+
+	if raw := query.Get("mode"); raw != "" {
+		mode, err := strconv.Atoi(raw)
+		if err != nil || (mode != 0 && mode != 1) {
+			return nil, errInvalidMode
+		}
+		filter.Mode = &mode
+	}
+
+	return filter, nil
+}
+
+func parseFeatureBound(raw string) (*float64, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, errInvalidFeatureBound
+	}
+	return &value, nil
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/models"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_HandleGetPlaylistEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	entryService := mock_service.NewMockEntry(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Entry: entryService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		playlistId     int
+		userId         int
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:       "successful get entries",
+			playlistId: 1,
+			userId:     1,
+			mockSetup: func() {
+				entryService.EXPECT().GetPlaylistEntries(1, 1).Return([]*models.PlaylistEntry{
+					{EntryId: 10, Position: 1, Song: models.Song{ID: "1", Title: "test song"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"entry_id":10, "position":1, "album":"", "album_cover":"", "artist":"", "duration":0,
+				"external_url":"", "id":"1", "popularity":0, "preview_url":"", "release_date":"", "title":"test song"}]`,
+		},
+		{
+			name:       "error getting entries",
+			playlistId: 1,
+			userId:     1,
+			mockSetup: func() {
+				entryService.EXPECT().GetPlaylistEntries(1, 1).Return(nil, errors.New("test error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"test error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("playlistId", fmt.Sprintf("%d", tt.playlistId))
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/playlist/%d/entries", tt.playlistId), nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, tt.userId))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleGetPlaylistEntries).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_HandleMovePlaylistEntry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	entryService := mock_service.NewMockEntry(ctrl)
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Entry:    entryService,
+			PlayList: playlistService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "successful move",
+			body: `{"position":2}`,
+			mockSetup: func() {
+				entryService.EXPECT().MovePlaylistEntry(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, 10, 2).
+					DoAndReturn(func(change *models.PlaylistChange, entryId, position int) error {
+						change.SnapshotId = 5
+						return nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":10,"position":2,"snapshot_id":5}`,
+		},
+		{
+			name:           "invalid position",
+			body:           `{"position":0}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("playlistId", "1")
+			chiCtx.URLParams.Add("entryId", "10")
+			req, _ := http.NewRequest(http.MethodPut, "/playlist/1/entries/10", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleMovePlaylistEntry).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_HandleCollapseDuplicateEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	entryService := mock_service.NewMockEntry(ctrl)
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Entry:    entryService,
+			PlayList: playlistService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	entryService.EXPECT().CollapseDuplicateEntries(&models.PlaylistChange{UserId: 1, PlaylistId: 1}).
+		DoAndReturn(func(change *models.PlaylistChange) (int64, error) {
+			change.SnapshotId = 5
+			return 3, nil
+		})
+
+	rec := httptest.NewRecorder()
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("playlistId", "1")
+	req, _ := http.NewRequest(http.MethodDelete, "/playlist/1/duplicates", nil)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleCollapseDuplicateEntries).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.JSONEq(t, `{"id":1,"removed":3,"snapshot_id":5}`, rec.Body.String())
+}
+
+func TestHandler_HandleUpdateEntryNote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	entryService := mock_service.NewMockEntry(ctrl)
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Entry:    entryService,
+			PlayList: playlistService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "successful note update",
+			body: `{"note":"opener"}`,
+			mockSetup: func() {
+				entryService.EXPECT().UpdateEntryNote(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, 10, "opener").
+					DoAndReturn(func(change *models.PlaylistChange, entryId int, note string) error {
+						change.SnapshotId = 5
+						return nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":10,"note":"opener","snapshot_id":5}`,
+		},
+		{
+			name:           "note too long",
+			body:           `{"note":"` + strings.Repeat("a", 501) + `"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("playlistId", "1")
+			chiCtx.URLParams.Add("entryId", "10")
+			req, _ := http.NewRequest(http.MethodPut, "/playlist/1/entries/10/note", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleUpdateEntryNote).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"music-service/internal/csvfile"
+	"music-service/internal/m3u"
+	"music-service/internal/models"
+	"music-service/internal/xspf"
+	"music-service/pkg/utils"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HandleExportM3U
+// @Summary Export playlist as M3U8
+// @Tags export
+// @Description Streams the playlist as extended M3U, tracks without a URL for the chosen source are left out
+// @Produce  audio/x-mpegurl
+// @Param playlistId path int true "Playlist ID"
+// @Param source query string false "external (default) or preview"
+// @Success 200 {string} string "M3U8 playlist"
+// @Failure 400 {object} error "invalid source"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/export.m3u8 [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleExportM3U(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	source := request.URL.Query().Get("source")
+	if source == "" {
+		source = m3u.SourceExternal
+	}
+	if source != m3u.SourceExternal && source != m3u.SourcePreview {
+		h.log.Error("HANDLER: error parsing export source: ", source)
+		utils.WriteError(writer, http.StatusBadRequest, m3u.ErrInvalidSource)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	playlist, entries, ok := h.loadExport(writer, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	writer.Header().Set("Content-Type", m3u.ContentType)
+	writer.Header().Set("Content-Disposition", attachment(playlist.Name, m3u.Extension))
+	writer.WriteHeader(http.StatusOK)
+
+	skipped, err := m3u.Encode(writer, playlist.Name, entrySongs(entries), source)
+	if err != nil {
+		h.log.Error("HANDLER: error streaming m3u export: ", err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist exported as m3u: ", playlistId, len(entries)-skipped)
+}
+
+// HandleExportXSPF
+// @Summary Export playlist as XSPF
+// @Tags export
+// @Description Streams the playlist as XSPF with title, creator, album, duration in ms, cover image and the Spotify URL as location and identifier
+// @Produce  application/xspf+xml
+// @Param playlistId path int true "Playlist ID"
+// @Success 200 {string} string "XSPF playlist"
+// @Failure 400 {object} error "invalid playlist id"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/export.xspf [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleExportXSPF(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	playlist, entries, ok := h.loadExport(writer, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	writer.Header().Set("Content-Type", xspf.ContentType)
+	writer.Header().Set("Content-Disposition", attachment(playlist.Name, xspf.Extension))
+	writer.WriteHeader(http.StatusOK)
+
+	if err := xspf.Encode(writer, xspf.FromSongs(playlist.Name, entrySongs(entries))); err != nil {
+		h.log.Error("HANDLER: error streaming xspf export: ", err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist exported as xspf: ", playlistId, len(entries))
+}
+
+// HandleExportCSV
+// @Summary Export playlist as CSV
+// @Tags export
+// @Description Streams the playlist as CSV with a header row. Columns are the song fields id, title, artist, album, album_cover, duration, release_date, popularity, preview_url, external_url and the entry fields position, entry_id, added_at, added_by, note
+// @Produce  text/csv
+// @Param playlistId path int true "Playlist ID"
+// @Param columns query string false "Comma separated columns, defaults to position,title,artist,album,duration,id,external_url,added_at,note"
+// @Success 200 {string} string "CSV playlist"
+// @Failure 400 {object} error "unknown column"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/export.csv [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleExportCSV(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	columns, err := csvfile.ParseColumns(request.URL.Query().Get("columns"))
+	if err != nil {
+		h.log.Error("HANDLER: error parsing export columns: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	playlist, entries, ok := h.loadExport(writer, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	writer.Header().Set("Content-Type", csvfile.ContentType)
+	writer.Header().Set("Content-Disposition", attachment(playlist.Name, csvfile.Extension))
+	writer.WriteHeader(http.StatusOK)
+
+	if err := csvfile.Encode(writer, columns, entries); err != nil {
+		h.log.Error("HANDLER: error streaming csv export: ", err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist exported as csv: ", playlistId, len(entries))
+}
+
+// loadExport reads the playlist and its tracks. It returns false when an error
+// response has already been written.
+func (h *Handler) loadExport(writer http.ResponseWriter, userId, playlistId int) (*models.Playlist, []*models.PlaylistEntry, bool) {
+	playlist, err := h.services.PlayList.GetPlaylistById(userId, playlistId)
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist from db: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return nil, nil, false
+	}
+
+	entries, err := h.services.Song.GetAllSongsFromPlaylist(userId, playlistId, nil)
+	if err != nil {
+		h.log.Error("HANDLER: error getting tracks from playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return nil, nil, false
+	}
+
+	return playlist, entries, true
+}
+
+func entrySongs(entries []*models.PlaylistEntry) []*models.Song {
+	songs := make([]*models.Song, 0, len(entries))
+	for _, entry := range entries {
+		songs = append(songs, &entry.Song)
+	}
+	return songs
+}
+
+// attachment builds a Content-Disposition header with a filename safe for any client.
+func attachment(name, extension string) string {
+	filename := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r == ' ' || r == '.':
+			return '_'
+		default:
+			return -1
+		}
+	}, name)
+	if filename == "" {
+		filename = "playlist"
+	}
+	return `attachment; filename="` + filename + extension + `"`
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/models"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_HandleExportM3U(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	songService := mock_service.NewMockSong(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			PlayList: playlistService,
+			Song:     songService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	entries := []*models.PlaylistEntry{
+		{EntryId: 1, Position: 1, Song: models.Song{Title: "Song A", Artist: "Artist X", Duration: 215,
+			ExternalURL: "https://open.spotify.com/track/1", PreviewURL: "https://p.scdn.co/1"}},
+	}
+
+	tests := []struct {
+		name                string
+		query               string
+		mockSetup           func()
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name: "external urls by default",
+			mockSetup: func() {
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{ID: 1, Name: "Road trip"}, nil)
+				songService.EXPECT().GetAllSongsFromPlaylist(1, 1, nil).Return(entries, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "audio/x-mpegurl; charset=utf-8",
+			expectedBody: "#EXTM3U\n#PLAYLIST:Road trip\n" +
+				"#EXTINF:215,Artist X - Song A\nhttps://open.spotify.com/track/1\n",
+		},
+		{
+			name:  "preview urls",
+			query: "?source=preview",
+			mockSetup: func() {
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{ID: 1, Name: "Road trip"}, nil)
+				songService.EXPECT().GetAllSongsFromPlaylist(1, 1, nil).Return(entries, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "audio/x-mpegurl; charset=utf-8",
+			expectedBody: "#EXTM3U\n#PLAYLIST:Road trip\n" +
+				"#EXTINF:215,Artist X - Song A\nhttps://p.scdn.co/1\n",
+		},
+		{
+			name:                "invalid source",
+			query:               "?source=spotify",
+			mockSetup:           func() {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"source must be external or preview"}` + "\n",
+		},
+		{
+			name: "error getting playlist",
+			mockSetup: func() {
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(nil, errors.New("playlist not found"))
+			},
+			expectedStatus:      http.StatusInternalServerError,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"playlist not found"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("playlistId", "1")
+			req, _ := http.NewRequest(http.MethodGet, "/playlist/1/export.m3u8"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleExportM3U).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.Equal(t, tt.expectedContentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, `attachment; filename="Road_trip.m3u8"`, rec.Header().Get("Content-Disposition"))
+			}
+		})
+	}
+}
+
+func TestHandler_HandleExportXSPF(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	songService := mock_service.NewMockSong(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			PlayList: playlistService,
+			Song:     songService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{ID: 1, Name: "Road trip"}, nil)
+	songService.EXPECT().GetAllSongsFromPlaylist(1, 1, nil).Return([]*models.PlaylistEntry{
+		{EntryId: 1, Position: 1, Song: models.Song{ID: "1", Title: "Song A", Artist: "Artist X", Duration: 215,
+			ExternalURL: "https://open.spotify.com/track/1"}},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("playlistId", "1")
+	req, _ := http.NewRequest(http.MethodGet, "/playlist/1/export.xspf", nil)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleExportXSPF).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.Equal(t, "application/xspf+xml; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="Road_trip.xspf"`, rec.Header().Get("Content-Disposition"))
+	assert.Contains(t, rec.Body.String(), "<title>Road trip</title>")
+	assert.Contains(t, rec.Body.String(), "<identifier>https://open.spotify.com/track/1</identifier>")
+	assert.Contains(t, rec.Body.String(), "<duration>215000</duration>")
+}
+
+func TestHandler_HandleExportCSV(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	songService := mock_service.NewMockSong(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			PlayList: playlistService,
+			Song:     songService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "selected columns",
+			query: "?columns=position,title,note",
+			mockSetup: func() {
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{ID: 1, Name: "Road trip"}, nil)
+				songService.EXPECT().GetAllSongsFromPlaylist(1, 1, nil).Return([]*models.PlaylistEntry{
+					{EntryId: 1, Position: 1, Note: "opener", Song: models.Song{Title: "Song A"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "position,title,note\n1,Song A,opener\n",
+		},
+		{
+			name:           "unknown column",
+			query:          "?columns=title,secret",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"unknown column \"secret\""}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("playlistId", "1")
+			req, _ := http.NewRequest(http.MethodGet, "/playlist/1/export.csv"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleExportCSV).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+				assert.Equal(t, `attachment; filename="Road_trip.csv"`, rec.Header().Get("Content-Disposition"))
+			}
+		})
+	}
+}
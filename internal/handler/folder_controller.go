@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"music-service/internal/models"
+	"music-service/internal/repository"
+	"music-service/pkg/utils"
+	"net/http"
+	"strconv"
+)
+
+// HandleCreateFolder
+// @Summary Create folder
+// @Tags folders
+// @Description Creates a playlist folder, optionally inside another folder
+// @Accept  json
+// @Produce  json
+// @Param input body models.CreateFolderDto true "Folder creation dto"
+// @Success 200 {object} map[string]interface{} "Folder created"
+// @Failure 400 {object} error "invalid input"
+// @Failure 500 {object} error "internal server error"
+// @Router /folder [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleCreateFolder(writer http.ResponseWriter, request *http.Request) {
+	var input models.CreateFolderDto
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	folder := &models.Folder{
+		UserId:   userId,
+		ParentId: input.ParentId,
+		Name:     input.Name,
+	}
+
+	id, err := h.services.Folder.CreateFolder(folder)
+	if err != nil {
+		h.log.Error("HANDLER: error creating folder: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: folder created: ", id)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"status": "ok",
+		"id":     id,
+	})
+}
+
+// HandleRenameFolder
+// @Summary Rename folder
+// @Tags folders
+// @Description Renames a playlist folder
+// @Accept  json
+// @Produce  json
+// @Param folderId path int true "Folder ID"
+// @Param input body models.RenameFolderDto true "New folder name"
+// @Success 200 {object} map[string]interface{} "Folder renamed"
+// @Failure 400 {object} error "invalid input"
+// @Failure 500 {object} error "internal server error"
+// @Router /folder/{folderId} [put]
+// @Security ApiKeyAuth
+func (h *Handler) HandleRenameFolder(writer http.ResponseWriter, request *http.Request) {
+	var input models.RenameFolderDto
+
+	folderId, err := strconv.Atoi(chi.URLParam(request, "folderId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting folder id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.services.Folder.RenameFolder(userId, folderId, input.Name); err != nil {
+		h.log.Error("HANDLER: error renaming folder: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: folder renamed: ", folderId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":   folderId,
+		"name": input.Name,
+	})
+}
+
+// HandleMoveFolder
+// @Summary Move folder
+// @Tags folders
+// @Description Moves a folder into another folder, or to the top level when parent_id is null
+// @Accept  json
+// @Produce  json
+// @Param folderId path int true "Folder ID"
+// @Param input body models.MoveFolderDto true "New parent folder"
+// @Success 200 {object} map[string]interface{} "Folder moved"
+// @Failure 400 {object} error "invalid input or folder cycle"
+// @Failure 500 {object} error "internal server error"
+// @Router /folder/{folderId}/parent [put]
+// @Security ApiKeyAuth
+func (h *Handler) HandleMoveFolder(writer http.ResponseWriter, request *http.Request) {
+	var input models.MoveFolderDto
+
+	folderId, err := strconv.Atoi(chi.URLParam(request, "folderId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting folder id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := h.services.Folder.MoveFolder(userId, folderId, input.ParentId); err != nil {
+		h.log.Error("HANDLER: error moving folder: ", err)
+		if errors.Is(err, repository.ErrFolderCycle) {
+			utils.WriteError(writer, http.StatusBadRequest, err)
+			return
+		}
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: folder moved: ", folderId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":        folderId,
+		"parent_id": input.ParentId,
+	})
+}
+
+// HandleDeleteFolder
+// @Summary Delete folder
+// @Tags folders
+// @Description Deletes a folder. mode=move_up (default) moves its contents one level up, mode=cascade deletes them too
+// @Accept  json
+// @Produce  json
+// @Param folderId path int true "Folder ID"
+// @Param mode query string false "move_up or cascade"
+// @Success 200 {object} map[string]interface{} "Folder deleted"
+// @Failure 400 {object} error "invalid input"
+// @Failure 500 {object} error "internal server error"
+// @Router /folder/{folderId} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) HandleDeleteFolder(writer http.ResponseWriter, request *http.Request) {
+	folderId, err := strconv.Atoi(chi.URLParam(request, "folderId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting folder id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	mode := request.URL.Query().Get("mode")
+	if mode != "" && mode != models.FolderDeleteMoveUp && mode != models.FolderDeleteCascade {
+		h.log.Error("HANDLER: invalid folder delete mode: ", mode)
+		utils.WriteError(writer, http.StatusBadRequest, fmt.Errorf("mode must be %s or %s", models.FolderDeleteMoveUp, models.FolderDeleteCascade))
+		return
+	}
+
+	if err := h.services.Folder.DeleteFolder(userId, folderId, mode); err != nil {
+		h.log.Error("HANDLER: error deleting folder: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: folder deleted: ", folderId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id": folderId,
+	})
+}
+
+// HandleMovePlaylistToFolder
+// @Summary Move playlist to folder
+// @Tags folders
+// @Description Moves a playlist into a folder, or to the top level when folder_id is null
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param input body models.MovePlaylistDto true "Target folder"
+// @Success 200 {object} map[string]interface{} "Playlist moved"
+// @Failure 400 {object} error "invalid input"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/folder [put]
+// @Security ApiKeyAuth
+func (h *Handler) HandleMovePlaylistToFolder(writer http.ResponseWriter, request *http.Request) {
+	var input models.MovePlaylistDto
+
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := h.services.Folder.MovePlaylist(userId, playlistId, input.FolderId); err != nil {
+		h.log.Error("HANDLER: error moving playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist moved: ", playlistId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":        playlistId,
+		"folder_id": input.FolderId,
+	})
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/models"
+	"music-service/internal/repository"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_HandleMoveFolder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	folderService := mock_service.NewMockFolder(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Folder: folderService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	parentId := 2
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "successful move",
+			body: `{"parent_id":2}`,
+			mockSetup: func() {
+				folderService.EXPECT().MoveFolder(1, 1, &parentId).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"parent_id":2}`,
+		},
+		{
+			name: "move to top level",
+			body: `{"parent_id":null}`,
+			mockSetup: func() {
+				folderService.EXPECT().MoveFolder(1, 1, nil).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"parent_id":null}`,
+		},
+		{
+			name: "move into own subfolder",
+			body: `{"parent_id":2}`,
+			mockSetup: func() {
+				folderService.EXPECT().MoveFolder(1, 1, &parentId).Return(repository.ErrFolderCycle)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"folder can't be moved into itself or its subfolder"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("folderId", "1")
+			req, _ := http.NewRequest(http.MethodPut, "/folder/1/parent", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleMoveFolder).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_HandleDeleteFolder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	folderService := mock_service.NewMockFolder(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Folder: folderService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "default mode",
+			query: "",
+			mockSetup: func() {
+				folderService.EXPECT().DeleteFolder(1, 1, "").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1}`,
+		},
+		{
+			name:  "cascade",
+			query: "?mode=cascade",
+			mockSetup: func() {
+				folderService.EXPECT().DeleteFolder(1, 1, models.FolderDeleteCascade).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1}`,
+		},
+		{
+			name:           "unknown mode",
+			query:          "?mode=everything",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"mode must be move_up or cascade"}`,
+		},
+		{
+			name:  "service error",
+			query: "?mode=move_up",
+			mockSetup: func() {
+				folderService.EXPECT().DeleteFolder(1, 1, models.FolderDeleteMoveUp).Return(errors.New("test error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"test error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("folderId", "1")
+			req, _ := http.NewRequest(http.MethodDelete, "/folder/1"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleDeleteFolder).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_HandleGetAllPlaylists_Tree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	folderService := mock_service.NewMockFolder(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Folder: folderService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	folderId := 3
+	folderService.EXPECT().GetPlaylistTree(1).Return(&models.PlaylistTree{
+		Folders: []*models.Folder{
+			{ID: 3, UserId: 1, Name: "Running", Position: 1, Playlists: []*models.Playlist{
+				{ID: 2, Name: "Fast", UserId: 1, FolderId: &folderId},
+			}},
+		},
+		Playlists: []*models.Playlist{{ID: 1, Name: "Loose", UserId: 1}},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/playlist?view=tree", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleGetAllPlaylists).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.JSONEq(t, `{
+		"folders": [{"id":3, "user_id":1, "parent_id":null, "name":"Running", "position":1,
+			"playlists": [{"id":2, "name":"Fast", "user_id":1, "folder_id":3}]}],
+		"playlists": [{"id":1, "name":"Loose", "user_id":1}]
+	}`, rec.Body.String())
+}
@@ -17,8 +17,52 @@ const (
 	playlist             = "/playlist"
 	playlistById         = "/playlist/{playlistId}"
 	trackFromSpotify     = "/tracks/{trackId}"
+	search               = "/search"
+	albumById            = "/albums/{albumId}"
+	albumToPlaylist      = "/albums/{albumId}/playlist/{playlistId}"
+	artistById           = "/artists/{artistId}"
 	trackFromPlayList    = "/tracks/playlist/{playlistId}"
 	insertAndDeleteTrack = "/tracks/{trackId}/playlist/{playlistId}"
+	playlistTracks       = "/playlist/{playlistId}/tracks"
+	playlistEntries      = "/playlist/{playlistId}/entries"
+	playlistEntryById    = "/playlist/{playlistId}/entries/{entryId}"
+	playlistEntryNote    = "/playlist/{playlistId}/entries/{entryId}/note"
+	playlistDuplicates   = "/playlist/{playlistId}/duplicates"
+	playlistHistory      = "/playlist/{playlistId}/history"
+	playlistSnapshotById = "/playlist/{playlistId}/snapshots/{snapshotId}"
+	playlistRevert       = "/playlist/{playlistId}/revert"
+	smartPlaylist        = "/playlist/smart"
+	smartPlaylistRules   = "/playlist/{playlistId}/rules"
+	smartPlaylistRefresh = "/playlist/{playlistId}/refresh"
+	smartPlaylistFreeze  = "/playlist/{playlistId}/freeze"
+	playlistFolder       = "/playlist/{playlistId}/folder"
+	playlistStats        = "/playlist/{playlistId}/stats"
+	playlistRecommend    = "/playlist/{playlistId}/recommendations"
+	playlistExportM3U    = "/playlist/{playlistId}/export.m3u8"
+	playlistExportXSPF   = "/playlist/{playlistId}/export.xspf"
+	playlistExportCSV    = "/playlist/{playlistId}/export.csv"
+	playlistImportXSPF   = "/playlist/import/xspf"
+	playlistImportCSV    = "/playlist/import/csv"
+	spotifyImport        = "/playlist/import/spotify"
+	textImport           = "/playlist/import/text"
+	importJobById        = "/playlist/import/jobs/{jobId}"
+	folder               = "/folder"
+	folderById           = "/folder/{folderId}"
+	folderParent         = "/folder/{folderId}/parent"
+	tags                 = "/tags"
+	tagById              = "/tags/{tagId}"
+	playlistTags         = "/playlist/{playlistId}/tags"
+	playlistTagById      = "/playlist/{playlistId}/tags/{tagId}"
+	trackTags            = "/tracks/{trackId}/tags"
+	trackTagById         = "/tracks/{trackId}/tags/{tagId}"
+	trash                = "/trash"
+	trashRestore         = "/trash/{playlistId}/restore"
+	meUsage              = "/me/usage"
+	meBackup             = "/me/backup"
+	meRestore            = "/me/restore"
+	adminUserQuota       = "/admin/users/{userId}/quota"
+	adminCatalogCache    = "/admin/catalog/cache"
+	adminCatalogRefresh  = "/admin/catalog/refresh"
 	swagger              = "/swagger/*"
 )
 
@@ -40,20 +84,81 @@ func (h *Handler) RegisterRoutes(router chi.Router) {
 
 		r.With(h.logRequest).Post(login, h.HandleLogin)
 		r.With(h.logRequest).Post(register, h.HandleRegister)
-		r.With(h.userIdentity, h.logRequest).Post(logout, h.LogoutHandler)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(logout, h.LogoutHandler)
 
-		r.With(h.userIdentity, h.logRequest).Get(ping, h.HandlePing)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(ping, h.HandlePing)
 
-		r.With(h.userIdentity, h.logRequest).Post(playlist, h.HandleCreatePlaylist)
-		r.With(h.userIdentity, h.logRequest).Get(playlist, h.HandleGetAllPlaylists)
-		r.With(h.userIdentity, h.logRequest).Get(playlistById, h.HandleGetPlaylistById)
-		r.With(h.userIdentity, h.logRequest).Put(playlistById, h.HandleUpdatePlaylistById)
-		r.With(h.userIdentity, h.logRequest).Delete(playlistById, h.HandleDeletePlaylistById)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(playlist, h.HandleCreatePlaylist)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(playlist, h.HandleGetAllPlaylists)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(playlistById, h.HandleGetPlaylistById)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Put(playlistById, h.HandleUpdatePlaylistById)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Delete(playlistById, h.HandleDeletePlaylistById)
 
-		r.With(h.userIdentity, h.logRequest).Get(trackFromSpotify, h.HandleGetTrackFromSpotify)
-		r.With(h.userIdentity, h.logRequest).Get(trackFromPlayList, h.HandleGetTracksFromPlaylist)
-		r.With(h.userIdentity, h.logRequest).Post(insertAndDeleteTrack, h.HandleInsertTrackToPlaylist)
-		r.With(h.userIdentity, h.logRequest).Delete(insertAndDeleteTrack, h.HandleDeleteTrackFromPlaylist)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(search, h.HandleSearch)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(albumById, h.HandleGetAlbum)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(albumToPlaylist, h.HandleAddAlbumToPlaylist)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(artistById, h.HandleGetArtist)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(trackFromSpotify, h.HandleGetTrackFromSpotify)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(trackFromPlayList, h.HandleGetTracksFromPlaylist)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(insertAndDeleteTrack, h.HandleInsertTrackToPlaylist)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Delete(insertAndDeleteTrack, h.HandleDeleteTrackFromPlaylist)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(playlistTracks, h.HandleAddTracksToPlaylist)
+
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(playlistEntries, h.HandleGetPlaylistEntries)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Put(playlistEntryById, h.HandleMovePlaylistEntry)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Delete(playlistEntryById, h.HandleDeletePlaylistEntry)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Put(playlistEntryNote, h.HandleUpdateEntryNote)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(playlistDuplicates, h.HandleGetDuplicateEntries)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Delete(playlistDuplicates, h.HandleCollapseDuplicateEntries)
+
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(playlistHistory, h.HandleGetPlaylistHistory)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(playlistSnapshotById, h.HandleGetPlaylistSnapshot)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(playlistRevert, h.HandleRevertPlaylist)
+
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(smartPlaylist, h.HandleCreateSmartPlaylist)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Put(smartPlaylistRules, h.HandleUpdateSmartRules)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(smartPlaylistRefresh, h.HandleRefreshSmartPlaylist)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(smartPlaylistFreeze, h.HandleFreezeSmartPlaylist)
+
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(folder, h.HandleCreateFolder)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Put(folderById, h.HandleRenameFolder)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Delete(folderById, h.HandleDeleteFolder)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Put(folderParent, h.HandleMoveFolder)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Put(playlistFolder, h.HandleMovePlaylistToFolder)
+
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(playlistStats, h.HandleGetPlaylistStats)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(playlistRecommend, h.HandleGetRecommendations)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(playlistRecommend, h.HandleAppendRecommendations)
+
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(playlistExportM3U, h.HandleExportM3U)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(playlistExportXSPF, h.HandleExportXSPF)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(playlistExportCSV, h.HandleExportCSV)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(playlistImportXSPF, h.HandleImportXSPF)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(playlistImportCSV, h.HandleImportCSV)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(spotifyImport, h.HandleImportSpotify)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(textImport, h.HandleImportText)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(importJobById, h.HandleGetImportJob)
+
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(tags, h.HandleGetTags)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(tags, h.HandleCreateTag)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Put(tagById, h.HandleRenameTag)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Delete(tagById, h.HandleDeleteTag)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(playlistTags, h.HandleGetPlaylistTags)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(playlistTags, h.HandleTagPlaylist)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Delete(playlistTagById, h.HandleUntagPlaylist)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(trackTags, h.HandleGetTrackTags)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(trackTags, h.HandleTagTrack)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Delete(trackTagById, h.HandleUntagTrack)
+
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(trash, h.HandleGetTrash)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(trashRestore, h.HandleRestorePlaylist)
+
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(meUsage, h.HandleGetUsage)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Get(meBackup, h.HandleExportBackup)
+		r.With(h.userIdentity, h.countRequest, h.logRequest).Post(meRestore, h.HandleRestoreBackup)
+		r.With(h.userIdentity, h.adminOnly, h.logRequest).Put(adminUserQuota, h.HandleSetQuotaOverride)
+		r.With(h.userIdentity, h.adminOnly, h.logRequest).Get(adminCatalogCache, h.HandleGetCatalogCacheStats)
+		r.With(h.userIdentity, h.adminOnly, h.logRequest).Post(adminCatalogRefresh, h.HandleRefreshCatalog)
 
 	})
 }
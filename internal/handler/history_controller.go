@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"music-service/internal/models"
+	"music-service/pkg/utils"
+	"net/http"
+	"strconv"
+)
+
+// HandleGetPlaylistHistory
+// @Summary Get playlist history
+// @Tags history
+// @Description Get versioned change events of the playlist, newest first
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Success 200 {array} models.PlaylistSnapshot "History"
+// @Failure 400 {object} error "invalid playlist id"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/history [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetPlaylistHistory(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	history, err := h.services.History.GetHistory(userId, playlistId)
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist history: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist history found: ", len(history))
+	utils.WriteJSON(writer, http.StatusOK, history)
+}
+
+// HandleGetPlaylistSnapshot
+// @Summary Get playlist snapshot
+// @Tags history
+// @Description Get the playlist state recorded by a snapshot
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param snapshotId path int true "Snapshot ID"
+// @Success 200 {object} models.PlaylistSnapshot "Snapshot"
+// @Failure 400 {object} error "invalid id"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/snapshots/{snapshotId} [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetPlaylistSnapshot(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	snapshotId, err := strconv.Atoi(chi.URLParam(request, "snapshotId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting snapshot id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	snapshot, err := h.services.History.GetSnapshot(userId, playlistId, snapshotId)
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist snapshot: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist snapshot found: ", snapshotId)
+	utils.WriteJSON(writer, http.StatusOK, snapshot)
+}
+
+// HandleRevertPlaylist
+// @Summary Revert playlist
+// @Tags history
+// @Description Restore the playlist name and tracks from an earlier snapshot
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param input body models.RevertPlaylistDto true "Snapshot to restore"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
+// @Success 200 {object} map[string]interface{} "Playlist reverted"
+// @Failure 400 {object} error "invalid input"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 412 {object} error "playlist was changed by another request"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/revert [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleRevertPlaylist(writer http.ResponseWriter, request *http.Request) {
+	var input models.RevertPlaylistDto
+
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	err = h.services.History.RevertToSnapshot(change, input.SnapshotId)
+	if h.versionMismatch(writer, err) {
+		return
+	}
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error reverting playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	setVersion(writer, change)
+	h.log.Info("HANDLER: playlist reverted to snapshot: ", input.SnapshotId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":            playlistId,
+		"reverted_from": input.SnapshotId,
+		"snapshot_id":   change.SnapshotId,
+	})
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/models"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_HandleGetPlaylistHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	historyService := mock_service.NewMockHistory(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			History: historyService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	historyService.EXPECT().GetHistory(1, 1).Return([]*models.PlaylistSnapshot{
+		{ID: 2, PlaylistId: 1, Version: 2, ActorId: 1, Action: models.ActionAdd, Name: "test", TrackCount: 1},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("playlistId", "1")
+	req, _ := http.NewRequest(http.MethodGet, "/playlist/1/history", nil)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleGetPlaylistHistory).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.JSONEq(t, `[{"snapshot_id":2, "playlist_id":1, "version":2, "actor_id":1, "action":"add",
+		"name":"test", "track_count":1, "created_at":"0001-01-01T00:00:00Z"}]`, rec.Body.String())
+}
+
+func TestHandler_HandleRevertPlaylist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	historyService := mock_service.NewMockHistory(ctrl)
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			History:  historyService,
+			PlayList: playlistService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "successful revert",
+			body: `{"snapshot_id":3}`,
+			mockSetup: func() {
+				historyService.EXPECT().RevertToSnapshot(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, 3).
+					DoAndReturn(func(change *models.PlaylistChange, snapshotId int) error {
+						change.SnapshotId = 8
+						return nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"reverted_from":3,"snapshot_id":8}`,
+		},
+		{
+			name: "error reverting",
+			body: `{"snapshot_id":3}`,
+			mockSetup: func() {
+				historyService.EXPECT().RevertToSnapshot(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, 3).Return(errors.New("snapshot not found"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"snapshot not found"}`,
+		},
+		{
+			name:           "missing snapshot id",
+			body:           `{}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("playlistId", "1")
+			req, _ := http.NewRequest(http.MethodPost, "/playlist/1/revert", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleRevertPlaylist).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
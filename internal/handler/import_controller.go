@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"music-service/internal/csvfile"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/tracklist"
+	"music-service/internal/xspf"
+	"music-service/pkg/utils"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxImportSize     = 5 << 20
+	defaultImportName = "Imported playlist"
+)
+
+var (
+	errEmptyImport   = errors.New("document contains no tracks")
+	errInvalidCommit = errors.New("commit must be true or false")
+
+	errInvalidSpotifyPlaylist  = errors.New("playlist must be a Spotify playlist URL, URI or id")
+	errSpotifyPlaylistNotFound = errors.New("spotify playlist not found")
+)
+
+// HandleImportXSPF
+// @Summary Import playlist from XSPF
+// @Tags import
+// @Description Creates a playlist from an XSPF document. Tracks with a Spotify identifier or location resolve directly, the others are matched through catalog search and reported back when nothing is found
+// @Accept  application/xspf+xml
+// @Produce  json
+// @Param name query string false "Playlist name, defaults to the document title"
+// @Param input body string true "XSPF document"
+// @Success 200 {object} models.ImportReport "Import report"
+// @Failure 400 {object} error "invalid document"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
+// @Router /playlist/import/xspf [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleImportXSPF(writer http.ResponseWriter, request *http.Request) {
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	document, err := xspf.Decode(http.MaxBytesReader(writer, request.Body, maxImportSize))
+	if err != nil {
+		h.log.Error("HANDLER: error parsing xspf: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	name := request.URL.Query().Get("name")
+	if name == "" {
+		name = document.Title
+	}
+
+	h.importPlaylist(writer, userId, name, document.Items(), false)
+}
+
+// HandleImportCSV
+// @Summary Import playlist from CSV
+// @Tags import
+// @Description Matches the rows of a CSV document against the catalog by Spotify id or url, or by artist and title through search. Runs as a dry run listing the rows that would fail unless commit is set, then creates the playlist in one transaction
+// @Accept  text/csv
+// @Produce  json
+// @Param name query string false "Playlist name"
+// @Param map query []string false "Header mapping as field:header, fields are id, url, title, artist, album, duration" collectionFormat(multi)
+// @Param commit query bool false "Create the playlist instead of a dry run"
+// @Param input body string true "CSV document with a header row"
+// @Success 200 {object} models.ImportReport "Import report"
+// @Failure 400 {object} error "invalid document or mapping"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
+// @Router /playlist/import/csv [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleImportCSV(writer http.ResponseWriter, request *http.Request) {
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	query := request.URL.Query()
+
+	mapping, err := csvfile.ParseMapping(query["map"])
+	if err != nil {
+		h.log.Error("HANDLER: error parsing csv mapping: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	commit := false
+	if value := query.Get("commit"); value != "" {
+		commit, err = strconv.ParseBool(value)
+		if err != nil {
+			h.log.Error("HANDLER: error parsing commit flag: ", err)
+			utils.WriteError(writer, http.StatusBadRequest, errInvalidCommit)
+			return
+		}
+	}
+
+	items, err := csvfile.Decode(http.MaxBytesReader(writer, request.Body, maxImportSize), mapping)
+	if err != nil {
+		h.log.Error("HANDLER: error parsing csv: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	h.importPlaylist(writer, userId, query.Get("name"), items, !commit)
+}
+
+// HandleImportText
+// @Summary Preview a pasted track list
+// @Tags import
+// @Description Reads plain text with one "Artist - Title" per line, separated by -, –, —, ~, | or a tab, and searches the catalog for each line. Nothing is saved: the candidates come with a confidence from 0 to 1 and the chosen tracks are added with POST /playlist/{playlistId}/tracks
+// @Accept  plain
+// @Produce  json
+// @Param input body string true "Track list, at most 200 lines"
+// @Success 200 {object} models.TracklistPreview "Candidates per line"
+// @Failure 400 {object} error "empty or too long track list"
+// @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
+// @Router /playlist/import/text [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleImportText(writer http.ResponseWriter, request *http.Request) {
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	lines, err := tracklist.Parse(http.MaxBytesReader(writer, request.Body, maxImportSize))
+	if err != nil {
+		h.log.Error("HANDLER: error parsing track list: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	lines, err = h.services.Import.PreviewTracklist(lines)
+	if errors.Is(err, provider.ErrUnavailable) {
+		h.log.Error("HANDLER: catalog unavailable: ", err)
+		utils.WriteError(writer, http.StatusServiceUnavailable, errCatalogUnavailable)
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error matching track list: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: track list matched: ", userId, len(lines))
+	utils.WriteJSON(writer, http.StatusOK, models.TracklistPreview{Lines: lines})
+}
+
+// HandleImportSpotify
+// @Summary Import playlist from Spotify
+// @Tags import
+// @Description Clones a Spotify playlist with its tracks in order. Playlists of up to 100 tracks are imported right away, larger ones answer 202 with a job to poll for progress
+// @Accept  json
+// @Produce  json
+// @Param input body models.SpotifyImportDto true "Playlist URL, URI or id and an optional name"
+// @Success 200 {object} models.ImportJob "Finished import"
+// @Success 202 {object} models.ImportJob "Import running in the background"
+// @Failure 400 {object} error "invalid input"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 404 {object} error "spotify playlist not found"
+// @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
+// @Router /playlist/import/spotify [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleImportSpotify(writer http.ResponseWriter, request *http.Request) {
+	var input models.SpotifyImportDto
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	spotifyId := utils.ParseSpotifyPlaylistID(input.Playlist)
+	if spotifyId == "" {
+		h.log.Error("HANDLER: error parsing spotify playlist: ", input.Playlist)
+		utils.WriteError(writer, http.StatusBadRequest, errInvalidSpotifyPlaylist)
+		return
+	}
+
+	playlist, err := h.services.Import.GetSpotifyPlaylist(spotifyId)
+	if errors.Is(err, provider.ErrNotFound) {
+		h.log.Error("HANDLER: spotify playlist not found: ", spotifyId)
+		utils.WriteError(writer, http.StatusNotFound, errSpotifyPlaylistNotFound)
+		return
+	}
+	if errors.Is(err, provider.ErrUnavailable) {
+		h.log.Error("HANDLER: catalog unavailable: ", err)
+		utils.WriteError(writer, http.StatusServiceUnavailable, errCatalogUnavailable)
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist from spotify: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	job, err := h.services.Import.StartSpotifyImport(userId, playlist, input.Name)
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error starting spotify import: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	switch job.Status {
+	case models.ImportJobFailed:
+		h.log.Error("HANDLER: spotify import failed: ", job.Error)
+		utils.WriteError(writer, http.StatusInternalServerError, errors.New(job.Error))
+	case models.ImportJobRunning:
+		h.log.Info("HANDLER: spotify import running: ", job.ID)
+		writer.Header().Set("Location", apiPath+strings.Replace(importJobById, "{jobId}", strconv.FormatInt(job.ID, 10), 1))
+		utils.WriteJSON(writer, http.StatusAccepted, job)
+	default:
+		h.log.Info("HANDLER: spotify playlist imported: ", job.ID)
+		utils.WriteJSON(writer, http.StatusOK, job)
+	}
+}
+
+// HandleGetImportJob
+// @Summary Get import job
+// @Tags import
+// @Description Progress of a background import, playlist_id is set once it's done
+// @Accept  json
+// @Produce  json
+// @Param jobId path int true "Job ID"
+// @Success 200 {object} models.ImportJob "Import job"
+// @Failure 400 {object} error "invalid job id"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/import/jobs/{jobId} [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetImportJob(writer http.ResponseWriter, request *http.Request) {
+	jobId, err := strconv.Atoi(chi.URLParam(request, "jobId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting job id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	job, err := h.services.Import.GetImportJob(userId, jobId)
+	if err != nil {
+		h.log.Error("HANDLER: error getting import job: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: import job found: ", jobId)
+	utils.WriteJSON(writer, http.StatusOK, job)
+}
+
+// importPlaylist matches the items against the catalog and creates a playlist with
+// every track found, in document order. A dry run stops after matching.
+func (h *Handler) importPlaylist(writer http.ResponseWriter, userId int, name string, items []*models.ImportItem, dryRun bool) {
+	if len(items) == 0 {
+		h.log.Error("HANDLER: error importing playlist: ", errEmptyImport)
+		utils.WriteError(writer, http.StatusBadRequest, errEmptyImport)
+		return
+	}
+	if name == "" {
+		name = defaultImportName
+	}
+
+	// The playlist doesn't exist yet, so only the imported tracks count against the limit.
+	songs, unmatched, err := h.services.Import.MatchItems(items)
+	if errors.Is(err, provider.ErrUnavailable) {
+		h.log.Error("HANDLER: catalog unavailable: ", err)
+		utils.WriteError(writer, http.StatusServiceUnavailable, errCatalogUnavailable)
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error matching imported tracks: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	if dryRun {
+		h.log.Info("HANDLER: playlist import checked: ", len(songs), len(unmatched))
+		utils.WriteJSON(writer, http.StatusOK, &models.ImportReport{
+			DryRun:    true,
+			Imported:  len(songs),
+			Unmatched: unmatched,
+		})
+		return
+	}
+
+	playlist := &models.Playlist{
+		Name:            name,
+		UserId:          userId,
+		DuplicatePolicy: models.DuplicatePolicyAllow,
+		Songs:           songs,
+	}
+	id, err := h.services.Import.ImportPlaylist(playlist)
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error importing playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist imported: ", id, len(songs), len(unmatched))
+	utils.WriteJSON(writer, http.StatusOK, &models.ImportReport{
+		PlaylistId: id,
+		SnapshotId: playlist.SnapshotId,
+		Imported:   len(songs),
+		Unmatched:  unmatched,
+	})
+}
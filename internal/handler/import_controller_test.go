@@ -0,0 +1,478 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/repository"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testXSPF = `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <title>Road trip</title>
+  <trackList>
+    <track><identifier>spotify:track:4uLU6hMCjMI75M1A2tKUQC</identifier><title>Song A</title></track>
+    <track><title>Song B</title><creator>Artist Y</creator></track>
+  </trackList>
+</playlist>`
+
+func TestHandler_HandleImportXSPF(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	importService := mock_service.NewMockImport(ctrl)
+	quotaService := mock_service.NewMockQuota(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Import: importService,
+			Quota:  quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	items := []*models.ImportItem{
+		{Title: "Song A", SpotifyId: "4uLU6hMCjMI75M1A2tKUQC"},
+		{Title: "Song B", Artist: "Artist Y"},
+	}
+	matched := []models.Song{{ID: "4uLU6hMCjMI75M1A2tKUQC", Title: "Song A"}}
+
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "imported with unmatched report",
+			body: testXSPF,
+			mockSetup: func() {
+				importService.EXPECT().MatchItems(items).Return(matched, items[1:], nil)
+				importService.EXPECT().ImportPlaylist(&models.Playlist{
+					Name:            "Road trip",
+					UserId:          1,
+					DuplicatePolicy: models.DuplicatePolicyAllow,
+					Songs:           matched,
+				}).DoAndReturn(func(playlist *models.Playlist) (int64, error) {
+					playlist.SnapshotId = 4
+					return 9, nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"playlist_id":9, "snapshot_id":4, "imported":1,
+				"unmatched":[{"title":"Song B", "artist":"Artist Y"}]}`,
+		},
+		{
+			name:  "name from query",
+			query: "?name=Weekend",
+			body:  testXSPF,
+			mockSetup: func() {
+				importService.EXPECT().MatchItems(items).Return(matched, items[1:], nil)
+				importService.EXPECT().ImportPlaylist(gomock.Any()).DoAndReturn(func(playlist *models.Playlist) (int64, error) {
+					assert.Equal(t, "Weekend", playlist.Name)
+					playlist.SnapshotId = 4
+					return 9, nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"playlist_id":9, "snapshot_id":4, "imported":1,
+				"unmatched":[{"title":"Song B", "artist":"Artist Y"}]}`,
+		},
+		{
+			name:           "invalid document",
+			body:           `<html></html>`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"expected element type <playlist> but have <html>"}`,
+		},
+		{
+			name:           "no tracks",
+			body:           `<playlist version="1" xmlns="http://xspf.org/ns/0/"><trackList/></playlist>`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"document contains no tracks"}`,
+		},
+		{
+			name: "track quota exceeded",
+			body: testXSPF,
+			mockSetup: func() {
+				importService.EXPECT().MatchItems(items).Return(matched, items[1:], nil)
+				importService.EXPECT().ImportPlaylist(gomock.Any()).
+					Return(int64(0), fmt.Errorf("%w: at most 1 tracks per playlist allowed", repository.ErrQuotaExceeded))
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"quota exceeded: at most 1 tracks per playlist allowed"}`,
+		},
+		{
+			name: "catalog error",
+			body: testXSPF,
+			mockSetup: func() {
+				importService.EXPECT().MatchItems(items).Return(nil, nil, errors.New("catalog unavailable"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"catalog unavailable"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/playlist/import/xspf"+tt.query, strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleImportXSPF).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_HandleImportCSV(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	importService := mock_service.NewMockImport(ctrl)
+	quotaService := mock_service.NewMockQuota(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Import: importService,
+			Quota:  quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	document := "Track Name,Artist\nSong A,Artist X\nSong B,Artist Y\n"
+	items := []*models.ImportItem{
+		{Row: 2, Title: "Song A", Artist: "Artist X"},
+		{Row: 3, Title: "Song B", Artist: "Artist Y"},
+	}
+	matched := []models.Song{{ID: "a1", Title: "Song A"}}
+
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "dry run by default",
+			query: "?map=title:Track+Name",
+			body:  document,
+			mockSetup: func() {
+				importService.EXPECT().MatchItems(items).Return(matched, items[1:], nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"dry_run":true, "imported":1,
+				"unmatched":[{"row":3, "title":"Song B", "artist":"Artist Y"}]}`,
+		},
+		{
+			name:  "commit",
+			query: "?map=title:Track+Name&commit=true&name=Sheet",
+			body:  document,
+			mockSetup: func() {
+				importService.EXPECT().MatchItems(items).Return(matched, items[1:], nil)
+				importService.EXPECT().ImportPlaylist(&models.Playlist{
+					Name:            "Sheet",
+					UserId:          1,
+					DuplicatePolicy: models.DuplicatePolicyAllow,
+					Songs:           matched,
+				}).DoAndReturn(func(playlist *models.Playlist) (int64, error) {
+					playlist.SnapshotId = 4
+					return 9, nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"playlist_id":9, "snapshot_id":4, "imported":1,
+				"unmatched":[{"row":3, "title":"Song B", "artist":"Artist Y"}]}`,
+		},
+		{
+			name:           "invalid mapping",
+			query:          "?map=genre:Genre",
+			body:           document,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"mapping \"genre:Genre\" must be field:header with field one of id, url, title, artist, album, duration"}`,
+		},
+		{
+			name:           "invalid commit flag",
+			query:          "?commit=maybe",
+			body:           document,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"commit must be true or false"}`,
+		},
+		{
+			name:           "no track column",
+			body:           document,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"csv needs an id, url or title column"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/playlist/import/csv"+tt.query, strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleImportCSV).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_HandleImportSpotify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	importService := mock_service.NewMockImport(ctrl)
+	quotaService := mock_service.NewMockQuota(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Import: importService,
+			Quota:  quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	createdAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	playlistId := int64(9)
+	playlist := func(total int) *models.RemotePlaylist {
+		return &models.RemotePlaylist{ID: "37i9dQZF1DXcBWIGoYBM5M", Name: "Top Hits", Total: total}
+	}
+
+	tests := []struct {
+		name             string
+		body             string
+		mockSetup        func()
+		expectedStatus   int
+		expectedLocation string
+		expectedBody     string
+	}{
+		{
+			name: "small playlist imported",
+			body: `{"playlist":"https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc"}`,
+			mockSetup: func() {
+				importService.EXPECT().GetSpotifyPlaylist("37i9dQZF1DXcBWIGoYBM5M").Return(playlist(2), nil)
+				importService.EXPECT().StartSpotifyImport(1, playlist(2), "").Return(&models.ImportJob{
+					ID: 3, UserId: 1, Source: "37i9dQZF1DXcBWIGoYBM5M", Status: models.ImportJobDone,
+					Total: 2, Processed: 2, PlaylistId: &playlistId, CreatedAt: createdAt, UpdatedAt: createdAt,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"id":3, "user_id":1, "source":"37i9dQZF1DXcBWIGoYBM5M", "status":"done", "total":2,
+				"processed":2, "skipped":0, "playlist_id":9,
+				"created_at":"2026-10-18T12:00:00Z", "updated_at":"2026-10-18T12:00:00Z"}`,
+		},
+		{
+			name: "large playlist in the background",
+			body: `{"playlist":"spotify:playlist:37i9dQZF1DXcBWIGoYBM5M", "name":"Copy"}`,
+			mockSetup: func() {
+				importService.EXPECT().GetSpotifyPlaylist("37i9dQZF1DXcBWIGoYBM5M").Return(playlist(250), nil)
+				importService.EXPECT().StartSpotifyImport(1, playlist(250), "Copy").Return(&models.ImportJob{
+					ID: 3, UserId: 1, Source: "37i9dQZF1DXcBWIGoYBM5M", Status: models.ImportJobRunning,
+					Total: 250, CreatedAt: createdAt, UpdatedAt: createdAt,
+				}, nil)
+			},
+			expectedStatus:   http.StatusAccepted,
+			expectedLocation: "/api/v1/playlist/import/jobs/3",
+			expectedBody: `{"id":3, "user_id":1, "source":"37i9dQZF1DXcBWIGoYBM5M", "status":"running", "total":250,
+				"processed":0, "skipped":0, "created_at":"2026-10-18T12:00:00Z", "updated_at":"2026-10-18T12:00:00Z"}`,
+		},
+		{
+			name:           "not a playlist link",
+			body:           `{"playlist":"https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"playlist must be a Spotify playlist URL, URI or id"}`,
+		},
+		{
+			name: "unknown playlist",
+			body: `{"playlist":"37i9dQZF1DXcBWIGoYBM5M"}`,
+			mockSetup: func() {
+				importService.EXPECT().GetSpotifyPlaylist("37i9dQZF1DXcBWIGoYBM5M").
+					Return(nil, fmt.Errorf("%w: Not found.", provider.ErrNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"spotify playlist not found"}`,
+		},
+		{
+			name: "too many tracks",
+			body: `{"playlist":"37i9dQZF1DXcBWIGoYBM5M"}`,
+			mockSetup: func() {
+				importService.EXPECT().GetSpotifyPlaylist("37i9dQZF1DXcBWIGoYBM5M").Return(playlist(250), nil)
+				importService.EXPECT().StartSpotifyImport(1, playlist(250), "").
+					Return(nil, fmt.Errorf("%w: at most 100 tracks per playlist allowed", repository.ErrQuotaExceeded))
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"quota exceeded: at most 100 tracks per playlist allowed"}`,
+		},
+		{
+			name: "small import failed",
+			body: `{"playlist":"37i9dQZF1DXcBWIGoYBM5M"}`,
+			mockSetup: func() {
+				importService.EXPECT().GetSpotifyPlaylist("37i9dQZF1DXcBWIGoYBM5M").Return(playlist(2), nil)
+				importService.EXPECT().StartSpotifyImport(1, playlist(2), "").Return(&models.ImportJob{
+					ID: 3, Status: models.ImportJobFailed, Error: "upstream unavailable",
+				}, nil)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"upstream unavailable"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/playlist/import/spotify", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleImportSpotify).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.Equal(t, tt.expectedLocation, rec.Header().Get("Location"))
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_HandleGetImportJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	importService := mock_service.NewMockImport(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Import: importService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	createdAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	importService.EXPECT().GetImportJob(1, 3).Return(&models.ImportJob{
+		ID: 3, UserId: 1, Source: "37i9dQZF1DXcBWIGoYBM5M", Status: models.ImportJobRunning,
+		Total: 250, Processed: 100, CreatedAt: createdAt, UpdatedAt: createdAt,
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("jobId", "3")
+	req, _ := http.NewRequest(http.MethodGet, "/playlist/import/jobs/3", nil)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleGetImportJob).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.JSONEq(t, `{"id":3, "user_id":1, "source":"37i9dQZF1DXcBWIGoYBM5M", "status":"running", "total":250,
+		"processed":100, "skipped":0, "created_at":"2026-10-18T12:00:00Z", "updated_at":"2026-10-18T12:00:00Z"}`, rec.Body.String())
+}
+
+func TestHandler_HandleImportText(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	importService := mock_service.NewMockImport(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Import: importService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	song := models.Song{ID: "67Hna13dNDkZvBpTXRIaOJ", Title: "Teardrop", Artist: "Massive Attack"}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "candidates per line",
+			body: "1. Massive Attack – Teardrop\nwhatever\n",
+			mockSetup: func() {
+				importService.EXPECT().PreviewTracklist([]*models.TracklistLine{
+					{Row: 1, Text: "1. Massive Attack – Teardrop", Artist: "Massive Attack", Title: "Teardrop", Candidates: []*models.TrackCandidate{}},
+					{Row: 2, Text: "whatever", Error: `line is not in "Artist - Title" form`, Candidates: []*models.TrackCandidate{}},
+				}).DoAndReturn(func(lines []*models.TracklistLine) ([]*models.TracklistLine, error) {
+					lines[0].Candidates = append(lines[0].Candidates, &models.TrackCandidate{Confidence: 1, Song: song})
+					return lines, nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"lines":[
+				{"row":1, "text":"1. Massive Attack – Teardrop", "artist":"Massive Attack", "title":"Teardrop", "candidates":[
+					{"confidence":1, "song":{"id":"67Hna13dNDkZvBpTXRIaOJ", "title":"Teardrop", "artist":"Massive Attack", "album":"",
+						"album_cover":"", "duration":0, "release_date":"", "popularity":0, "preview_url":"", "external_url":""}}]},
+				{"row":2, "text":"whatever", "error":"line is not in \"Artist - Title\" form", "candidates":[]}]}`,
+		},
+		{
+			name:           "empty track list",
+			body:           "\n\n",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"track list has no lines"}`,
+		},
+		{
+			name: "catalog unavailable",
+			body: "Massive Attack - Teardrop",
+			mockSetup: func() {
+				importService.EXPECT().PreviewTracklist(gomock.Any()).Return(nil, errors.New("server error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"server error"}`,
+		},
+		{
+			name: "spotify unavailable",
+			body: "Massive Attack - Teardrop",
+			mockSetup: func() {
+				importService.EXPECT().PreviewTracklist(gomock.Any()).Return(nil, provider.ErrUnavailable)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"error":"catalog is unavailable, try again later"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/playlist/import/text", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleImportText).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
@@ -3,8 +3,10 @@ package handler
 import (
 	"context"
 	"errors"
+	"music-service/internal/repository"
 	"music-service/pkg/utils"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -19,6 +21,7 @@ var (
 	errInvalidToken      = errors.New("invalid token")
 	errUsersTokenIsEmpty = errors.New("user not found")
 	errUserNotFound      = errors.New("user not found")
+	errAdminOnly         = errors.New("only admins can do this")
 )
 
 func (h *Handler) userIdentity(next http.Handler) http.Handler {
@@ -63,6 +66,57 @@ func (h *Handler) userIdentity(next http.Handler) http.Handler {
 	})
 }
 
+// countRequest enforces the daily request limit of the authenticated user, so it
+// has to run after userIdentity.
+func (h *Handler) countRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId, err := getUserId(r.Context())
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, errContext)
+			return
+		}
+
+		err = h.services.Quota.CountRequest(userId)
+		if errors.Is(err, repository.ErrQuotaExceeded) {
+			now := time.Now().UTC()
+			tomorrow := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+			w.Header().Set("Retry-After", strconv.Itoa(int(tomorrow.Sub(now).Seconds())+1))
+			utils.WriteError(w, http.StatusTooManyRequests, err)
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// adminOnly has to run after userIdentity.
+func (h *Handler) adminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId, err := getUserId(r.Context())
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, errContext)
+			return
+		}
+
+		isAdmin, err := h.services.Authorization.IsAdmin(userId)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !isAdmin {
+			utils.WriteError(w, http.StatusForbidden, errAdminOnly)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (h *Handler) logRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
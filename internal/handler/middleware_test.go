@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"context"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"music-service/internal/repository"
 	"music-service/internal/service"
 	mock_service "music-service/internal/service/mocks"
 	"music-service/pkg/logging"
@@ -93,6 +96,111 @@ func TestUserIdentity(t *testing.T) {
 	}
 }
 
+func TestCountRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	quotaService := mock_service.NewMockQuota(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			Quota: quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Within Limit",
+			mockSetup: func() {
+				quotaService.EXPECT().CountRequest(1).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Limit Exceeded",
+			mockSetup: func() {
+				quotaService.EXPECT().CountRequest(1).Return(fmt.Errorf("%w: at most 10 requests per day allowed", repository.ErrQuotaExceeded))
+			},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test, nil)
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+			rec := httptest.NewRecorder()
+
+			tt.mockSetup()
+
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			handler.countRequest(nextHandler).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusTooManyRequests {
+				assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestAdminOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_service.NewMockAuthorization(ctrl)
+
+	handler := &Handler{
+		services: &service.Service{
+			Authorization: mockAuthService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		isAdmin        bool
+		expectedStatus int
+	}{
+		{
+			name:           "Admin",
+			isAdmin:        true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Regular User",
+			isAdmin:        false,
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test, nil)
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+			rec := httptest.NewRecorder()
+
+			mockAuthService.EXPECT().IsAdmin(1).Return(tt.isAdmin, nil)
+
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			handler.adminOnly(nextHandler).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
 func TestLogRequest(t *testing.T) {
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
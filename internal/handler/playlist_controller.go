@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"music-service/internal/models"
+	"music-service/internal/service"
 	"music-service/pkg/utils"
 	"net/http"
 	"strconv"
@@ -18,6 +20,7 @@ import (
 // @Param input body models.CreatePlaylistDto true "Playlist creation dto"
 // @Success 200 {object} map[string]interface{} "Playlist created"
 // @Failure 400 {object} error "invalid parsing JSON"
+// @Failure 403 {object} error "quota exceeded"
 // @Failure 500 {object} error "internal server error"
 // @Router /playlist [post]
 // @Security ApiKeyAuth
@@ -44,11 +47,15 @@ func (h *Handler) HandleCreatePlaylist(writer http.ResponseWriter, request *http
 	}
 
 	playlist := &models.Playlist{
-		Name:   input.Name,
-		UserId: userId,
+		Name:            input.Name,
+		UserId:          userId,
+		DuplicatePolicy: input.DuplicatePolicy,
 	}
 
 	id, err := h.services.PlayList.CreatePlaylist(playlist)
+	if h.quotaExceeded(writer, err) {
+		return
+	}
 	if err != nil {
 		h.log.Error("HANDLER: error creating playlist: ", err)
 		utils.WriteError(writer, http.StatusInternalServerError, err)
@@ -57,18 +64,23 @@ func (h *Handler) HandleCreatePlaylist(writer http.ResponseWriter, request *http
 
 	h.log.Info("HANDLER: playlist created: ", id)
 	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
-		"status": "ok",
-		"id":     id,
+		"status":      "ok",
+		"id":          id,
+		"snapshot_id": playlist.SnapshotId,
 	})
 }
 
 // HandleGetAllPlaylists
 // @Summary Get all playlists
 // @Tags playlist
-// @Description Get all playlists for the authenticated user
+// @Description Get all playlists for the authenticated user. view=tree groups them into folders
 // @Accept  json
 // @Produce  json
+// @Param view query string false "tree"
+// @Param tag query []string false "Only playlists carrying all of these tags" collectionFormat(multi)
 // @Success 200 {array} models.Playlist "Playlists"
+// @Success 200 {object} models.PlaylistTree "Playlists grouped into folders"
+// @Failure 400 {object} error "tag filter has only blank names"
 // @Failure 500 {object} error "internal server error"
 // @Router /playlist [get]
 // @Security ApiKeyAuth
@@ -80,6 +92,19 @@ func (h *Handler) HandleGetAllPlaylists(writer http.ResponseWriter, request *htt
 		return
 	}
 
+	if request.URL.Query().Get("view") == "tree" {
+		tree, err := h.services.Folder.GetPlaylistTree(userId)
+		if err != nil {
+			h.log.Error("HANDLER: error getting playlist tree: ", err)
+			utils.WriteError(writer, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.log.Info("HANDLER: playlist tree found: ", len(tree.Folders), len(tree.Playlists))
+		utils.WriteJSON(writer, http.StatusOK, tree)
+		return
+	}
+
 	playlists, err := h.services.PlayList.GetAllPlaylists(userId)
 	if err != nil {
 		h.log.Error("HANDLER: error getting playlists: ", err)
@@ -87,6 +112,20 @@ func (h *Handler) HandleGetAllPlaylists(writer http.ResponseWriter, request *htt
 		return
 	}
 
+	if tagNames := request.URL.Query()["tag"]; len(tagNames) > 0 {
+		playlists, err = h.services.Tag.FilterPlaylistsByTags(userId, playlists, tagNames)
+		if errors.Is(err, service.ErrNoTags) {
+			h.log.Error("HANDLER: empty tag filter: ", tagNames)
+			utils.WriteError(writer, http.StatusBadRequest, err)
+			return
+		}
+		if err != nil {
+			h.log.Error("HANDLER: error filtering playlists by tags: ", err)
+			utils.WriteError(writer, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	h.log.Info("HANDLER: playlists found: ", playlists)
 	utils.WriteJSON(writer, http.StatusOK, playlists)
 }
@@ -98,7 +137,9 @@ func (h *Handler) HandleGetAllPlaylists(writer http.ResponseWriter, request *htt
 // @Accept  json
 // @Produce  json
 // @Param id path int true "Playlist id"
+// @Param If-None-Match header string false "ETag of a cached playlist version"
 // @Success 200 {object} models.Playlist "Playlist"
+// @Success 304 "playlist not modified"
 // @Failure 500 {object} error "internal server error"
 // @Router /playlist/{id} [get]
 // @Security ApiKeyAuth
@@ -125,7 +166,7 @@ func (h *Handler) HandleGetPlaylistById(writer http.ResponseWriter, request *htt
 	}
 
 	h.log.Info("HANDLER: playlist found: ", playlist)
-	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+	writeTagged(writer, request, playlist.Version, map[string]interface{}{
 		"playlist": playlist,
 	})
 }
@@ -138,8 +179,10 @@ func (h *Handler) HandleGetPlaylistById(writer http.ResponseWriter, request *htt
 // @Produce  json
 // @Param id path int true "Playlist id"
 // @Param input body models.UpdatePlaylistDto true "Playlist update dto"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
 // @Success 200 {object} map[string]interface{} "Playlist updated"
 // @Failure 400 {object} error "invalid parsing JSON"
+// @Failure 412 {object} error "playlist was changed by another request"
 // @Failure 500 {object} error "internal server error"
 // @Router /playlist/{id} [put]
 // @Security ApiKeyAuth
@@ -159,6 +202,12 @@ func (h *Handler) HandleUpdatePlaylistById(writer http.ResponseWriter, request *
 		return
 	}
 
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
 	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
 	if err != nil {
 		h.log.Error("HANDLER: error getting playlist id: ", err)
@@ -167,17 +216,28 @@ func (h *Handler) HandleUpdatePlaylistById(writer http.ResponseWriter, request *
 	}
 
 	updated := &models.Playlist{
-		Name: input.Name,
-		ID:   playlistId,
+		Name:            input.Name,
+		ID:              playlistId,
+		DuplicatePolicy: input.DuplicatePolicy,
+	}
+
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
 	}
 
-	err = h.services.PlayList.UpdatePlaylistById(userId, updated)
+	err = h.services.PlayList.UpdatePlaylistById(change, updated)
+	if h.versionMismatch(writer, err) {
+		return
+	}
 	if err != nil {
 		h.log.Error("HANDLER: error updating playlist: ", err)
 		utils.WriteError(writer, http.StatusInternalServerError, err)
 		return
 	}
+	updated.SnapshotId = change.SnapshotId
 
+	setVersion(writer, change)
 	h.log.Info("HANDLER: playlist updated: ", updated)
 	utils.WriteJSON(writer, http.StatusOK, updated)
 }
@@ -185,12 +245,14 @@ func (h *Handler) HandleUpdatePlaylistById(writer http.ResponseWriter, request *
 // HandleDeletePlaylistById
 // @Summary Delete playlist by id
 // @Tags playlist
-// @Description Delete playlist by id
+// @Description Moves the playlist to the trash, from where it can be restored until the retention window ends
 // @Accept  json
 // @Produce  json
 // @Param id path int true "Playlist id"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
 // @Success 200 {object} map[string]interface{} "Playlist deleted"
 // @Failure 400 {object} error "invalid playlist id"
+// @Failure 412 {object} error "playlist was changed by another request"
 // @Failure 500 {object} error "internal server error"
 // @Router /playlist/{id} [delete]
 // @Security ApiKeyAuth
@@ -209,13 +271,22 @@ func (h *Handler) HandleDeletePlaylistById(writer http.ResponseWriter, request *
 		return
 	}
 
-	err = h.services.PlayList.DeletePlaylistById(userId, playlistId)
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	err = h.services.PlayList.DeletePlaylistById(change)
+	if h.versionMismatch(writer, err) {
+		return
+	}
 	if err != nil {
 		h.log.Error("HANDLER: error deleting playlist: ", err)
 		utils.WriteError(writer, http.StatusInternalServerError, err)
 		return
 	}
 
+	setVersion(writer, change)
 	h.log.Info("HANDLER: playlist deleted: ", playlistId)
 	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
 		"id": playlistId,
@@ -10,6 +10,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"music-service/internal/models"
+	"music-service/internal/repository"
 	"music-service/internal/service"
 	mock_service "music-service/internal/service/mocks"
 	"music-service/pkg/logging"
@@ -24,10 +25,12 @@ func TestHandler_HandleCreatePlaylist(t *testing.T) {
 	defer ctrl.Finish()
 
 	playlistService := mock_service.NewMockPlayList(ctrl)
+	quotaService := mock_service.NewMockQuota(ctrl)
 
 	handler := &Handler{
 		services: &service.Service{
 			PlayList: playlistService,
+			Quota:    quotaService,
 		},
 		log: logging.NewLogger(),
 	}
@@ -51,10 +54,13 @@ func TestHandler_HandleCreatePlaylist(t *testing.T) {
 				playlistService.EXPECT().CreatePlaylist(&models.Playlist{
 					Name:   "test playlist",
 					UserId: 1,
-				}).Return(int64(1), nil)
+				}).DoAndReturn(func(playlist *models.Playlist) (int64, error) {
+					playlist.SnapshotId = 5
+					return 1, nil
+				})
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"status":"ok","id":1}`,
+			expectedBody:   `{"status":"ok","id":1,"snapshot_id":5}`,
 			isJSON:         true,
 		},
 		{
@@ -72,6 +78,20 @@ func TestHandler_HandleCreatePlaylist(t *testing.T) {
 			userId: 1,
 			isJSON: true,
 		},
+		{
+			name: "playlist quota exceeded",
+			input: models.CreatePlaylistDto{
+				Name: "test playlist",
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"quota exceeded: at most 2 playlists allowed"}`,
+			mockSetup: func() {
+				playlistService.EXPECT().CreatePlaylist(gomock.Any()).
+					Return(int64(0), fmt.Errorf("%w: at most 2 playlists allowed", repository.ErrQuotaExceeded))
+			},
+			userId: 1,
+			isJSON: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -116,6 +136,7 @@ func TestHandler_HandleGetPlaylistById(t *testing.T) {
 		name           string
 		playlistId     int
 		userId         int
+		ifNoneMatch    string
 		mockSetup      func()
 		expectedStatus int
 		expectedBody   string
@@ -127,12 +148,44 @@ func TestHandler_HandleGetPlaylistById(t *testing.T) {
 			userId:     1,
 			mockSetup: func() {
 				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{
-					ID:   1,
-					Name: "test playlist",
+					ID:      1,
+					Name:    "test playlist",
+					Version: 3,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"playlist":{"id":1,"name":"test playlist","user_id":0,"version":3}}`,
+			isJSON:         true,
+		},
+		{
+			name:        "playlist not modified",
+			playlistId:  1,
+			userId:      1,
+			ifNoneMatch: `"3-af9253a9a09f4ed2"`,
+			mockSetup: func() {
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{
+					ID:      1,
+					Name:    "test playlist",
+					Version: 3,
+				}, nil)
+			},
+			expectedStatus: http.StatusNotModified,
+			expectedBody:   "",
+		},
+		{
+			name:        "playlist renamed since cached",
+			playlistId:  1,
+			userId:      1,
+			ifNoneMatch: `"3-af9253a9a09f4ed2"`,
+			mockSetup: func() {
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{
+					ID:      1,
+					Name:    "renamed playlist",
+					Version: 3,
 				}, nil)
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"playlist":{"id":1,"name":"test playlist","user_id":0}}`,
+			expectedBody:   `{"playlist":{"id":1,"name":"renamed playlist","user_id":0,"version":3}}`,
 			isJSON:         true,
 		},
 		{
@@ -151,6 +204,9 @@ func TestHandler_HandleGetPlaylistById(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			rec := httptest.NewRecorder()
 			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/playlist/%d", tt.playlistId), nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set(ifNoneMatchHeader, tt.ifNoneMatch)
+			}
 			chiCtx := chi.NewRouteContext()
 			chiCtx.URLParams.Add("playlistId", fmt.Sprintf("%d", tt.playlistId))
 			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
@@ -271,8 +327,10 @@ func TestHandler_HandleUpdatePlaylistById(t *testing.T) {
 		input          models.UpdatePlaylistDto
 		playlistId     int
 		userId         int
+		ifMatch        string
 		expectedStatus int
 		expectedBody   string
+		expectedETag   string
 		mockSetup      func()
 		isJSON         bool
 	}{
@@ -283,14 +341,21 @@ func TestHandler_HandleUpdatePlaylistById(t *testing.T) {
 			},
 			playlistId: 1,
 			userId:     1,
+			ifMatch:    `"2"`,
 			mockSetup: func() {
-				playlistService.EXPECT().UpdatePlaylistById(1, &models.Playlist{
+				version := 2
+				playlistService.EXPECT().UpdatePlaylistById(&models.PlaylistChange{UserId: 1, PlaylistId: 1, IfMatch: &version}, &models.Playlist{
 					Name: "test playlist",
 					ID:   1,
-				}).Return(nil)
+				}).DoAndReturn(func(change *models.PlaylistChange, playlist *models.Playlist) error {
+					change.Version = 3
+					change.SnapshotId = 5
+					return nil
+				})
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"id":1, "name":"test playlist", "user_id":0}`,
+			expectedBody:   `{"id":1, "name":"test playlist", "user_id":0, "snapshot_id":5}`,
+			expectedETag:   `"3"`,
 			isJSON:         true,
 		},
 		{
@@ -301,7 +366,7 @@ func TestHandler_HandleUpdatePlaylistById(t *testing.T) {
 			playlistId: 1,
 			userId:     1,
 			mockSetup: func() {
-				playlistService.EXPECT().UpdatePlaylistById(1, &models.Playlist{
+				playlistService.EXPECT().UpdatePlaylistById(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, &models.Playlist{
 					Name: "test playlist",
 					ID:   1,
 				}).Return(errors.New("internal server error"))
@@ -309,6 +374,48 @@ func TestHandler_HandleUpdatePlaylistById(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"internal server error"}`,
 		},
+		{
+			name: "stale playlist version",
+			input: models.UpdatePlaylistDto{
+				Name: "test playlist",
+			},
+			playlistId: 1,
+			userId:     1,
+			ifMatch:    `"1"`,
+			mockSetup: func() {
+				version := 1
+				playlistService.EXPECT().UpdatePlaylistById(&models.PlaylistChange{UserId: 1, PlaylistId: 1, IfMatch: &version}, &models.Playlist{
+					Name: "test playlist",
+					ID:   1,
+				}).Return(repository.ErrVersionMismatch)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   `{"error":"playlist was changed by another request"}`,
+		},
+		{
+			name: "invalid If-Match header",
+			input: models.UpdatePlaylistDto{
+				Name: "test playlist",
+			},
+			playlistId:     1,
+			userId:         1,
+			ifMatch:        "latest",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"If-Match must be a single ETag or *"}`,
+		},
+		{
+			name: "weak If-Match header",
+			input: models.UpdatePlaylistDto{
+				Name: "test playlist",
+			},
+			playlistId:     1,
+			userId:         1,
+			ifMatch:        `W/"2"`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   `{"error":"If-Match only matches strong ETags"}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -316,6 +423,9 @@ func TestHandler_HandleUpdatePlaylistById(t *testing.T) {
 			requestBody, _ := json.Marshal(tt.input)
 			rec := httptest.NewRecorder()
 			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/playlist/%d", tt.playlistId), strings.NewReader(string(requestBody)))
+			if tt.ifMatch != "" {
+				req.Header.Set(ifMatchHeader, tt.ifMatch)
+			}
 			chiCtx := chi.NewRouteContext()
 			chiCtx.URLParams.Add("playlistId", fmt.Sprintf("%d", tt.playlistId))
 			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
@@ -330,6 +440,7 @@ func TestHandler_HandleUpdatePlaylistById(t *testing.T) {
 
 			res := rec.Result()
 			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+			assert.Equal(t, tt.expectedETag, res.Header.Get(etagHeader))
 
 			if tt.isJSON {
 				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
@@ -368,7 +479,7 @@ func TestHandler_HandleDeletePlaylistById(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectedBody:   `{"id":1}`,
 			mockSetup: func() {
-				playlistService.EXPECT().DeletePlaylistById(1, 1).Return(nil)
+				playlistService.EXPECT().DeletePlaylistById(&models.PlaylistChange{UserId: 1, PlaylistId: 1}).Return(nil)
 			},
 			isJSON: true,
 		},
@@ -379,7 +490,7 @@ func TestHandler_HandleDeletePlaylistById(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"internal server error"}`,
 			mockSetup: func() {
-				playlistService.EXPECT().DeletePlaylistById(1, 1).Return(errors.New("internal server error"))
+				playlistService.EXPECT().DeletePlaylistById(&models.PlaylistChange{UserId: 1, PlaylistId: 1}).Return(errors.New("internal server error"))
 			},
 			isJSON: true,
 		},
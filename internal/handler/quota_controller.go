@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"music-service/internal/models"
+	"music-service/internal/repository"
+	"music-service/pkg/utils"
+	"net/http"
+	"strconv"
+)
+
+// HandleGetUsage
+// @Summary Get quota usage
+// @Tags quota
+// @Description Current consumption against the playlist, track and daily request limits, a limit of 0 means unlimited
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.Usage "Usage"
+// @Failure 500 {object} error "internal server error"
+// @Router /me/usage [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetUsage(writer http.ResponseWriter, request *http.Request) {
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	usage, err := h.services.Quota.GetUsage(userId)
+	if err != nil {
+		h.log.Error("HANDLER: error getting quota usage: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: quota usage found: ", userId)
+	utils.WriteJSON(writer, http.StatusOK, usage)
+}
+
+// HandleSetQuotaOverride
+// @Summary Override user quota
+// @Tags admin
+// @Description Replace the limits of a single user, omitted limits fall back to the configured defaults and 0 means unlimited
+// @Accept  json
+// @Produce  json
+// @Param userId path int true "User ID"
+// @Param input body models.QuotaOverride true "Quota override"
+// @Success 200 {object} models.QuotaLimits "Effective limits"
+// @Failure 400 {object} error "invalid input"
+// @Failure 403 {object} error "admin only"
+// @Failure 500 {object} error "internal server error"
+// @Router /admin/users/{userId}/quota [put]
+// @Security ApiKeyAuth
+func (h *Handler) HandleSetQuotaOverride(writer http.ResponseWriter, request *http.Request) {
+	var input models.QuotaOverride
+
+	userId, err := strconv.Atoi(chi.URLParam(request, "userId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.services.Quota.SetQuotaOverride(userId, &input); err != nil {
+		h.log.Error("HANDLER: error setting quota override: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	limits, err := h.services.Quota.GetLimits(userId)
+	if err != nil {
+		h.log.Error("HANDLER: error getting quota limits: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: quota override set: ", userId)
+	utils.WriteJSON(writer, http.StatusOK, limits)
+}
+
+// quotaExceeded answers 403 when the write failed because it would take the user
+// past a quota. It returns true when the response has been written.
+func (h *Handler) quotaExceeded(writer http.ResponseWriter, err error) bool {
+	if !errors.Is(err, repository.ErrQuotaExceeded) {
+		return false
+	}
+	h.log.Error("HANDLER: quota exceeded: ", err)
+	utils.WriteError(writer, http.StatusForbidden, err)
+	return true
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/models"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_HandleGetUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	quotaService := mock_service.NewMockQuota(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Quota: quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	quotaService.EXPECT().GetUsage(1).Return(&models.Usage{
+		Playlists:       models.UsageCount{Used: 3, Limit: 500},
+		LargestPlaylist: models.UsageCount{Used: 120, Limit: 10000},
+		RequestsToday:   models.UsageCount{Used: 42, Limit: 20000},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/me/usage", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleGetUsage).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.JSONEq(t, `{"playlists":{"used":3,"limit":500},
+		"largest_playlist":{"used":120,"limit":10000},
+		"requests_today":{"used":42,"limit":20000}}`, rec.Body.String())
+}
+
+func TestHandler_HandleSetQuotaOverride(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	quotaService := mock_service.NewMockQuota(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Quota: quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "successful override",
+			body: `{"max_playlists":1000}`,
+			mockSetup: func() {
+				maxPlaylists := 1000
+				quotaService.EXPECT().SetQuotaOverride(7, &models.QuotaOverride{MaxPlaylists: &maxPlaylists}).Return(nil)
+				quotaService.EXPECT().GetLimits(7).Return(&models.QuotaLimits{
+					MaxPlaylists:         1000,
+					MaxTracksPerPlaylist: 10000,
+					MaxRequestsPerDay:    20000,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"max_playlists":1000,"max_tracks_per_playlist":10000,"max_requests_per_day":20000}`,
+		},
+		{
+			name:           "negative limit",
+			body:           `{"max_requests_per_day":-1}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("userId", "7")
+			req, _ := http.NewRequest(http.MethodPut, "/admin/users/7/quota", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleSetQuotaOverride).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
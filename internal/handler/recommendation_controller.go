@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/service"
+	"music-service/pkg/utils"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	defaultRecommendationLimit = 20
+	maxRecommendationLimit     = 100
+)
+
+var (
+	errInvalidRecommendationLimit = fmt.Errorf("limit must be between 1 and %d", maxRecommendationLimit)
+	errInvalidTarget              = errors.New("targets must be numbers, energy, danceability and valence between 0 and 1")
+)
+
+// HandleGetRecommendations
+// @Summary Get recommendations for a playlist
+// @Tags recommendations
+// @Description Tracks like the playlist's, seeded by its most frequent artists and most popular tracks. Tracks already in the playlist are left out
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param limit query int false "Number of tracks, 1 to 100, defaults to 20"
+// @Param market query string false "ISO 3166-1 alpha-2 country code, only content playable there is returned"
+// @Param target_tempo query number false "Preferred tempo in BPM"
+// @Param target_energy query number false "Preferred energy, 0 to 1"
+// @Param target_danceability query number false "Preferred danceability, 0 to 1"
+// @Param target_valence query number false "Preferred valence, 0 to 1"
+// @Param target_loudness query number false "Preferred loudness in dB"
+// @Success 200 {array} models.Song "Recommended tracks"
+// @Failure 400 {object} error "invalid query or playlist without Spotify tracks"
+// @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
+// @Router /playlist/{playlistId}/recommendations [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetRecommendations(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	songs, ok := h.recommend(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	h.log.Info("HANDLER: recommendations found: ", playlistId, len(songs))
+	utils.WriteJSON(writer, http.StatusOK, songs)
+}
+
+// HandleAppendRecommendations
+// @Summary Append recommendations to a playlist
+// @Tags recommendations
+// @Description Appends the top recommendations to the playlist, limit sets how many
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param limit query int false "Number of tracks to append, 1 to 100, defaults to 20"
+// @Param market query string false "ISO 3166-1 alpha-2 country code, only content playable there is returned"
+// @Param target_tempo query number false "Preferred tempo in BPM"
+// @Param target_energy query number false "Preferred energy, 0 to 1"
+// @Param target_danceability query number false "Preferred danceability, 0 to 1"
+// @Param target_valence query number false "Preferred valence, 0 to 1"
+// @Param target_loudness query number false "Preferred loudness in dB"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
+// @Success 200 {object} models.AddTracksReport "Added tracks"
+// @Failure 400 {object} error "invalid query or playlist without Spotify tracks"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 412 {object} error "playlist was changed by another request"
+// @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
+// @Router /playlist/{playlistId}/recommendations [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleAppendRecommendations(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	songs, ok := h.recommend(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	report, err := h.services.Song.CreateSongs(change, songs)
+	if h.versionMismatch(writer, err) {
+		return
+	}
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error inserting recommendations to playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	setVersion(writer, change)
+	h.log.Info("HANDLER: recommendations inserted to playlist: ", playlistId, len(report.Songs), len(report.Rejected))
+	utils.WriteJSON(writer, http.StatusOK, report)
+}
+
+// recommend parses the query and fetches the recommendations, answering the request
+// itself when either fails.
+func (h *Handler) recommend(writer http.ResponseWriter, request *http.Request, userId, playlistId int) ([]models.Song, bool) {
+	query, err := parseRecommendationQuery(request.URL.Query())
+	if err != nil {
+		h.log.Error("HANDLER: error parsing recommendation query: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	songs, err := h.services.Song.GetRecommendations(userId, playlistId, query)
+	if errors.Is(err, service.ErrNoRecommendationSeeds) {
+		h.log.Error("HANDLER: no recommendation seeds in playlist: ", playlistId)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return nil, false
+	}
+	if errors.Is(err, provider.ErrUnavailable) {
+		h.log.Error("HANDLER: catalog unavailable: ", err)
+		utils.WriteError(writer, http.StatusServiceUnavailable, errCatalogUnavailable)
+		return nil, false
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error getting recommendations: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return songs, true
+}
+
+func parseRecommendationQuery(values url.Values) (*models.RecommendationQuery, error) {
+	query := &models.RecommendationQuery{Limit: defaultRecommendationLimit, Targets: make(map[string]float64)}
+
+	if limit := values.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 || parsed > maxRecommendationLimit {
+			return nil, errInvalidRecommendationLimit
+		}
+		query.Limit = parsed
+	}
+
+	if market := values.Get("market"); market != "" {
+		market, err := parseMarket(market)
+		if err != nil {
+			return nil, err
+		}
+		query.Market = market
+	}
+
+	for _, feature := range models.RangeFeatures {
+		raw := values.Get("target_" + feature)
+		if raw == "" {
+			continue
+		}
+		target, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, errInvalidTarget
+		}
+		switch feature {
+		case models.FeatureEnergy, models.FeatureDanceability, models.FeatureValence:
+			if target < 0 || target > 1 {
+				return nil, errInvalidTarget
+			}
+		}
+		query.Targets[feature] = target
+	}
+
+	return query, nil
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_HandleGetRecommendations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	songService := mock_service.NewMockSong(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Song: songService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "default limit",
+			query: "",
+			mockSetup: func() {
+				songService.EXPECT().GetRecommendations(1, 1, &models.RecommendationQuery{Limit: 20, Targets: map[string]float64{}}).
+					Return([]models.Song{{ID: "1", Title: "Teardrop"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"id":"1", "title":"Teardrop", "artist":"", "album":"", "album_cover":"", "duration":0,
+				"release_date":"", "popularity":0, "preview_url":"", "external_url":""}]`,
+		},
+		{
+			name:  "tuned targets",
+			query: "?limit=5&market=gb&target_tempo=170&target_energy=0.9",
+			mockSetup: func() {
+				songService.EXPECT().GetRecommendations(1, 1, &models.RecommendationQuery{
+					Limit:   5,
+					Market:  "GB",
+					Targets: map[string]float64{models.FeatureTempo: 170, models.FeatureEnergy: 0.9},
+				}).Return([]models.Song{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:           "energy target out of range",
+			query:          "?target_energy=2",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"targets must be numbers, energy, danceability and valence between 0 and 1"}`,
+		},
+		{
+			name:           "limit too large",
+			query:          "?limit=101",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"limit must be between 1 and 100"}`,
+		},
+		{
+			name:  "empty playlist",
+			query: "",
+			mockSetup: func() {
+				songService.EXPECT().GetRecommendations(1, 1, gomock.Any()).Return(nil, service.ErrNoRecommendationSeeds)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"playlist has no Spotify tracks to seed recommendations from"}`,
+		},
+		{
+			name:  "spotify error",
+			query: "",
+			mockSetup: func() {
+				songService.EXPECT().GetRecommendations(1, 1, gomock.Any()).Return(nil, errors.New("spotify is down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"spotify is down"}`,
+		},
+		{
+			name:  "spotify unavailable",
+			query: "",
+			mockSetup: func() {
+				songService.EXPECT().GetRecommendations(1, 1, gomock.Any()).Return(nil, provider.ErrUnavailable)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"error":"catalog is unavailable, try again later"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("playlistId", "1")
+
+			req, _ := http.NewRequest(http.MethodGet, "/playlist/1/recommendations"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleGetRecommendations).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_HandleAppendRecommendations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	songService := mock_service.NewMockSong(ctrl)
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	quotaService := mock_service.NewMockQuota(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Song:     songService,
+			PlayList: playlistService,
+			Quota:    quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	songs := []models.Song{{ID: "1", Title: "Teardrop"}, {ID: "2", Title: "Angel"}}
+
+	songService.EXPECT().GetRecommendations(1, 1, &models.RecommendationQuery{Limit: 2, Targets: map[string]float64{}}).Return(songs, nil)
+	songService.EXPECT().CreateSongs(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, songs).Return(&models.AddTracksReport{Songs: songs, SnapshotId: 7}, nil)
+
+	rec := httptest.NewRecorder()
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("playlistId", "1")
+
+	req, _ := http.NewRequest(http.MethodPost, "/playlist/1/recommendations?limit=2", nil)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleAppendRecommendations).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.JSONEq(t, `{"songs":[
+		{"id":"1", "title":"Teardrop", "artist":"", "album":"", "album_cover":"", "duration":0, "release_date":"", "popularity":0, "preview_url":"", "external_url":""},
+		{"id":"2", "title":"Angel", "artist":"", "album":"", "album_cover":"", "duration":0, "release_date":"", "popularity":0, "preview_url":"", "external_url":""}],
+		"snapshot_id":7}`, rec.Body.String())
+}
@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"music-service/internal/models"
+	"music-service/internal/rules"
+	"music-service/pkg/utils"
+	"net/http"
+	"strconv"
+)
+
+// HandleCreateSmartPlaylist
+// @Summary Create smart playlist
+// @Tags smart playlists
+// @Description Creates a playlist whose tracks are computed from a rule tree over the tracks in the user's own playlists
+// @Accept  json
+// @Produce  json
+// @Param input body models.CreateSmartPlaylistDto true "Smart playlist creation dto"
+// @Success 200 {object} map[string]interface{} "Smart playlist created"
+// @Failure 400 {object} error "invalid rules"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/smart [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleCreateSmartPlaylist(writer http.ResponseWriter, request *http.Request) {
+	var input models.CreateSmartPlaylistDto
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := rules.Validate(&input.Rules); err != nil {
+		h.log.Error("HANDLER: error validating smart rules: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	playlist := &models.Playlist{
+		Name:        input.Name,
+		UserId:      userId,
+		Kind:        models.PlaylistKindSmart,
+		Rules:       &input.Rules,
+		RefreshMode: input.RefreshMode,
+	}
+
+	id, err := h.services.SmartPlaylist.CreateSmartPlaylist(playlist)
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error creating smart playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: smart playlist created: ", id)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"status":      "ok",
+		"id":          id,
+		"snapshot_id": playlist.SnapshotId,
+	})
+}
+
+// HandleUpdateSmartRules
+// @Summary Update smart playlist rules
+// @Tags smart playlists
+// @Description Replaces the rule tree of a smart playlist and refreshes its tracks
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param input body models.UpdateSmartRulesDto true "Smart rules update dto"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
+// @Success 200 {object} map[string]interface{} "Rules updated"
+// @Failure 400 {object} error "invalid rules"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 412 {object} error "playlist was changed by another request"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/rules [put]
+// @Security ApiKeyAuth
+func (h *Handler) HandleUpdateSmartRules(writer http.ResponseWriter, request *http.Request) {
+	var input models.UpdateSmartRulesDto
+
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := rules.Validate(&input.Rules); err != nil {
+		h.log.Error("HANDLER: error validating smart rules: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	err = h.services.SmartPlaylist.UpdateSmartRules(change, &input.Rules, input.RefreshMode)
+	if h.versionMismatch(writer, err) {
+		return
+	}
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error updating smart rules: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	setVersion(writer, change)
+	h.log.Info("HANDLER: smart rules updated: ", playlistId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":          playlistId,
+		"snapshot_id": change.SnapshotId,
+	})
+}
+
+// HandleRefreshSmartPlaylist
+// @Summary Refresh smart playlist
+// @Tags smart playlists
+// @Description Re-evaluates the rules and stores the resulting tracks
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
+// @Success 200 {object} map[string]interface{} "Playlist refreshed"
+// @Failure 400 {object} error "invalid playlist id"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 412 {object} error "playlist was changed by another request"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/refresh [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleRefreshSmartPlaylist(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	count, err := h.services.SmartPlaylist.RefreshSmartPlaylist(change)
+	if h.versionMismatch(writer, err) {
+		return
+	}
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error refreshing smart playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	setVersion(writer, change)
+	h.log.Info("HANDLER: smart playlist refreshed: ", playlistId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":     playlistId,
+		"tracks": count,
+	})
+}
+
+// HandleFreezeSmartPlaylist
+// @Summary Freeze smart playlist
+// @Tags smart playlists
+// @Description Saves the current tracks of a smart playlist as a new normal playlist
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param input body models.FreezePlaylistDto true "Name of the new playlist"
+// @Success 200 {object} map[string]interface{} "Playlist frozen"
+// @Failure 400 {object} error "invalid input"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/freeze [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleFreezeSmartPlaylist(writer http.ResponseWriter, request *http.Request) {
+	var input models.FreezePlaylistDto
+
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	frozen := &models.Playlist{Name: input.Name, UserId: userId}
+	id, err := h.services.SmartPlaylist.FreezeSmartPlaylist(playlistId, frozen)
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error freezing smart playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: smart playlist frozen: ", playlistId, id)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"status":      "ok",
+		"id":          id,
+		"snapshot_id": frozen.SnapshotId,
+	})
+}
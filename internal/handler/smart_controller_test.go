@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/models"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_HandleCreateSmartPlaylist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	smartService := mock_service.NewMockSmartPlaylist(ctrl)
+	quotaService := mock_service.NewMockQuota(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			SmartPlaylist: smartService,
+			Quota:         quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "successful smart playlist creation",
+			body: `{"name":"Top hits","rules":{"match":{"field":"popularity","op":">","value":60},"limit":50}}`,
+			mockSetup: func() {
+				smartService.EXPECT().CreateSmartPlaylist(gomock.Any()).
+					DoAndReturn(func(playlist *models.Playlist) (int64, error) {
+						assert.Equal(t, "Top hits", playlist.Name)
+						assert.Equal(t, models.PlaylistKindSmart, playlist.Kind)
+						assert.Equal(t, 50, playlist.Rules.Limit)
+						playlist.SnapshotId = 5
+						return 3, nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"ok","id":3,"snapshot_id":5}`,
+		},
+		{
+			name:           "invalid rules",
+			body:           `{"name":"Top hits","rules":{"match":{"field":"plays","op":">","value":60}}}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"unknown rule field \"plays\""}`,
+		},
+		{
+			name:           "missing name",
+			body:           `{"rules":{"match":{"field":"popularity","op":">","value":60}}}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, smartPlaylist, bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleCreateSmartPlaylist).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -2,11 +2,16 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"github.com/go-chi/chi/v5"
 	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/repository"
+	"music-service/internal/service"
 	"music-service/pkg/utils"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 var (
@@ -23,32 +28,108 @@ var (
 // @Param trackId path string true "Track ID"
 // @Success 200 {object} models.Song "Track"
 // @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
 // @Router /tracks/{trackId} [get]
 // @Security ApiKeyAuth
 func (h *Handler) HandleGetTrackFromSpotify(writer http.ResponseWriter, request *http.Request) {
 	trackID := chi.URLParam(request, "trackId")
 
-	track, err := h.services.Song.GetTrackByID(trackID)
+	song, err := h.services.Song.GetTrackByID(trackID)
+	if errors.Is(err, provider.ErrUnavailable) {
+		h.log.Error("HANDLER: catalog unavailable: ", err)
+		utils.WriteError(writer, http.StatusServiceUnavailable, errCatalogUnavailable)
+		return
+	}
 	if err != nil {
 		h.log.Error("HANDLER: error getting track from spotify: ", err)
 		utils.WriteError(writer, http.StatusInternalServerError, errTrackNotFound)
 		return
 	}
 
-	song := utils.MapTrackToSong(track)
 	h.log.Info("HANDLER: track mapped: ", song)
 	utils.WriteJSON(writer, http.StatusOK, song)
 }
 
+// HandleGetCatalogCacheStats
+// @Summary Get catalog cache stats
+// @Tags admin
+// @Description Hits, misses and stale answers of the track cache since the server started
+// @Produce  json
+// @Success 200 {object} models.CacheStats "Cache stats"
+// @Failure 403 {object} error "admin only"
+// @Router /admin/catalog/cache [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetCatalogCacheStats(writer http.ResponseWriter, request *http.Request) {
+	stats := h.services.Song.GetCacheStats()
+
+	h.log.Info("HANDLER: catalog cache stats found: ", stats)
+	utils.WriteJSON(writer, http.StatusOK, stats)
+}
+
+// HandleRefreshCatalog
+// @Summary Refresh stale track metadata
+// @Tags admin
+// @Description Runs the metadata refresher now instead of waiting for its schedule and reports what it did
+// @Produce  json
+// @Success 200 {object} models.RefreshReport "Refresh report"
+// @Failure 403 {object} error "admin only"
+// @Failure 409 {object} error "a metadata refresh is already running"
+// @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
+// @Router /admin/catalog/refresh [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleRefreshCatalog(writer http.ResponseWriter, request *http.Request) {
+	report, err := h.services.Refresh.RefreshStaleSongs()
+	if errors.Is(err, service.ErrRefreshRunning) {
+		h.log.Error("HANDLER: metadata refresh already running")
+		utils.WriteError(writer, http.StatusConflict, err)
+		return
+	}
+	if errors.Is(err, provider.ErrUnavailable) {
+		h.log.Error("HANDLER: catalog unavailable: ", err)
+		utils.WriteError(writer, http.StatusServiceUnavailable, errCatalogUnavailable)
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error refreshing track metadata: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: track metadata refreshed: ", report.Checked, report.Updated, len(report.Unavailable))
+	utils.WriteJSON(writer, http.StatusOK, report)
+}
+
 // HandleGetTracksFromPlaylist
 // @Summary Get tracks from playlist
 // @Tags tracks
-// @Description Get tracks from playlist
+// @Description Get the playlist entries with their track, added_at, added_by and note
 // @Accept  json
 // @Produce  json
 // @Param playlistId path int true "Playlist ID"
-// @Success 200 {object} []models.Song "Tracks"
+// @Param tag query []string false "Only tracks carrying all of these tags" collectionFormat(multi)
+// @Param sort query string false "position (default), added_at, -added_at, or tempo, energy, danceability, valence, loudness with an optional - for descending"
+// @Param added_after query string false "Only entries added at or after this RFC3339 time"
+// @Param added_before query string false "Only entries added before this RFC3339 time"
+// @Param artist_id query string false "Only tracks crediting this Spotify artist, featured artists included"
+// @Param tempo_min query number false "Lowest tempo in BPM"
+// @Param tempo_max query number false "Highest tempo in BPM"
+// @Param energy_min query number false "Lowest energy, 0 to 1"
+// @Param energy_max query number false "Highest energy, 0 to 1"
+// @Param danceability_min query number false "Lowest danceability, 0 to 1"
+// @Param danceability_max query number false "Highest danceability, 0 to 1"
+// @Param valence_min query number false "Lowest valence, 0 to 1"
+// @Param valence_max query number false "Highest valence, 0 to 1"
+// @Param loudness_min query number false "Lowest loudness in dB"
+// @Param loudness_max query number false "Highest loudness in dB"
+// @Param key query int false "Pitch class, 0 (C) to 11 (B)"
+// @Param mode query int false "1 for major, 0 for minor"
+// @Param If-None-Match header string false "ETag of a cached playlist version"
+// @Success 200 {array} models.PlaylistEntry "Tracks"
+// @Success 304 "playlist not modified"
+// @Failure 400 {object} error "tag filter has only blank names"
 // @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "artist credits of the playlist are still being resolved"
 // @Router /playlist/{playlistId}/tracks [get]
 // @Security ApiKeyAuth
 func (h *Handler) HandleGetTracksFromPlaylist(writer http.ResponseWriter, request *http.Request) {
@@ -59,6 +140,13 @@ func (h *Handler) HandleGetTracksFromPlaylist(writer http.ResponseWriter, reques
 		return
 	}
 
+	filter, err := parseEntryFilter(request.URL.Query())
+	if err != nil {
+		h.log.Error("HANDLER: error parsing track filter: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
 	userId, err := getUserId(request.Context())
 	if err != nil {
 		h.log.Error("HANDLER: error getting user id: ", err)
@@ -66,14 +154,41 @@ func (h *Handler) HandleGetTracksFromPlaylist(writer http.ResponseWriter, reques
 		return
 	}
 
-	track, err := h.services.Song.GetAllSongsFromPlaylist(userId, playlistId)
+	playlist, err := h.services.PlayList.GetPlaylistById(userId, playlistId)
 	if err != nil {
+		h.log.Error("HANDLER: error getting playlist from db: ", err)
 		utils.WriteError(writer, http.StatusInternalServerError, err)
 		return
 	}
 
+	track, err := h.services.Song.GetAllSongsFromPlaylist(userId, playlistId, filter)
+	if errors.Is(err, service.ErrArtistsPending) {
+		h.log.Error("HANDLER: artist credits pending in playlist: ", playlistId)
+		utils.WriteError(writer, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error getting tracks from playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	if tagNames := request.URL.Query()["tag"]; len(tagNames) > 0 {
+		track, err = h.services.Tag.FilterSongsByTags(userId, track, tagNames)
+		if errors.Is(err, service.ErrNoTags) {
+			h.log.Error("HANDLER: empty tag filter: ", tagNames)
+			utils.WriteError(writer, http.StatusBadRequest, err)
+			return
+		}
+		if err != nil {
+			h.log.Error("HANDLER: error filtering tracks by tags: ", err)
+			utils.WriteError(writer, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	h.log.Info("HANDLER: track founded: ", track)
-	utils.WriteJSON(writer, http.StatusOK, track)
+	writeTagged(writer, request, playlist.Version, track)
 }
 
 // HandleInsertTrackToPlaylist
@@ -84,8 +199,13 @@ func (h *Handler) HandleGetTracksFromPlaylist(writer http.ResponseWriter, reques
 // @Produce  json
 // @Param playlistId path int true "Playlist ID"
 // @Param trackId path string true "Track ID"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
 // @Success 200 {object} models.Song "Track"
+// @Failure 409 {object} error "track is already in the playlist"
+// @Failure 412 {object} error "playlist was changed by another request"
+// @Failure 403 {object} error "quota exceeded"
 // @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
 // @Router /tracks/{trackId}/playlist/{playlistId} [post]
 // @Security ApiKeyAuth
 func (h *Handler) HandleInsertTrackToPlaylist(writer http.ResponseWriter, request *http.Request) {
@@ -105,30 +225,146 @@ func (h *Handler) HandleInsertTrackToPlaylist(writer http.ResponseWriter, reques
 		return
 	}
 
-	var song models.Song
-
-	track, err := h.services.Song.GetTrackByID(trackId)
+	song, err := h.services.Song.GetTrackByID(trackId)
+	if errors.Is(err, provider.ErrUnavailable) {
+		h.log.Error("HANDLER: catalog unavailable: ", err)
+		utils.WriteError(writer, http.StatusServiceUnavailable, errCatalogUnavailable)
+		return
+	}
 	if err != nil {
 		h.log.Error("HANDLER: error getting track from spotify: ", err)
 		utils.WriteError(writer, http.StatusInternalServerError, errTrackNotFound)
 		return
 	}
 
-	song = utils.MapTrackToSong(track)
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
 
-	_, err = h.services.Song.CreateSong(userId, playlistId, &song)
+	_, err = h.services.Song.CreateSong(change, song)
+	if h.versionMismatch(writer, err) {
+		return
+	}
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if errors.Is(err, repository.ErrDuplicateSong) {
+		h.log.Error("HANDLER: duplicate track rejected: ", err)
+		utils.WriteError(writer, http.StatusConflict, err)
+		return
+	}
 	if err != nil {
 		h.log.Error("HANDLER: error inserting track to playlist: ", err)
 		utils.WriteError(writer, http.StatusInternalServerError, err)
 		return
 	}
 
+	setVersion(writer, change)
 	h.log.Info("HANDLER: track inserted to playlist: ", song)
 	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
-		"song": song,
+		"song":        song,
+		"snapshot_id": change.SnapshotId,
 	})
 }
 
+// HandleAddTracksToPlaylist
+// @Summary Insert several tracks
+// @Tags tracks
+// @Description Appends the tracks in order the same way a single insert does, for instance the candidates confirmed from a track list preview. Ids the playlist's duplicate policy rejects are listed instead of failing the request
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
+// @Param input body models.AddTracksDto true "Spotify track ids or URLs"
+// @Success 200 {object} models.AddTracksReport "Added tracks"
+// @Failure 400 {object} error "invalid input"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 412 {object} error "playlist was changed by another request"
+// @Failure 500 {object} error "internal server error"
+// @Failure 503 {object} error "catalog is unavailable"
+// @Router /playlist/{playlistId}/tracks [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleAddTracksToPlaylist(writer http.ResponseWriter, request *http.Request) {
+	var input models.AddTracksDto
+
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	trackIds := make([]string, 0, len(input.TrackIds))
+	for _, value := range input.TrackIds {
+		trackId := strings.TrimSpace(value)
+		if !utils.IsSpotifyID(trackId) {
+			trackId = utils.ParseSpotifyTrackID(value)
+		}
+		if trackId == "" {
+			h.log.Error("HANDLER: error parsing track id: ", value)
+			utils.WriteError(writer, http.StatusBadRequest, fmt.Errorf("%q is not a Spotify track id or url", value))
+			return
+		}
+		trackIds = append(trackIds, trackId)
+	}
+
+	// All tracks are looked up before the insert, so an unknown id leaves the playlist
+	// untouched.
+	songs, err := h.services.Song.GetTracksByIDs(trackIds)
+	if errors.Is(err, provider.ErrUnavailable) {
+		h.log.Error("HANDLER: catalog unavailable: ", err)
+		utils.WriteError(writer, http.StatusServiceUnavailable, errCatalogUnavailable)
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error getting tracks from spotify: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errTrackNotFound)
+		return
+	}
+
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	report, err := h.services.Song.CreateSongs(change, songs)
+	if h.versionMismatch(writer, err) {
+		return
+	}
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error inserting tracks to playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	setVersion(writer, change)
+	h.log.Info("HANDLER: tracks inserted to playlist: ", playlistId, len(report.Songs), len(report.Rejected))
+	utils.WriteJSON(writer, http.StatusOK, report)
+}
+
 // HandleDeleteTrackFromPlaylist
 // @Summary Delete track from playlist
 // @Tags tracks
@@ -137,7 +373,10 @@ func (h *Handler) HandleInsertTrackToPlaylist(writer http.ResponseWriter, reques
 // @Produce  json
 // @Param playlistId path int true "Playlist ID"
 // @Param trackId path string true "Track ID"
+// @Param If-Match header string false "ETag of the playlist version the change is based on"
 // @Success 200 {string} string "Track removed from playlist"
+// @Failure 404 {object} error "track is not in the playlist"
+// @Failure 412 {object} error "playlist was changed by another request"
 // @Failure 500 {object} error "internal server error"
 // @Router /tracks/{trackId}/playlist/{playlistId} [delete]
 // @Security ApiKeyAuth
@@ -158,15 +397,30 @@ func (h *Handler) HandleDeleteTrackFromPlaylist(writer http.ResponseWriter, requ
 		return
 	}
 
-	err = h.services.Song.DeleteSongFromPlaylist(userId, playlistId, trackId)
+	change, ok := h.playlistChange(writer, request, userId, playlistId)
+	if !ok {
+		return
+	}
+
+	err = h.services.Song.DeleteSongFromPlaylist(change, trackId)
+	if h.versionMismatch(writer, err) {
+		return
+	}
+	if errors.Is(err, repository.ErrSongNotInPlaylist) {
+		h.log.Error("HANDLER: track not in playlist: ", trackId)
+		utils.WriteError(writer, http.StatusNotFound, err)
+		return
+	}
 	if err != nil {
 		h.log.Error("HANDLER: error deleting track from playlist: ", err)
 		utils.WriteError(writer, http.StatusInternalServerError, errTrackNotFound)
 		return
 	}
 
+	setVersion(writer, change)
 	h.log.Info("HANDLER: track removed from playlist")
 	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
-		"id": trackId,
+		"id":          trackId,
+		"snapshot_id": change.SnapshotId,
 	})
 }
@@ -8,6 +8,8 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/repository"
 	"music-service/internal/service"
 	mock_service "music-service/internal/service/mocks"
 	"music-service/pkg/logging"
@@ -15,6 +17,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestHandler_HandleGetTracksFromPlaylist(t *testing.T) {
@@ -22,9 +25,11 @@ func TestHandler_HandleGetTracksFromPlaylist(t *testing.T) {
 	defer ctrl.Finish()
 
 	songService := mock_service.NewMockSong(ctrl)
+	playlistService := mock_service.NewMockPlayList(ctrl)
 	handler := &Handler{
 		services: &service.Service{
-			Song: songService,
+			Song:     songService,
+			PlayList: playlistService,
 		},
 		log: logging.NewLogger(),
 	}
@@ -33,6 +38,8 @@ func TestHandler_HandleGetTracksFromPlaylist(t *testing.T) {
 		name           string
 		playlistId     int
 		userId         int
+		query          string
+		ifNoneMatch    string
 		mockSetup      func()
 		expectedStatus int
 		expectedBody   string
@@ -43,28 +50,171 @@ func TestHandler_HandleGetTracksFromPlaylist(t *testing.T) {
 			playlistId: 1,
 			userId:     1,
 			mockSetup: func() {
-				songService.EXPECT().GetAllSongsFromPlaylist(1, 1).Return([]*models.Song{
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{ID: 1, Version: 4}, nil)
+				songService.EXPECT().GetAllSongsFromPlaylist(1, 1, &models.EntryFilter{Sort: models.EntrySortPosition}).Return([]*models.PlaylistEntry{
 					{
-						ID:    "1",
-						Title: "test song",
+						EntryId:  10,
+						Position: 1,
+						Note:     "opener",
+						Song: models.Song{
+							ID:    "1",
+							Title: "test song",
+						},
 					},
 				}, nil)
 			},
 			isJSON:         true,
 			expectedStatus: http.StatusOK,
-			expectedBody:   `[{"album":"", "album_cover":"", "artist":"", "duration":0, "external_url":"", "id":"1", "popularity":0, "preview_url":"", "release_date":"", "title":"test song"}]`,
+			expectedBody:   `[{"entry_id":10, "position":1, "note":"opener", "album":"", "album_cover":"", "artist":"", "duration":0, "external_url":"", "id":"1", "popularity":0, "preview_url":"", "release_date":"", "title":"test song"}]`,
+		},
+		{
+			name:       "tracks sorted and filtered by added_at",
+			playlistId: 1,
+			userId:     1,
+			query:      "?sort=-added_at&added_after=2026-10-01T00:00:00Z",
+			mockSetup: func() {
+				after := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{ID: 1, Version: 4}, nil)
+				songService.EXPECT().GetAllSongsFromPlaylist(1, 1, &models.EntryFilter{AddedAfter: &after, Sort: models.EntrySortAddedAtDesc}).
+					Return([]*models.PlaylistEntry{}, nil)
+			},
+			isJSON:         true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:       "tracks filtered by artist",
+			playlistId: 1,
+			userId:     1,
+			query:      "?artist_id=6FXMGgJwohJLUSr5nVlf9X",
+			mockSetup: func() {
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{ID: 1, Version: 4}, nil)
+				songService.EXPECT().GetAllSongsFromPlaylist(1, 1, &models.EntryFilter{ArtistId: "6FXMGgJwohJLUSr5nVlf9X", Sort: models.EntrySortPosition}).
+					Return([]*models.PlaylistEntry{}, nil)
+			},
+			isJSON:         true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:       "artist filter before the backfill",
+			playlistId: 1,
+			userId:     1,
+			query:      "?artist_id=6FXMGgJwohJLUSr5nVlf9X",
+			mockSetup: func() {
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{ID: 1, Version: 4}, nil)
+				songService.EXPECT().GetAllSongsFromPlaylist(1, 1, gomock.Any()).Return(nil, service.ErrArtistsPending)
+			},
+			isJSON:         true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"error":"artist credits of this playlist are still being resolved"}`,
+		},
+		{
+			name:           "invalid sort",
+			playlistId:     1,
+			userId:         1,
+			query:          "?sort=title",
+			mockSetup:      func() {},
+			isJSON:         true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"sort must be one of position, added_at, -added_at or an audio feature such as tempo or -energy"}`,
+		},
+		{
+			name:       "tracks filtered and sorted by audio features",
+			playlistId: 1,
+			userId:     1,
+			query:      "?tempo_min=120&tempo_max=140&energy_min=0.7&key=9&mode=0&sort=-energy",
+			mockSetup: func() {
+				minTempo, maxTempo, minEnergy := 120.0, 140.0, 0.7
+				key, mode := 9, 0
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{ID: 1, Version: 4}, nil)
+				songService.EXPECT().GetAllSongsFromPlaylist(1, 1, &models.EntryFilter{
+					Features: []models.FeatureRange{
+						{Feature: models.FeatureTempo, Min: &minTempo, Max: &maxTempo},
+						{Feature: models.FeatureEnergy, Min: &minEnergy},
+					},
+					Key:  &key,
+					Mode: &mode,
+					Sort: "-energy",
+				}).Return([]*models.PlaylistEntry{{
+					EntryId:  10,
+					Position: 1,
+					Song: models.Song{
+						ID:            "1",
+						Title:         "test song",
+						AudioFeatures: &models.AudioFeatures{Tempo: 128, Energy: 0.8, Danceability: 0.7, Valence: 0.6, Key: 9, Loudness: -5.5},
+					},
+				}}, nil)
+			},
+			isJSON:         true,
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"entry_id":10, "position":1, "album":"", "album_cover":"", "artist":"", "duration":0, "external_url":"", "id":"1",
+				"popularity":0, "preview_url":"", "release_date":"", "title":"test song",
+				"audio_features":{"tempo":128, "energy":0.8, "danceability":0.7, "valence":0.6, "key":9, "mode":0, "loudness":-5.5}}]`,
+		},
+		{
+			name:           "invalid audio feature bound",
+			playlistId:     1,
+			userId:         1,
+			query:          "?tempo_min=fast",
+			mockSetup:      func() {},
+			isJSON:         true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"audio feature bounds must be numbers"}`,
+		},
+		{
+			name:           "invalid key",
+			playlistId:     1,
+			userId:         1,
+			query:          "?key=12",
+			mockSetup:      func() {},
+			isJSON:         true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"key must be a pitch class from 0 (C) to 11 (B)"}`,
 		},
 		{
 			name:       "error getting tracks from playlist",
 			playlistId: 1,
 			userId:     1,
 			mockSetup: func() {
-				songService.EXPECT().GetAllSongsFromPlaylist(1, 1).Return(nil, errors.New("test error"))
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{ID: 1, Version: 4}, nil)
+				songService.EXPECT().GetAllSongsFromPlaylist(1, 1, gomock.Any()).Return(nil, errors.New("test error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"test error"}`,
 			isJSON:         false,
 		},
+		{
+			name:        "tracks not modified",
+			playlistId:  1,
+			userId:      1,
+			ifNoneMatch: `W/"4-e9edd5de35a304d5"`,
+			mockSetup: func() {
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{ID: 1, Version: 4}, nil)
+				songService.EXPECT().GetAllSongsFromPlaylist(1, 1, gomock.Any()).Return([]*models.PlaylistEntry{
+					{EntryId: 10, Song: models.Song{ID: "a1", Title: "Song A"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusNotModified,
+			expectedBody:   "",
+			isJSON:         false,
+		},
+		{
+			name:        "tracks changed without a new version",
+			playlistId:  1,
+			userId:      1,
+			ifNoneMatch: `"4-e9edd5de35a304d5"`,
+			mockSetup: func() {
+				playlistService.EXPECT().GetPlaylistById(1, 1).Return(&models.Playlist{ID: 1, Version: 4}, nil)
+				songService.EXPECT().GetAllSongsFromPlaylist(1, 1, gomock.Any()).Return([]*models.PlaylistEntry{
+					{EntryId: 10, Song: models.Song{ID: "a1", Title: "Song A (Remastered)"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"entry_id":10, "position":0, "id":"a1", "title":"Song A (Remastered)", "artist":"", "album":"",
+				"album_cover":"", "duration":0, "external_url":"", "preview_url":"", "release_date":"", "popularity":0}]`,
+			isJSON: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -72,7 +222,10 @@ func TestHandler_HandleGetTracksFromPlaylist(t *testing.T) {
 			rec := httptest.NewRecorder()
 			chiCtx := chi.NewRouteContext()
 			chiCtx.URLParams.Add("playlistId", fmt.Sprintf("%d", tt.playlistId))
-			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/tracks/playlist/%d", tt.playlistId), nil)
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/tracks/playlist/%d%s", tt.playlistId, tt.query), nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set(ifNoneMatchHeader, tt.ifNoneMatch)
+			}
 			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
 			if tt.userId != 0 {
 				ctx := context.WithValue(req.Context(), userCtx, tt.userId)
@@ -100,9 +253,11 @@ func TestHandler_HandleDeleteTrackFromPlaylist(t *testing.T) {
 	defer ctrl.Finish()
 
 	songService := mock_service.NewMockSong(ctrl)
+	playlistService := mock_service.NewMockPlayList(ctrl)
 	handler := &Handler{
 		services: &service.Service{
-			Song: songService,
+			Song:     songService,
+			PlayList: playlistService,
 		},
 		log: logging.NewLogger(),
 	}
@@ -122,11 +277,15 @@ func TestHandler_HandleDeleteTrackFromPlaylist(t *testing.T) {
 			playlistId: 1,
 			trackId:    "1",
 			mockSetup: func() {
-				songService.EXPECT().DeleteSongFromPlaylist(1, 1, "1").Return(nil)
+				songService.EXPECT().DeleteSongFromPlaylist(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, "1").
+					DoAndReturn(func(change *models.PlaylistChange, songId string) error {
+						change.SnapshotId = 5
+						return nil
+					})
 			},
 			userId:         1,
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"id":"1"}`,
+			expectedBody:   `{"id":"1","snapshot_id":5}`,
 			isJSON:         true,
 		},
 		{
@@ -135,12 +294,24 @@ func TestHandler_HandleDeleteTrackFromPlaylist(t *testing.T) {
 			userId:     1,
 			trackId:    "1",
 			mockSetup: func() {
-				songService.EXPECT().DeleteSongFromPlaylist(1, 1, "1").Return(errTrackNotFound)
+				songService.EXPECT().DeleteSongFromPlaylist(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, "1").Return(errTrackNotFound)
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"track not found"}`,
 			isJSON:         true,
 		},
+		{
+			name:       "track not in playlist",
+			playlistId: 1,
+			userId:     1,
+			trackId:    "1",
+			mockSetup: func() {
+				songService.EXPECT().DeleteSongFromPlaylist(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, "1").Return(repository.ErrSongNotInPlaylist)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"track is not in the playlist"}`,
+			isJSON:         true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -172,3 +343,254 @@ func TestHandler_HandleDeleteTrackFromPlaylist(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_HandleInsertTrackToPlaylist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	songService := mock_service.NewMockSong(ctrl)
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	quotaService := mock_service.NewMockQuota(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Song:     songService,
+			PlayList: playlistService,
+			Quota:    quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "successful insert track",
+			mockSetup: func() {
+				songService.EXPECT().GetTrackByID("1").Return(&models.Song{ID: "1"}, nil)
+				songService.EXPECT().CreateSong(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, gomock.Any()).Return("1", nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "duplicate track rejected",
+			mockSetup: func() {
+				songService.EXPECT().GetTrackByID("1").Return(&models.Song{ID: "1"}, nil)
+				songService.EXPECT().CreateSong(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, gomock.Any()).Return("", repository.ErrDuplicateSong)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "track quota exceeded",
+			mockSetup: func() {
+				songService.EXPECT().GetTrackByID("1").Return(&models.Song{ID: "1"}, nil)
+				songService.EXPECT().CreateSong(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, gomock.Any()).
+					Return("", fmt.Errorf("%w: at most 3 tracks per playlist allowed", repository.ErrQuotaExceeded))
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("trackId", "1")
+			chiCtx.URLParams.Add("playlistId", "1")
+
+			req, _ := http.NewRequest(http.MethodPost, "/tracks/1/playlist/1", nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleInsertTrackToPlaylist).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+}
+
+func TestHandler_HandleAddTracksToPlaylist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	songService := mock_service.NewMockSong(ctrl)
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	quotaService := mock_service.NewMockQuota(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Song:     songService,
+			PlayList: playlistService,
+			Quota:    quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	const (
+		trackA = "4uLU6hMCjMI75M1A2tKUQC"
+		trackB = "6rqhFgbbKwnb9MLmUQDhG6"
+	)
+	body := `{"track_ids":["` + trackA + `", "https://open.spotify.com/track/` + trackB + `"]}`
+	track := func(id, name string) models.Song {
+		return models.Song{ID: id, Title: name, Artist: "Unknown Artist"}
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "tracks added in order",
+			body: body,
+			mockSetup: func() {
+				songs := []models.Song{track(trackA, "Song A"), track(trackB, "Song B")}
+				songService.EXPECT().GetTracksByIDs([]string{trackA, trackB}).Return(songs, nil)
+				songService.EXPECT().CreateSongs(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, songs).
+					Return(&models.AddTracksReport{Songs: songs[:1], Rejected: []string{trackB}, SnapshotId: 5}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"songs":[{"id":"` + trackA + `", "title":"Song A", "artist":"Unknown Artist", "album":"", "album_cover":"",
+				"duration":0, "release_date":"", "popularity":0, "preview_url":"", "external_url":""}],
+				"rejected":["` + trackB + `"], "snapshot_id":5}`,
+		},
+		{
+			name:           "not a track",
+			body:           `{"track_ids":["https://open.spotify.com/album/4uLU6hMCjMI75M1A2tKUQC"]}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"\"https://open.spotify.com/album/4uLU6hMCjMI75M1A2tKUQC\" is not a Spotify track id or url"}`,
+		},
+		{
+			name: "unknown track leaves the playlist alone",
+			body: body,
+			mockSetup: func() {
+				songService.EXPECT().GetTracksByIDs([]string{trackA, trackB}).Return(nil, errCatalogNotFound)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"track not found"}`,
+		},
+		{
+			name: "spotify unavailable",
+			body: body,
+			mockSetup: func() {
+				songService.EXPECT().GetTracksByIDs([]string{trackA, trackB}).Return(nil, provider.ErrUnavailable)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"error":"catalog is unavailable, try again later"}`,
+		},
+		{
+			name: "track quota exceeded",
+			body: body,
+			mockSetup: func() {
+				songs := []models.Song{track(trackA, "Song A"), track(trackB, "Song B")}
+				songService.EXPECT().GetTracksByIDs([]string{trackA, trackB}).Return(songs, nil)
+				songService.EXPECT().CreateSongs(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, songs).
+					Return(nil, fmt.Errorf("%w: at most 3 tracks per playlist allowed", repository.ErrQuotaExceeded))
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"quota exceeded: at most 3 tracks per playlist allowed"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("playlistId", "1")
+
+			req, _ := http.NewRequest(http.MethodPost, "/playlist/1/tracks", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleAddTracksToPlaylist).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_HandleGetCatalogCacheStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	songService := mock_service.NewMockSong(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Song: songService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	songService.EXPECT().GetCacheStats().Return(models.CacheStats{Hits: 12, Misses: 3, Stale: 1, Size: 4, Capacity: 100})
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin/catalog/cache", nil)
+	handler.HandleGetCatalogCacheStats(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"hits":12,"misses":3,"stale":1,"errors":0,"size":4,"capacity":100}`, rec.Body.String())
+}
+
+func TestHandler_HandleRefreshCatalog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	refreshService := mock_service.NewMockRefresh(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Refresh: refreshService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "refresh run",
+			mockSetup: func() {
+				refreshService.EXPECT().RefreshStaleSongs().Return(&models.RefreshReport{Checked: 3, Updated: 2, Unavailable: []string{"gone"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"checked":3, "updated":2, "unavailable":["gone"], "rate_limited":false}`,
+		},
+		{
+			name: "refresh already running",
+			mockSetup: func() {
+				refreshService.EXPECT().RefreshStaleSongs().Return(nil, service.ErrRefreshRunning)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":"a metadata refresh is already running"}`,
+		},
+		{
+			name: "spotify unavailable",
+			mockSetup: func() {
+				refreshService.EXPECT().RefreshStaleSongs().Return(nil, provider.ErrUnavailable)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"error":"catalog is unavailable, try again later"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/admin/catalog/refresh", nil)
+			handler.HandleRefreshCatalog(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"music-service/pkg/utils"
+	"net/http"
+	"strconv"
+)
+
+// HandleGetPlaylistStats
+// @Summary Get playlist statistics
+// @Tags stats
+// @Description Track count, duration, popularity, top artists and albums and release years of a playlist. Smart playlists report their last refresh
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Success 200 {object} models.PlaylistStats "Playlist statistics"
+// @Failure 400 {object} error "invalid playlist id"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/stats [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetPlaylistStats(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	stats, err := h.services.Stats.GetPlaylistStats(userId, playlistId)
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist stats: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist stats found: ", playlistId)
+	utils.WriteJSON(writer, http.StatusOK, stats)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/models"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_HandleGetPlaylistStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	statsService := mock_service.NewMockStats(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Stats: statsService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		playlistId     string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:       "successful get stats",
+			playlistId: "1",
+			mockSetup: func() {
+				statsService.EXPECT().GetPlaylistStats(1, 1).Return(&models.PlaylistStats{
+					PlaylistId:        1,
+					TrackCount:        2,
+					TotalDuration:     400000,
+					AveragePopularity: 55,
+					MedianPopularity:  55,
+					TopArtists:        []*models.StatCount{{Name: "Artist A", Count: 2}},
+					TopAlbums:         []*models.StatCount{{Name: "Album A", Count: 2}},
+					ReleaseYears:      []*models.YearCount{{Year: 2020, Count: 2}},
+					AudioFeatures: &models.AudioFeatureStats{
+						TrackCount: 1,
+						Tempo:      &models.Distribution{Min: 128, Max: 128, Average: 128, Buckets: []*models.Bucket{{From: 120, To: 130, Count: 1}}},
+						Keys:       []*models.StatCount{{Name: "A minor", Count: 1}},
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"playlist_id":1, "track_count":2, "total_duration":400000, "average_popularity":55,
+				"median_popularity":55, "missing_previews":0, "top_artists":[{"name":"Artist A","count":2}],
+				"top_albums":[{"name":"Album A","count":2}], "release_years":[{"year":2020,"count":2}],
+				"audio_features":{"track_count":1, "tempo":{"min":128, "max":128, "average":128, "buckets":[{"from":120, "to":130, "count":1}]},
+				"energy":null, "danceability":null, "valence":null, "loudness":null, "keys":[{"name":"A minor", "count":1}]}}`,
+		},
+		{
+			name:           "invalid playlist id",
+			playlistId:     "abc",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+		},
+		{
+			name:       "service error",
+			playlistId: "1",
+			mockSetup: func() {
+				statsService.EXPECT().GetPlaylistStats(1, 1).Return(nil, errors.New("test error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"test error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("playlistId", tt.playlistId)
+			req, _ := http.NewRequest(http.MethodGet, "/playlist/"+tt.playlistId+"/stats", nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleGetPlaylistStats).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
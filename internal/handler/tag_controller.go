@@ -0,0 +1,457 @@
+package handler
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"music-service/internal/models"
+	"music-service/internal/repository"
+	"music-service/pkg/utils"
+	"net/http"
+	"strconv"
+)
+
+// HandleGetTags
+// @Summary Get tags
+// @Tags tags
+// @Description Lists the user's tags with usage counts. With q set, returns up to 10 tags starting with it
+// @Accept  json
+// @Produce  json
+// @Param q query string false "Tag name prefix for autocomplete"
+// @Success 200 {array} models.Tag "Tags"
+// @Failure 500 {object} error "internal server error"
+// @Router /tags [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetTags(writer http.ResponseWriter, request *http.Request) {
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	tagList, err := h.services.Tag.GetTags(userId, request.URL.Query().Get("q"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting tags: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: tags found: ", len(tagList))
+	utils.WriteJSON(writer, http.StatusOK, tagList)
+}
+
+// HandleCreateTag
+// @Summary Create tag
+// @Tags tags
+// @Description Creates a tag, or returns the existing one with the same name
+// @Accept  json
+// @Produce  json
+// @Param input body models.TagDto true "Tag name"
+// @Success 200 {object} map[string]interface{} "Tag created"
+// @Failure 400 {object} error "invalid input"
+// @Failure 500 {object} error "internal server error"
+// @Router /tags [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleCreateTag(writer http.ResponseWriter, request *http.Request) {
+	var input models.TagDto
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := h.services.Tag.CreateTag(userId, input.Name)
+	if err != nil {
+		h.log.Error("HANDLER: error creating tag: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: tag created: ", id)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"status": "ok",
+		"id":     id,
+	})
+}
+
+// HandleRenameTag
+// @Summary Rename tag
+// @Tags tags
+// @Description Renames a tag everywhere it is used
+// @Accept  json
+// @Produce  json
+// @Param tagId path int true "Tag ID"
+// @Param input body models.TagDto true "New tag name"
+// @Success 200 {object} map[string]interface{} "Tag renamed"
+// @Failure 400 {object} error "invalid input"
+// @Failure 409 {object} error "tag with this name already exists"
+// @Failure 500 {object} error "internal server error"
+// @Router /tags/{tagId} [put]
+// @Security ApiKeyAuth
+func (h *Handler) HandleRenameTag(writer http.ResponseWriter, request *http.Request) {
+	var input models.TagDto
+
+	tagId, err := strconv.Atoi(chi.URLParam(request, "tagId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting tag id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.services.Tag.RenameTag(userId, tagId, input.Name)
+	if errors.Is(err, repository.ErrTagExists) {
+		h.log.Error("HANDLER: tag name taken: ", err)
+		utils.WriteError(writer, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error renaming tag: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: tag renamed: ", tagId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id": tagId,
+	})
+}
+
+// HandleDeleteTag
+// @Summary Delete tag
+// @Tags tags
+// @Description Deletes a tag and removes it from all playlists and tracks
+// @Accept  json
+// @Produce  json
+// @Param tagId path int true "Tag ID"
+// @Success 200 {object} map[string]interface{} "Tag deleted"
+// @Failure 400 {object} error "invalid tag id"
+// @Failure 500 {object} error "internal server error"
+// @Router /tags/{tagId} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) HandleDeleteTag(writer http.ResponseWriter, request *http.Request) {
+	tagId, err := strconv.Atoi(chi.URLParam(request, "tagId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting tag id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := h.services.Tag.DeleteTag(userId, tagId); err != nil {
+		h.log.Error("HANDLER: error deleting tag: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: tag deleted: ", tagId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id": tagId,
+	})
+}
+
+// HandleGetPlaylistTags
+// @Summary Get playlist tags
+// @Tags tags
+// @Description Lists the tags of a playlist
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Success 200 {array} models.Tag "Tags"
+// @Failure 400 {object} error "invalid playlist id"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/tags [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetPlaylistTags(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	tagList, err := h.services.Tag.GetPlaylistTags(userId, playlistId)
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist tags: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist tags found: ", len(tagList))
+	utils.WriteJSON(writer, http.StatusOK, tagList)
+}
+
+// HandleTagPlaylist
+// @Summary Tag playlist
+// @Tags tags
+// @Description Adds a tag to a playlist, creating the tag when it doesn't exist yet
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param input body models.TagDto true "Tag name"
+// @Success 200 {object} map[string]interface{} "Playlist tagged"
+// @Failure 400 {object} error "invalid input"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/tags [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleTagPlaylist(writer http.ResponseWriter, request *http.Request) {
+	var input models.TagDto
+
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	tagId, err := h.services.Tag.TagPlaylist(userId, playlistId, input.Name)
+	if err != nil {
+		h.log.Error("HANDLER: error tagging playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist tagged: ", playlistId, tagId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":     playlistId,
+		"tag_id": tagId,
+	})
+}
+
+// HandleUntagPlaylist
+// @Summary Untag playlist
+// @Tags tags
+// @Description Removes a tag from a playlist
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Param tagId path int true "Tag ID"
+// @Success 200 {object} map[string]interface{} "Tag removed"
+// @Failure 400 {object} error "invalid input"
+// @Failure 500 {object} error "internal server error"
+// @Router /playlist/{playlistId}/tags/{tagId} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) HandleUntagPlaylist(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	tagId, err := strconv.Atoi(chi.URLParam(request, "tagId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting tag id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := h.services.Tag.UntagPlaylist(userId, playlistId, tagId); err != nil {
+		h.log.Error("HANDLER: error untagging playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist untagged: ", playlistId, tagId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":     playlistId,
+		"tag_id": tagId,
+	})
+}
+
+// HandleGetTrackTags
+// @Summary Get track tags
+// @Tags tags
+// @Description Lists the user's tags on a track
+// @Accept  json
+// @Produce  json
+// @Param trackId path string true "Track ID"
+// @Success 200 {array} models.Tag "Tags"
+// @Failure 500 {object} error "internal server error"
+// @Router /tracks/{trackId}/tags [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetTrackTags(writer http.ResponseWriter, request *http.Request) {
+	trackId := chi.URLParam(request, "trackId")
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	tagList, err := h.services.Tag.GetSongTags(userId, trackId)
+	if err != nil {
+		h.log.Error("HANDLER: error getting track tags: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: track tags found: ", len(tagList))
+	utils.WriteJSON(writer, http.StatusOK, tagList)
+}
+
+// HandleTagTrack
+// @Summary Tag track
+// @Tags tags
+// @Description Adds a tag to a track from one of the user's playlists
+// @Accept  json
+// @Produce  json
+// @Param trackId path string true "Track ID"
+// @Param input body models.TagDto true "Tag name"
+// @Success 200 {object} map[string]interface{} "Track tagged"
+// @Failure 400 {object} error "invalid input"
+// @Failure 500 {object} error "internal server error"
+// @Router /tracks/{trackId}/tags [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleTagTrack(writer http.ResponseWriter, request *http.Request) {
+	var input models.TagDto
+
+	trackId := chi.URLParam(request, "trackId")
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := utils.ParseJSON(request, &input); err != nil {
+		h.log.Error("HANDLER: error parsing JSON: ", err)
+		utils.InvalidParsingJSON(writer)
+		return
+	}
+
+	if err := utils.Validate.Struct(input); err != nil {
+		h.log.Error("HANDLER: error validating input: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	tagId, err := h.services.Tag.TagSong(userId, trackId, input.Name)
+	if err != nil {
+		h.log.Error("HANDLER: error tagging track: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: track tagged: ", trackId, tagId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":     trackId,
+		"tag_id": tagId,
+	})
+}
+
+// HandleUntagTrack
+// @Summary Untag track
+// @Tags tags
+// @Description Removes a tag from a track
+// @Accept  json
+// @Produce  json
+// @Param trackId path string true "Track ID"
+// @Param tagId path int true "Tag ID"
+// @Success 200 {object} map[string]interface{} "Tag removed"
+// @Failure 400 {object} error "invalid tag id"
+// @Failure 500 {object} error "internal server error"
+// @Router /tracks/{trackId}/tags/{tagId} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) HandleUntagTrack(writer http.ResponseWriter, request *http.Request) {
+	trackId := chi.URLParam(request, "trackId")
+
+	tagId, err := strconv.Atoi(chi.URLParam(request, "tagId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting tag id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	if err := h.services.Tag.UntagSong(userId, trackId, tagId); err != nil {
+		h.log.Error("HANDLER: error untagging track: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: track untagged: ", trackId, tagId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"id":     trackId,
+		"tag_id": tagId,
+	})
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/models"
+	"music-service/internal/repository"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_HandleTagPlaylist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_service.NewMockTag(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Tag: tagService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "successful tag",
+			body: `{"name":"Workout"}`,
+			mockSetup: func() {
+				tagService.EXPECT().TagPlaylist(1, 1, "Workout").Return(int64(7), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"tag_id":7}`,
+		},
+		{
+			name:           "empty name",
+			body:           `{"name":""}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "service error",
+			body: `{"name":"Workout"}`,
+			mockSetup: func() {
+				tagService.EXPECT().TagPlaylist(1, 1, "Workout").Return(int64(0), errors.New("test error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"test error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("playlistId", "1")
+			req, _ := http.NewRequest(http.MethodPost, "/playlist/1/tags", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleTagPlaylist).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_HandleGetTags_Autocomplete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_service.NewMockTag(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Tag: tagService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tagService.EXPECT().GetTags(1, "wo").Return([]*models.Tag{{ID: 3, Name: "workout", Usage: 2}}, nil)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/tags?q=wo", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleGetTags).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.JSONEq(t, `[{"id":3,"name":"workout","usage":2}]`, rec.Body.String())
+}
+
+func TestHandler_HandleGetAllPlaylists_TagFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	tagService := mock_service.NewMockTag(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			PlayList: playlistService,
+			Tag:      tagService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	playlists := []*models.Playlist{{ID: 1, Name: "Run", UserId: 1}, {ID: 2, Name: "Sleep", UserId: 1}}
+	playlistService.EXPECT().GetAllPlaylists(1).Return(playlists, nil)
+	tagService.EXPECT().FilterPlaylistsByTags(1, playlists, []string{"workout", "outdoor"}).
+		Return(playlists[:1], nil)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/playlist?tag=workout&tag=outdoor", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleGetAllPlaylists).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.JSONEq(t, `[{"id":1,"name":"Run","user_id":1}]`, rec.Body.String())
+}
+
+func TestHandler_HandleRenameTag_NameTaken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_service.NewMockTag(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Tag: tagService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tagService.EXPECT().RenameTag(1, 3, "focus").Return(repository.ErrTagExists)
+
+	rec := httptest.NewRecorder()
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("tagId", "3")
+	req, _ := http.NewRequest(http.MethodPut, "/tags/3", bytes.NewBufferString(`{"name":"focus"}`))
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleRenameTag).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Result().StatusCode)
+}
+
+func TestHandler_HandleGetAllPlaylists_BlankTagFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	playlistService := mock_service.NewMockPlayList(ctrl)
+	tagService := mock_service.NewMockTag(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			PlayList: playlistService,
+			Tag:      tagService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	playlists := []*models.Playlist{{ID: 1, Name: "Run", UserId: 1}}
+	playlistService.EXPECT().GetAllPlaylists(1).Return(playlists, nil)
+	tagService.EXPECT().FilterPlaylistsByTags(1, playlists, []string{" "}).Return(nil, service.ErrNoTags)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/playlist?tag=%20", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleGetAllPlaylists).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"music-service/pkg/utils"
+	"net/http"
+	"strconv"
+)
+
+// HandleGetTrash
+// @Summary Get trash
+// @Tags trash
+// @Description Lists deleted playlists together with the time they will be purged
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} models.TrashedPlaylist "Deleted playlists"
+// @Failure 500 {object} error "internal server error"
+// @Router /trash [get]
+// @Security ApiKeyAuth
+func (h *Handler) HandleGetTrash(writer http.ResponseWriter, request *http.Request) {
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	playlists, err := h.services.Trash.GetTrash(userId)
+	if err != nil {
+		h.log.Error("HANDLER: error getting trash: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: trashed playlists found: ", len(playlists))
+	utils.WriteJSON(writer, http.StatusOK, playlists)
+}
+
+// HandleRestorePlaylist
+// @Summary Restore playlist
+// @Tags trash
+// @Description Restores a deleted playlist with its tracks, history and tags
+// @Accept  json
+// @Produce  json
+// @Param playlistId path int true "Playlist ID"
+// @Success 200 {object} map[string]interface{} "Playlist restored"
+// @Failure 400 {object} error "invalid playlist id"
+// @Failure 403 {object} error "quota exceeded"
+// @Failure 500 {object} error "internal server error"
+// @Router /trash/{playlistId}/restore [post]
+// @Security ApiKeyAuth
+func (h *Handler) HandleRestorePlaylist(writer http.ResponseWriter, request *http.Request) {
+	playlistId, err := strconv.Atoi(chi.URLParam(request, "playlistId"))
+	if err != nil {
+		h.log.Error("HANDLER: error getting playlist id: ", err)
+		utils.WriteError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	userId, err := getUserId(request.Context())
+	if err != nil {
+		h.log.Error("HANDLER: error getting user id: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, errContext)
+		return
+	}
+
+	err = h.services.Trash.RestorePlaylist(userId, playlistId)
+	if h.quotaExceeded(writer, err) {
+		return
+	}
+	if err != nil {
+		h.log.Error("HANDLER: error restoring playlist: ", err)
+		utils.WriteError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("HANDLER: playlist restored: ", playlistId)
+	utils.WriteJSON(writer, http.StatusOK, map[string]interface{}{
+		"status": "ok",
+		"id":     playlistId,
+	})
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"music-service/internal/models"
+	"music-service/internal/service"
+	mock_service "music-service/internal/service/mocks"
+	"music-service/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_HandleGetTrash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trashService := mock_service.NewMockTrash(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Trash: trashService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	deletedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	trashService.EXPECT().GetTrash(1).Return([]*models.TrashedPlaylist{
+		{ID: 3, Name: "Old mix", Kind: "manual", DeletedAt: deletedAt, PurgeAt: deletedAt.Add(720 * time.Hour)},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/trash", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+	http.HandlerFunc(handler.HandleGetTrash).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.JSONEq(t, `[{"id":3, "name":"Old mix", "kind":"manual",
+		"deleted_at":"2026-10-01T12:00:00Z", "purge_at":"2026-10-31T12:00:00Z"}]`, rec.Body.String())
+}
+
+func TestHandler_HandleRestorePlaylist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trashService := mock_service.NewMockTrash(ctrl)
+	quotaService := mock_service.NewMockQuota(ctrl)
+	handler := &Handler{
+		services: &service.Service{
+			Trash: trashService,
+			Quota: quotaService,
+		},
+		log: logging.NewLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		playlistId     string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:       "successful restore",
+			playlistId: "3",
+			mockSetup: func() {
+				trashService.EXPECT().RestorePlaylist(1, 3).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"ok","id":3}`,
+		},
+		{
+			name:       "not in trash",
+			playlistId: "3",
+			mockSetup: func() {
+				trashService.EXPECT().RestorePlaylist(1, 3).Return(errors.New("playlist not found in trash"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"playlist not found in trash"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			chiCtx := chi.NewRouteContext()
+			chiCtx.URLParams.Add("playlistId", tt.playlistId)
+			req, _ := http.NewRequest(http.MethodPost, "/trash/"+tt.playlistId+"/restore", nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+			req = req.WithContext(context.WithValue(req.Context(), userCtx, 1))
+
+			tt.mockSetup()
+
+			http.HandlerFunc(handler.HandleRestorePlaylist).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Result().StatusCode)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
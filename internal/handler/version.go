@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"music-service/internal/models"
+	"music-service/internal/repository"
+	"music-service/pkg/utils"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	etagHeader        = "ETag"
+	ifMatchHeader     = "If-Match"
+	ifNoneMatchHeader = "If-None-Match"
+)
+
+var (
+	errInvalidIfMatch = errors.New("If-Match must be a single ETag or *")
+	errWeakIfMatch    = errors.New("If-Match only matches strong ETags")
+)
+
+func formatETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// parseETag returns the version an ETag was issued for. Listings are tagged with the
+// version followed by a hash of their content, only the version counts for If-Match.
+// If-Match compares strongly, so a weak ETag never matches.
+func parseETag(tag string) (int, error) {
+	tag = strings.TrimSpace(tag)
+	if strings.HasPrefix(tag, "W/") {
+		return 0, errWeakIfMatch
+	}
+
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, errInvalidIfMatch
+	}
+
+	version, _, _ := strings.Cut(unquoted, "-")
+	parsed, err := strconv.Atoi(version)
+	if err != nil {
+		return 0, errInvalidIfMatch
+	}
+	return parsed, nil
+}
+
+// playlistChange starts a write to the playlist, taking the version it is based on
+// from If-Match. It returns false when the response has already been written.
+func (h *Handler) playlistChange(writer http.ResponseWriter, request *http.Request, userId, playlistId int) (*models.PlaylistChange, bool) {
+	change := &models.PlaylistChange{UserId: userId, PlaylistId: playlistId}
+	if header := request.Header.Get(ifMatchHeader); header != "" && header != "*" {
+		version, err := parseETag(header)
+		if errors.Is(err, errWeakIfMatch) {
+			h.log.Error("HANDLER: weak If-Match: ", header)
+			utils.WriteError(writer, http.StatusPreconditionFailed, err)
+			return nil, false
+		}
+		if err != nil {
+			h.log.Error("HANDLER: error parsing If-Match: ", err)
+			utils.WriteError(writer, http.StatusBadRequest, err)
+			return nil, false
+		}
+		change.IfMatch = &version
+	}
+	return change, true
+}
+
+// versionMismatch answers 412 when the write failed because the playlist had moved
+// past the version in If-Match. It returns true when the response has been written.
+func (h *Handler) versionMismatch(writer http.ResponseWriter, err error) bool {
+	if !errors.Is(err, repository.ErrVersionMismatch) {
+		return false
+	}
+	h.log.Error("HANDLER: playlist version mismatch: ", err)
+	utils.WriteError(writer, http.StatusPreconditionFailed, err)
+	return true
+}
+
+// setVersion sets the ETag of the version a committed change left the playlist at.
+func setVersion(writer http.ResponseWriter, change *models.PlaylistChange) {
+	if change.Version != 0 {
+		writer.Header().Set(etagHeader, formatETag(change.Version))
+	}
+}
+
+// writeTagged writes the body with an ETag made of the playlist version and a hash
+// of the body, or answers 304 when If-None-Match already names it. The hash catches
+// what changes a listing without a write to the playlist, such as smart playlists
+// evaluated on read, tag edits and refreshed track metadata.
+func writeTagged(writer http.ResponseWriter, request *http.Request, version int, body any) error {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	hash := fnv.New64a()
+	hash.Write(encoded)
+
+	etag := strconv.Quote(fmt.Sprintf("%d-%x", version, hash.Sum64()))
+	writer.Header().Set(etagHeader, etag)
+
+	for _, tag := range strings.Split(request.Header.Get(ifNoneMatchHeader), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			writer.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+	}
+	return utils.WriteJSON(writer, http.StatusOK, body)
+}
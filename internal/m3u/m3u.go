@@ -0,0 +1,74 @@
+// Package m3u writes playlists as extended M3U (M3U8, always UTF-8).
+package m3u
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"music-service/internal/models"
+	"strings"
+)
+
+const (
+	ContentType = "audio/x-mpegurl; charset=utf-8"
+	Extension   = ".m3u8"
+
+	SourceExternal = "external"
+	SourcePreview  = "preview"
+)
+
+var (
+	ErrInvalidSource = errors.New("source must be external or preview")
+)
+
+var lineBreaks = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// Encode streams the playlist to w. Tracks without a URL for the chosen source
+// can't be played and are skipped, their number is returned.
+func Encode(w io.Writer, name string, songs []*models.Song, source string) (int, error) {
+	if source != SourceExternal && source != SourcePreview {
+		return 0, ErrInvalidSource
+	}
+
+	buf := bufio.NewWriter(w)
+	fmt.Fprintln(buf, "#EXTM3U")
+	if name != "" {
+		fmt.Fprintf(buf, "#PLAYLIST:%s\n", clean(name))
+	}
+
+	skipped := 0
+	for _, song := range songs {
+		location := song.ExternalURL
+		if source == SourcePreview {
+			location = song.PreviewURL
+		}
+		if location == "" {
+			skipped++
+			continue
+		}
+
+		fmt.Fprintf(buf, "#EXTINF:%d,%s\n%s\n", duration(song), title(song), clean(location))
+	}
+
+	return skipped, buf.Flush()
+}
+
+// duration follows the M3U convention of -1 for an unknown length.
+func duration(song *models.Song) int {
+	if song.Duration <= 0 {
+		return -1
+	}
+	return song.Duration
+}
+
+func title(song *models.Song) string {
+	if song.Artist == "" {
+		return clean(song.Title)
+	}
+	return clean(song.Artist) + " - " + clean(song.Title)
+}
+
+func clean(value string) string {
+	return strings.TrimSpace(lineBreaks.Replace(value))
+}
@@ -0,0 +1,88 @@
+package m3u
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	songs := []*models.Song{
+		{ID: "1", Title: "Song A", Artist: "Artist X", Duration: 215, ExternalURL: "https://open.spotify.com/track/1", PreviewURL: "https://p.scdn.co/1"},
+		{ID: "2", Title: "Song\nB", Artist: "", Duration: 0, ExternalURL: "https://open.spotify.com/track/2"},
+	}
+
+	tests := []struct {
+		name            string
+		playlistName    string
+		source          string
+		expected        string
+		expectedSkipped int
+	}{
+		{
+			name:         "external urls",
+			playlistName: "Road trip",
+			source:       SourceExternal,
+			expected: "#EXTM3U\n" +
+				"#PLAYLIST:Road trip\n" +
+				"#EXTINF:215,Artist X - Song A\n" +
+				"https://open.spotify.com/track/1\n" +
+				"#EXTINF:-1,Song B\n" +
+				"https://open.spotify.com/track/2\n",
+		},
+		{
+			name:   "preview urls skip tracks without preview",
+			source: SourcePreview,
+			expected: "#EXTM3U\n" +
+				"#EXTINF:215,Artist X - Song A\n" +
+				"https://p.scdn.co/1\n",
+			expectedSkipped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			skipped, err := Encode(&buf, tt.playlistName, songs, tt.source)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+			assert.Equal(t, tt.expectedSkipped, skipped)
+		})
+	}
+}
+
+func TestEncodeEmptyPlaylist(t *testing.T) {
+	var buf bytes.Buffer
+
+	skipped, err := Encode(&buf, "", nil, SourceExternal)
+
+	require.NoError(t, err)
+	assert.Equal(t, "#EXTM3U\n", buf.String())
+	assert.Zero(t, skipped)
+}
+
+func TestEncodeInvalidSource(t *testing.T) {
+	var buf bytes.Buffer
+
+	_, err := Encode(&buf, "Road trip", nil, "spotify")
+
+	assert.True(t, errors.Is(err, ErrInvalidSource))
+	assert.Empty(t, buf.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestEncodeWriteError(t *testing.T) {
+	_, err := Encode(failingWriter{}, "Road trip", []*models.Song{{Title: "A", ExternalURL: "u"}}, SourceExternal)
+
+	assert.EqualError(t, err, "connection reset")
+}
@@ -0,0 +1,23 @@
+package models
+
+const (
+	RestoreStatusCreated = "created"
+	RestoreStatusSkipped = "skipped"
+)
+
+// RestoredPlaylist maps a playlist of the backup to the one it was restored to.
+// Skipped means an earlier restore of the same backup already brought it in.
+// MissingTracks counts the entries left out as the catalog doesn't know their track.
+type RestoredPlaylist struct {
+	SourceId      int    `json:"source_id"`
+	PlaylistId    int64  `json:"playlist_id"`
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	MissingTracks int    `json:"missing_tracks,omitempty"`
+}
+
+type RestoreReport struct {
+	Created   int                 `json:"created"`
+	Skipped   int                 `json:"skipped"`
+	Playlists []*RestoredPlaylist `json:"playlists"`
+}
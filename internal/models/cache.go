@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// CacheSettings configures the track cache. Tracks younger than TTL are served as
+// they are, for StaleTTL after that they are still served while a refresh runs in
+// the background. Size is the number of tracks kept in memory.
+type CacheSettings struct {
+	Size     int
+	TTL      time.Duration
+	StaleTTL time.Duration
+}
+
+// CacheStats counts lookups since start. Misses are the lookups that had to wait
+// for Spotify, Stale the ones answered with an outdated track and Errors the failed
+// Spotify calls, in the background or not.
+type CacheStats struct {
+	Hits     int64 `json:"hits"`
+	Misses   int64 `json:"misses"`
+	Stale    int64 `json:"stale"`
+	Errors   int64 `json:"errors"`
+	Size     int   `json:"size"`
+	Capacity int   `json:"capacity"`
+}
@@ -0,0 +1,77 @@
+package models
+
+const (
+	SearchTypeTrack  = "track"
+	SearchTypeAlbum  = "album"
+	SearchTypeArtist = "artist"
+)
+
+type Album struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Artist      string `json:"artist"`
+	AlbumType   string `json:"album_type"`
+	AlbumCover  string `json:"album_cover"`
+	ReleaseDate string `json:"release_date"`
+	ExternalURL string `json:"external_url"`
+}
+
+type Artist struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Genres      []string `json:"genres"`
+	Popularity  int      `json:"popularity"`
+	Image       string   `json:"image"`
+	ExternalURL string   `json:"external_url"`
+}
+
+type SearchQuery struct {
+	Query  string
+	Types  []string
+	Limit  int
+	Offset int
+	Market string
+}
+
+// SearchResult holds one page for each requested type, the others are left out.
+type SearchResult struct {
+	Tracks  *TrackPage  `json:"tracks,omitempty"`
+	Albums  *AlbumPage  `json:"albums,omitempty"`
+	Artists *ArtistPage `json:"artists,omitempty"`
+}
+
+type TrackPage struct {
+	Items  []Song `json:"items"`
+	Total  int    `json:"total"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+type AlbumPage struct {
+	Items  []Album `json:"items"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
+
+type ArtistPage struct {
+	Items  []Artist `json:"items"`
+	Total  int      `json:"total"`
+	Limit  int      `json:"limit"`
+	Offset int      `json:"offset"`
+}
+
+// AlbumDetails lists every track of the album in disc and track order.
+type AlbumDetails struct {
+	Album
+	TotalTracks int    `json:"total_tracks"`
+	Tracks      []Song `json:"tracks"`
+}
+
+// ArtistDetails holds the artist's top tracks in the market and the first page of
+// their albums and singles.
+type ArtistDetails struct {
+	Artist
+	TopTracks []Song     `json:"top_tracks"`
+	Albums    *AlbumPage `json:"albums"`
+}
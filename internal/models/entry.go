@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const (
+	EntrySortPosition    = "position"
+	EntrySortAddedAt     = "added_at"
+	EntrySortAddedAtDesc = "-added_at"
+)
+
+type PlaylistEntry struct {
+	EntryId  int        `json:"entry_id"`
+	Position int        `json:"position"`
+	AddedAt  *time.Time `json:"added_at,omitempty"`
+	AddedBy  *int       `json:"added_by,omitempty"`
+	Note     string     `json:"note,omitempty"`
+	Song
+}
+
+// EntryFilter narrows a track listing. Sort is one of the EntrySort values or a
+// feature from RangeFeatures, prefixed with "-" for descending order. Tracks without
+// audio features never match a feature bound and sort last.
+type EntryFilter struct {
+	AddedAfter  *time.Time
+	AddedBefore *time.Time
+	ArtistId    string
+	Features    []FeatureRange
+	Key         *int
+	Mode        *int
+	Sort        string
+}
+
+type DuplicateGroup struct {
+	Song     Song  `json:"song"`
+	EntryIds []int `json:"entry_ids"`
+}
+
+type MoveEntryDto struct {
+	Position int `json:"position" validate:"required,min=1"`
+}
+
+type EntryNoteDto struct {
+	Note string `json:"note" validate:"max=500"`
+}
@@ -0,0 +1,57 @@
+package models
+
+// Audio features that can bound or order a track listing, by query parameter name.
+const (
+	FeatureTempo        = "tempo"
+	FeatureEnergy       = "energy"
+	FeatureDanceability = "danceability"
+	FeatureValence      = "valence"
+	FeatureLoudness     = "loudness"
+)
+
+var RangeFeatures = []string{FeatureTempo, FeatureEnergy, FeatureDanceability, FeatureValence, FeatureLoudness}
+
+// AudioFeatures is Spotify's analysis of a track. Key is a pitch class, 0 is C and
+// 11 is B, Mode is 1 for major and 0 for minor. Tempo is in BPM, loudness in dB.
+type AudioFeatures struct {
+	SongId       string  `json:"-"`
+	Tempo        float64 `json:"tempo"`
+	Energy       float64 `json:"energy"`
+	Danceability float64 `json:"danceability"`
+	Valence      float64 `json:"valence"`
+	Key          int     `json:"key"`
+	Mode         int     `json:"mode"`
+	Loudness     float64 `json:"loudness"`
+}
+
+// FeatureRange bounds a feature from RangeFeatures, both ends inclusive.
+type FeatureRange struct {
+	Feature string
+	Min     *float64
+	Max     *float64
+}
+
+type AudioFeatureStats struct {
+	TrackCount   int           `json:"track_count"`
+	Tempo        *Distribution `json:"tempo"`
+	Energy       *Distribution `json:"energy"`
+	Danceability *Distribution `json:"danceability"`
+	Valence      *Distribution `json:"valence"`
+	Loudness     *Distribution `json:"loudness"`
+	Keys         []*StatCount  `json:"keys"`
+}
+
+type Distribution struct {
+	Min     float64   `json:"min"`
+	Max     float64   `json:"max"`
+	Average float64   `json:"average"`
+	Buckets []*Bucket `json:"buckets"`
+}
+
+// Bucket counts the values in [From, To), the last bucket of a bounded feature
+// also holds its upper bound.
+type Bucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
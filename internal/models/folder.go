@@ -0,0 +1,38 @@
+package models
+
+const (
+	FolderDeleteMoveUp  = "move_up"
+	FolderDeleteCascade = "cascade"
+)
+
+type Folder struct {
+	ID        int         `json:"id"`
+	UserId    int         `json:"user_id"`
+	ParentId  *int        `json:"parent_id"`
+	Name      string      `json:"name"`
+	Position  int         `json:"position"`
+	Folders   []*Folder   `json:"folders,omitempty"`
+	Playlists []*Playlist `json:"playlists,omitempty"`
+}
+
+type PlaylistTree struct {
+	Folders   []*Folder   `json:"folders"`
+	Playlists []*Playlist `json:"playlists"`
+}
+
+type CreateFolderDto struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	ParentId *int   `json:"parent_id"`
+}
+
+type RenameFolderDto struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type MoveFolderDto struct {
+	ParentId *int `json:"parent_id"`
+}
+
+type MovePlaylistDto struct {
+	FolderId *int `json:"folder_id"`
+}
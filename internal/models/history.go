@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionAdd     = "add"
+	ActionRemove  = "remove"
+	ActionReorder = "reorder"
+	ActionRevert  = "revert"
+	ActionDelete  = "delete"
+)
+
+type PlaylistSnapshot struct {
+	ID         int       `json:"snapshot_id"`
+	PlaylistId int       `json:"playlist_id"`
+	Version    int       `json:"version"`
+	ActorId    int       `json:"actor_id"`
+	Action     string    `json:"action"`
+	Name       string    `json:"name"`
+	TrackCount int       `json:"track_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	Songs      []*Song   `json:"songs,omitempty"`
+}
+
+// PlaylistChange is a write to an existing playlist. When IfMatch is set the write
+// only goes through while the playlist is still at that version, Limits are the
+// quotas of the user checked before the write commits. Version and SnapshotId are
+// set once the change is committed, SnapshotId stays 0 when the change left nothing
+// to record.
+type PlaylistChange struct {
+	UserId     int
+	PlaylistId int
+	IfMatch    *int
+	Limits     *QuotaLimits
+	Version    int
+	SnapshotId int64
+}
+
+type RevertPlaylistDto struct {
+	SnapshotId int `json:"snapshot_id" validate:"required"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// ImportItem is one track of an imported document before it's matched against the
+// catalog. Duration is in seconds like on Song. Error is set when the row itself
+// couldn't be read, unmatched items without it found no track in the catalog.
+type ImportItem struct {
+	Row       int    `json:"row,omitempty"`
+	Title     string `json:"title"`
+	Artist    string `json:"artist,omitempty"`
+	Album     string `json:"album,omitempty"`
+	Duration  int    `json:"duration,omitempty"`
+	SpotifyId string `json:"spotify_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ImportReport describes an import. A dry run matches the tracks without creating
+// the playlist, so it carries no playlist id.
+type ImportReport struct {
+	DryRun     bool          `json:"dry_run,omitempty"`
+	PlaylistId int64         `json:"playlist_id,omitempty"`
+	SnapshotId int64         `json:"snapshot_id,omitempty"`
+	Imported   int           `json:"imported"`
+	Unmatched  []*ImportItem `json:"unmatched"`
+}
+
+const (
+	ImportJobRunning = "running"
+	ImportJobDone    = "done"
+	ImportJobFailed  = "failed"
+)
+
+// ImportJob tracks an import that may outlive the request. Processed counts the
+// tracks fetched from the source so far, skipped ones are local files the catalog
+// doesn't have.
+type ImportJob struct {
+	ID         int64     `json:"id"`
+	UserId     int       `json:"user_id"`
+	Source     string    `json:"source"`
+	Status     string    `json:"status"`
+	Total      int       `json:"total"`
+	Processed  int       `json:"processed"`
+	Skipped    int       `json:"skipped"`
+	PlaylistId *int64    `json:"playlist_id,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// RemotePlaylist is a playlist of the music service that is about to be imported.
+type RemotePlaylist struct {
+	ID    string
+	Name  string
+	Total int
+}
+
+// RemoteTrackPage is a page of a RemotePlaylist's tracks, with nil for local files
+// the catalog doesn't have.
+type RemoteTrackPage struct {
+	Items []*Song
+	Total int
+}
+
+type SpotifyImportDto struct {
+	Playlist string `json:"playlist" validate:"required,max=255"`
+	Name     string `json:"name" validate:"max=100"`
+}
@@ -1,16 +1,31 @@
 package models
 
+const (
+	DuplicatePolicyAllow  = "allow"
+	DuplicatePolicyReject = "reject"
+	DuplicatePolicySkip   = "skip"
+)
+
 type Playlist struct {
-	ID     int    `json:"id"`
-	Name   string `json:"name"`
-	UserId int    `json:"user_id"`
-	Songs  []Song `json:"songs,omitempty"`
+	ID              int         `json:"id"`
+	Name            string      `json:"name"`
+	UserId          int         `json:"user_id"`
+	DuplicatePolicy string      `json:"duplicate_policy,omitempty"`
+	Kind            string      `json:"kind,omitempty"`
+	Rules           *SmartRules `json:"rules,omitempty"`
+	RefreshMode     string      `json:"refresh_mode,omitempty"`
+	FolderId        *int        `json:"folder_id,omitempty"`
+	Version         int         `json:"version,omitempty"`
+	SnapshotId      int64       `json:"snapshot_id,omitempty"`
+	Songs           []Song      `json:"songs,omitempty"`
 }
 
 type CreatePlaylistDto struct {
-	Name string `json:"name"`
+	Name            string `json:"name"`
+	DuplicatePolicy string `json:"duplicate_policy" validate:"omitempty,oneof=allow reject skip"`
 }
 
 type UpdatePlaylistDto struct {
-	Name string `json:"name"`
+	Name            string `json:"name"`
+	DuplicatePolicy string `json:"duplicate_policy" validate:"omitempty,oneof=allow reject skip"`
 }
@@ -0,0 +1,26 @@
+package models
+
+// QuotaLimits holds the effective limits of a user. A limit of 0 means unlimited.
+type QuotaLimits struct {
+	MaxPlaylists         int `json:"max_playlists"`
+	MaxTracksPerPlaylist int `json:"max_tracks_per_playlist"`
+	MaxRequestsPerDay    int `json:"max_requests_per_day"`
+}
+
+// QuotaOverride replaces single limits of one user, nil keeps the configured default.
+type QuotaOverride struct {
+	MaxPlaylists         *int `json:"max_playlists" validate:"omitempty,min=0"`
+	MaxTracksPerPlaylist *int `json:"max_tracks_per_playlist" validate:"omitempty,min=0"`
+	MaxRequestsPerDay    *int `json:"max_requests_per_day" validate:"omitempty,min=0"`
+}
+
+type UsageCount struct {
+	Used  int `json:"used"`
+	Limit int `json:"limit"`
+}
+
+type Usage struct {
+	Playlists       UsageCount `json:"playlists"`
+	LargestPlaylist UsageCount `json:"largest_playlist"`
+	RequestsToday   UsageCount `json:"requests_today"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// MaxRecommendationSeeds is the most artists and tracks a recommendation can be
+// seeded with together.
+const MaxRecommendationSeeds = 5
+
+type RecommendationSeeds struct {
+	Artists []string
+	Tracks  []string
+}
+
+// RecommendationQuery tunes recommendations. Targets are keyed by the features in
+// RangeFeatures, Spotify prefers tracks close to them without filtering on them.
+type RecommendationQuery struct {
+	Limit   int
+	Market  string
+	Targets map[string]float64
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// RefreshSettings configures the metadata refresher. Songs fetched longer than
+// MaxAge ago are re-fetched, at most Batches batches of BatchSize per run and no
+// more than RequestsPerSecond Spotify calls. BatchSize is capped at Spotify's 50
+// tracks per call.
+type RefreshSettings struct {
+	MaxAge            time.Duration
+	BatchSize         int
+	Batches           int
+	RequestsPerSecond float64
+}
+
+// RefreshReport sums up one refresher run. RateLimited runs stopped early when
+// Spotify asked to slow down, the rest of the stale songs wait for the next run.
+type RefreshReport struct {
+	Checked     int      `json:"checked"`
+	Updated     int      `json:"updated"`
+	Unavailable []string `json:"unavailable"`
+	RateLimited bool     `json:"rate_limited"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "encoding/json"
+
+const (
+	PlaylistKindManual = "manual"
+	PlaylistKindSmart  = "smart"
+
+	RefreshOnRead    = "on_read"
+	RefreshScheduled = "scheduled"
+
+	// Rules only ever match the user's library; catalog is still accepted so rules
+	// saved with it keep validating.
+	RuleSourceCatalog = "catalog"
+	RuleSourceLibrary = "library"
+)
+
+type SmartRules struct {
+	Match  RuleNode  `json:"match"`
+	Source string    `json:"source,omitempty"`
+	Sort   *RuleSort `json:"sort,omitempty"`
+	Limit  int       `json:"limit,omitempty"`
+}
+
+type RuleNode struct {
+	All   []RuleNode      `json:"all,omitempty"`
+	Any   []RuleNode      `json:"any,omitempty"`
+	Field string          `json:"field,omitempty"`
+	Op    string          `json:"op,omitempty"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
+
+type RuleSort struct {
+	Field string `json:"field"`
+	Order string `json:"order,omitempty"`
+}
+
+type CreateSmartPlaylistDto struct {
+	Name        string     `json:"name" validate:"required"`
+	Rules       SmartRules `json:"rules"`
+	RefreshMode string     `json:"refresh_mode" validate:"omitempty,oneof=on_read scheduled"`
+}
+
+type UpdateSmartRulesDto struct {
+	Rules       SmartRules `json:"rules"`
+	RefreshMode string     `json:"refresh_mode" validate:"omitempty,oneof=on_read scheduled"`
+}
+
+type FreezePlaylistDto struct {
+	Name string `json:"name" validate:"required"`
+}
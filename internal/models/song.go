@@ -1,14 +1,29 @@
 package models
 
+// Song keeps Artist as the display name of the primary artist for older clients,
+// Artists lists every credited artist in Spotify's order. Rows stored before
+// artists were tracked have no Artists until the backfill reaches them.
 type Song struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Artist      string `json:"artist"`
-	Album       string `json:"album"`
-	AlbumCover  string `json:"album_cover"`
-	Duration    int    `json:"duration"`
-	ReleaseDate string `json:"release_date"`
-	Popularity  int    `json:"popularity"`
-	PreviewURL  string `json:"preview_url"`
-	ExternalURL string `json:"external_url"`
+	ID                   string         `json:"id"`
+	Title                string         `json:"title"`
+	Artist               string         `json:"artist"`
+	Artists              []SongArtist   `json:"artists,omitempty"`
+	Album                string         `json:"album"`
+	AlbumId              string         `json:"album_id,omitempty"`
+	AlbumCover           string         `json:"album_cover"`
+	Duration             int            `json:"duration"`
+	ReleaseDate          string         `json:"release_date"`
+	ReleaseDatePrecision string         `json:"release_date_precision,omitempty"`
+	Popularity           int            `json:"popularity"`
+	PreviewURL           string         `json:"preview_url"`
+	ExternalURL          string         `json:"external_url"`
+	Explicit             bool           `json:"explicit,omitempty"`
+	TrackNumber          int            `json:"track_number,omitempty"`
+	DiscNumber           int            `json:"disc_number,omitempty"`
+	AudioFeatures        *AudioFeatures `json:"audio_features,omitempty"`
+}
+
+type SongArtist struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
@@ -0,0 +1,24 @@
+package models
+
+type PlaylistStats struct {
+	PlaylistId        int                `json:"playlist_id"`
+	TrackCount        int                `json:"track_count"`
+	TotalDuration     int                `json:"total_duration"`
+	AveragePopularity float64            `json:"average_popularity"`
+	MedianPopularity  float64            `json:"median_popularity"`
+	MissingPreviews   int                `json:"missing_previews"`
+	TopArtists        []*StatCount       `json:"top_artists"`
+	TopAlbums         []*StatCount       `json:"top_albums"`
+	ReleaseYears      []*YearCount       `json:"release_years"`
+	AudioFeatures     *AudioFeatureStats `json:"audio_features"`
+}
+
+type StatCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type YearCount struct {
+	Year  int `json:"year"`
+	Count int `json:"count"`
+}
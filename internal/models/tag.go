@@ -0,0 +1,11 @@
+package models
+
+type Tag struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Usage int    `json:"usage"`
+}
+
+type TagDto struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
@@ -0,0 +1,33 @@
+package models
+
+// TracklistLine is one line of a pasted track list. Candidates are ordered by
+// confidence, from 0 for unrelated to 1 for the same artist and title.
+type TracklistLine struct {
+	Row        int               `json:"row"`
+	Text       string            `json:"text"`
+	Artist     string            `json:"artist,omitempty"`
+	Title      string            `json:"title,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Candidates []*TrackCandidate `json:"candidates"`
+}
+
+type TrackCandidate struct {
+	Confidence float64 `json:"confidence"`
+	Song       Song    `json:"song"`
+}
+
+type TracklistPreview struct {
+	Lines []*TracklistLine `json:"lines"`
+}
+
+type AddTracksDto struct {
+	TrackIds []string `json:"track_ids" validate:"required,min=1,max=100,dive,required"`
+}
+
+// AddTracksReport lists the songs added in order. Rejected holds the ids the
+// playlist's duplicate policy turned away.
+type AddTracksReport struct {
+	Songs      []Song   `json:"songs"`
+	Rejected   []string `json:"rejected,omitempty"`
+	SnapshotId int64    `json:"snapshot_id,omitempty"`
+}
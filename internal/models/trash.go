@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type TrashedPlaylist struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
@@ -0,0 +1,435 @@
+package provider
+
+import (
+	"fmt"
+	"music-service/internal/models"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// fakeTopTracks is how many top tracks the fake lists for an artist, as Spotify does.
+	fakeTopTracks        = 10
+	fakeSearchLimit      = 20
+	fakeAlbumType        = "album"
+	fakeIDLength         = 22
+	fakeTrackIDPrefix    = "fakeTrack"
+	fakeAlbumIDPrefix    = "fakeAlbum"
+	fakeArtistIDPrefix   = "fakeArtist"
+	fakePlaylistIDPrefix = "fakePlaylist"
+)
+
+// Fake serves a catalog held in memory. Albums and artists are derived from the
+// tracks, and every listing keeps the order the tracks were added in, so the same
+// catalog always gives the same answers. Markets are ignored.
+type Fake struct {
+	mu        sync.RWMutex
+	tracks    map[string]models.Song
+	order     []string
+	playlists map[string]fakePlaylist
+}
+
+type fakePlaylist struct {
+	name     string
+	trackIds []string
+}
+
+func NewFake(songs ...models.Song) *Fake {
+	f := &Fake{
+		tracks:    make(map[string]models.Song),
+		playlists: make(map[string]fakePlaylist),
+	}
+	f.Add(songs...)
+	return f
+}
+
+// Add puts the songs into the catalog, replacing tracks with the same id.
+func (f *Fake) Add(songs ...models.Song) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, song := range songs {
+		if _, ok := f.tracks[song.ID]; !ok {
+			f.order = append(f.order, song.ID)
+		}
+		f.tracks[song.ID] = song
+	}
+}
+
+// AddPlaylist puts a playlist of the tracks into the catalog. Ids the catalog
+// doesn't know are listed like local files.
+func (f *Fake) AddPlaylist(playlistId, name string, trackIds ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.playlists[playlistId] = fakePlaylist{name: name, trackIds: trackIds}
+}
+
+func (f *Fake) GetTrack(trackId string) (*models.Song, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	song, ok := f.tracks[trackId]
+	if !ok {
+		return nil, fmt.Errorf("%w: track %s", ErrNotFound, trackId)
+	}
+	return &song, nil
+}
+
+func (f *Fake) GetTracks(trackIds ...string) ([]*models.Song, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	songs := make([]*models.Song, 0, len(trackIds))
+	for _, trackId := range trackIds {
+		song, ok := f.tracks[trackId]
+		if !ok {
+			songs = append(songs, nil)
+			continue
+		}
+		songs = append(songs, &song)
+	}
+	return songs, nil
+}
+
+// Search matches when every word of the query occurs in the title, artists or
+// album of a track, the name and artist of an album or the name of an artist.
+// Field filters such as artist: only count for their value.
+func (f *Fake) Search(query *models.SearchQuery) (*models.SearchResult, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	words := searchWords(query.Query)
+	limit := query.Limit
+	if limit <= 0 {
+		limit = fakeSearchLimit
+	}
+
+	result := &models.SearchResult{}
+	for _, name := range query.Types {
+		switch name {
+		case models.SearchTypeTrack:
+			var found []models.Song
+			for _, song := range f.songs() {
+				if matches(words, song.Title, song.Artist, song.Album, artistNames(song)) {
+					found = append(found, song)
+				}
+			}
+			items, total := page(found, query.Offset, limit)
+			result.Tracks = &models.TrackPage{Items: items, Total: total, Limit: limit, Offset: query.Offset}
+		case models.SearchTypeAlbum:
+			var found []models.Album
+			for _, album := range f.albums() {
+				if matches(words, album.Name, album.Artist) {
+					found = append(found, album.Album)
+				}
+			}
+			items, total := page(found, query.Offset, limit)
+			result.Albums = &models.AlbumPage{Items: items, Total: total, Limit: limit, Offset: query.Offset}
+		case models.SearchTypeArtist:
+			var found []models.Artist
+			for _, artist := range f.artists() {
+				if matches(words, artist.Name) {
+					found = append(found, artist)
+				}
+			}
+			items, total := page(found, query.Offset, limit)
+			result.Artists = &models.ArtistPage{Items: items, Total: total, Limit: limit, Offset: query.Offset}
+		}
+	}
+	return result, nil
+}
+
+func (f *Fake) GetAlbum(albumId string) (*models.AlbumDetails, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, album := range f.albums() {
+		if album.ID == albumId {
+			return album.AlbumDetails, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: album %s", ErrNotFound, albumId)
+}
+
+func (f *Fake) GetArtist(artistId, market string) (*models.ArtistDetails, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var details *models.ArtistDetails
+	for _, artist := range f.artists() {
+		if artist.ID == artistId {
+			details = &models.ArtistDetails{Artist: artist, TopTracks: []models.Song{}}
+		}
+	}
+	if details == nil {
+		return nil, fmt.Errorf("%w: artist %s", ErrNotFound, artistId)
+	}
+
+	for _, song := range f.songs() {
+		for _, credited := range song.Artists {
+			if credited.ID == artistId {
+				details.TopTracks = append(details.TopTracks, song)
+				break
+			}
+		}
+	}
+	sort.SliceStable(details.TopTracks, func(i, j int) bool {
+		return details.TopTracks[i].Popularity > details.TopTracks[j].Popularity
+	})
+	details.TopTracks = details.TopTracks[:min(fakeTopTracks, len(details.TopTracks))]
+
+	albums := make([]models.Album, 0)
+	for _, album := range f.albums() {
+		if album.artistId == artistId {
+			albums = append(albums, album.Album)
+		}
+	}
+	items, total := page(albums, 0, artistAlbumsLimit)
+	details.Albums = &models.AlbumPage{Items: items, Total: total, Limit: artistAlbumsLimit}
+	return details, nil
+}
+
+func (f *Fake) GetPlaylist(playlistId string) (*models.RemotePlaylist, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	playlist, ok := f.playlists[playlistId]
+	if !ok {
+		return nil, fmt.Errorf("%w: playlist %s", ErrNotFound, playlistId)
+	}
+	return &models.RemotePlaylist{ID: playlistId, Name: playlist.name, Total: len(playlist.trackIds)}, nil
+}
+
+func (f *Fake) GetPlaylistTracks(playlistId string, offset, limit int) (*models.RemoteTrackPage, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	playlist, ok := f.playlists[playlistId]
+	if !ok {
+		return nil, fmt.Errorf("%w: playlist %s", ErrNotFound, playlistId)
+	}
+
+	trackIds, total := page(playlist.trackIds, offset, limit)
+	result := &models.RemoteTrackPage{Items: make([]*models.Song, 0, len(trackIds)), Total: total}
+	for _, trackId := range trackIds {
+		song, ok := f.tracks[trackId]
+		if !ok {
+			result.Items = append(result.Items, nil)
+			continue
+		}
+		result.Items = append(result.Items, &song)
+	}
+	return result, nil
+}
+
+// GetAudioFeatures serves the features the tracks were added with.
+func (f *Fake) GetAudioFeatures(trackIds ...string) ([]models.AudioFeatures, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	features := make([]models.AudioFeatures, 0, len(trackIds))
+	for _, trackId := range trackIds {
+		song, ok := f.tracks[trackId]
+		if !ok || song.AudioFeatures == nil {
+			continue
+		}
+		feature := *song.AudioFeatures
+		feature.SongId = trackId
+		features = append(features, feature)
+	}
+	return features, nil
+}
+
+// GetRecommendations recommends the other tracks of the seed artists and of the
+// albums of the seed tracks in catalog order. Targets and markets are ignored.
+func (f *Fake) GetRecommendations(seeds *models.RecommendationSeeds, query *models.RecommendationQuery) ([]string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	artists := make(map[string]bool, len(seeds.Artists))
+	for _, artistId := range seeds.Artists {
+		artists[artistId] = true
+	}
+	seedTracks := make(map[string]bool, len(seeds.Tracks))
+	albums := make(map[string]bool, len(seeds.Tracks))
+	for _, trackId := range seeds.Tracks {
+		seedTracks[trackId] = true
+		if song, ok := f.tracks[trackId]; ok && song.AlbumId != "" {
+			albums[song.AlbumId] = true
+		}
+	}
+
+	ids := make([]string, 0, query.Limit)
+	for _, song := range f.songs() {
+		if len(ids) == query.Limit {
+			break
+		}
+		if seedTracks[song.ID] {
+			continue
+		}
+		recommended := albums[song.AlbumId]
+		for _, credited := range song.Artists {
+			recommended = recommended || artists[credited.ID]
+		}
+		if recommended {
+			ids = append(ids, song.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (f *Fake) songs() []models.Song {
+	songs := make([]models.Song, 0, len(f.order))
+	for _, trackId := range f.order {
+		songs = append(songs, f.tracks[trackId])
+	}
+	return songs
+}
+
+type fakeAlbum struct {
+	*models.AlbumDetails
+	artistId string
+}
+
+// albums groups the tracks by album id in the order each album first appears. The
+// album takes its name, artist, cover and release date from its first track.
+func (f *Fake) albums() []fakeAlbum {
+	var albums []fakeAlbum
+	index := make(map[string]int)
+	for _, song := range f.songs() {
+		if song.AlbumId == "" {
+			continue
+		}
+		position, ok := index[song.AlbumId]
+		if !ok {
+			album := fakeAlbum{AlbumDetails: &models.AlbumDetails{
+				Album: models.Album{
+					ID:          song.AlbumId,
+					Name:        song.Album,
+					Artist:      song.Artist,
+					AlbumType:   fakeAlbumType,
+					AlbumCover:  song.AlbumCover,
+					ReleaseDate: song.ReleaseDate,
+				},
+				Tracks: []models.Song{},
+			}}
+			if len(song.Artists) > 0 {
+				album.artistId = song.Artists[0].ID
+			}
+			position = len(albums)
+			index[song.AlbumId] = position
+			albums = append(albums, album)
+		}
+		albums[position].Tracks = append(albums[position].Tracks, song)
+	}
+
+	for _, album := range albums {
+		sort.SliceStable(album.Tracks, func(i, j int) bool {
+			a, b := album.Tracks[i], album.Tracks[j]
+			if a.DiscNumber != b.DiscNumber {
+				return a.DiscNumber < b.DiscNumber
+			}
+			return a.TrackNumber < b.TrackNumber
+		})
+		album.TotalTracks = len(album.Tracks)
+	}
+	return albums
+}
+
+// artists lists every credited artist once. Their popularity is that of their most
+// popular track.
+func (f *Fake) artists() []models.Artist {
+	var artists []models.Artist
+	index := make(map[string]int)
+	for _, song := range f.songs() {
+		for _, credited := range song.Artists {
+			position, ok := index[credited.ID]
+			if !ok {
+				position = len(artists)
+				index[credited.ID] = position
+				artists = append(artists, models.Artist{ID: credited.ID, Name: credited.Name, Genres: []string{}})
+			}
+			artists[position].Popularity = max(artists[position].Popularity, song.Popularity)
+		}
+	}
+	return artists
+}
+
+func artistNames(song models.Song) string {
+	names := make([]string, 0, len(song.Artists))
+	for _, credited := range song.Artists {
+		names = append(names, credited.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func searchWords(query string) []string {
+	var words []string
+	for _, field := range strings.Fields(strings.ToLower(strings.ReplaceAll(query, `"`, ""))) {
+		if index := strings.Index(field, ":"); index >= 0 {
+			field = field[index+1:]
+		}
+		if field != "" {
+			words = append(words, field)
+		}
+	}
+	return words
+}
+
+func matches(words []string, values ...string) bool {
+	text := strings.ToLower(strings.Join(values, " "))
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+func page[T any](items []T, offset, limit int) ([]T, int) {
+	start := min(offset, len(items))
+	end := min(start+limit, len(items))
+	return append(make([]T, 0, end-start), items[start:end]...), len(items)
+}
+
+// fakeID returns a well-formed id that can't clash with a real one, as ids are
+// checked to look like Spotify ids before they reach the catalog.
+func fakeID(prefix string, n int) string {
+	return fmt.Sprintf("%s%0*d", prefix, fakeIDLength-len(prefix), n)
+}
+
+// DemoCatalog is a small catalog to run the fake with locally.
+func DemoCatalog() []models.Song {
+	massiveAttack := models.SongArtist{ID: fakeID(fakeArtistIDPrefix, 1), Name: "Massive Attack"}
+	portishead := models.SongArtist{ID: fakeID(fakeArtistIDPrefix, 2), Name: "Portishead"}
+
+	track := func(n int, title string, artist models.SongArtist, album string, albumN, number, duration, popularity int, released string) models.Song {
+		return models.Song{
+			ID:                   fakeID(fakeTrackIDPrefix, n),
+			Title:                title,
+			Artist:               artist.Name,
+			Artists:              []models.SongArtist{artist},
+			Album:                album,
+			AlbumId:              fakeID(fakeAlbumIDPrefix, albumN),
+			Duration:             duration,
+			ReleaseDate:          released,
+			ReleaseDatePrecision: "day",
+			Popularity:           popularity,
+			TrackNumber:          number,
+			DiscNumber:           1,
+		}
+	}
+
+	return []models.Song{
+		track(1, "Angel", massiveAttack, "Mezzanine", 1, 1, 379, 68, "1998-04-20"),
+		track(2, "Risingson", massiveAttack, "Mezzanine", 1, 2, 298, 55, "1998-04-20"),
+		track(3, "Teardrop", massiveAttack, "Mezzanine", 1, 3, 330, 78, "1998-04-20"),
+		track(4, "Inertia Creeps", massiveAttack, "Mezzanine", 1, 4, 356, 52, "1998-04-20"),
+		track(5, "Mysterons", portishead, "Dummy", 2, 1, 302, 56, "1994-08-22"),
+		track(6, "Sour Times", portishead, "Dummy", 2, 2, 251, 64, "1994-08-22"),
+		track(7, "Strangers", portishead, "Dummy", 2, 3, 238, 54, "1994-08-22"),
+		track(8, "Glory Box", portishead, "Dummy", 2, 11, 306, 72, "1994-08-22"),
+	}
+}
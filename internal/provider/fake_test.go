@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"testing"
+)
+
+var (
+	massiveAttackId = fakeID(fakeArtistIDPrefix, 1)
+	portisheadId    = fakeID(fakeArtistIDPrefix, 2)
+	mezzanineId     = fakeID(fakeAlbumIDPrefix, 1)
+	teardropId      = fakeID(fakeTrackIDPrefix, 3)
+)
+
+func titles(songs []models.Song) []string {
+	result := make([]string, 0, len(songs))
+	for _, song := range songs {
+		result = append(result, song.Title)
+	}
+	return result
+}
+
+func TestFake_GetTrack(t *testing.T) {
+	fake := NewFake(DemoCatalog()...)
+
+	song, err := fake.GetTrack(teardropId)
+	require.NoError(t, err)
+	assert.Equal(t, "Teardrop", song.Title)
+	assert.Equal(t, []models.SongArtist{{ID: massiveAttackId, Name: "Massive Attack"}}, song.Artists)
+	assert.Len(t, song.ID, 22)
+
+	_, err = fake.GetTrack(fakeID(fakeTrackIDPrefix, 99))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFake_GetTracks(t *testing.T) {
+	fake := NewFake(DemoCatalog()...)
+
+	songs, err := fake.GetTracks(teardropId, fakeID(fakeTrackIDPrefix, 99), fakeID(fakeTrackIDPrefix, 8))
+	require.NoError(t, err)
+	require.Len(t, songs, 3)
+	assert.Equal(t, "Teardrop", songs[0].Title)
+	assert.Nil(t, songs[1])
+	assert.Equal(t, "Glory Box", songs[2].Title)
+}
+
+func TestFake_Search(t *testing.T) {
+	fake := NewFake(DemoCatalog()...)
+
+	tests := []struct {
+		name            string
+		query           *models.SearchQuery
+		expectedTracks  []string
+		expectedTotal   int
+		expectedAlbums  []string
+		expectedArtists []string
+	}{
+		{
+			name:           "words anywhere in the track",
+			query:          &models.SearchQuery{Query: "massive TEAR", Types: []string{models.SearchTypeTrack}, Limit: 20},
+			expectedTracks: []string{"Teardrop"},
+			expectedTotal:  1,
+		},
+		{
+			name:           "field filters",
+			query:          &models.SearchQuery{Query: `track:"Sour Times" artist:"Portishead"`, Types: []string{models.SearchTypeTrack}, Limit: 20},
+			expectedTracks: []string{"Sour Times"},
+			expectedTotal:  1,
+		},
+		{
+			name:           "paged in catalog order",
+			query:          &models.SearchQuery{Query: "mezzanine", Types: []string{models.SearchTypeTrack}, Limit: 2, Offset: 1},
+			expectedTracks: []string{"Risingson", "Teardrop"},
+			expectedTotal:  4,
+		},
+		{
+			name:            "albums and artists",
+			query:           &models.SearchQuery{Query: "portishead", Types: []string{models.SearchTypeAlbum, models.SearchTypeArtist}, Limit: 20},
+			expectedAlbums:  []string{"Dummy"},
+			expectedArtists: []string{"Portishead"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := fake.Search(tt.query)
+			require.NoError(t, err)
+
+			if tt.expectedTracks == nil {
+				assert.Nil(t, result.Tracks)
+			} else {
+				require.NotNil(t, result.Tracks)
+				assert.Equal(t, tt.expectedTracks, titles(result.Tracks.Items))
+				assert.Equal(t, tt.expectedTotal, result.Tracks.Total)
+				assert.Equal(t, tt.query.Offset, result.Tracks.Offset)
+			}
+			if tt.expectedAlbums == nil {
+				assert.Nil(t, result.Albums)
+			} else {
+				require.NotNil(t, result.Albums)
+				require.Len(t, result.Albums.Items, len(tt.expectedAlbums))
+				assert.Equal(t, tt.expectedAlbums[0], result.Albums.Items[0].Name)
+			}
+			if tt.expectedArtists == nil {
+				assert.Nil(t, result.Artists)
+			} else {
+				require.NotNil(t, result.Artists)
+				require.Len(t, result.Artists.Items, len(tt.expectedArtists))
+				assert.Equal(t, tt.expectedArtists[0], result.Artists.Items[0].Name)
+			}
+		})
+	}
+}
+
+func TestFake_GetAlbum(t *testing.T) {
+	fake := NewFake(DemoCatalog()...)
+	// A track added later still lands in its place on the album.
+	fake.Add(models.Song{
+		ID: fakeID(fakeTrackIDPrefix, 9), Title: "Exchange", Artist: "Massive Attack",
+		Artists: []models.SongArtist{{ID: massiveAttackId, Name: "Massive Attack"}},
+		Album:   "Mezzanine", AlbumId: mezzanineId, TrackNumber: 3, DiscNumber: 2,
+	}, models.Song{
+		ID: fakeID(fakeTrackIDPrefix, 10), Title: "Man Next Door", Artist: "Massive Attack",
+		Artists: []models.SongArtist{{ID: massiveAttackId, Name: "Massive Attack"}},
+		Album:   "Mezzanine", AlbumId: mezzanineId, TrackNumber: 10, DiscNumber: 1,
+	})
+
+	album, err := fake.GetAlbum(mezzanineId)
+	require.NoError(t, err)
+	assert.Equal(t, "Mezzanine", album.Name)
+	assert.Equal(t, "Massive Attack", album.Artist)
+	assert.Equal(t, "1998-04-20", album.ReleaseDate)
+	assert.Equal(t, 6, album.TotalTracks)
+	assert.Equal(t, []string{"Angel", "Risingson", "Teardrop", "Inertia Creeps", "Man Next Door", "Exchange"}, titles(album.Tracks))
+
+	_, err = fake.GetAlbum(fakeID(fakeAlbumIDPrefix, 99))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFake_GetArtist(t *testing.T) {
+	fake := NewFake(DemoCatalog()...)
+
+	artist, err := fake.GetArtist(portisheadId, "US")
+	require.NoError(t, err)
+	assert.Equal(t, "Portishead", artist.Name)
+	assert.Equal(t, 72, artist.Popularity)
+	assert.Equal(t, []string{}, artist.Genres)
+	assert.Equal(t, []string{"Glory Box", "Sour Times", "Mysterons", "Strangers"}, titles(artist.TopTracks))
+	require.Len(t, artist.Albums.Items, 1)
+	assert.Equal(t, "Dummy", artist.Albums.Items[0].Name)
+	assert.Equal(t, 1, artist.Albums.Total)
+
+	_, err = fake.GetArtist(fakeID(fakeArtistIDPrefix, 99), "US")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFake_GetPlaylistTracks(t *testing.T) {
+	fake := NewFake(DemoCatalog()...)
+	fake.AddPlaylist("playlist1", "Trip hop", teardropId, "local.mp3", fakeID(fakeTrackIDPrefix, 8))
+
+	playlist, err := fake.GetPlaylist("playlist1")
+	require.NoError(t, err)
+	assert.Equal(t, &models.RemotePlaylist{ID: "playlist1", Name: "Trip hop", Total: 3}, playlist)
+
+	page, err := fake.GetPlaylistTracks("playlist1", 1, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, page.Items, 2)
+	assert.Nil(t, page.Items[0])
+	assert.Equal(t, "Glory Box", page.Items[1].Title)
+
+	_, err = fake.GetPlaylistTracks("playlist2", 0, 5)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFake_GetAudioFeatures(t *testing.T) {
+	teardrop := DemoCatalog()[2]
+	teardrop.AudioFeatures = &models.AudioFeatures{Tempo: 77, Energy: 0.4}
+	fake := NewFake(DemoCatalog()...)
+	fake.Add(teardrop)
+
+	features, err := fake.GetAudioFeatures(teardropId, fakeID(fakeTrackIDPrefix, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []models.AudioFeatures{{SongId: teardropId, Tempo: 77, Energy: 0.4}}, features)
+}
+
+func TestFake_GetRecommendations(t *testing.T) {
+	fake := NewFake(DemoCatalog()...)
+
+	ids, err := fake.GetRecommendations(
+		&models.RecommendationSeeds{Artists: []string{portisheadId}, Tracks: []string{teardropId}},
+		&models.RecommendationQuery{Limit: 5},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		fakeID(fakeTrackIDPrefix, 1), fakeID(fakeTrackIDPrefix, 2), fakeID(fakeTrackIDPrefix, 4),
+		fakeID(fakeTrackIDPrefix, 5), fakeID(fakeTrackIDPrefix, 6),
+	}, ids)
+}
+
+func TestNew(t *testing.T) {
+	catalog, err := New(NameFake, nil)
+	require.NoError(t, err)
+	_, err = catalog.GetTrack(teardropId)
+	assert.NoError(t, err)
+	playlist, err := catalog.GetPlaylist(fakeID(fakePlaylistIDPrefix, 1))
+	require.NoError(t, err)
+	assert.Equal(t, 8, playlist.Total)
+
+	catalog, err = New(NameSpotify, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &Spotify{}, catalog)
+
+	_, err = New("deezer", nil)
+	assert.EqualError(t, err, `unknown music provider "deezer", expected spotify or fake`)
+}
@@ -0,0 +1,67 @@
+// Package provider puts the music catalog behind an interface speaking our own
+// models, so services don't depend on the Spotify SDK. Spotify is one adapter, an
+// in-memory fake serves the same calls locally and in tests without a network.
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"github.com/zmb3/spotify"
+	"music-service/internal/models"
+)
+
+const (
+	NameSpotify = "spotify"
+	NameFake    = "fake"
+)
+
+var (
+	// ErrNotFound is returned for ids the catalog doesn't know.
+	ErrNotFound = errors.New("not found in the catalog")
+	// ErrUnavailable is returned when the catalog can't answer right now, whatever
+	// was asked. The call may succeed when retried later.
+	ErrUnavailable = errors.New("catalog is unavailable")
+	// ErrRateLimited is the ErrUnavailable of a catalog throttling this client.
+	ErrRateLimited = fmt.Errorf("%w: rate limited", ErrUnavailable)
+)
+
+type MusicProvider interface {
+	GetTrack(trackId string) (*models.Song, error)
+	// GetTracks returns the tracks in the order of the ids, with nil for tracks the
+	// catalog doesn't know or no longer serves anywhere.
+	GetTracks(trackIds ...string) ([]*models.Song, error)
+	Search(query *models.SearchQuery) (*models.SearchResult, error)
+	// GetAlbum returns the album with all its tracks in disc and track order.
+	GetAlbum(albumId string) (*models.AlbumDetails, error)
+	// GetArtist returns the artist with the top tracks in the market and the first
+	// page of albums and singles available there.
+	GetArtist(artistId, market string) (*models.ArtistDetails, error)
+	// GetPlaylist returns the playlist with its track count but without its tracks.
+	GetPlaylist(playlistId string) (*models.RemotePlaylist, error)
+	GetPlaylistTracks(playlistId string, offset, limit int) (*models.RemoteTrackPage, error)
+	// GetAudioFeatures leaves out tracks it has no analysis for.
+	GetAudioFeatures(trackIds ...string) ([]models.AudioFeatures, error)
+	// GetRecommendations returns the ids of up to query.Limit tracks like the seeds,
+	// in the order the catalog ranks them.
+	GetRecommendations(seeds *models.RecommendationSeeds, query *models.RecommendationQuery) ([]string, error)
+}
+
+// New returns the provider configured by name. The fake starts out with the demo
+// catalog and a playlist of all its tracks, and doesn't use the client.
+func New(name string, client *spotify.Client) (MusicProvider, error) {
+	switch name {
+	case NameSpotify:
+		return NewSpotify(client), nil
+	case NameFake:
+		demo := DemoCatalog()
+		trackIds := make([]string, 0, len(demo))
+		for _, song := range demo {
+			trackIds = append(trackIds, song.ID)
+		}
+		fake := NewFake(demo...)
+		fake.AddPlaylist(fakeID(fakePlaylistIDPrefix, 1), "Bristol", trackIds...)
+		return fake, nil
+	default:
+		return nil, fmt.Errorf("unknown music provider %q, expected %s or %s", name, NameSpotify, NameFake)
+	}
+}
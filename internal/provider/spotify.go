@@ -0,0 +1,346 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"github.com/zmb3/spotify"
+	"music-service/internal/models"
+	"music-service/pkg/client"
+	"music-service/pkg/utils"
+	"net/http"
+)
+
+const (
+	// tracksBatchSize is the most tracks Spotify returns from a single several-tracks call.
+	tracksBatchSize = 50
+	// artistAlbumsLimit is the largest page Spotify serves for an artist's albums.
+	artistAlbumsLimit = 50
+	// featuresBatchSize is the most tracks Spotify analyses in a single audio-features call.
+	featuresBatchSize = 100
+)
+
+var searchTypes = map[string]spotify.SearchType{
+	models.SearchTypeTrack:  spotify.SearchTypeTrack,
+	models.SearchTypeAlbum:  spotify.SearchTypeAlbum,
+	models.SearchTypeArtist: spotify.SearchTypeArtist,
+}
+
+var recommendationTargets = map[string]func(*spotify.TrackAttributes, float64) *spotify.TrackAttributes{
+	models.FeatureTempo:        (*spotify.TrackAttributes).TargetTempo,
+	models.FeatureEnergy:       (*spotify.TrackAttributes).TargetEnergy,
+	models.FeatureDanceability: (*spotify.TrackAttributes).TargetDanceability,
+	models.FeatureValence:      (*spotify.TrackAttributes).TargetValence,
+	models.FeatureLoudness:     (*spotify.TrackAttributes).TargetLoudness,
+}
+
+// Spotify serves the catalog from the Spotify Web API.
+type Spotify struct {
+	client *spotify.Client
+}
+
+func NewSpotify(client *spotify.Client) *Spotify {
+	return &Spotify{
+		client: client,
+	}
+}
+
+func (s *Spotify) GetTrack(trackId string) (*models.Song, error) {
+	track, err := s.client.GetTrack(spotify.ID(trackId))
+	if err != nil {
+		return nil, lookupError(err)
+	}
+	song := utils.MapTrackToSong(track)
+	return &song, nil
+}
+
+// GetTracks asks for the tracks in batches Spotify accepts. Spotify answers null for
+// removed tracks and an empty market list for tracks no longer playable anywhere.
+func (s *Spotify) GetTracks(trackIds ...string) ([]*models.Song, error) {
+	songs := make([]*models.Song, 0, len(trackIds))
+	for start := 0; start < len(trackIds); start += tracksBatchSize {
+		batch := trackIds[start:min(start+tracksBatchSize, len(trackIds))]
+		ids := make([]spotify.ID, 0, len(batch))
+		for _, trackId := range batch {
+			ids = append(ids, spotify.ID(trackId))
+		}
+
+		tracks, err := s.client.GetTracks(ids...)
+		if err != nil {
+			return nil, SpotifyError(err)
+		}
+		for index := range batch {
+			if index >= len(tracks) || tracks[index] == nil ||
+				(tracks[index].AvailableMarkets != nil && len(tracks[index].AvailableMarkets) == 0) {
+				songs = append(songs, nil)
+				continue
+			}
+			song := utils.MapTrackToSong(tracks[index])
+			songs = append(songs, &song)
+		}
+	}
+	return songs, nil
+}
+
+// Search runs one catalog search for all requested types. Limit and offset apply to
+// each type on its own, as they do on Spotify.
+func (s *Spotify) Search(query *models.SearchQuery) (*models.SearchResult, error) {
+	var searchType spotify.SearchType
+	for _, name := range query.Types {
+		searchType |= searchTypes[name]
+	}
+
+	options := &spotify.Options{Limit: &query.Limit, Offset: &query.Offset}
+	if query.Market != "" {
+		options.Country = &query.Market
+	}
+
+	found, err := s.client.SearchOpt(query.Query, searchType, options)
+	if err != nil {
+		return nil, SpotifyError(err)
+	}
+
+	result := &models.SearchResult{}
+	if found.Tracks != nil {
+		result.Tracks = &models.TrackPage{
+			Items:  make([]models.Song, 0, len(found.Tracks.Tracks)),
+			Total:  found.Tracks.Total,
+			Limit:  found.Tracks.Limit,
+			Offset: found.Tracks.Offset,
+		}
+		for index := range found.Tracks.Tracks {
+			result.Tracks.Items = append(result.Tracks.Items, utils.MapTrackToSong(&found.Tracks.Tracks[index]))
+		}
+	}
+	if found.Albums != nil {
+		result.Albums = &models.AlbumPage{
+			Items:  make([]models.Album, 0, len(found.Albums.Albums)),
+			Total:  found.Albums.Total,
+			Limit:  found.Albums.Limit,
+			Offset: found.Albums.Offset,
+		}
+		for index := range found.Albums.Albums {
+			result.Albums.Items = append(result.Albums.Items, utils.MapAlbum(&found.Albums.Albums[index]))
+		}
+	}
+	if found.Artists != nil {
+		result.Artists = &models.ArtistPage{
+			Items:  make([]models.Artist, 0, len(found.Artists.Artists)),
+			Total:  found.Artists.Total,
+			Limit:  found.Artists.Limit,
+			Offset: found.Artists.Offset,
+		}
+		for index := range found.Artists.Artists {
+			result.Artists.Items = append(result.Artists.Items, utils.MapArtist(&found.Artists.Artists[index]))
+		}
+	}
+
+	return result, nil
+}
+
+// GetAlbum pages through the album tracks. Album tracks come without an album of
+// their own, so each one is mapped together with the album it was listed on.
+func (s *Spotify) GetAlbum(albumId string) (*models.AlbumDetails, error) {
+	album, err := s.client.GetAlbum(spotify.ID(albumId))
+	if err != nil {
+		return nil, lookupError(err)
+	}
+
+	details := &models.AlbumDetails{
+		Album:       utils.MapAlbum(&album.SimpleAlbum),
+		TotalTracks: album.Tracks.Total,
+		Tracks:      make([]models.Song, 0, album.Tracks.Total),
+	}
+
+	page := &album.Tracks
+	for {
+		for _, track := range page.Tracks {
+			details.Tracks = append(details.Tracks, utils.MapTrackToSong(&spotify.FullTrack{
+				SimpleTrack: track,
+				Album:       album.SimpleAlbum,
+			}))
+		}
+
+		err := s.client.NextPage(page)
+		if errors.Is(err, spotify.ErrNoMorePages) {
+			return details, nil
+		}
+		if err != nil {
+			return nil, SpotifyError(err)
+		}
+	}
+}
+
+func (s *Spotify) GetArtist(artistId, market string) (*models.ArtistDetails, error) {
+	id := spotify.ID(artistId)
+	artist, err := s.client.GetArtist(id)
+	if err != nil {
+		return nil, lookupError(err)
+	}
+
+	topTracks, err := s.client.GetArtistsTopTracks(id, market)
+	if err != nil {
+		return nil, SpotifyError(err)
+	}
+
+	limit := artistAlbumsLimit
+	albums, err := s.client.GetArtistAlbumsOpt(id, &spotify.Options{Country: &market, Limit: &limit},
+		spotify.AlbumTypeAlbum, spotify.AlbumTypeSingle)
+	if err != nil {
+		return nil, SpotifyError(err)
+	}
+
+	details := &models.ArtistDetails{
+		Artist:    utils.MapArtist(artist),
+		TopTracks: make([]models.Song, 0, len(topTracks)),
+		Albums: &models.AlbumPage{
+			Items:  make([]models.Album, 0, len(albums.Albums)),
+			Total:  albums.Total,
+			Limit:  albums.Limit,
+			Offset: albums.Offset,
+		},
+	}
+	for index := range topTracks {
+		details.TopTracks = append(details.TopTracks, utils.MapTrackToSong(&topTracks[index]))
+	}
+	for index := range albums.Albums {
+		details.Albums.Items = append(details.Albums.Items, utils.MapAlbum(&albums.Albums[index]))
+	}
+	return details, nil
+}
+
+func (s *Spotify) GetPlaylist(playlistId string) (*models.RemotePlaylist, error) {
+	playlist, err := s.client.GetPlaylistOpt(spotify.ID(playlistId), "id,name,tracks.total")
+	if err != nil {
+		return nil, SpotifyError(err)
+	}
+	return &models.RemotePlaylist{
+		ID:    string(playlist.ID),
+		Name:  playlist.Name,
+		Total: int(playlist.Tracks.Total),
+	}, nil
+}
+
+func (s *Spotify) GetPlaylistTracks(playlistId string, offset, limit int) (*models.RemoteTrackPage, error) {
+	page, err := s.client.GetPlaylistTracksOpt(spotify.ID(playlistId), &spotify.Options{Limit: &limit, Offset: &offset}, "")
+	if err != nil {
+		return nil, SpotifyError(err)
+	}
+
+	result := &models.RemoteTrackPage{
+		Items: make([]*models.Song, 0, len(page.Tracks)),
+		Total: page.Total,
+	}
+	for index := range page.Tracks {
+		item := &page.Tracks[index]
+		if item.IsLocal || item.Track.ID == "" {
+			result.Items = append(result.Items, nil)
+			continue
+		}
+		song := utils.MapTrackToSong(&item.Track)
+		result.Items = append(result.Items, &song)
+	}
+	return result, nil
+}
+
+func (s *Spotify) GetAudioFeatures(trackIds ...string) ([]models.AudioFeatures, error) {
+	features := make([]models.AudioFeatures, 0, len(trackIds))
+	for start := 0; start < len(trackIds); start += featuresBatchSize {
+		batch := trackIds[start:min(start+featuresBatchSize, len(trackIds))]
+		ids := make([]spotify.ID, 0, len(batch))
+		for _, trackId := range batch {
+			ids = append(ids, spotify.ID(trackId))
+		}
+
+		found, err := s.client.GetAudioFeatures(ids...)
+		if err != nil {
+			return nil, SpotifyError(err)
+		}
+		for _, feature := range found {
+			if feature == nil {
+				continue
+			}
+			features = append(features, models.AudioFeatures{
+				SongId:       feature.ID.String(),
+				Tempo:        float64(feature.Tempo),
+				Energy:       float64(feature.Energy),
+				Danceability: float64(feature.Danceability),
+				Valence:      float64(feature.Valence),
+				Key:          feature.Key,
+				Mode:         feature.Mode,
+				Loudness:     float64(feature.Loudness),
+			})
+		}
+	}
+	return features, nil
+}
+
+// GetRecommendations passes the targets on, Spotify prefers tracks close to them
+// without filtering on them.
+func (s *Spotify) GetRecommendations(seeds *models.RecommendationSeeds, query *models.RecommendationQuery) ([]string, error) {
+	var spotifySeeds spotify.Seeds
+	for _, artistId := range seeds.Artists {
+		spotifySeeds.Artists = append(spotifySeeds.Artists, spotify.ID(artistId))
+	}
+	for _, trackId := range seeds.Tracks {
+		spotifySeeds.Tracks = append(spotifySeeds.Tracks, spotify.ID(trackId))
+	}
+
+	attributes := spotify.NewTrackAttributes()
+	for feature, target := range query.Targets {
+		attributes = recommendationTargets[feature](attributes, target)
+	}
+
+	limit := query.Limit
+	options := &spotify.Options{Limit: &limit}
+	if query.Market != "" {
+		options.Country = &query.Market
+	}
+
+	recommended, err := s.client.GetRecommendations(spotifySeeds, attributes, options)
+	if err != nil {
+		return nil, SpotifyError(err)
+	}
+
+	ids := make([]string, 0, len(recommended.Tracks))
+	for _, track := range recommended.Tracks {
+		ids = append(ids, track.ID.String())
+	}
+	return ids, nil
+}
+
+// SpotifyError translates an error of the Spotify client into the provider errors,
+// keeping the original in the chain, so callers only ever check for the provider
+// errors.
+func SpotifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, client.ErrUnavailable) {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	var apiErr spotify.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch {
+	case apiErr.Status == http.StatusNotFound:
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case apiErr.Status == http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	case apiErr.Status >= 500:
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	default:
+		return err
+	}
+}
+
+// lookupError also counts a 400 as not found, Spotify answers it for ids that are
+// malformed rather than unknown.
+func lookupError(err error) error {
+	var apiErr spotify.Error
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusBadRequest {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return SpotifyError(err)
+}
@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zmb3/spotify"
+	"music-service/internal/models"
+	"music-service/pkg/client"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (r redirectTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	request.URL.Scheme = r.target.Scheme
+	request.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+// newTestSpotify returns the adapter with every Spotify call sent to handler.
+func newTestSpotify(t *testing.T, handler http.HandlerFunc) *Spotify {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	target, _ := url.Parse(server.URL)
+	spotifyClient := spotify.NewClient(&http.Client{Transport: redirectTransport{target: target}})
+	return NewSpotify(&spotifyClient)
+}
+
+func writeError(writer http.ResponseWriter, status int, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(map[string]any{
+		"error": map[string]any{"status": status, "message": message},
+	})
+}
+
+func TestSpotify_GetTracks(t *testing.T) {
+	var requested []string
+	catalog := newTestSpotify(t, func(writer http.ResponseWriter, request *http.Request) {
+		ids := strings.Split(request.URL.Query().Get("ids"), ",")
+		requested = append(requested, request.URL.Query().Get("ids"))
+
+		tracks := make([]any, 0, len(ids))
+		for _, id := range ids {
+			switch id {
+			case "removed":
+				tracks = append(tracks, nil)
+			case "unplayable":
+				tracks = append(tracks, map[string]any{"id": id, "name": id, "available_markets": []string{}})
+			default:
+				tracks = append(tracks, map[string]any{"id": id, "name": "Song " + id, "available_markets": []string{"US"}})
+			}
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]any{"tracks": tracks})
+	})
+
+	ids := make([]string, 0, 52)
+	for i := 0; i < 50; i++ {
+		ids = append(ids, fmt.Sprintf("t%d", i))
+	}
+	ids = append(ids, "removed", "unplayable")
+
+	songs, err := catalog.GetTracks(ids...)
+	require.NoError(t, err)
+	require.Len(t, songs, 52)
+	assert.Equal(t, "Song t0", songs[0].Title)
+	assert.Equal(t, "t49", songs[49].ID)
+	assert.Nil(t, songs[50])
+	assert.Nil(t, songs[51])
+	assert.Len(t, requested, 2)
+	assert.Equal(t, "removed,unplayable", requested[1])
+}
+
+func TestSpotify_GetTrackErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		expected error
+	}{
+		{name: "unknown id", status: http.StatusNotFound, expected: ErrNotFound},
+		{name: "malformed id", status: http.StatusBadRequest, expected: ErrNotFound},
+		{name: "rate limited", status: http.StatusTooManyRequests, expected: ErrRateLimited},
+		{name: "server error", status: http.StatusBadGateway, expected: ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := newTestSpotify(t, func(writer http.ResponseWriter, request *http.Request) {
+				writeError(writer, tt.status, "failed")
+			})
+
+			_, err := catalog.GetTrack("67Hna13dNDkZvBpTXRIaOJ")
+			assert.ErrorIs(t, err, tt.expected)
+
+			var apiErr spotify.Error
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.Status)
+		})
+	}
+}
+
+func TestSpotify_GetPlaylistTracks(t *testing.T) {
+	catalog := newTestSpotify(t, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/v1/playlists/37i9dQZF1DXcBWIGoYBM5M/tracks", request.URL.Path)
+		assert.Equal(t, "100", request.URL.Query().Get("offset"))
+		assert.Equal(t, "2", request.URL.Query().Get("limit"))
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]any{"total": 102, "items": []any{
+			map[string]any{"track": map[string]any{"id": "a1", "name": "Teardrop"}},
+			map[string]any{"is_local": true, "track": map[string]any{"name": "demo.mp3"}},
+		}})
+	})
+
+	page, err := catalog.GetPlaylistTracks("37i9dQZF1DXcBWIGoYBM5M", 100, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 102, page.Total)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "Teardrop", page.Items[0].Title)
+	assert.Nil(t, page.Items[1])
+}
+
+func TestSpotify_GetAudioFeatures(t *testing.T) {
+	catalog := newTestSpotify(t, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "a1,b2", request.URL.Query().Get("ids"))
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]any{"audio_features": []any{
+			map[string]any{
+				"id": "a1", "tempo": 128.5, "energy": 0.75, "danceability": 0.5,
+				"valence": 0.25, "key": 9, "mode": 0, "loudness": -5.5,
+			},
+			nil,
+		}})
+	})
+
+	features, err := catalog.GetAudioFeatures("a1", "b2")
+	require.NoError(t, err)
+	assert.Equal(t, []models.AudioFeatures{{
+		SongId: "a1", Tempo: 128.5, Energy: 0.75, Danceability: 0.5, Valence: 0.25, Key: 9, Mode: 0, Loudness: -5.5,
+	}}, features)
+}
+
+func TestSpotify_GetRecommendations(t *testing.T) {
+	catalog := newTestSpotify(t, func(writer http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+		assert.Equal(t, "artist1", query.Get("seed_artists"))
+		assert.Equal(t, "a1,b2", query.Get("seed_tracks"))
+		assert.Equal(t, "0.8", query.Get("target_energy"))
+		assert.Equal(t, "GB", query.Get("market"))
+		assert.Equal(t, "3", query.Get("limit"))
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]any{"tracks": []any{
+			map[string]any{"id": "c3", "name": "Roads"},
+			map[string]any{"id": "d4", "name": "Glory Box"},
+		}})
+	})
+
+	ids, err := catalog.GetRecommendations(
+		&models.RecommendationSeeds{Artists: []string{"artist1"}, Tracks: []string{"a1", "b2"}},
+		&models.RecommendationQuery{Limit: 3, Market: "GB", Targets: map[string]float64{models.FeatureEnergy: 0.8}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c3", "d4"}, ids)
+}
+
+func TestSpotifyError(t *testing.T) {
+	other := errors.New("connection reset")
+
+	assert.Nil(t, SpotifyError(nil))
+	assert.Same(t, other, SpotifyError(other))
+	assert.ErrorIs(t, SpotifyError(fmt.Errorf("get: %w", client.ErrUnavailable)), ErrUnavailable)
+	assert.ErrorIs(t, SpotifyError(spotify.Error{Status: http.StatusTooManyRequests}), ErrUnavailable)
+	assert.NotErrorIs(t, SpotifyError(spotify.Error{Status: http.StatusBadRequest}), ErrNotFound)
+	assert.NotErrorIs(t, SpotifyError(spotify.Error{Status: http.StatusServiceUnavailable}), ErrRateLimited)
+}
@@ -122,6 +122,16 @@ func (a *AuthRepository) GetUserByUsernameAndPassword(username string, password
 	return user, nil
 }
 
+func (a *AuthRepository) IsAdmin(userId int) (bool, error) {
+	var isAdmin bool
+	err := a.storage.QueryRow("SELECT EXISTS(SELECT 1 FROM admins WHERE user_id = ?)", userId).Scan(&isAdmin)
+	if err != nil {
+		a.log.Error("REPOSITORY: unsuccessful check admin: ", err)
+		return false, err
+	}
+	return isAdmin, nil
+}
+
 func scanRowsIntoUser(rows *sql.Rows) (*models.User, error) {
 	var user models.User
 
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+)
+
+type BackupRepository struct {
+	storage *sql.DB
+	log     *logging.LogrusLogger
+}
+
+func NewBackupRepository(
+	storage *sql.DB,
+	log *logging.LogrusLogger,
+) *BackupRepository {
+	return &BackupRepository{
+		storage: storage,
+		log:     log,
+	}
+}
+
+// RestoreBackupPlaylist creates the playlist with its entries and remembers which
+// playlist of the backup it came from. When an earlier restore already created it
+// and it is not in the trash, nothing is written and the existing id is returned
+// with false. The entries must hold songs of the catalog, not of the document, as
+// a stored song is shared by every user. A smart playlist is created from its rules
+// instead, entries are ignored.
+func (b *BackupRepository) RestoreBackupPlaylist(origin string, sourceId int, playlist *models.Playlist, entries []*models.PlaylistEntry, limits *models.QuotaLimits) (int64, bool, error) {
+	tx, err := b.storage.Begin()
+	if err != nil {
+		b.log.Error("REPOSITORY: begin restore playlist: ", err)
+		return 0, false, err
+	}
+	defer tx.Rollback()
+
+	var rules any
+	if playlist.Kind == models.PlaylistKindSmart {
+		encoded, err := json.Marshal(playlist.Rules)
+		if err != nil {
+			b.log.Error("REPOSITORY: can't encode smart rules: ", err)
+			return 0, false, err
+		}
+		rules, entries = encoded, nil
+	}
+
+	var restoredId int64
+	err = tx.QueryRow(`
+		SELECT rp.playlist_id
+		FROM restored_playlists rp
+		JOIN playlists p ON p.id = rp.playlist_id
+		WHERE rp.user_id = ? AND rp.origin = ? AND rp.source_id = ? AND p.deleted_at IS NULL`,
+		playlist.UserId,
+		origin,
+		sourceId,
+	).Scan(&restoredId)
+	if err == nil {
+		b.log.Info("REPOSITORY: playlist already restored: ", sourceId, restoredId)
+		return restoredId, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		b.log.Error("REPOSITORY: unsuccessful get restored playlist: ", err)
+		return 0, false, err
+	}
+
+	if err := checkPlaylistQuota(tx, playlist.UserId, 1, limits); err != nil {
+		b.log.Error("REPOSITORY: playlist quota: ", err)
+		return 0, false, err
+	}
+
+	result, err := tx.Exec(
+		"INSERT INTO playlists (name, user_id, duplicate_policy, kind, rules, refresh_mode) VALUES (?, ?, ?, ?, ?, NULLIF(?, ''))",
+		playlist.Name,
+		playlist.UserId,
+		playlist.DuplicatePolicy,
+		playlist.Kind,
+		rules,
+		playlist.RefreshMode,
+	)
+	if err != nil {
+		b.log.Error("REPOSITORY: restored playlist not created: ", err)
+		return 0, false, err
+	}
+
+	playlistId, err := result.LastInsertId()
+	if err != nil {
+		b.log.Error("REPOSITORY: restored playlist not created! Id is empty: ", err)
+		return 0, false, err
+	}
+
+	for _, entry := range entries {
+		err = insertSong(tx, &entry.Song, false)
+		if err != nil {
+			b.log.Error("REPOSITORY: restored track not created: ", err)
+			return 0, false, err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO playlist_songs (playlist_id, song_id, position, added_at, added_by, note)
+			VALUES (?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP), COALESCE(?, ?), NULLIF(?, ''))`,
+			playlistId,
+			entry.ID,
+			entry.Position,
+			entry.AddedAt,
+			entry.AddedBy,
+			playlist.UserId,
+			entry.Note,
+		)
+		if err != nil {
+			b.log.Error("REPOSITORY: restored track not added to playlist_songs: ", err)
+			return 0, false, err
+		}
+	}
+
+	if playlist.Kind == models.PlaylistKindSmart && playlist.RefreshMode == models.RefreshScheduled {
+		if _, err := refreshRuleResults(tx, int(playlistId), playlist.UserId, playlist.Rules); err != nil {
+			b.log.Error("REPOSITORY: smart playlist tracks not saved: ", err)
+			return 0, false, err
+		}
+	}
+
+	if err := checkTrackQuota(tx, int(playlistId), limits); err != nil {
+		b.log.Error("REPOSITORY: track quota: ", err)
+		return 0, false, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO restored_playlists (user_id, origin, source_id, playlist_id) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE playlist_id = VALUES(playlist_id)`,
+		playlist.UserId,
+		origin,
+		sourceId,
+		playlistId,
+	)
+	if err != nil {
+		b.log.Error("REPOSITORY: restored playlist not recorded: ", err)
+		return 0, false, err
+	}
+
+	snapshotId, err := createSnapshot(tx, playlist.UserId, int(playlistId), models.ActionCreate)
+	if err != nil {
+		b.log.Error("REPOSITORY: snapshot not created: ", err)
+		return 0, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		b.log.Error("REPOSITORY: commit restore playlist: ", err)
+		return 0, false, err
+	}
+	playlist.SnapshotId = snapshotId
+
+	b.log.Info("REPOSITORY: playlist restored: ", sourceId, playlistId, len(entries))
+	return playlistId, true, nil
+}
+
+// GetRestoredPlaylists returns the ids of the backup playlists an earlier restore of
+// the origin created and that are not in the trash, the ones a restore would skip.
+func (b *BackupRepository) GetRestoredPlaylists(userId int, origin string) (map[int]bool, error) {
+	rows, err := b.storage.Query(`
+		SELECT rp.source_id
+		FROM restored_playlists rp
+		JOIN playlists p ON p.id = rp.playlist_id
+		WHERE rp.user_id = ? AND rp.origin = ? AND p.deleted_at IS NULL`,
+		userId,
+		origin,
+	)
+	if err != nil {
+		b.log.Error("REPOSITORY: unsuccessful get restored playlists: ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	restored := make(map[int]bool)
+	for rows.Next() {
+		var sourceId int
+		if err := rows.Scan(&sourceId); err != nil {
+			b.log.Error("REPOSITORY: unsuccessful scan restored playlist: ", err)
+			return nil, err
+		}
+		restored[sourceId] = true
+	}
+	if err := rows.Err(); err != nil {
+		b.log.Error("REPOSITORY: unsuccessful get restored playlists: ", err)
+		return nil, err
+	}
+	return restored, nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"testing"
+	"time"
+)
+
+func TestBackupRepository_RestoreBackupPlaylist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBackupRepository(db, logging.NewLogger())
+
+	addedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	addedBy := 5
+	playlist := &models.Playlist{Name: "Road trip", UserId: 4, DuplicatePolicy: models.DuplicatePolicySkip, Kind: models.PlaylistKindManual}
+	entries := []*models.PlaylistEntry{
+		{Position: 1, AddedAt: &addedAt, AddedBy: &addedBy, Note: "opener", Song: models.Song{ID: "a1", Title: "Song A"}},
+		{Position: 2, Song: models.Song{ID: "b2", Title: "Song B"}},
+	}
+
+	testCases := []struct {
+		name            string
+		mockSetup       func()
+		expectedId      int64
+		expectedCreated bool
+		expectedErr     bool
+	}{
+		{
+			name: "first restore creates the playlist",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT rp.playlist_id FROM restored_playlists rp JOIN playlists p ON p.id = rp.playlist_id WHERE rp.user_id = \? AND rp.origin = \? AND rp.source_id = \? AND p.deleted_at IS NULL`).
+					WithArgs(4, "3-1790000000", 7).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec(`^INSERT INTO playlists \(name, user_id, duplicate_policy, kind, rules, refresh_mode\) VALUES \(\?, \?, \?, \?, \?, NULLIF\(\?, ''\)\)$`).
+					WithArgs("Road trip", 4, models.DuplicatePolicySkip, models.PlaylistKindManual, nil, "").
+					WillReturnResult(sqlmock.NewResult(21, 1))
+				mock.ExpectExec(`INSERT INTO songs`).
+					WithArgs("a1", "Song A", "", "", "", "", 0, "", "", 0, "", "", false, 0, 0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO playlist_songs \(playlist_id, song_id, position, added_at, added_by, note\) VALUES \(\?, \?, \?, COALESCE\(\?, CURRENT_TIMESTAMP\), COALESCE\(\?, \?\), NULLIF\(\?, ''\)\)`).
+					WithArgs(21, "a1", 1, &addedAt, &addedBy, 4, "opener").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`INSERT INTO songs`).
+					WithArgs("b2", "Song B", "", "", "", "", 0, "", "", 0, "", "", false, 0, 0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO playlist_songs`).
+					WithArgs(21, "b2", 2, nil, nil, 4, "").
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec(`INSERT INTO restored_playlists \(user_id, origin, source_id, playlist_id\) VALUES \(\?, \?, \?, \?\) ON DUPLICATE KEY UPDATE playlist_id = VALUES\(playlist_id\)`).
+					WithArgs(4, "3-1790000000", 7, 21).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectSnapshot(mock, 4, 21, models.ActionCreate, 30)
+				mock.ExpectCommit()
+			},
+			expectedId:      21,
+			expectedCreated: true,
+		},
+		{
+			name: "second restore skips",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM restored_playlists rp`).
+					WithArgs(4, "3-1790000000", 7).
+					WillReturnRows(sqlmock.NewRows([]string{"playlist_id"}).AddRow(21))
+				mock.ExpectRollback()
+			},
+			expectedId: 21,
+		},
+		{
+			name: "failed entry rolls back",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM restored_playlists rp`).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec(`^INSERT INTO playlists`).
+					WillReturnResult(sqlmock.NewResult(21, 1))
+				mock.ExpectExec(`INSERT INTO songs`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO playlist_songs`).
+					WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			id, created, err := repo.RestoreBackupPlaylist("3-1790000000", 7, playlist, entries, nil)
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedId, id)
+				assert.Equal(t, tt.expectedCreated, created)
+			}
+
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBackupRepository_RestoreBackupSmartPlaylist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBackupRepository(db, logging.NewLogger())
+
+	rules := &models.SmartRules{Match: models.RuleNode{Field: "popularity", Op: "=", Value: []byte(`60`)}}
+	playlist := &models.Playlist{Name: "Hits", UserId: 4, DuplicatePolicy: models.DuplicatePolicyAllow, Kind: models.PlaylistKindSmart, Rules: rules, RefreshMode: models.RefreshOnRead}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM restored_playlists rp`).
+		WithArgs(4, "3-1790000000", 7).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(`^INSERT INTO playlists \(name, user_id, duplicate_policy, kind, rules, refresh_mode\)`).
+		WithArgs("Hits", 4, models.DuplicatePolicyAllow, models.PlaylistKindSmart, []byte(`{"match":{"field":"popularity","op":"=","value":60}}`), models.RefreshOnRead).
+		WillReturnResult(sqlmock.NewResult(22, 1))
+	mock.ExpectExec(`INSERT INTO restored_playlists`).
+		WithArgs(4, "3-1790000000", 7, 22).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectSnapshot(mock, 4, 22, models.ActionCreate, 31)
+	mock.ExpectCommit()
+
+	entries := []*models.PlaylistEntry{{Position: 1, Song: models.Song{ID: "a1"}}}
+	id, created, err := repo.RestoreBackupPlaylist("3-1790000000", 7, playlist, entries, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(22), id)
+	assert.True(t, created)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBackupRepository_GetRestoredPlaylists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBackupRepository(db, logging.NewLogger())
+
+	mock.ExpectQuery(`SELECT rp.source_id FROM restored_playlists rp JOIN playlists p ON p.id = rp.playlist_id WHERE rp.user_id = \? AND rp.origin = \? AND p.deleted_at IS NULL`).
+		WithArgs(4, "3-1790000000").
+		WillReturnRows(sqlmock.NewRows([]string{"source_id"}).AddRow(7).AddRow(9))
+
+	restored, err := repo.GetRestoredPlaylists(4, "3-1790000000")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[int]bool{7: true, 9: true}, restored)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
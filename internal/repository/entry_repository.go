@@ -0,0 +1,402 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"strings"
+	"time"
+)
+
+const entryColumns = `ps.id, ps.position, ps.added_at, ps.added_by, ps.note, ` + songColumns
+
+var (
+	entryNotFound = errors.New("playlist entry not found")
+)
+
+type EntryRepository struct {
+	storage *sql.DB
+	log     *logging.LogrusLogger
+}
+
+func NewEntryRepository(
+	storage *sql.DB,
+	log *logging.LogrusLogger,
+) *EntryRepository {
+	return &EntryRepository{
+		storage: storage,
+		log:     log,
+	}
+}
+
+func (e *EntryRepository) GetPlaylistEntries(userId, playlistId int) ([]*models.PlaylistEntry, error) {
+	if err := checkPlaylistOwner(e.storage, userId, playlistId); err != nil {
+		e.log.Error("REPOSITORY: check playlist owner:", err)
+		return nil, err
+	}
+
+	rows, err := e.storage.Query(`
+		SELECT `+entryColumns+`
+		FROM playlist_songs ps
+		JOIN songs s ON ps.song_id = s.id
+		WHERE ps.playlist_id = ?
+		ORDER BY ps.position, ps.id
+	`, playlistId)
+	if err != nil {
+		e.log.Error("REPOSITORY: unsuccessful get playlist entries:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.PlaylistEntry
+	for rows.Next() {
+		entry, err := scanRowsIntoEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := loadEntryArtists(e.storage, entries); err != nil {
+		e.log.Error("REPOSITORY: unsuccessful get entry artists:", err)
+		return nil, err
+	}
+
+	e.log.Info("REPOSITORY: get list of playlist entries:", len(entries))
+	return entries, nil
+}
+
+func (e *EntryRepository) MovePlaylistEntry(change *models.PlaylistChange, entryId, position int) error {
+	userId, playlistId := change.UserId, change.PlaylistId
+	tx, err := e.storage.Begin()
+	if err != nil {
+		e.log.Error("REPOSITORY: begin move entry:", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	version, err := claimPlaylistVersion(tx, change)
+	if err != nil {
+		e.log.Error("REPOSITORY: claim playlist version:", err)
+		return err
+	}
+
+	var current int
+	err = tx.QueryRow(`
+		SELECT position FROM playlist_songs
+		WHERE playlist_id = ? AND id = ?
+		FOR UPDATE
+	`, playlistId, entryId).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		e.log.Error("REPOSITORY: entry not found:", entryId)
+		return entryNotFound
+	}
+	if err != nil {
+		e.log.Error("REPOSITORY: unsuccessful get entry position:", err)
+		return err
+	}
+
+	// Positions may have gaps after removals, so the target is the position of
+	// the entry now standing at the requested place, or the last one.
+	offset := position - 1
+	if offset < 0 {
+		offset = 0
+	}
+	var target int
+	err = tx.QueryRow(`
+		SELECT COALESCE(
+		    (SELECT position FROM playlist_songs WHERE playlist_id = ? ORDER BY position, id LIMIT 1 OFFSET ?),
+		    (SELECT MAX(position) FROM playlist_songs WHERE playlist_id = ?)
+		)
+	`, playlistId, offset, playlistId).Scan(&target)
+	if err != nil {
+		e.log.Error("REPOSITORY: unsuccessful get target position:", err)
+		return err
+	}
+
+	if target != current {
+		shift, from, to := -1, current+1, target
+		if target < current {
+			shift, from, to = 1, target, current-1
+		}
+		_, err = tx.Exec(`
+			UPDATE playlist_songs SET position = position + ?
+			WHERE playlist_id = ? AND position BETWEEN ? AND ? AND id <> ?
+		`, shift, playlistId, from, to, entryId)
+		if err != nil {
+			e.log.Error("REPOSITORY: unsuccessful shift entry positions:", err)
+			return err
+		}
+
+		_, err = tx.Exec(`UPDATE playlist_songs SET position = ? WHERE id = ?`, target, entryId)
+		if err != nil {
+			e.log.Error("REPOSITORY: unsuccessful update entry position:", err)
+			return err
+		}
+	}
+
+	snapshotId, err := createSnapshot(tx, userId, playlistId, models.ActionReorder)
+	if err != nil {
+		e.log.Error("REPOSITORY: snapshot not created:", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		e.log.Error("REPOSITORY: commit move entry:", err)
+		return err
+	}
+	change.Version = version
+	change.SnapshotId = snapshotId
+
+	e.log.Info("REPOSITORY: entry moved:", entryId, position)
+	return nil
+}
+
+func (e *EntryRepository) DeletePlaylistEntry(change *models.PlaylistChange, entryId int) error {
+	userId, playlistId := change.UserId, change.PlaylistId
+	tx, err := e.storage.Begin()
+	if err != nil {
+		e.log.Error("REPOSITORY: begin remove entry:", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	version, err := claimPlaylistVersion(tx, change)
+	if err != nil {
+		e.log.Error("REPOSITORY: claim playlist version:", err)
+		return err
+	}
+
+	result, err := tx.Exec(`
+		DELETE FROM playlist_songs
+		WHERE playlist_id = ? AND id = ?
+	`, playlistId, entryId)
+	if err != nil {
+		e.log.Error("REPOSITORY: entry not removed from playlist_songs:", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		e.log.Error("REPOSITORY: entry not removed from playlist_songs:", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		e.log.Error("REPOSITORY: entry not found:", entryId)
+		return entryNotFound
+	}
+
+	if err := removeOrphanSongTags(tx, userId); err != nil {
+		e.log.Error("REPOSITORY: orphan track tags not removed:", err)
+		return err
+	}
+
+	snapshotId, err := createSnapshot(tx, userId, playlistId, models.ActionRemove)
+	if err != nil {
+		e.log.Error("REPOSITORY: snapshot not created:", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		e.log.Error("REPOSITORY: commit remove entry:", err)
+		return err
+	}
+	change.Version = version
+	change.SnapshotId = snapshotId
+
+	e.log.Info("REPOSITORY: entry removed successfully:", entryId)
+	return nil
+}
+
+func (e *EntryRepository) UpdateEntryNote(change *models.PlaylistChange, entryId int, note string) error {
+	tx, err := e.storage.Begin()
+	if err != nil {
+		e.log.Error("REPOSITORY: begin update entry note:", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	version, err := claimPlaylistVersion(tx, change)
+	if err != nil {
+		e.log.Error("REPOSITORY: claim playlist version:", err)
+		return err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE playlist_songs SET note = NULLIF(?, '')
+		WHERE playlist_id = ? AND id = ?
+	`, note, change.PlaylistId, entryId)
+	if err != nil {
+		e.log.Error("REPOSITORY: entry note not updated:", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		e.log.Error("REPOSITORY: entry note not updated:", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		var exists bool
+		err := tx.QueryRow(
+			`SELECT EXISTS(SELECT 1 FROM playlist_songs WHERE playlist_id = ? AND id = ?)`,
+			change.PlaylistId,
+			entryId,
+		).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			e.log.Error("REPOSITORY: entry not found:", entryId)
+			return entryNotFound
+		}
+	}
+
+	snapshotId, err := createSnapshot(tx, change.UserId, change.PlaylistId, models.ActionUpdate)
+	if err != nil {
+		e.log.Error("REPOSITORY: snapshot not created:", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		e.log.Error("REPOSITORY: commit update entry note:", err)
+		return err
+	}
+	change.Version = version
+	change.SnapshotId = snapshotId
+
+	e.log.Info("REPOSITORY: entry note updated:", entryId)
+	return nil
+}
+
+func (e *EntryRepository) GetDuplicateEntries(userId, playlistId int) ([]*models.DuplicateGroup, error) {
+	entries, err := e.GetPlaylistEntries(userId, playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*models.DuplicateGroup)
+	var order []string
+	for _, entry := range entries {
+		group, ok := groups[entry.Song.ID]
+		if !ok {
+			group = &models.DuplicateGroup{Song: entry.Song}
+			groups[entry.Song.ID] = group
+			order = append(order, entry.Song.ID)
+		}
+		group.EntryIds = append(group.EntryIds, entry.EntryId)
+	}
+
+	var duplicates []*models.DuplicateGroup
+	for _, songId := range order {
+		if len(groups[songId].EntryIds) > 1 {
+			duplicates = append(duplicates, groups[songId])
+		}
+	}
+
+	e.log.Info("REPOSITORY: get list of duplicate tracks:", len(duplicates))
+	return duplicates, nil
+}
+
+func (e *EntryRepository) CollapseDuplicateEntries(change *models.PlaylistChange) (int64, error) {
+	userId, playlistId := change.UserId, change.PlaylistId
+	tx, err := e.storage.Begin()
+	if err != nil {
+		e.log.Error("REPOSITORY: begin collapse duplicates:", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	version, err := claimPlaylistVersion(tx, change)
+	if err != nil {
+		e.log.Error("REPOSITORY: claim playlist version:", err)
+		return 0, err
+	}
+
+	result, err := tx.Exec(`
+		DELETE ps FROM playlist_songs ps
+		JOIN playlist_songs keep ON keep.playlist_id = ps.playlist_id AND keep.song_id = ps.song_id
+		    AND (keep.position < ps.position OR (keep.position = ps.position AND keep.id < ps.id))
+		WHERE ps.playlist_id = ?
+	`, playlistId)
+	if err != nil {
+		e.log.Error("REPOSITORY: duplicates not collapsed:", err)
+		return 0, err
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		e.log.Error("REPOSITORY: duplicates not collapsed:", err)
+		return 0, err
+	}
+
+	var snapshotId int64
+	if removed > 0 {
+		snapshotId, err = createSnapshot(tx, userId, playlistId, models.ActionRemove)
+		if err != nil {
+			e.log.Error("REPOSITORY: snapshot not created:", err)
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		e.log.Error("REPOSITORY: commit collapse duplicates:", err)
+		return 0, err
+	}
+	change.Version = version
+	change.SnapshotId = snapshotId
+
+	e.log.Info("REPOSITORY: duplicates collapsed:", removed)
+	return removed, nil
+}
+
+func entryOrderBy(filter *models.EntryFilter) string {
+	if filter == nil {
+		return "ps.position, ps.id"
+	}
+
+	if column, ok := featureColumns[strings.TrimPrefix(filter.Sort, "-")]; ok {
+		if strings.HasPrefix(filter.Sort, "-") {
+			return column + " IS NULL, " + column + " DESC, ps.position, ps.id"
+		}
+		return column + " IS NULL, " + column + ", ps.position, ps.id"
+	}
+
+	switch filter.Sort {
+	case models.EntrySortAddedAt:
+		return "ps.added_at, ps.id"
+	case models.EntrySortAddedAtDesc:
+		return "ps.added_at DESC, ps.id DESC"
+	default:
+		return "ps.position, ps.id"
+	}
+}
+
+func scanRowsIntoEntry(rows *sql.Rows) (*models.PlaylistEntry, error) {
+	var entry models.PlaylistEntry
+	var addedAt time.Time
+	var addedBy sql.NullInt64
+	var note sql.NullString
+	var albumId sql.NullString
+	dest := append([]any{&entry.EntryId, &entry.Position, &addedAt, &addedBy, &note}, songDest(&entry.Song, &albumId)...)
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	entry.Song.AlbumId = albumId.String
+
+	entry.AddedAt = &addedAt
+	if addedBy.Valid {
+		userId := int(addedBy.Int64)
+		entry.AddedBy = &userId
+	}
+	entry.Note = note.String
+	return &entry, nil
+}
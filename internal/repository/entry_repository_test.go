@@ -0,0 +1,462 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"testing"
+	"time"
+)
+
+var songRowColumns = []string{"id", "title", "artist", "album", "album_cover", "duration", "release_date", "popularity",
+	"preview_url", "external_url", "album_id", "explicit", "track_number", "disc_number", "release_date_precision"}
+
+var entryRowColumns = append([]string{"id", "position", "added_at", "added_by", "note"}, songRowColumns...)
+
+var songArtistColumns = []string{"song_id", "id", "name"}
+
+func TestEntryRepository_GetPlaylistEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewEntryRepository(db, logging.NewLogger())
+
+	song := models.Song{ID: "song123", Title: "Test Song", Artist: "Test Artist"}
+	credited := song
+	credited.Artists = []models.SongArtist{{ID: "artist1", Name: "Test Artist"}}
+	addedAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	addedBy := 1
+
+	testCases := []struct {
+		name            string
+		userId          int
+		playlistId      int
+		mockSetup       func()
+		expectedError   error
+		expectedEntries []*models.PlaylistEntry
+	}{
+		{
+			name:       "successful get entries",
+			userId:     1,
+			playlistId: 1,
+			mockSetup: func() {
+				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \? AND deleted_at IS NULL$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+
+				mock.ExpectQuery(`^SELECT ps\.id, ps\.position, ps\.added_at, ps\.added_by, ps\.note, s\.id, .* FROM playlist_songs ps JOIN songs s ON ps\.song_id = s\.id WHERE ps\.playlist_id = \? ORDER BY ps\.position, ps\.id$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(entryRowColumns).
+						AddRow(10, 1, addedAt, 1, "opener", song.ID, song.Title, song.Artist, "", "", 0, "", 0, "", "", nil, false, 0, 0, "").
+						AddRow(11, 2, addedAt, nil, nil, song.ID, song.Title, song.Artist, "", "", 0, "", 0, "", "", nil, false, 0, 0, ""))
+				mock.ExpectQuery(`^SELECT sa\.song_id, a\.id, a\.name FROM song_artists sa JOIN artists a ON sa\.artist_id = a\.id WHERE sa\.song_id IN \(\?\) ORDER BY sa\.song_id, sa\.position$`).
+					WithArgs(song.ID).
+					WillReturnRows(sqlmock.NewRows(songArtistColumns).AddRow(song.ID, "artist1", song.Artist))
+			},
+			expectedEntries: []*models.PlaylistEntry{
+				{EntryId: 10, Position: 1, AddedAt: &addedAt, AddedBy: &addedBy, Note: "opener", Song: credited},
+				{EntryId: 11, Position: 2, AddedAt: &addedAt, Song: credited},
+			},
+		},
+		{
+			name:       "playlist not found",
+			userId:     1,
+			playlistId: 2,
+			mockSetup: func() {
+				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \? AND deleted_at IS NULL$`).
+					WithArgs(2).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: playlistNotFound,
+		},
+		{
+			name:       "permission denied",
+			userId:     1,
+			playlistId: 3,
+			mockSetup: func() {
+				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \? AND deleted_at IS NULL$`).
+					WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(2))
+			},
+			expectedError: permissionDenied,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			entries, err := repo.GetPlaylistEntries(tt.userId, tt.playlistId)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+				assert.Nil(t, entries)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedEntries, entries)
+			}
+
+			err = mock.ExpectationsWereMet()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEntryRepository_MovePlaylistEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewEntryRepository(db, logging.NewLogger())
+
+	testCases := []struct {
+		name          string
+		entryId       int
+		position      int
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name:     "successful move to first position",
+			entryId:  12,
+			position: 1,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectQuery(`^SELECT position FROM playlist_songs WHERE playlist_id = \? AND id = \? FOR UPDATE$`).
+					WithArgs(1, 12).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(4))
+				mock.ExpectQuery(`^SELECT COALESCE\( \(SELECT position FROM playlist_songs WHERE playlist_id = \? ORDER BY position, id LIMIT 1 OFFSET \?\), \(SELECT MAX\(position\) FROM playlist_songs WHERE playlist_id = \?\) \)$`).
+					WithArgs(1, 0, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(1))
+				mock.ExpectExec(`^UPDATE playlist_songs SET position = position \+ \? WHERE playlist_id = \? AND position BETWEEN \? AND \? AND id <> \?$`).
+					WithArgs(1, 1, 1, 3, 12).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`^UPDATE playlist_songs SET position = \? WHERE id = \?$`).
+					WithArgs(1, 12).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectSnapshot(mock, 1, 1, models.ActionReorder, 5)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:     "move past the end lands last",
+			entryId:  10,
+			position: 9,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectQuery(`^SELECT position FROM playlist_songs WHERE playlist_id = \? AND id = \? FOR UPDATE$`).
+					WithArgs(1, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(1))
+				mock.ExpectQuery(`^SELECT COALESCE`).
+					WithArgs(1, 8, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(4))
+				mock.ExpectExec(`^UPDATE playlist_songs SET position = position \+ \?`).
+					WithArgs(-1, 1, 2, 4, 10).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`^UPDATE playlist_songs SET position = \? WHERE id = \?$`).
+					WithArgs(4, 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectSnapshot(mock, 1, 1, models.ActionReorder, 5)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:     "move in place",
+			entryId:  11,
+			position: 2,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectQuery(`^SELECT position FROM playlist_songs`).
+					WithArgs(1, 11).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(2))
+				mock.ExpectQuery(`^SELECT COALESCE`).
+					WithArgs(1, 1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(2))
+				expectSnapshot(mock, 1, 1, models.ActionReorder, 5)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:     "entry not found",
+			entryId:  99,
+			position: 1,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectQuery(`^SELECT position FROM playlist_songs WHERE playlist_id = \? AND id = \? FOR UPDATE$`).
+					WithArgs(1, 99).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: entryNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			change := &models.PlaylistChange{UserId: 1, PlaylistId: 1}
+			err := repo.MovePlaylistEntry(change, tt.entryId, tt.position)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(5), change.SnapshotId)
+			}
+
+			err = mock.ExpectationsWereMet()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEntryRepository_DeletePlaylistEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewEntryRepository(db, logging.NewLogger())
+
+	testCases := []struct {
+		name          string
+		entryId       int
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name:    "successful delete entry",
+			entryId: 10,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectExec(`^DELETE FROM playlist_songs WHERE playlist_id = \? AND id = \?$`).
+					WithArgs(1, 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`^DELETE FROM song_tags WHERE user_id = \? AND song_id NOT IN`).
+					WithArgs(1, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				expectSnapshot(mock, 1, 1, models.ActionRemove, 5)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "entry not found",
+			entryId: 99,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectExec(`^DELETE FROM playlist_songs WHERE playlist_id = \? AND id = \?$`).
+					WithArgs(1, 99).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedError: entryNotFound,
+		},
+		{
+			name:    "error deleting entry",
+			entryId: 10,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectExec(`^DELETE FROM playlist_songs WHERE playlist_id = \? AND id = \?$`).
+					WithArgs(1, 10).
+					WillReturnError(errors.New("failed to delete entry"))
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("failed to delete entry"),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			change := &models.PlaylistChange{UserId: 1, PlaylistId: 1}
+			err := repo.DeletePlaylistEntry(change, tt.entryId)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(5), change.SnapshotId)
+			}
+
+			err = mock.ExpectationsWereMet()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEntryRepository_UpdateEntryNote(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewEntryRepository(db, logging.NewLogger())
+
+	testCases := []struct {
+		name          string
+		entryId       int
+		note          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name:    "successful note update",
+			entryId: 10,
+			note:    "opener",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectExec(`^UPDATE playlist_songs SET note = NULLIF\(\?, ''\) WHERE playlist_id = \? AND id = \?$`).
+					WithArgs("opener", 1, 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectSnapshot(mock, 1, 1, models.ActionUpdate, 5)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "unchanged note",
+			entryId: 10,
+			note:    "opener",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectExec(`^UPDATE playlist_songs SET note`).
+					WithArgs("opener", 1, 10).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`^SELECT EXISTS\(SELECT 1 FROM playlist_songs WHERE playlist_id = \? AND id = \?\)$`).
+					WithArgs(1, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				expectSnapshot(mock, 1, 1, models.ActionUpdate, 5)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "entry not found",
+			entryId: 99,
+			note:    "",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectExec(`^UPDATE playlist_songs SET note`).
+					WithArgs("", 1, 99).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`^SELECT EXISTS`).
+					WithArgs(1, 99).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+			expectedError: entryNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			change := &models.PlaylistChange{UserId: 1, PlaylistId: 1}
+			err := repo.UpdateEntryNote(change, tt.entryId, tt.note)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(5), change.SnapshotId)
+			}
+
+			err = mock.ExpectationsWereMet()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEntryRepository_GetDuplicateEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewEntryRepository(db, logging.NewLogger())
+
+	mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \? AND deleted_at IS NULL$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	mock.ExpectQuery(`^SELECT ps\.id, ps\.position, ps\.added_at, .*`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(entryRowColumns).
+			AddRow(10, 1, time.Now(), 1, nil, "a", "A", "", "", "", 0, "", 0, "", "", nil, false, 0, 0, "").
+			AddRow(11, 2, time.Now(), 1, nil, "b", "B", "", "", "", 0, "", 0, "", "", nil, false, 0, 0, "").
+			AddRow(12, 3, time.Now(), 1, nil, "a", "A", "", "", "", 0, "", 0, "", "", nil, false, 0, 0, ""))
+	mock.ExpectQuery(`^SELECT sa\.song_id, a\.id, a\.name FROM song_artists sa`).
+		WithArgs("a", "b").
+		WillReturnRows(sqlmock.NewRows(songArtistColumns))
+
+	duplicates, err := repo.GetDuplicateEntries(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.DuplicateGroup{
+		{Song: models.Song{ID: "a", Title: "A"}, EntryIds: []int{10, 12}},
+	}, duplicates)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEntryRepository_CollapseDuplicateEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewEntryRepository(db, logging.NewLogger())
+
+	mock.ExpectBegin()
+	expectClaimVersion(mock, 1, 1, 2)
+	mock.ExpectExec(`^DELETE ps FROM playlist_songs ps JOIN playlist_songs keep .* WHERE ps\.playlist_id = \?$`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	expectSnapshot(mock, 1, 1, models.ActionRemove, 5)
+	mock.ExpectCommit()
+
+	change := &models.PlaylistChange{UserId: 1, PlaylistId: 1}
+	removed, err := repo.CollapseDuplicateEntries(change)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+	assert.Equal(t, int64(5), change.SnapshotId)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEntryRepository_CollapseDuplicateEntriesNothingRemoved(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewEntryRepository(db, logging.NewLogger())
+
+	mock.ExpectBegin()
+	expectClaimVersion(mock, 1, 1, 2)
+	mock.ExpectExec(`^DELETE ps FROM playlist_songs ps`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	change := &models.PlaylistChange{UserId: 1, PlaylistId: 1}
+	removed, err := repo.CollapseDuplicateEntries(change)
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+	assert.Zero(t, change.SnapshotId, "no snapshot when nothing was removed")
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
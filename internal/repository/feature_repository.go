@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+)
+
+// featureColumns maps the features a listing can be bounded or sorted by to their column.
+var featureColumns = map[string]string{
+	models.FeatureTempo:        "af.tempo",
+	models.FeatureEnergy:       "af.energy",
+	models.FeatureDanceability: "af.danceability",
+	models.FeatureValence:      "af.valence",
+	models.FeatureLoudness:     "af.loudness",
+}
+
+type FeatureRepository struct {
+	storage *sql.DB
+	log     *logging.LogrusLogger
+}
+
+func NewFeatureRepository(
+	storage *sql.DB,
+	log *logging.LogrusLogger,
+) *FeatureRepository {
+	return &FeatureRepository{
+		storage: storage,
+		log:     log,
+	}
+}
+
+// SaveAudioFeatures stores the features of analysed tracks. Unavailable tracks get a
+// row without features, so they no longer count as missing.
+func (f *FeatureRepository) SaveAudioFeatures(features []models.AudioFeatures, unavailable []string) error {
+	rows := len(features) + len(unavailable)
+	if rows == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, rows*8)
+	for _, feature := range features {
+		args = append(args, feature.SongId, feature.Tempo, feature.Energy, feature.Danceability,
+			feature.Valence, feature.Key, feature.Mode, feature.Loudness)
+	}
+	for _, songId := range unavailable {
+		args = append(args, songId, nil, nil, nil, nil, nil, nil, nil)
+	}
+
+	_, err := f.storage.Exec(`
+		INSERT INTO audio_features (song_id, tempo, energy, danceability, valence, musical_key, mode, loudness)
+		VALUES `+valuesPlaceholders(rows, 8)+`
+		ON DUPLICATE KEY UPDATE tempo = VALUES(tempo), energy = VALUES(energy),
+			danceability = VALUES(danceability), valence = VALUES(valence), musical_key = VALUES(musical_key),
+			mode = VALUES(mode), loudness = VALUES(loudness), fetched_at = CURRENT_TIMESTAMP
+	`, args...)
+	if err != nil {
+		f.log.Error("REPOSITORY: unsuccessful save audio features:", err)
+		return err
+	}
+
+	f.log.Info("REPOSITORY: audio features saved:", len(features), len(unavailable))
+	return nil
+}
+
+func (f *FeatureRepository) GetSongsWithoutAudioFeatures(limit int) ([]string, error) {
+	rows, err := f.storage.Query(`
+		SELECT s.id
+		FROM songs s
+		LEFT JOIN audio_features af ON af.song_id = s.id
+		WHERE af.song_id IS NULL
+		ORDER BY s.id
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		f.log.Error("REPOSITORY: unsuccessful get songs without audio features:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// loadEntryFeatures fills in the audio features of the entries in one query. Tracks
+// that were never analysed, or that Spotify has no analysis for, keep nil.
+func loadEntryFeatures(q rowsQuerier, entries []*models.PlaylistEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	byId := make(map[string][]*models.PlaylistEntry, len(entries))
+	args := make([]any, 0, len(entries))
+	for _, entry := range entries {
+		if _, ok := byId[entry.ID]; !ok {
+			args = append(args, entry.ID)
+		}
+		byId[entry.ID] = append(byId[entry.ID], entry)
+	}
+
+	rows, err := q.Query(`
+		SELECT song_id, tempo, energy, danceability, valence, musical_key, mode, loudness
+		FROM audio_features
+		WHERE song_id IN (`+tagPlaceholders(len(args))+`) AND tempo IS NOT NULL
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var feature models.AudioFeatures
+		err := rows.Scan(&feature.SongId, &feature.Tempo, &feature.Energy, &feature.Danceability,
+			&feature.Valence, &feature.Key, &feature.Mode, &feature.Loudness)
+		if err != nil {
+			return err
+		}
+		for _, entry := range byId[feature.SongId] {
+			features := feature
+			entry.AudioFeatures = &features
+		}
+	}
+	return rows.Err()
+}
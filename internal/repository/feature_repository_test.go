@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"testing"
+)
+
+var featureRowColumns = []string{"song_id", "tempo", "energy", "danceability", "valence", "musical_key", "mode", "loudness"}
+
+func TestFeatureRepository_SaveAudioFeatures(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewFeatureRepository(db, logging.NewLogger())
+	features := []models.AudioFeatures{{SongId: "song1", Tempo: 128, Energy: 0.8, Danceability: 0.7, Valence: 0.6, Key: 9, Mode: 0, Loudness: -5.5}}
+
+	mock.ExpectExec(`^INSERT INTO audio_features \(song_id, tempo, energy, danceability, valence, musical_key, mode, loudness\) VALUES \(\?, \?, \?, \?, \?, \?, \?, \?\), \(\?, \?, \?, \?, \?, \?, \?, \?\) ON DUPLICATE KEY UPDATE .* fetched_at = CURRENT_TIMESTAMP$`).
+		WithArgs("song1", 128.0, 0.8, 0.7, 0.6, 9, 0, -5.5, "song2", nil, nil, nil, nil, nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	require.NoError(t, repo.SaveAudioFeatures(features, []string{"song2"}))
+
+	mock.ExpectExec(`^INSERT INTO audio_features`).WillReturnError(errors.New("database error"))
+	assert.Error(t, repo.SaveAudioFeatures(features, nil))
+
+	require.NoError(t, repo.SaveAudioFeatures(nil, nil))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFeatureRepository_GetSongsWithoutAudioFeatures(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewFeatureRepository(db, logging.NewLogger())
+
+	mock.ExpectQuery(`^SELECT s\.id FROM songs s LEFT JOIN audio_features af ON af\.song_id = s\.id WHERE af\.song_id IS NULL ORDER BY s\.id LIMIT \?$`).
+		WithArgs(100).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("song1").AddRow("song2"))
+
+	ids, err := repo.GetSongsWithoutAudioFeatures(100)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"song1", "song2"}, ids)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
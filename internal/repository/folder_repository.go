@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+)
+
+var (
+	folderNotFound = errors.New("folder not found")
+
+	ErrFolderCycle = errors.New("folder can't be moved into itself or its subfolder")
+)
+
+type FolderRepository struct {
+	storage *sql.DB
+	log     *logging.LogrusLogger
+}
+
+func NewFolderRepository(
+	storage *sql.DB,
+	log *logging.LogrusLogger,
+) *FolderRepository {
+	return &FolderRepository{
+		storage: storage,
+		log:     log,
+	}
+}
+
+func (f *FolderRepository) CreateFolder(folder *models.Folder) (int64, error) {
+	if folder.ParentId != nil {
+		if _, err := getFolder(f.storage, folder.UserId, *folder.ParentId); err != nil {
+			f.log.Error("REPOSITORY: get parent folder: ", err)
+			return 0, err
+		}
+	}
+
+	result, err := f.storage.Exec(`
+		INSERT INTO playlist_folders (user_id, parent_id, name, position)
+		SELECT ?, ?, ?, COALESCE(MAX(position), 0) + 1
+		FROM playlist_folders
+		WHERE user_id = ? AND parent_id <=> ?
+	`, folder.UserId, folder.ParentId, folder.Name, folder.UserId, folder.ParentId)
+	if err != nil {
+		f.log.Error("REPOSITORY: unsuccessful create folder: ", err)
+		return 0, err
+	}
+
+	folderId, err := result.LastInsertId()
+	if err != nil {
+		f.log.Error("REPOSITORY: unsuccessful create folder! Id is empty: ", err)
+		return 0, err
+	}
+
+	f.log.Info("REPOSITORY: create folder: ", folderId)
+	return folderId, nil
+}
+
+func (f *FolderRepository) GetFolders(userId int) ([]*models.Folder, error) {
+	rows, err := f.storage.Query(
+		"SELECT id, user_id, parent_id, name, position FROM playlist_folders WHERE user_id = ? ORDER BY position, id",
+		userId,
+	)
+	if err != nil {
+		f.log.Error("REPOSITORY: unsuccessful get folders: ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var folders []*models.Folder
+	for rows.Next() {
+		folder, err := scanRowsIntoFolder(rows)
+		if err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	f.log.Info("REPOSITORY: get list of folders: ", len(folders))
+	return folders, nil
+}
+
+func (f *FolderRepository) RenameFolder(userId, folderId int, name string) error {
+	if _, err := getFolder(f.storage, userId, folderId); err != nil {
+		f.log.Error("REPOSITORY: get folder: ", err)
+		return err
+	}
+
+	_, err := f.storage.Exec(`UPDATE playlist_folders SET name = ? WHERE id = ?`, name, folderId)
+	if err != nil {
+		f.log.Error("REPOSITORY: unsuccessful rename folder: ", err)
+		return err
+	}
+
+	f.log.Info("REPOSITORY: folder renamed: ", folderId)
+	return nil
+}
+
+func (f *FolderRepository) MoveFolder(userId, folderId int, parentId *int) error {
+	tx, err := f.storage.Begin()
+	if err != nil {
+		f.log.Error("REPOSITORY: begin move folder: ", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := getFolder(tx, userId, folderId); err != nil {
+		f.log.Error("REPOSITORY: get folder: ", err)
+		return err
+	}
+
+	if parentId != nil {
+		if _, err := getFolder(tx, userId, *parentId); err != nil {
+			f.log.Error("REPOSITORY: get parent folder: ", err)
+			return err
+		}
+
+		var cycle bool
+		err := tx.QueryRow(`
+			WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM playlist_folders WHERE id = ?
+				UNION ALL
+				SELECT pf.id, pf.parent_id FROM playlist_folders pf
+				JOIN ancestors a ON pf.id = a.parent_id
+			)
+			SELECT EXISTS(SELECT 1 FROM ancestors WHERE id = ?)
+		`, *parentId, folderId).Scan(&cycle)
+		if err != nil {
+			f.log.Error("REPOSITORY: unsuccessful check folder ancestors: ", err)
+			return err
+		}
+
+		if cycle {
+			f.log.Error("REPOSITORY: folder cycle: ", folderId, *parentId)
+			return ErrFolderCycle
+		}
+	}
+
+	var position int
+	err = tx.QueryRow(`
+		SELECT COALESCE(MAX(position), 0) + 1 FROM playlist_folders
+		WHERE user_id = ? AND parent_id <=> ?
+	`, userId, parentId).Scan(&position)
+	if err != nil {
+		f.log.Error("REPOSITORY: unsuccessful get folder position: ", err)
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE playlist_folders SET parent_id = ?, position = ? WHERE id = ?`,
+		parentId,
+		position,
+		folderId,
+	)
+	if err != nil {
+		f.log.Error("REPOSITORY: unsuccessful move folder: ", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		f.log.Error("REPOSITORY: commit move folder: ", err)
+		return err
+	}
+
+	f.log.Info("REPOSITORY: folder moved: ", folderId)
+	return nil
+}
+
+func (f *FolderRepository) DeleteFolder(userId, folderId int, mode string) error {
+	tx, err := f.storage.Begin()
+	if err != nil {
+		f.log.Error("REPOSITORY: begin delete folder: ", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	folder, err := getFolder(tx, userId, folderId)
+	if err != nil {
+		f.log.Error("REPOSITORY: get folder: ", err)
+		return err
+	}
+
+	if mode == models.FolderDeleteCascade {
+		_, err := tx.Exec(`
+			UPDATE playlists SET deleted_at = CURRENT_TIMESTAMP
+			WHERE user_id = ? AND deleted_at IS NULL AND folder_id IN (
+				WITH RECURSIVE subtree AS (
+					SELECT id FROM playlist_folders WHERE id = ?
+					UNION ALL
+					SELECT pf.id FROM playlist_folders pf
+					JOIN subtree st ON pf.parent_id = st.id
+				)
+				SELECT id FROM subtree
+			)
+		`, userId, folderId)
+		if err != nil {
+			f.log.Error("REPOSITORY: folder playlists not moved to trash: ", err)
+			return err
+		}
+	} else {
+		_, err := tx.Exec(`UPDATE playlist_folders SET parent_id = ? WHERE parent_id = ?`, folder.ParentId, folderId)
+		if err != nil {
+			f.log.Error("REPOSITORY: subfolders not moved up: ", err)
+			return err
+		}
+
+		_, err = tx.Exec(`UPDATE playlists SET folder_id = ? WHERE folder_id = ?`, folder.ParentId, folderId)
+		if err != nil {
+			f.log.Error("REPOSITORY: folder playlists not moved up: ", err)
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM playlist_folders WHERE id = ?`, folderId); err != nil {
+		f.log.Error("REPOSITORY: unsuccessful delete folder: ", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		f.log.Error("REPOSITORY: commit delete folder: ", err)
+		return err
+	}
+
+	f.log.Info("REPOSITORY: folder deleted: ", folderId, mode)
+	return nil
+}
+
+func (f *FolderRepository) MovePlaylist(userId, playlistId int, folderId *int) error {
+	tx, err := f.storage.Begin()
+	if err != nil {
+		f.log.Error("REPOSITORY: begin move playlist: ", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkPlaylistOwner(tx, userId, playlistId); err != nil {
+		f.log.Error("REPOSITORY: check playlist owner: ", err)
+		return err
+	}
+
+	if folderId != nil {
+		if _, err := getFolder(tx, userId, *folderId); err != nil {
+			f.log.Error("REPOSITORY: get folder: ", err)
+			return err
+		}
+	}
+
+	var position int
+	err = tx.QueryRow(`
+		SELECT COALESCE(MAX(position), 0) + 1 FROM playlists
+		WHERE user_id = ? AND folder_id <=> ?
+	`, userId, folderId).Scan(&position)
+	if err != nil {
+		f.log.Error("REPOSITORY: unsuccessful get playlist position: ", err)
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE playlists SET folder_id = ?, position = ? WHERE id = ?`,
+		folderId,
+		position,
+		playlistId,
+	)
+	if err != nil {
+		f.log.Error("REPOSITORY: unsuccessful move playlist: ", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		f.log.Error("REPOSITORY: commit move playlist: ", err)
+		return err
+	}
+
+	f.log.Info("REPOSITORY: playlist moved: ", playlistId)
+	return nil
+}
+
+func getFolder(q querier, userId, folderId int) (*models.Folder, error) {
+	var folder models.Folder
+	var parentId sql.NullInt64
+	err := q.QueryRow(`SELECT id, user_id, parent_id, name, position FROM playlist_folders WHERE id = ?`, folderId).
+		Scan(&folder.ID, &folder.UserId, &parentId, &folder.Name, &folder.Position)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, folderNotFound
+		}
+		return nil, err
+	}
+
+	if folder.UserId != userId {
+		return nil, folderNotFound
+	}
+
+	if parentId.Valid {
+		id := int(parentId.Int64)
+		folder.ParentId = &id
+	}
+	return &folder, nil
+}
+
+func scanRowsIntoFolder(rows *sql.Rows) (*models.Folder, error) {
+	var folder models.Folder
+	var parentId sql.NullInt64
+	err := rows.Scan(
+		&folder.ID,
+		&folder.UserId,
+		&parentId,
+		&folder.Name,
+		&folder.Position,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentId.Valid {
+		id := int(parentId.Int64)
+		folder.ParentId = &id
+	}
+	return &folder, nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/pkg/logging"
+	"testing"
+)
+
+var folderColumns = []string{"id", "user_id", "parent_id", "name", "position"}
+
+func TestFolderRepository_MoveFolder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewFolderRepository(db, logging.NewLogger())
+
+	parentId := 2
+
+	testCases := []struct {
+		name          string
+		parentId      *int
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name:     "successful move",
+			parentId: &parentId,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT id, user_id, parent_id, name, position FROM playlist_folders WHERE id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(folderColumns).AddRow(1, 1, nil, "Rock", 1))
+				mock.ExpectQuery(`^SELECT id, user_id, parent_id, name, position FROM playlist_folders WHERE id = \?$`).
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows(folderColumns).AddRow(2, 1, nil, "Music", 2))
+				mock.ExpectQuery(`^WITH RECURSIVE ancestors AS .* SELECT EXISTS\(SELECT 1 FROM ancestors WHERE id = \?\)$`).
+					WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery(`^SELECT COALESCE\(MAX\(position\), 0\) \+ 1 FROM playlist_folders WHERE user_id = \? AND parent_id <=> \?$`).
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(1))
+				mock.ExpectExec(`^UPDATE playlist_folders SET parent_id = \?, position = \? WHERE id = \?$`).
+					WithArgs(2, 1, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:     "move into own subfolder",
+			parentId: &parentId,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT id, user_id, parent_id, name, position FROM playlist_folders WHERE id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(folderColumns).AddRow(1, 1, nil, "Rock", 1))
+				mock.ExpectQuery(`^SELECT id, user_id, parent_id, name, position FROM playlist_folders WHERE id = \?$`).
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows(folderColumns).AddRow(2, 1, 1, "Punk", 1))
+				mock.ExpectQuery(`^WITH RECURSIVE ancestors AS .*`).
+					WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedError: ErrFolderCycle,
+		},
+		{
+			name:     "folder of another user",
+			parentId: nil,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT id, user_id, parent_id, name, position FROM playlist_folders WHERE id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(folderColumns).AddRow(1, 2, nil, "Rock", 1))
+				mock.ExpectRollback()
+			},
+			expectedError: folderNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.MoveFolder(1, 1, tt.parentId)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			err = mock.ExpectationsWereMet()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFolderRepository_DeleteFolder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewFolderRepository(db, logging.NewLogger())
+
+	testCases := []struct {
+		name          string
+		mode          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "move contents up",
+			mode: "move_up",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT id, user_id, parent_id, name, position FROM playlist_folders WHERE id = \?$`).
+					WithArgs(3).
+					WillReturnRows(sqlmock.NewRows(folderColumns).AddRow(3, 1, 2, "Rock", 1))
+				mock.ExpectExec(`^UPDATE playlist_folders SET parent_id = \? WHERE parent_id = \?$`).
+					WithArgs(2, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`^UPDATE playlists SET folder_id = \? WHERE folder_id = \?$`).
+					WithArgs(2, 3).
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec(`^DELETE FROM playlist_folders WHERE id = \?$`).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "cascade",
+			mode: "cascade",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT id, user_id, parent_id, name, position FROM playlist_folders WHERE id = \?$`).
+					WithArgs(3).
+					WillReturnRows(sqlmock.NewRows(folderColumns).AddRow(3, 1, nil, "Rock", 1))
+				mock.ExpectExec(`^UPDATE playlists SET deleted_at = CURRENT_TIMESTAMP WHERE user_id = \? AND deleted_at IS NULL AND folder_id IN \( WITH RECURSIVE subtree AS .*\)$`).
+					WithArgs(1, 3).
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec(`^DELETE FROM playlist_folders WHERE id = \?$`).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "folder not found",
+			mode: "move_up",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT id, user_id, parent_id, name, position FROM playlist_folders WHERE id = \?$`).
+					WithArgs(3).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: folderNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.DeleteFolder(1, 3, tt.mode)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			err = mock.ExpectationsWereMet()
+			require.NoError(t, err)
+		})
+	}
+}
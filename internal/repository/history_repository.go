@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+)
+
+var (
+	snapshotNotFound = errors.New("snapshot not found")
+)
+
+// snapshotKeyframeInterval bounds how many snapshots are read to rebuild one.
+const snapshotKeyframeInterval = 50
+
+// snapshotEntries rebuilds the entries of the snapshot whose id it takes from the
+// newest row of each entry between the snapshot's base and the snapshot itself.
+const snapshotEntries = `
+	SELECT entry_id, position, song_id, added_at, added_by, note
+	FROM (
+		SELECT pss.entry_id, pss.position, pss.song_id, pss.added_at, pss.added_by, pss.note, pss.removed,
+		       ROW_NUMBER() OVER (PARTITION BY pss.entry_id ORDER BY ps.version DESC) AS newest
+		FROM playlist_snapshots target
+		JOIN playlist_snapshots ps
+		  ON ps.playlist_id = target.playlist_id AND ps.version BETWEEN target.base_version AND target.version
+		JOIN playlist_snapshot_songs pss ON pss.snapshot_id = ps.id
+		WHERE target.id = ?
+	) history
+	WHERE newest = 1 AND NOT removed
+`
+
+type HistoryRepository struct {
+	storage *sql.DB
+	log     *logging.LogrusLogger
+}
+
+func NewHistoryRepository(
+	storage *sql.DB,
+	log *logging.LogrusLogger,
+) *HistoryRepository {
+	return &HistoryRepository{
+		storage: storage,
+		log:     log,
+	}
+}
+
+func (h *HistoryRepository) GetHistory(userId, playlistId int) ([]*models.PlaylistSnapshot, error) {
+	if err := checkPlaylistOwner(h.storage, userId, playlistId); err != nil {
+		h.log.Error("REPOSITORY: check playlist owner:", err)
+		return nil, err
+	}
+
+	rows, err := h.storage.Query(`
+		SELECT ps.id, ps.playlist_id, ps.version, ps.actor_id, ps.action, ps.name, ps.track_count, ps.created_at
+		FROM playlist_snapshots ps
+		WHERE ps.playlist_id = ?
+		ORDER BY ps.version DESC
+	`, playlistId)
+	if err != nil {
+		h.log.Error("REPOSITORY: unsuccessful get playlist history:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []*models.PlaylistSnapshot
+	for rows.Next() {
+		snapshot, err := scanRowsIntoSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	h.log.Info("REPOSITORY: get playlist history:", len(history))
+	return history, nil
+}
+
+func (h *HistoryRepository) GetSnapshot(userId, playlistId, snapshotId int) (*models.PlaylistSnapshot, error) {
+	if err := checkPlaylistOwner(h.storage, userId, playlistId); err != nil {
+		h.log.Error("REPOSITORY: check playlist owner:", err)
+		return nil, err
+	}
+
+	rows, err := h.storage.Query(`
+		SELECT ps.id, ps.playlist_id, ps.version, ps.actor_id, ps.action, ps.name, ps.track_count, ps.created_at
+		FROM playlist_snapshots ps
+		WHERE ps.id = ? AND ps.playlist_id = ?
+	`, snapshotId, playlistId)
+	if err != nil {
+		h.log.Error("REPOSITORY: unsuccessful get snapshot:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		h.log.Error("REPOSITORY: snapshot not found:", snapshotId)
+		return nil, snapshotNotFound
+	}
+
+	snapshot, err := scanRowsIntoSnapshot(rows)
+	if err != nil {
+		h.log.Error("REPOSITORY: unsuccessful scan snapshot:", err)
+		return nil, err
+	}
+	rows.Close()
+
+	songRows, err := h.storage.Query(`
+		SELECT `+songColumns+`
+		FROM (`+snapshotEntries+`) entries
+		JOIN songs s ON entries.song_id = s.id
+		ORDER BY entries.position, entries.entry_id
+	`, snapshotId)
+	if err != nil {
+		h.log.Error("REPOSITORY: unsuccessful get snapshot tracks:", err)
+		return nil, err
+	}
+	defer songRows.Close()
+
+	for songRows.Next() {
+		song, err := scanRowsIntoSong(songRows)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Songs = append(snapshot.Songs, song)
+	}
+
+	if err := songRows.Err(); err != nil {
+		return nil, err
+	}
+
+	h.log.Info("REPOSITORY: get snapshot:", snapshotId)
+	return snapshot, nil
+}
+
+func (h *HistoryRepository) RevertToSnapshot(change *models.PlaylistChange, snapshotId int) error {
+	tx, err := h.storage.Begin()
+	if err != nil {
+		h.log.Error("REPOSITORY: begin revert snapshot:", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	userId, playlistId := change.UserId, change.PlaylistId
+	version, err := claimPlaylistVersion(tx, change)
+	if err != nil {
+		h.log.Error("REPOSITORY: claim playlist version:", err)
+		return err
+	}
+
+	var duplicatePolicy, kind string
+	query := `SELECT duplicate_policy, kind FROM playlists WHERE id = ?`
+	if err := tx.QueryRow(query, playlistId).Scan(&duplicatePolicy, &kind); err != nil {
+		h.log.Error("REPOSITORY: get playlist duplicate policy:", err)
+		return err
+	}
+
+	if kind == models.PlaylistKindSmart {
+		h.log.Error("REPOSITORY: smart playlist not reverted:", playlistId)
+		return smartPlaylistReadOnly
+	}
+
+	var name string
+	err = tx.QueryRow(
+		`SELECT name FROM playlist_snapshots WHERE id = ? AND playlist_id = ?`,
+		snapshotId,
+		playlistId,
+	).Scan(&name)
+	if err != nil {
+		h.log.Error("REPOSITORY: get snapshot:", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return snapshotNotFound
+		}
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE playlists SET name = ? WHERE id = ?`, name, playlistId); err != nil {
+		h.log.Error("REPOSITORY: playlist name not restored:", err)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM playlist_songs WHERE playlist_id = ?`, playlistId); err != nil {
+		h.log.Error("REPOSITORY: playlist tracks not cleared:", err)
+		return err
+	}
+
+	// Snapshots taken before entries kept their metadata have no added_at. Unless the
+	// playlist allows duplicates, only the first entry of each track comes back.
+	_, err = tx.Exec(`
+		INSERT INTO playlist_songs (playlist_id, song_id, position, added_at, added_by, note)
+		SELECT ?, song_id, position, COALESCE(added_at, CURRENT_TIMESTAMP), added_by, note
+		FROM (
+			SELECT entries.*, ROW_NUMBER() OVER (PARTITION BY song_id ORDER BY position, entry_id) AS occurrence
+			FROM (`+snapshotEntries+`) entries
+		) snapshot
+		WHERE ? OR occurrence = 1
+	`, playlistId, snapshotId, duplicatePolicy == models.DuplicatePolicyAllow)
+	if err != nil {
+		h.log.Error("REPOSITORY: playlist tracks not restored:", err)
+		return err
+	}
+
+	if err := checkTrackQuota(tx, playlistId, change.Limits); err != nil {
+		h.log.Error("REPOSITORY: track quota:", err)
+		return err
+	}
+
+	if err := removeOrphanSongTags(tx, userId); err != nil {
+		h.log.Error("REPOSITORY: orphan track tags not removed:", err)
+		return err
+	}
+
+	revertId, err := createSnapshot(tx, userId, playlistId, models.ActionRevert)
+	if err != nil {
+		h.log.Error("REPOSITORY: snapshot not created:", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error("REPOSITORY: commit revert snapshot:", err)
+		return err
+	}
+	change.Version = version
+	change.SnapshotId = revertId
+
+	h.log.Info("REPOSITORY: playlist reverted to snapshot:", snapshotId)
+	return nil
+}
+
+func scanRowsIntoSnapshot(rows *sql.Rows) (*models.PlaylistSnapshot, error) {
+	var snapshot models.PlaylistSnapshot
+	err := rows.Scan(
+		&snapshot.ID,
+		&snapshot.PlaylistId,
+		&snapshot.Version,
+		&snapshot.ActorId,
+		&snapshot.Action,
+		&snapshot.Name,
+		&snapshot.TrackCount,
+		&snapshot.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+type snapshotWriter interface {
+	execer
+	querier
+}
+
+// createSnapshot records the playlist as it stands within the transaction of the
+// change it follows, so the two commit or roll back together. Only entries that
+// differ from the previous snapshot are stored, except on keyframes.
+func createSnapshot(e snapshotWriter, userId, playlistId int, action string) (int64, error) {
+	var previousId, version, baseVersion int
+	err := e.QueryRow(`
+		SELECT id, version, base_version
+		FROM playlist_snapshots
+		WHERE playlist_id = ?
+		ORDER BY version DESC
+		LIMIT 1
+	`, playlistId).Scan(&previousId, &version, &baseVersion)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	// A keyframe is a delta against no snapshot at all.
+	version++
+	if previousId == 0 || version-baseVersion >= snapshotKeyframeInterval {
+		previousId, baseVersion = 0, version
+	}
+
+	result, err := e.Exec(`
+		INSERT INTO playlist_snapshots (playlist_id, version, base_version, actor_id, action, name, track_count)
+		SELECT p.id, ?, ?, ?, ?, p.name, (SELECT COUNT(*) FROM playlist_songs WHERE playlist_id = p.id)
+		FROM playlists p
+		WHERE p.id = ?
+	`, version, baseVersion, userId, action, playlistId)
+	if err != nil {
+		return 0, err
+	}
+
+	snapshotId, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = e.Exec(`
+		INSERT INTO playlist_snapshot_songs (snapshot_id, entry_id, position, song_id, added_at, added_by, note, removed)
+		SELECT ?, current.id, current.position, current.song_id, current.added_at, current.added_by, current.note, FALSE
+		FROM playlist_songs current
+		LEFT JOIN (`+snapshotEntries+`) previous ON previous.entry_id = current.id
+		WHERE current.playlist_id = ?
+		  AND NOT (previous.position <=> current.position AND previous.song_id <=> current.song_id
+		       AND previous.added_at <=> current.added_at AND previous.added_by <=> current.added_by
+		       AND previous.note <=> current.note)
+		UNION ALL
+		SELECT ?, previous.entry_id, NULL, NULL, NULL, NULL, NULL, TRUE
+		FROM (`+snapshotEntries+`) previous
+		LEFT JOIN playlist_songs current ON current.id = previous.entry_id AND current.playlist_id = ?
+		WHERE current.id IS NULL
+	`, snapshotId, previousId, playlistId, snapshotId, previousId, playlistId)
+	if err != nil {
+		return 0, err
+	}
+	return snapshotId, nil
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"testing"
+	"time"
+)
+
+// expectSnapshot expects the statements createSnapshot runs inside the change's
+// transaction for the first snapshot of a playlist.
+func expectSnapshot(mock sqlmock.Sqlmock, userId, playlistId int, action string, snapshotId int64) {
+	expectPreviousSnapshot(mock, playlistId, sqlmock.NewRows([]string{"id", "version", "base_version"}))
+	expectSnapshotInserts(mock, userId, playlistId, action, snapshotId, 1, 1, 0)
+}
+
+func expectPreviousSnapshot(mock sqlmock.Sqlmock, playlistId int, rows *sqlmock.Rows) {
+	mock.ExpectQuery(`^SELECT id, version, base_version FROM playlist_snapshots WHERE playlist_id = \? ORDER BY version DESC LIMIT 1$`).
+		WithArgs(playlistId).
+		WillReturnRows(rows)
+}
+
+func expectSnapshotInserts(mock sqlmock.Sqlmock, userId, playlistId int, action string, snapshotId int64, version, baseVersion, previousId int) {
+	mock.ExpectExec(`^INSERT INTO playlist_snapshots \(playlist_id, version, base_version, actor_id, action, name, track_count\)`).
+		WithArgs(version, baseVersion, userId, action, playlistId).
+		WillReturnResult(sqlmock.NewResult(snapshotId, 1))
+	mock.ExpectExec(`^INSERT INTO playlist_snapshot_songs \(snapshot_id, entry_id, position, song_id, added_at, added_by, note, removed\) SELECT .* FROM playlist_songs current LEFT JOIN \(.*\) previous .* UNION ALL SELECT \?, previous\.entry_id, NULL, NULL, NULL, NULL, NULL, TRUE`).
+		WithArgs(snapshotId, previousId, playlistId, snapshotId, previousId, playlistId).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+}
+
+func TestCreateSnapshot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	testCases := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+		expectedID    int64
+	}{
+		{
+			name: "successful snapshot",
+			mockSetup: func() {
+				expectSnapshot(mock, 1, 1, models.ActionAdd, 7)
+			},
+			expectedID: 7,
+		},
+		{
+			name: "delta against the previous snapshot",
+			mockSetup: func() {
+				expectPreviousSnapshot(mock, 1, sqlmock.NewRows([]string{"id", "version", "base_version"}).AddRow(6, 3, 1))
+				expectSnapshotInserts(mock, 1, 1, models.ActionAdd, 7, 4, 1, 6)
+			},
+			expectedID: 7,
+		},
+		{
+			name: "keyframe once the interval is reached",
+			mockSetup: func() {
+				expectPreviousSnapshot(mock, 1, sqlmock.NewRows([]string{"id", "version", "base_version"}).AddRow(6, 50, 1))
+				expectSnapshotInserts(mock, 1, 1, models.ActionAdd, 7, 51, 51, 0)
+			},
+			expectedID: 7,
+		},
+		{
+			name: "error saving snapshot tracks",
+			mockSetup: func() {
+				expectPreviousSnapshot(mock, 1, sqlmock.NewRows([]string{"id", "version", "base_version"}))
+				mock.ExpectExec(`^INSERT INTO playlist_snapshots`).
+					WithArgs(1, 1, 1, models.ActionAdd, 1).
+					WillReturnResult(sqlmock.NewResult(7, 1))
+				mock.ExpectExec(`^INSERT INTO playlist_snapshot_songs`).
+					WithArgs(int64(7), 0, 1, int64(7), 0, 1).
+					WillReturnError(errors.New("failed to save tracks"))
+			},
+			expectedError: errors.New("failed to save tracks"),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			id, err := createSnapshot(db, 1, 1, models.ActionAdd)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedID, id)
+			}
+
+			err = mock.ExpectationsWereMet()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHistoryRepository_GetHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewHistoryRepository(db, logging.NewLogger())
+	createdAt := time.Date(2024, 9, 26, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \? AND deleted_at IS NULL$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	mock.ExpectQuery(`^SELECT ps\.id, ps\.playlist_id, ps\.version, ps\.actor_id, ps\.action, ps\.name, ps\.track_count, ps\.created_at FROM playlist_snapshots ps WHERE ps\.playlist_id = \? ORDER BY ps\.version DESC$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "playlist_id", "version", "actor_id", "action", "name", "track_count", "created_at"}).
+			AddRow(2, 1, 2, 1, models.ActionAdd, "Playlist", 1, createdAt).
+			AddRow(1, 1, 1, 1, models.ActionCreate, "Playlist", 0, createdAt))
+
+	history, err := repo.GetHistory(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.PlaylistSnapshot{
+		{ID: 2, PlaylistId: 1, Version: 2, ActorId: 1, Action: models.ActionAdd, Name: "Playlist", TrackCount: 1, CreatedAt: createdAt},
+		{ID: 1, PlaylistId: 1, Version: 1, ActorId: 1, Action: models.ActionCreate, Name: "Playlist", TrackCount: 0, CreatedAt: createdAt},
+	}, history)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHistoryRepository_RevertToSnapshot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewHistoryRepository(db, logging.NewLogger())
+
+	testCases := []struct {
+		name          string
+		snapshotId    int
+		limits        *models.QuotaLimits
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name:       "successful revert",
+			snapshotId: 3,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectQuery(`^SELECT duplicate_policy, kind FROM playlists WHERE id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"duplicate_policy", "kind"}).AddRow(models.DuplicatePolicyAllow, models.PlaylistKindManual))
+				mock.ExpectQuery(`^SELECT name FROM playlist_snapshots WHERE id = \? AND playlist_id = \?$`).
+					WithArgs(3, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Old name"))
+				mock.ExpectExec(`^UPDATE playlists SET name = \? WHERE id = \?$`).
+					WithArgs("Old name", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`^DELETE FROM playlist_songs WHERE playlist_id = \?$`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`^INSERT INTO playlist_songs \(playlist_id, song_id, position, added_at, added_by, note\) SELECT \?, song_id, position, COALESCE\(added_at, CURRENT_TIMESTAMP\), added_by, note FROM \(.*\) snapshot WHERE \? OR occurrence = 1$`).
+					WithArgs(1, 3, true).
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec(`^DELETE FROM song_tags WHERE user_id = \? AND song_id NOT IN`).
+					WithArgs(1, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				expectSnapshot(mock, 1, 1, models.ActionRevert, 8)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:       "snapshot not found",
+			snapshotId: 9,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectQuery(`^SELECT duplicate_policy, kind FROM playlists WHERE id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"duplicate_policy", "kind"}).AddRow(models.DuplicatePolicyAllow, models.PlaylistKindManual))
+				mock.ExpectQuery(`^SELECT name FROM playlist_snapshots WHERE id = \? AND playlist_id = \?$`).
+					WithArgs(9, 1).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: snapshotNotFound,
+		},
+		{
+			name:       "smart playlist",
+			snapshotId: 3,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectQuery(`^SELECT duplicate_policy, kind FROM playlists WHERE id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"duplicate_policy", "kind"}).AddRow(models.DuplicatePolicyAllow, models.PlaylistKindSmart))
+				mock.ExpectRollback()
+			},
+			expectedError: smartPlaylistReadOnly,
+		},
+		{
+			name:       "snapshot over the track quota",
+			snapshotId: 3,
+			limits:     &models.QuotaLimits{MaxTracksPerPlaylist: 3},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectQuery(`^SELECT duplicate_policy, kind FROM playlists WHERE id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"duplicate_policy", "kind"}).AddRow(models.DuplicatePolicySkip, models.PlaylistKindManual))
+				mock.ExpectQuery(`^SELECT name FROM playlist_snapshots WHERE id = \? AND playlist_id = \?$`).
+					WithArgs(3, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Old name"))
+				mock.ExpectExec(`^UPDATE playlists SET name = \? WHERE id = \?$`).
+					WithArgs("Old name", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`^DELETE FROM playlist_songs WHERE playlist_id = \?$`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`^INSERT INTO playlist_songs`).
+					WithArgs(1, 3, false).
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM playlist_songs WHERE playlist_id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+				mock.ExpectRollback()
+			},
+			expectedError: fmt.Errorf("%w: at most 3 tracks per playlist allowed", ErrQuotaExceeded),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			change := &models.PlaylistChange{UserId: 1, PlaylistId: 1, Limits: tt.limits}
+			err := repo.RevertToSnapshot(change, tt.snapshotId)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(8), change.SnapshotId)
+			}
+
+			err = mock.ExpectationsWereMet()
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+)
+
+var (
+	importJobNotFound = errors.New("import job not found")
+)
+
+type ImportRepository struct {
+	storage *sql.DB
+	log     *logging.LogrusLogger
+}
+
+func NewImportRepository(
+	storage *sql.DB,
+	log *logging.LogrusLogger,
+) *ImportRepository {
+	return &ImportRepository{
+		storage: storage,
+		log:     log,
+	}
+}
+
+// ImportPlaylist creates the playlist together with its songs in one transaction, so
+// a failed import never leaves a half filled playlist behind.
+func (i *ImportRepository) ImportPlaylist(playlist *models.Playlist, limits *models.QuotaLimits) (int64, error) {
+	tx, err := i.storage.Begin()
+	if err != nil {
+		i.log.Error("REPOSITORY: begin import playlist: ", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := checkPlaylistQuota(tx, playlist.UserId, 1, limits); err != nil {
+		i.log.Error("REPOSITORY: playlist quota: ", err)
+		return 0, err
+	}
+
+	result, err := tx.Exec(
+		"INSERT INTO playlists (name, user_id, duplicate_policy) VALUES (?, ?, ?)",
+		playlist.Name,
+		playlist.UserId,
+		playlist.DuplicatePolicy,
+	)
+	if err != nil {
+		i.log.Error("REPOSITORY: imported playlist not created: ", err)
+		return 0, err
+	}
+
+	playlistId, err := result.LastInsertId()
+	if err != nil {
+		i.log.Error("REPOSITORY: imported playlist not created! Id is empty: ", err)
+		return 0, err
+	}
+
+	for index, song := range playlist.Songs {
+		err = insertSong(tx, &playlist.Songs[index], false)
+		if err != nil {
+			i.log.Error("REPOSITORY: imported track not created: ", err)
+			return 0, err
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO playlist_songs (playlist_id, song_id, position, added_by) VALUES (?, ?, ?, ?)",
+			playlistId,
+			song.ID,
+			index+1,
+			playlist.UserId,
+		)
+		if err != nil {
+			i.log.Error("REPOSITORY: imported track not added to playlist_songs: ", err)
+			return 0, err
+		}
+	}
+
+	if err := checkTrackQuota(tx, int(playlistId), limits); err != nil {
+		i.log.Error("REPOSITORY: track quota: ", err)
+		return 0, err
+	}
+
+	snapshotId, err := createSnapshot(tx, playlist.UserId, int(playlistId), models.ActionCreate)
+	if err != nil {
+		i.log.Error("REPOSITORY: snapshot not created: ", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		i.log.Error("REPOSITORY: commit import playlist: ", err)
+		return 0, err
+	}
+	playlist.SnapshotId = snapshotId
+
+	i.log.Info("REPOSITORY: playlist imported: ", playlistId, len(playlist.Songs))
+	return playlistId, nil
+}
+
+func (i *ImportRepository) CreateImportJob(job *models.ImportJob) (int64, error) {
+	result, err := i.storage.Exec(
+		"INSERT INTO import_jobs (user_id, source, status, total) VALUES (?, ?, ?, ?)",
+		job.UserId,
+		job.Source,
+		job.Status,
+		job.Total,
+	)
+	if err != nil {
+		i.log.Error("REPOSITORY: unsuccessful create import job: ", err)
+		return 0, err
+	}
+
+	jobId, err := result.LastInsertId()
+	if err != nil {
+		i.log.Error("REPOSITORY: unsuccessful create import job! Id is empty: ", err)
+		return 0, err
+	}
+
+	i.log.Info("REPOSITORY: import job created: ", jobId)
+	return jobId, nil
+}
+
+func (i *ImportRepository) UpdateImportJob(job *models.ImportJob) error {
+	_, err := i.storage.Exec(
+		"UPDATE import_jobs SET status = ?, processed = ?, skipped = ?, playlist_id = ?, error = NULLIF(?, '') WHERE id = ?",
+		job.Status,
+		job.Processed,
+		job.Skipped,
+		job.PlaylistId,
+		job.Error,
+		job.ID,
+	)
+	if err != nil {
+		i.log.Error("REPOSITORY: unsuccessful update import job: ", err)
+		return err
+	}
+
+	i.log.Info("REPOSITORY: import job updated: ", job.ID, job.Status, job.Processed)
+	return nil
+}
+
+func (i *ImportRepository) GetImportJob(userId, jobId int) (*models.ImportJob, error) {
+	var job models.ImportJob
+	var playlistId sql.NullInt64
+	var jobError sql.NullString
+	err := i.storage.QueryRow(`
+		SELECT id, user_id, source, status, total, processed, skipped, playlist_id, error, created_at, updated_at
+		FROM import_jobs
+		WHERE id = ? AND user_id = ?`,
+		jobId,
+		userId,
+	).Scan(&job.ID, &job.UserId, &job.Source, &job.Status, &job.Total, &job.Processed, &job.Skipped,
+		&playlistId, &jobError, &job.CreatedAt, &job.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		i.log.Error("REPOSITORY: import job not found: ", jobId)
+		return nil, importJobNotFound
+	}
+	if err != nil {
+		i.log.Error("REPOSITORY: unsuccessful get import job: ", err)
+		return nil, err
+	}
+
+	if playlistId.Valid {
+		job.PlaylistId = &playlistId.Int64
+	}
+	job.Error = jobError.String
+
+	i.log.Info("REPOSITORY: get import job: ", jobId)
+	return &job, nil
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"testing"
+	"time"
+)
+
+func TestImportRepository_ImportPlaylist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewImportRepository(db, logging.NewLogger())
+
+	playlist := &models.Playlist{
+		Name:            "Imported",
+		UserId:          1,
+		DuplicatePolicy: models.DuplicatePolicyAllow,
+		Songs: []models.Song{
+			{ID: "a1", Title: "First"},
+			{ID: "b2", Title: "Second"},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		mockSetup   func()
+		expectedId  int64
+		expectedErr bool
+	}{
+		{
+			name: "playlist and tracks saved",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`^INSERT INTO playlists \(name, user_id, duplicate_policy\) VALUES \(\?, \?, \?\)$`).
+					WithArgs("Imported", 1, models.DuplicatePolicyAllow).
+					WillReturnResult(sqlmock.NewResult(7, 1))
+				mock.ExpectExec(`INSERT INTO songs`).
+					WithArgs("a1", "First", "", "", "", "", 0, "", "", 0, "", "", false, 0, 0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`^INSERT INTO playlist_songs \(playlist_id, song_id, position, added_by\) VALUES \(\?, \?, \?, \?\)$`).
+					WithArgs(7, "a1", 1, 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`INSERT INTO songs`).
+					WithArgs("b2", "Second", "", "", "", "", 0, "", "", 0, "", "", false, 0, 0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`^INSERT INTO playlist_songs`).
+					WithArgs(7, "b2", 2, 1).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				expectSnapshot(mock, 1, 7, models.ActionCreate, 3)
+				mock.ExpectCommit()
+			},
+			expectedId: 7,
+		},
+		{
+			name: "failed track rolls back",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`^INSERT INTO playlists`).
+					WillReturnResult(sqlmock.NewResult(7, 1))
+				mock.ExpectExec(`INSERT INTO songs`).
+					WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			id, err := repo.ImportPlaylist(playlist, nil)
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedId, id)
+			}
+
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestImportRepository_CreateImportJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewImportRepository(db, logging.NewLogger())
+
+	mock.ExpectExec(`^INSERT INTO import_jobs \(user_id, source, status, total\) VALUES \(\?, \?, \?, \?\)$`).
+		WithArgs(1, "37i9dQZF1DXcBWIGoYBM5M", models.ImportJobRunning, 250).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	id, err := repo.CreateImportJob(&models.ImportJob{
+		UserId: 1,
+		Source: "37i9dQZF1DXcBWIGoYBM5M",
+		Status: models.ImportJobRunning,
+		Total:  250,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), id)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportRepository_UpdateImportJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewImportRepository(db, logging.NewLogger())
+
+	playlistId := int64(9)
+	mock.ExpectExec(`^UPDATE import_jobs SET status = \?, processed = \?, skipped = \?, playlist_id = \?, error = NULLIF\(\?, ''\) WHERE id = \?$`).
+		WithArgs(models.ImportJobDone, 250, 2, &playlistId, "", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.UpdateImportJob(&models.ImportJob{
+		ID:         3,
+		Status:     models.ImportJobDone,
+		Processed:  250,
+		Skipped:    2,
+		PlaylistId: &playlistId,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportRepository_GetImportJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewImportRepository(db, logging.NewLogger())
+
+	createdAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	jobColumns := []string{"id", "user_id", "source", "status", "total", "processed", "skipped",
+		"playlist_id", "error", "created_at", "updated_at"}
+
+	testCases := []struct {
+		name        string
+		mockSetup   func()
+		expectedJob *models.ImportJob
+		expectedErr error
+	}{
+		{
+			name: "running job",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT id, user_id, source, status, total, processed, skipped, playlist_id, error, created_at, updated_at FROM import_jobs WHERE id = \? AND user_id = \?`).
+					WithArgs(3, 1).
+					WillReturnRows(sqlmock.NewRows(jobColumns).
+						AddRow(3, 1, "37i9dQZF1DXcBWIGoYBM5M", "running", 250, 100, 0, nil, nil, createdAt, createdAt))
+			},
+			expectedJob: &models.ImportJob{ID: 3, UserId: 1, Source: "37i9dQZF1DXcBWIGoYBM5M", Status: "running",
+				Total: 250, Processed: 100, CreatedAt: createdAt, UpdatedAt: createdAt},
+		},
+		{
+			name: "job of another user",
+			mockSetup: func() {
+				mock.ExpectQuery(`FROM import_jobs WHERE id = \? AND user_id = \?`).
+					WithArgs(3, 1).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: importJobNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			job, err := repo.GetImportJob(1, 3)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedJob, job)
+			}
+
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
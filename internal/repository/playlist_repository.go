@@ -2,14 +2,21 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"music-service/internal/models"
 	"music-service/pkg/logging"
 )
 
+const (
+	playlistColumns = "id, user_id, name, duplicate_policy, kind, rules, refresh_mode, folder_id, version"
+)
+
 var (
 	dataNotFound = errors.New("data not found")
+
+	ErrVersionMismatch = errors.New("playlist was changed by another request")
 )
 
 type PlayListRepository struct {
@@ -27,11 +34,24 @@ func NewPlayListRepository(
 	}
 }
 
-func (p *PlayListRepository) CreatePlaylist(playlist *models.Playlist) (int64, error) {
-	result, err := p.storage.Exec(
-		"INSERT INTO playlists (name, user_id) VALUES (?, ?)",
+func (p *PlayListRepository) CreatePlaylist(playlist *models.Playlist, limits *models.QuotaLimits) (int64, error) {
+	tx, err := p.storage.Begin()
+	if err != nil {
+		p.log.Error("REPOSITORY: begin create playlist: ", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := checkPlaylistQuota(tx, playlist.UserId, 1, limits); err != nil {
+		p.log.Error("REPOSITORY: playlist quota: ", err)
+		return 0, err
+	}
+
+	result, err := tx.Exec(
+		"INSERT INTO playlists (name, user_id, duplicate_policy) VALUES (?, ?, ?)",
 		playlist.Name,
 		playlist.UserId,
+		playlist.DuplicatePolicy,
 	)
 	if err != nil {
 		p.log.Error("REPOSITORY: unsuccessful create playlist: ", err)
@@ -44,13 +64,25 @@ func (p *PlayListRepository) CreatePlaylist(playlist *models.Playlist) (int64, e
 		return 0, err
 	}
 
+	snapshotId, err := createSnapshot(tx, playlist.UserId, int(playlistId), models.ActionCreate)
+	if err != nil {
+		p.log.Error("REPOSITORY: snapshot not created: ", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		p.log.Error("REPOSITORY: commit create playlist: ", err)
+		return 0, err
+	}
+	playlist.SnapshotId = snapshotId
+
 	p.log.Info("REPOSITORY: create playlist: ", playlistId)
 	return playlistId, nil
 }
 
 func (p *PlayListRepository) GetAllPlaylists(userId int) ([]*models.Playlist, error) {
 	rows, err := p.storage.Query(
-		"SELECT * FROM playlists WHERE user_id = ?",
+		"SELECT "+playlistColumns+" FROM playlists WHERE user_id = ? AND deleted_at IS NULL ORDER BY position, id",
 		userId,
 	)
 	if err != nil {
@@ -80,7 +112,7 @@ func (p *PlayListRepository) GetAllPlaylists(userId int) ([]*models.Playlist, er
 
 func (p *PlayListRepository) GetPlaylistById(userId int, playlistId int) (*models.Playlist, error) {
 	rows, err := p.storage.Query(
-		"SELECT * FROM playlists WHERE user_id = ? AND id = ?",
+		"SELECT "+playlistColumns+" FROM playlists WHERE user_id = ? AND id = ? AND deleted_at IS NULL",
 		userId,
 		playlistId,
 	)
@@ -105,12 +137,26 @@ func (p *PlayListRepository) GetPlaylistById(userId int, playlistId int) (*model
 	return playlist, nil
 }
 
-func (p *PlayListRepository) UpdatePlaylistById(userId int, playlist *models.Playlist) error {
-	result, err := p.storage.Exec(
-		"UPDATE playlists SET name = ? WHERE user_id = ? AND id = ?",
+func (p *PlayListRepository) UpdatePlaylistById(change *models.PlaylistChange, playlist *models.Playlist) error {
+	tx, err := p.storage.Begin()
+	if err != nil {
+		p.log.Error("REPOSITORY: begin update playlist: ", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	version, err := claimPlaylistVersion(tx, change)
+	if err != nil {
+		p.log.Error("REPOSITORY: claim playlist version: ", err)
+		return err
+	}
+
+	result, err := tx.Exec(
+		"UPDATE playlists SET name = ?, duplicate_policy = COALESCE(NULLIF(?, ''), duplicate_policy) WHERE user_id = ? AND id = ? AND deleted_at IS NULL",
 		playlist.Name,
-		userId,
-		playlist.ID,
+		playlist.DuplicatePolicy,
+		change.UserId,
+		change.PlaylistId,
 	)
 	if err != nil {
 		p.log.Error("REPOSITORY: unsuccessful update playlist: ", err)
@@ -128,15 +174,41 @@ func (p *PlayListRepository) UpdatePlaylistById(userId int, playlist *models.Pla
 		return dataNotFound
 	}
 
+	snapshotId, err := createSnapshot(tx, change.UserId, change.PlaylistId, models.ActionUpdate)
+	if err != nil {
+		p.log.Error("REPOSITORY: snapshot not created: ", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		p.log.Error("REPOSITORY: commit update playlist: ", err)
+		return err
+	}
+	change.Version = version
+	change.SnapshotId = snapshotId
+
 	p.log.Info("REPOSITORY: update playlist, rows affected: ", rowsAffected)
 	return nil
 }
 
-func (p *PlayListRepository) DeletePlaylistById(userId int, playlistId int) error {
-	result, err := p.storage.Exec(
-		"DELETE FROM playlists WHERE user_id = ? AND id = ?",
-		userId,
-		playlistId,
+func (p *PlayListRepository) DeletePlaylistById(change *models.PlaylistChange) error {
+	tx, err := p.storage.Begin()
+	if err != nil {
+		p.log.Error("REPOSITORY: begin delete playlist: ", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	version, err := claimPlaylistVersion(tx, change)
+	if err != nil {
+		p.log.Error("REPOSITORY: claim playlist version: ", err)
+		return err
+	}
+
+	result, err := tx.Exec(
+		"UPDATE playlists SET deleted_at = CURRENT_TIMESTAMP WHERE user_id = ? AND id = ? AND deleted_at IS NULL",
+		change.UserId,
+		change.PlaylistId,
 	)
 	if err != nil {
 		p.log.Error("REPOSITORY: unsuccessful delete playlist: ", err)
@@ -154,19 +226,84 @@ func (p *PlayListRepository) DeletePlaylistById(userId int, playlistId int) erro
 		return dataNotFound
 	}
 
+	snapshotId, err := createSnapshot(tx, change.UserId, change.PlaylistId, models.ActionDelete)
+	if err != nil {
+		p.log.Error("REPOSITORY: snapshot not created: ", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		p.log.Error("REPOSITORY: commit delete playlist: ", err)
+		return err
+	}
+	change.Version = version
+	change.SnapshotId = snapshotId
+
 	p.log.Info("REPOSITORY: delete playlist, rows affected: ", rowsAffected)
 	return nil
 }
 
+// claimPlaylistVersion locks the playlist for the rest of the transaction, checks
+// the version the change is based on and bumps it. It returns the new version, which
+// only counts once the transaction commits.
+func claimPlaylistVersion(tx *sql.Tx, change *models.PlaylistChange) (int, error) {
+	var owner, version int
+	err := tx.QueryRow(
+		"SELECT user_id, version FROM playlists WHERE id = ? AND deleted_at IS NULL FOR UPDATE",
+		change.PlaylistId,
+	).Scan(&owner, &version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, playlistNotFound
+		}
+		return 0, err
+	}
+
+	if owner != change.UserId {
+		return 0, permissionDenied
+	}
+
+	if change.IfMatch != nil && *change.IfMatch != version {
+		return 0, ErrVersionMismatch
+	}
+
+	if _, err := tx.Exec("UPDATE playlists SET version = version + 1 WHERE id = ?", change.PlaylistId); err != nil {
+		return 0, err
+	}
+	return version + 1, nil
+}
+
 func scanRowsIntoPlayList(rows *sql.Rows) (*models.Playlist, error) {
 	var playlist models.Playlist
+	var rules []byte
+	var refreshMode sql.NullString
+	var folderId sql.NullInt64
 	err := rows.Scan(
 		&playlist.ID,
 		&playlist.UserId,
 		&playlist.Name,
+		&playlist.DuplicatePolicy,
+		&playlist.Kind,
+		&rules,
+		&refreshMode,
+		&folderId,
+		&playlist.Version,
 	)
 	if err != nil {
 		return nil, err
 	}
+
+	if len(rules) > 0 {
+		playlist.Rules = new(models.SmartRules)
+		if err := json.Unmarshal(rules, playlist.Rules); err != nil {
+			return nil, err
+		}
+	}
+	playlist.RefreshMode = refreshMode.String
+	if folderId.Valid {
+		id := int(folderId.Int64)
+		playlist.FolderId = &id
+	}
+
 	return &playlist, nil
 }
@@ -21,8 +21,9 @@ func TestPlayListRepository_CreatePlaylist(t *testing.T) {
 	playlistRepo := NewPlayListRepository(db, logger)
 
 	playlist := &models.Playlist{
-		Name:   "My Playlist",
-		UserId: 1,
+		Name:            "My Playlist",
+		UserId:          1,
+		DuplicatePolicy: models.DuplicatePolicyAllow,
 	}
 
 	tests := []struct {
@@ -36,9 +37,12 @@ func TestPlayListRepository_CreatePlaylist(t *testing.T) {
 			name:     "successful playlist creation",
 			playlist: playlist,
 			mockSetup: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO playlists").
-					WithArgs("My Playlist", 1).
+					WithArgs("My Playlist", 1, models.DuplicatePolicyAllow).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectSnapshot(mock, 1, 1, models.ActionCreate, 5)
+				mock.ExpectCommit()
 			},
 			expectedID:    1,
 			expectedError: nil,
@@ -47,9 +51,11 @@ func TestPlayListRepository_CreatePlaylist(t *testing.T) {
 			name:     "error on insert",
 			playlist: playlist,
 			mockSetup: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO playlists").
-					WithArgs("My Playlist", 1).
+					WithArgs("My Playlist", 1, models.DuplicatePolicyAllow).
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			expectedID:    0,
 			expectedError: sql.ErrConnDone,
@@ -60,7 +66,7 @@ func TestPlayListRepository_CreatePlaylist(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			playlistId, err := playlistRepo.CreatePlaylist(tt.playlist)
+			playlistId, err := playlistRepo.CreatePlaylist(tt.playlist, nil)
 
 			assert.Equal(t, tt.expectedID, playlistId)
 			assert.Equal(t, tt.expectedError, err)
@@ -95,23 +101,23 @@ func TestPlayListRepository_GetAllPlaylists(t *testing.T) {
 			name:   "successful playlist get",
 			userId: 1,
 			mockSetup: func() {
-				mock.ExpectQuery("^SELECT \\* FROM playlists WHERE user_id = \\?$").
+				mock.ExpectQuery("^SELECT id, user_id, name, duplicate_policy, kind, rules, refresh_mode, folder_id, version FROM playlists WHERE user_id = \\? AND deleted_at IS NULL ORDER BY position, id$").
 					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id"}).
-						AddRow(1, 1, "Playlist 1").
-						AddRow(2, 1, "Playlist 2"))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "duplicate_policy", "kind", "rules", "refresh_mode", "folder_id", "version"}).
+						AddRow(1, 1, "Playlist 1", "allow", "manual", nil, nil, nil, 1).
+						AddRow(2, 1, "Playlist 2", "allow", "manual", nil, nil, nil, 1))
 			},
 			expectedError: nil,
 			expectedResult: []*models.Playlist{
-				{ID: 1, Name: "Playlist 1", UserId: 1},
-				{ID: 2, Name: "Playlist 2", UserId: 1},
+				{ID: 1, Name: "Playlist 1", UserId: 1, DuplicatePolicy: "allow", Kind: "manual", Version: 1},
+				{ID: 2, Name: "Playlist 2", UserId: 1, DuplicatePolicy: "allow", Kind: "manual", Version: 1},
 			},
 		},
 		{
 			name:   "error getting playlist",
 			userId: 1,
 			mockSetup: func() {
-				mock.ExpectQuery("^SELECT \\* FROM playlists WHERE user_id = \\?$").
+				mock.ExpectQuery("^SELECT id, user_id, name, duplicate_policy, kind, rules, refresh_mode, folder_id, version FROM playlists WHERE user_id = \\? AND deleted_at IS NULL ORDER BY position, id$").
 					WithArgs(1).
 					WillReturnError(sql.ErrConnDone)
 			},
@@ -154,9 +160,12 @@ func TestPlayListRepository_GetPlaylistById(t *testing.T) {
 	}
 
 	playlist := &models.Playlist{
-		ID:     1,
-		Name:   "Playlist 1",
-		UserId: 1,
+		ID:              1,
+		Name:            "Playlist 1",
+		UserId:          1,
+		DuplicatePolicy: "allow",
+		Kind:            "manual",
+		Version:         1,
 	}
 	tests := []struct {
 		name           string
@@ -171,10 +180,10 @@ func TestPlayListRepository_GetPlaylistById(t *testing.T) {
 			playlistId: 1,
 			userId:     1,
 			mockSetup: func() {
-				mock.ExpectQuery("^SELECT \\* FROM playlists WHERE user_id = \\? AND id = \\?$").
+				mock.ExpectQuery("^SELECT id, user_id, name, duplicate_policy, kind, rules, refresh_mode, folder_id, version FROM playlists WHERE user_id = \\? AND id = \\? AND deleted_at IS NULL$").
 					WithArgs(1, 1).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id"}).
-						AddRow(1, 1, "Playlist 1"))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "duplicate_policy", "kind", "rules", "refresh_mode", "folder_id", "version"}).
+						AddRow(1, 1, "Playlist 1", "allow", "manual", nil, nil, nil, 1))
 			},
 			expectedError:  nil,
 			expectedResult: playlist,
@@ -184,7 +193,7 @@ func TestPlayListRepository_GetPlaylistById(t *testing.T) {
 			playlistId: 1,
 			userId:     1,
 			mockSetup: func() {
-				mock.ExpectQuery("^SELECT \\* FROM playlists WHERE user_id = \\? AND id = \\?$").
+				mock.ExpectQuery("^SELECT id, user_id, name, duplicate_policy, kind, rules, refresh_mode, folder_id, version FROM playlists WHERE user_id = \\? AND id = \\? AND deleted_at IS NULL$").
 					WithArgs(1, 1).
 					WillReturnError(sql.ErrConnDone)
 			},
@@ -230,6 +239,7 @@ func TestPlayListRepository_UpdatePlaylistById(t *testing.T) {
 		name           string
 		playlistId     int
 		userId         int
+		ifMatch        int
 		playlist       *models.Playlist
 		mockSetup      func()
 		expectedError  error
@@ -245,9 +255,13 @@ func TestPlayListRepository_UpdatePlaylistById(t *testing.T) {
 				UserId: 1,
 			},
 			mockSetup: func() {
-				mock.ExpectExec("^UPDATE playlists SET name = \\? WHERE user_id = \\? AND id = \\?$").
-					WithArgs("Playlist 1", 1, 1).
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectExec("^UPDATE playlists SET name = \\?, duplicate_policy = COALESCE\\(NULLIF\\(\\?, ''\\), duplicate_policy\\) WHERE user_id = \\? AND id = \\? AND deleted_at IS NULL$").
+					WithArgs("Playlist 1", "", 1, 1).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectSnapshot(mock, 1, 1, models.ActionUpdate, 5)
+				mock.ExpectCommit()
 			},
 			expectedError:  nil,
 			expectedResult: &models.Playlist{ID: 1, Name: "Playlist 1", UserId: 1},
@@ -261,27 +275,55 @@ func TestPlayListRepository_UpdatePlaylistById(t *testing.T) {
 				ID:   1,
 			},
 			mockSetup: func() {
-				mock.ExpectExec("^UPDATE playlists SET name = \\? WHERE user_id = \\? AND id = \\?$").
-					WithArgs("Playlist 1", 1, 1).
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectExec("^UPDATE playlists SET name = \\?, duplicate_policy = COALESCE\\(NULLIF\\(\\?, ''\\), duplicate_policy\\) WHERE user_id = \\? AND id = \\? AND deleted_at IS NULL$").
+					WithArgs("Playlist 1", "", 1, 1).
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			expectedError:  sql.ErrConnDone,
 			expectedResult: nil,
 		},
+		{
+			name:       "stale version",
+			playlistId: 1,
+			userId:     1,
+			ifMatch:    2,
+			playlist: &models.Playlist{
+				Name: "Playlist 1",
+				ID:   1,
+			},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("^SELECT user_id, version FROM playlists").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "version"}).AddRow(1, 3))
+				mock.ExpectRollback()
+			},
+			expectedError:  ErrVersionMismatch,
+			expectedResult: nil,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			err := repo.UpdatePlaylistById(tt.userId, tt.playlist)
+			change := &models.PlaylistChange{UserId: tt.userId, PlaylistId: tt.playlistId}
+			if tt.ifMatch != 0 {
+				change.IfMatch = &tt.ifMatch
+			}
+			err := repo.UpdatePlaylistById(change, tt.playlist)
 
 			if tt.expectedError != nil {
 				require.Error(t, err)
 				assert.Equal(t, tt.expectedError, err)
 				assert.Nil(t, tt.expectedResult)
+				assert.Zero(t, change.Version)
 			} else {
 				assert.Equal(t, tt.expectedResult, tt.playlist)
+				assert.Equal(t, 3, change.Version)
 			}
 
 			err = mock.ExpectationsWereMet()
@@ -314,9 +356,13 @@ func TestPlayListRepository_DeletePlaylistById(t *testing.T) {
 			playlistId: 1,
 			userId:     1,
 			mockSetup: func() {
-				mock.ExpectExec("^DELETE FROM playlists WHERE user_id = \\? AND id = \\?$").
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectExec("^UPDATE playlists SET deleted_at = CURRENT_TIMESTAMP WHERE user_id = \\? AND id = \\? AND deleted_at IS NULL$").
 					WithArgs(1, 1).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectSnapshot(mock, 1, 1, models.ActionDelete, 6)
+				mock.ExpectCommit()
 			},
 			expectedError: nil,
 		},
@@ -325,9 +371,12 @@ func TestPlayListRepository_DeletePlaylistById(t *testing.T) {
 			playlistId: 1,
 			userId:     1,
 			mockSetup: func() {
-				mock.ExpectExec("^DELETE FROM playlists WHERE user_id = \\? AND id = \\?$").
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectExec("^UPDATE playlists SET deleted_at = CURRENT_TIMESTAMP WHERE user_id = \\? AND id = \\? AND deleted_at IS NULL$").
 					WithArgs(1, 1).
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			expectedError: sql.ErrConnDone,
 		},
@@ -337,7 +386,7 @@ func TestPlayListRepository_DeletePlaylistById(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			err := repo.DeletePlaylistById(tt.userId, tt.playlistId)
+			err := repo.DeletePlaylistById(&models.PlaylistChange{UserId: tt.userId, PlaylistId: tt.playlistId})
 
 			if tt.expectedError != nil {
 				require.Error(t, err)
@@ -351,3 +400,95 @@ func TestPlayListRepository_DeletePlaylistById(t *testing.T) {
 		})
 	}
 }
+
+// expectClaimVersion expects the playlist to be locked at the given version and bumped.
+func expectClaimVersion(mock sqlmock.Sqlmock, playlistId, userId, version int) {
+	mock.ExpectQuery(`^SELECT user_id, version FROM playlists WHERE id = \? AND deleted_at IS NULL FOR UPDATE$`).
+		WithArgs(playlistId).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "version"}).AddRow(userId, version))
+	mock.ExpectExec(`^UPDATE playlists SET version = version \+ 1 WHERE id = \?$`).
+		WithArgs(playlistId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestClaimPlaylistVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	current := 3
+	stale := 2
+
+	tests := []struct {
+		name            string
+		change          *models.PlaylistChange
+		mockSetup       func()
+		expectedVersion int
+		expectedError   error
+	}{
+		{
+			name:   "matching version is bumped",
+			change: &models.PlaylistChange{UserId: 1, PlaylistId: 1, IfMatch: &current},
+			mockSetup: func() {
+				expectClaimVersion(mock, 1, 1, 3)
+			},
+			expectedVersion: 4,
+		},
+		{
+			name:   "no expected version is bumped",
+			change: &models.PlaylistChange{UserId: 1, PlaylistId: 1},
+			mockSetup: func() {
+				expectClaimVersion(mock, 1, 1, 3)
+			},
+			expectedVersion: 4,
+		},
+		{
+			name:   "stale version is rejected",
+			change: &models.PlaylistChange{UserId: 1, PlaylistId: 1, IfMatch: &stale},
+			mockSetup: func() {
+				mock.ExpectQuery(`^SELECT user_id, version FROM playlists`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "version"}).AddRow(1, 3))
+			},
+			expectedError: ErrVersionMismatch,
+		},
+		{
+			name:   "foreign playlist",
+			change: &models.PlaylistChange{UserId: 1, PlaylistId: 1},
+			mockSetup: func() {
+				mock.ExpectQuery(`^SELECT user_id, version FROM playlists`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "version"}).AddRow(2, 3))
+			},
+			expectedError: permissionDenied,
+		},
+		{
+			name:   "deleted playlist",
+			change: &models.PlaylistChange{UserId: 1, PlaylistId: 1},
+			mockSetup: func() {
+				mock.ExpectQuery(`^SELECT user_id, version FROM playlists`).
+					WithArgs(1).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: playlistNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			tt.mockSetup()
+			mock.ExpectRollback()
+
+			tx, err := db.Begin()
+			require.NoError(t, err)
+
+			version, err := claimPlaylistVersion(tx, tt.change)
+			require.NoError(t, tx.Rollback())
+
+			assert.Equal(t, tt.expectedError, err)
+			assert.Equal(t, tt.expectedVersion, version)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+)
+
+var (
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+type QuotaRepository struct {
+	storage *sql.DB
+	log     *logging.LogrusLogger
+}
+
+func NewQuotaRepository(
+	storage *sql.DB,
+	log *logging.LogrusLogger,
+) *QuotaRepository {
+	return &QuotaRepository{
+		storage: storage,
+		log:     log,
+	}
+}
+
+func (q *QuotaRepository) GetQuotaOverride(userId int) (*models.QuotaOverride, error) {
+	var maxPlaylists, maxTracks, maxRequests sql.NullInt64
+	err := q.storage.QueryRow(
+		"SELECT max_playlists, max_tracks_per_playlist, max_requests_per_day FROM user_quotas WHERE user_id = ?",
+		userId,
+	).Scan(&maxPlaylists, &maxTracks, &maxRequests)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.QuotaOverride{}, nil
+	}
+	if err != nil {
+		q.log.Error("REPOSITORY: unsuccessful get quota override: ", err)
+		return nil, err
+	}
+
+	return &models.QuotaOverride{
+		MaxPlaylists:         nullIntPtr(maxPlaylists),
+		MaxTracksPerPlaylist: nullIntPtr(maxTracks),
+		MaxRequestsPerDay:    nullIntPtr(maxRequests),
+	}, nil
+}
+
+func (q *QuotaRepository) SetQuotaOverride(userId int, override *models.QuotaOverride) error {
+	_, err := q.storage.Exec(`
+		INSERT INTO user_quotas (user_id, max_playlists, max_tracks_per_playlist, max_requests_per_day)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			max_playlists = VALUES(max_playlists),
+			max_tracks_per_playlist = VALUES(max_tracks_per_playlist),
+			max_requests_per_day = VALUES(max_requests_per_day)
+	`, userId, override.MaxPlaylists, override.MaxTracksPerPlaylist, override.MaxRequestsPerDay)
+	if err != nil {
+		q.log.Error("REPOSITORY: unsuccessful set quota override: ", err)
+		return err
+	}
+
+	q.log.Info("REPOSITORY: quota override set: ", userId)
+	return nil
+}
+
+func (q *QuotaRepository) CountPlaylists(userId int) (int, error) {
+	var count int
+	err := q.storage.QueryRow(
+		"SELECT COUNT(*) FROM playlists WHERE user_id = ? AND deleted_at IS NULL",
+		userId,
+	).Scan(&count)
+	if err != nil {
+		q.log.Error("REPOSITORY: unsuccessful count playlists: ", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (q *QuotaRepository) GetLargestPlaylistSize(userId int) (int, error) {
+	var size int
+	err := q.storage.QueryRow(`
+		SELECT COALESCE(MAX(tracks), 0) FROM (
+			SELECT COUNT(ps.id) AS tracks
+			FROM playlists p
+			LEFT JOIN playlist_songs ps ON ps.playlist_id = p.id
+			WHERE p.user_id = ? AND p.deleted_at IS NULL
+			GROUP BY p.id
+		) sizes
+	`, userId).Scan(&size)
+	if err != nil {
+		q.log.Error("REPOSITORY: unsuccessful get largest playlist size: ", err)
+		return 0, err
+	}
+	return size, nil
+}
+
+// IncrementRequestCount counts one more request of the user on the given day and
+// returns the new total. The total comes back as the insert id of the same
+// statement, so concurrent requests each see their own count.
+func (q *QuotaRepository) IncrementRequestCount(userId int, day string) (int, error) {
+	result, err := q.storage.Exec(`
+		INSERT INTO user_request_counts (user_id, day, count) VALUES (?, ?, LAST_INSERT_ID(1))
+		ON DUPLICATE KEY UPDATE count = LAST_INSERT_ID(count + 1)
+	`, userId, day)
+	if err != nil {
+		q.log.Error("REPOSITORY: unsuccessful count request: ", err)
+		return 0, err
+	}
+
+	count, err := result.LastInsertId()
+	if err != nil {
+		q.log.Error("REPOSITORY: unsuccessful count request: ", err)
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// PurgeRequestCounts deletes the request counts of the days before the given one.
+func (q *QuotaRepository) PurgeRequestCounts(before string) (int64, error) {
+	result, err := q.storage.Exec("DELETE FROM user_request_counts WHERE day < ?", before)
+	if err != nil {
+		q.log.Error("REPOSITORY: unsuccessful purge request counts: ", err)
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		q.log.Error("REPOSITORY: unsuccessful purge request counts: ", err)
+		return 0, err
+	}
+	return purged, nil
+}
+
+func (q *QuotaRepository) GetRequestCount(userId int, day string) (int, error) {
+	var count int
+	err := q.storage.QueryRow(
+		"SELECT count FROM user_request_counts WHERE user_id = ? AND day = ?",
+		userId,
+		day,
+	).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		q.log.Error("REPOSITORY: unsuccessful get request count: ", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// checkPlaylistQuota locks the user until the transaction ends, so concurrent writes
+// can't both pass the check, and fails when adding playlists would go past the
+// limit. Nil limits and a limit of 0 are unlimited.
+func checkPlaylistQuota(tx *sql.Tx, userId, adding int, limits *models.QuotaLimits) error {
+	if limits == nil || limits.MaxPlaylists == 0 {
+		return nil
+	}
+
+	var locked int
+	if err := tx.QueryRow("SELECT id FROM users WHERE id = ? FOR UPDATE", userId).Scan(&locked); err != nil {
+		return err
+	}
+
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM playlists WHERE user_id = ? AND deleted_at IS NULL", userId).Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	if count+adding > limits.MaxPlaylists {
+		return fmt.Errorf("%w: at most %d playlists allowed", ErrQuotaExceeded, limits.MaxPlaylists)
+	}
+	return nil
+}
+
+// checkTrackQuota runs after the transaction has written the tracks and fails when
+// the playlist ends up over the limit. The playlist row must be locked or created by
+// the same transaction.
+func checkTrackQuota(q querier, playlistId int, limits *models.QuotaLimits) error {
+	if limits == nil || limits.MaxTracksPerPlaylist == 0 {
+		return nil
+	}
+
+	var count int
+	if err := q.QueryRow("SELECT COUNT(*) FROM playlist_songs WHERE playlist_id = ?", playlistId).Scan(&count); err != nil {
+		return err
+	}
+
+	if count > limits.MaxTracksPerPlaylist {
+		return fmt.Errorf("%w: at most %d tracks per playlist allowed", ErrQuotaExceeded, limits.MaxTracksPerPlaylist)
+	}
+	return nil
+}
+
+func nullIntPtr(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	v := int(value.Int64)
+	return &v
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"testing"
+)
+
+func TestQuotaRepository_GetQuotaOverride(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewQuotaRepository(db, logging.NewLogger())
+
+	maxPlaylists := 1000
+
+	testCases := []struct {
+		name             string
+		mockSetup        func()
+		expectedOverride *models.QuotaOverride
+	}{
+		{
+			name: "override found",
+			mockSetup: func() {
+				mock.ExpectQuery(`^SELECT max_playlists, max_tracks_per_playlist, max_requests_per_day FROM user_quotas WHERE user_id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"max_playlists", "max_tracks_per_playlist", "max_requests_per_day"}).
+						AddRow(1000, nil, nil))
+			},
+			expectedOverride: &models.QuotaOverride{MaxPlaylists: &maxPlaylists},
+		},
+		{
+			name: "no override",
+			mockSetup: func() {
+				mock.ExpectQuery(`^SELECT max_playlists, max_tracks_per_playlist, max_requests_per_day FROM user_quotas`).
+					WithArgs(1).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedOverride: &models.QuotaOverride{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			override, err := repo.GetQuotaOverride(1)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOverride, override)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestQuotaRepository_SetQuotaOverride(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewQuotaRepository(db, logging.NewLogger())
+
+	maxRequests := 50
+	mock.ExpectExec(`^INSERT INTO user_quotas \(user_id, max_playlists, max_tracks_per_playlist, max_requests_per_day\) VALUES \(\?, \?, \?, \?\) ON DUPLICATE KEY UPDATE`).
+		WithArgs(1, nil, nil, &maxRequests).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.SetQuotaOverride(1, &models.QuotaOverride{MaxRequestsPerDay: &maxRequests})
+
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuotaRepository_CountPlaylists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewQuotaRepository(db, logging.NewLogger())
+
+	mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM playlists WHERE user_id = \? AND deleted_at IS NULL$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+	count, err := repo.CountPlaylists(1)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuotaRepository_IncrementRequestCount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewQuotaRepository(db, logging.NewLogger())
+
+	mock.ExpectExec(`^INSERT INTO user_request_counts \(user_id, day, count\) VALUES \(\?, \?, LAST_INSERT_ID\(1\)\) ON DUPLICATE KEY UPDATE count = LAST_INSERT_ID\(count \+ 1\)$`).
+		WithArgs(1, "2026-10-18").
+		WillReturnResult(sqlmock.NewResult(8, 2))
+
+	count, err := repo.IncrementRequestCount(1, "2026-10-18")
+
+	require.NoError(t, err)
+	assert.Equal(t, 8, count)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuotaRepository_PurgeRequestCounts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewQuotaRepository(db, logging.NewLogger())
+
+	mock.ExpectExec(`^DELETE FROM user_request_counts WHERE day < \?$`).
+		WithArgs("2026-10-18").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	purged, err := repo.PurgeRequestCounts("2026-10-18")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"music-service/internal/models"
 	"music-service/pkg/logging"
+	"time"
 )
 
 type Repository struct {
@@ -11,6 +13,17 @@ type Repository struct {
 	PlayList
 	Song
 	Token
+	Entry
+	History
+	SmartPlaylist
+	Folder
+	Stats
+	Tag
+	Trash
+	Quota
+	Import
+	Backup
+	AudioFeatures
 }
 
 type Authorization interface {
@@ -18,6 +31,7 @@ type Authorization interface {
 	GetUserByID(id int) (*models.User, error)
 	CreateUser(user *models.User) error
 	GetUserByUsernameAndPassword(username string, password string) (*models.User, error)
+	IsAdmin(userId int) (bool, error)
 }
 
 type Token interface {
@@ -27,17 +41,111 @@ type Token interface {
 }
 
 type PlayList interface {
-	CreatePlaylist(playlist *models.Playlist) (int64, error)
+	CreatePlaylist(playlist *models.Playlist, limits *models.QuotaLimits) (int64, error)
 	GetAllPlaylists(userId int) ([]*models.Playlist, error)
 	GetPlaylistById(userId int, playlistId int) (*models.Playlist, error)
-	UpdatePlaylistById(userId int, playlist *models.Playlist) error
-	DeletePlaylistById(userId int, playlistId int) error
+	UpdatePlaylistById(change *models.PlaylistChange, playlist *models.Playlist) error
+	DeletePlaylistById(change *models.PlaylistChange) error
 }
 
 type Song interface {
-	GetAllSongsFromPlaylist(userId, playlistId int) ([]*models.Song, error)
-	CreateSong(userId, playlistId int, song *models.Song) (string, error)
-	DeleteSongFromPlaylist(userId, playlistId int, songId string) error
+	GetAllSongsFromPlaylist(userId, playlistId int, filter *models.EntryFilter) ([]*models.PlaylistEntry, error)
+	CreateSong(change *models.PlaylistChange, song *models.Song) (string, error)
+	CreateSongs(change *models.PlaylistChange, songs []models.Song) (*models.AddTracksReport, error)
+	DeleteSongFromPlaylist(change *models.PlaylistChange, songId string) error
+	GetCachedTrack(trackId string) (*models.Song, time.Time, error)
+	SaveCachedTrack(song *models.Song) error
+	GetSongsWithoutArtists(limit int) ([]string, error)
+	HasSongsWithoutArtists(playlistId int) (bool, error)
+	GetStaleSongs(olderThan time.Duration, limit int) ([]string, error)
+	RecordPopularity(songs []models.Song) error
+	MarkSongsUnavailable(songIds []string) error
+}
+
+type Entry interface {
+	GetPlaylistEntries(userId, playlistId int) ([]*models.PlaylistEntry, error)
+	MovePlaylistEntry(change *models.PlaylistChange, entryId, position int) error
+	DeletePlaylistEntry(change *models.PlaylistChange, entryId int) error
+	UpdateEntryNote(change *models.PlaylistChange, entryId int, note string) error
+	GetDuplicateEntries(userId, playlistId int) ([]*models.DuplicateGroup, error)
+	CollapseDuplicateEntries(change *models.PlaylistChange) (int64, error)
+}
+
+type History interface {
+	GetHistory(userId, playlistId int) ([]*models.PlaylistSnapshot, error)
+	GetSnapshot(userId, playlistId, snapshotId int) (*models.PlaylistSnapshot, error)
+	RevertToSnapshot(change *models.PlaylistChange, snapshotId int) error
+}
+
+type SmartPlaylist interface {
+	CreateSmartPlaylist(playlist *models.Playlist, limits *models.QuotaLimits) (int64, error)
+	UpdateSmartRules(change *models.PlaylistChange, rules *models.SmartRules, refreshMode string) error
+	EvaluateRules(userId int, rules *models.SmartRules) ([]*models.Song, error)
+	RefreshSmartPlaylist(change *models.PlaylistChange) (int64, error)
+	GetScheduledSmartPlaylists() ([]*models.Playlist, error)
+	FreezeSmartPlaylist(playlistId int, frozen *models.Playlist, limits *models.QuotaLimits) (int64, error)
+}
+
+type Folder interface {
+	CreateFolder(folder *models.Folder) (int64, error)
+	GetFolders(userId int) ([]*models.Folder, error)
+	RenameFolder(userId, folderId int, name string) error
+	MoveFolder(userId, folderId int, parentId *int) error
+	DeleteFolder(userId, folderId int, mode string) error
+	MovePlaylist(userId, playlistId int, folderId *int) error
+}
+
+type Stats interface {
+	GetStatsRevision(userId, playlistId int) (string, error)
+	GetPlaylistStats(userId, playlistId int) (*models.PlaylistStats, error)
+}
+
+type Tag interface {
+	CreateTag(userId int, name string) (int64, error)
+	GetTags(userId int, prefix string) ([]*models.Tag, error)
+	RenameTag(userId, tagId int, name string) error
+	DeleteTag(userId, tagId int) error
+	TagPlaylist(userId, playlistId int, name string) (int64, error)
+	UntagPlaylist(userId, playlistId, tagId int) error
+	GetPlaylistTags(userId, playlistId int) ([]*models.Tag, error)
+	TagSong(userId int, songId string, name string) (int64, error)
+	UntagSong(userId int, songId string, tagId int) error
+	GetSongTags(userId int, songId string) ([]*models.Tag, error)
+	GetTaggedPlaylistIds(userId int, tags []string) (map[int]bool, error)
+	GetTaggedSongIds(userId int, tags []string) (map[string]bool, error)
+}
+
+type Trash interface {
+	GetTrash(userId int) ([]*models.TrashedPlaylist, error)
+	RestorePlaylist(userId, playlistId int, limits *models.QuotaLimits) error
+	PurgeDeletedPlaylists(olderThan time.Duration) (int64, error)
+}
+
+type Quota interface {
+	GetQuotaOverride(userId int) (*models.QuotaOverride, error)
+	SetQuotaOverride(userId int, override *models.QuotaOverride) error
+	CountPlaylists(userId int) (int, error)
+	GetLargestPlaylistSize(userId int) (int, error)
+	IncrementRequestCount(userId int, day string) (int, error)
+	GetRequestCount(userId int, day string) (int, error)
+	PurgeRequestCounts(before string) (int64, error)
+}
+
+type Import interface {
+	ImportPlaylist(playlist *models.Playlist, limits *models.QuotaLimits) (int64, error)
+	CreateImportJob(job *models.ImportJob) (int64, error)
+	UpdateImportJob(job *models.ImportJob) error
+	GetImportJob(userId, jobId int) (*models.ImportJob, error)
+}
+
+type AudioFeatures interface {
+	SaveAudioFeatures(features []models.AudioFeatures, unavailable []string) error
+	GetSongsWithoutAudioFeatures(limit int) ([]string, error)
+}
+
+type Backup interface {
+	RestoreBackupPlaylist(origin string, sourceId int, playlist *models.Playlist, entries []*models.PlaylistEntry, limits *models.QuotaLimits) (int64, bool, error)
+	GetRestoredPlaylists(userId int, origin string) (map[int]bool, error)
 }
 
 func NewRepository(db *sql.DB, log *logging.LogrusLogger) *Repository {
@@ -46,5 +154,36 @@ func NewRepository(db *sql.DB, log *logging.LogrusLogger) *Repository {
 		PlayList:      NewPlayListRepository(db, log),
 		Song:          NewSpotifyRepository(db, log),
 		Token:         NewTokenRepository(db, log),
+		Entry:         NewEntryRepository(db, log),
+		History:       NewHistoryRepository(db, log),
+		SmartPlaylist: NewSmartRepository(db, log),
+		Folder:        NewFolderRepository(db, log),
+		Stats:         NewStatsRepository(db, log),
+		Tag:           NewTagRepository(db, log),
+		Trash:         NewTrashRepository(db, log),
+		Quota:         NewQuotaRepository(db, log),
+		Import:        NewImportRepository(db, log),
+		Backup:        NewBackupRepository(db, log),
+		AudioFeatures: NewFeatureRepository(db, log),
+	}
+}
+
+type querier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func checkPlaylistOwner(q querier, userId, playlistId int) error {
+	var playlistOwner int
+	err := q.QueryRow(`SELECT user_id FROM playlists WHERE id = ? AND deleted_at IS NULL`, playlistId).Scan(&playlistOwner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return playlistNotFound
+		}
+		return err
+	}
+
+	if playlistOwner != userId {
+		return permissionDenied
 	}
+	return nil
 }
@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"music-service/internal/models"
+	"music-service/internal/rules"
+	"music-service/pkg/logging"
+)
+
+var (
+	notSmartPlaylist = errors.New("playlist is not a smart playlist")
+)
+
+type SmartRepository struct {
+	storage *sql.DB
+	log     *logging.LogrusLogger
+}
+
+func NewSmartRepository(
+	storage *sql.DB,
+	log *logging.LogrusLogger,
+) *SmartRepository {
+	return &SmartRepository{
+		storage: storage,
+		log:     log,
+	}
+}
+
+// CreateSmartPlaylist stores the playlist and, in scheduled mode, its first set of
+// tracks.
+func (s *SmartRepository) CreateSmartPlaylist(playlist *models.Playlist, limits *models.QuotaLimits) (int64, error) {
+	encoded, err := json.Marshal(playlist.Rules)
+	if err != nil {
+		s.log.Error("REPOSITORY: can't encode smart rules: ", err)
+		return 0, err
+	}
+
+	tx, err := s.storage.Begin()
+	if err != nil {
+		s.log.Error("REPOSITORY: begin create smart playlist: ", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := checkPlaylistQuota(tx, playlist.UserId, 1, limits); err != nil {
+		s.log.Error("REPOSITORY: playlist quota: ", err)
+		return 0, err
+	}
+
+	result, err := tx.Exec(
+		"INSERT INTO playlists (name, user_id, duplicate_policy, kind, rules, refresh_mode) VALUES (?, ?, ?, ?, ?, ?)",
+		playlist.Name,
+		playlist.UserId,
+		models.DuplicatePolicyAllow,
+		models.PlaylistKindSmart,
+		encoded,
+		playlist.RefreshMode,
+	)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful create smart playlist: ", err)
+		return 0, err
+	}
+
+	playlistId, err := result.LastInsertId()
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful create smart playlist! Id is empty: ", err)
+		return 0, err
+	}
+
+	if playlist.RefreshMode == models.RefreshScheduled {
+		if _, err := refreshRuleResults(tx, int(playlistId), playlist.UserId, playlist.Rules); err != nil {
+			s.log.Error("REPOSITORY: smart playlist tracks not saved: ", err)
+			return 0, err
+		}
+
+		if err := checkTrackQuota(tx, int(playlistId), limits); err != nil {
+			s.log.Error("REPOSITORY: track quota: ", err)
+			return 0, err
+		}
+	}
+
+	snapshotId, err := createSnapshot(tx, playlist.UserId, int(playlistId), models.ActionCreate)
+	if err != nil {
+		s.log.Error("REPOSITORY: snapshot not created: ", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log.Error("REPOSITORY: commit create smart playlist: ", err)
+		return 0, err
+	}
+	playlist.SnapshotId = snapshotId
+
+	s.log.Info("REPOSITORY: create smart playlist: ", playlistId)
+	return playlistId, nil
+}
+
+// UpdateSmartRules replaces the rules and refreshes the tracks in one go.
+func (s *SmartRepository) UpdateSmartRules(change *models.PlaylistChange, smartRules *models.SmartRules, refreshMode string) error {
+	userId, playlistId := change.UserId, change.PlaylistId
+	encoded, err := json.Marshal(smartRules)
+	if err != nil {
+		s.log.Error("REPOSITORY: can't encode smart rules: ", err)
+		return err
+	}
+
+	tx, err := s.storage.Begin()
+	if err != nil {
+		s.log.Error("REPOSITORY: begin update smart rules: ", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	version, err := claimPlaylistVersion(tx, change)
+	if err != nil {
+		s.log.Error("REPOSITORY: claim playlist version: ", err)
+		return err
+	}
+
+	if _, err := getSmartRules(tx, userId, playlistId); err != nil {
+		s.log.Error("REPOSITORY: get smart rules: ", err)
+		return err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE playlists SET rules = ?, refresh_mode = COALESCE(NULLIF(?, ''), refresh_mode) WHERE user_id = ? AND id = ?",
+		encoded,
+		refreshMode,
+		userId,
+		playlistId,
+	)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful update smart rules: ", err)
+		return err
+	}
+
+	if _, err := refreshRuleResults(tx, playlistId, userId, smartRules); err != nil {
+		s.log.Error("REPOSITORY: smart playlist tracks not saved: ", err)
+		return err
+	}
+
+	if err := checkTrackQuota(tx, playlistId, change.Limits); err != nil {
+		s.log.Error("REPOSITORY: track quota: ", err)
+		return err
+	}
+
+	snapshotId, err := createSnapshot(tx, userId, playlistId, models.ActionUpdate)
+	if err != nil {
+		s.log.Error("REPOSITORY: snapshot not created: ", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log.Error("REPOSITORY: commit update smart rules: ", err)
+		return err
+	}
+	change.Version = version
+	change.SnapshotId = snapshotId
+
+	s.log.Info("REPOSITORY: update smart rules: ", playlistId)
+	return nil
+}
+
+func (s *SmartRepository) EvaluateRules(userId int, smartRules *models.SmartRules) ([]*models.Song, error) {
+	query, err := rules.Compile(smartRules, userId)
+	if err != nil {
+		s.log.Error("REPOSITORY: can't compile smart rules: ", err)
+		return nil, err
+	}
+
+	rows, err := s.storage.Query(fmt.Sprintf(`
+		SELECT `+songColumns+`
+		FROM songs s
+		WHERE %s
+		ORDER BY %s
+		LIMIT ?
+	`, query.Where, query.OrderBy), append(query.Args, query.Limit)...)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful evaluate smart rules: ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var songs []*models.Song
+	for rows.Next() {
+		song, err := scanRowsIntoSong(rows)
+		if err != nil {
+			return nil, err
+		}
+		songs = append(songs, song)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("REPOSITORY: evaluate smart rules: ", len(songs))
+	return songs, nil
+}
+
+func (s *SmartRepository) RefreshSmartPlaylist(change *models.PlaylistChange) (int64, error) {
+	userId, playlistId := change.UserId, change.PlaylistId
+	tx, err := s.storage.Begin()
+	if err != nil {
+		s.log.Error("REPOSITORY: begin refresh smart playlist: ", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	version, err := claimPlaylistVersion(tx, change)
+	if err != nil {
+		s.log.Error("REPOSITORY: claim playlist version: ", err)
+		return 0, err
+	}
+
+	smartRules, err := getSmartRules(tx, userId, playlistId)
+	if err != nil {
+		s.log.Error("REPOSITORY: get smart rules: ", err)
+		return 0, err
+	}
+
+	count, err := refreshRuleResults(tx, playlistId, userId, smartRules)
+	if err != nil {
+		s.log.Error("REPOSITORY: smart playlist tracks not saved: ", err)
+		return 0, err
+	}
+
+	if err := checkTrackQuota(tx, playlistId, change.Limits); err != nil {
+		s.log.Error("REPOSITORY: track quota: ", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log.Error("REPOSITORY: commit refresh smart playlist: ", err)
+		return 0, err
+	}
+	change.Version = version
+
+	s.log.Info("REPOSITORY: smart playlist refreshed: ", playlistId, count)
+	return count, nil
+}
+
+func (s *SmartRepository) GetScheduledSmartPlaylists() ([]*models.Playlist, error) {
+	rows, err := s.storage.Query(
+		"SELECT "+playlistColumns+" FROM playlists WHERE kind = ? AND refresh_mode = ? AND deleted_at IS NULL",
+		models.PlaylistKindSmart,
+		models.RefreshScheduled,
+	)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful get scheduled smart playlists: ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var playlists []*models.Playlist
+	for rows.Next() {
+		playlist, err := scanRowsIntoPlayList(rows)
+		if err != nil {
+			return nil, err
+		}
+		playlists = append(playlists, playlist)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("REPOSITORY: get scheduled smart playlists: ", len(playlists))
+	return playlists, nil
+}
+
+// FreezeSmartPlaylist saves what the smart playlist holds right now as a normal
+// playlist: the stored tracks of a scheduled playlist as they are, the rules
+// evaluated now for a live one, as that is what reading it returns.
+func (s *SmartRepository) FreezeSmartPlaylist(playlistId int, frozen *models.Playlist, limits *models.QuotaLimits) (int64, error) {
+	tx, err := s.storage.Begin()
+	if err != nil {
+		s.log.Error("REPOSITORY: begin freeze smart playlist: ", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := checkPlaylistQuota(tx, frozen.UserId, 1, limits); err != nil {
+		s.log.Error("REPOSITORY: playlist quota: ", err)
+		return 0, err
+	}
+
+	smartRules, err := getSmartRules(tx, frozen.UserId, playlistId)
+	if err != nil {
+		s.log.Error("REPOSITORY: get smart rules: ", err)
+		return 0, err
+	}
+
+	var refreshMode string
+	if err := tx.QueryRow(`SELECT refresh_mode FROM playlists WHERE id = ?`, playlistId).Scan(&refreshMode); err != nil {
+		s.log.Error("REPOSITORY: get refresh mode: ", err)
+		return 0, err
+	}
+
+	result, err := tx.Exec(
+		"INSERT INTO playlists (name, user_id, duplicate_policy) VALUES (?, ?, ?)",
+		frozen.Name,
+		frozen.UserId,
+		models.DuplicatePolicyAllow,
+	)
+	if err != nil {
+		s.log.Error("REPOSITORY: frozen playlist not created: ", err)
+		return 0, err
+	}
+
+	frozenId, err := result.LastInsertId()
+	if err != nil {
+		s.log.Error("REPOSITORY: frozen playlist not created! Id is empty: ", err)
+		return 0, err
+	}
+
+	if refreshMode == models.RefreshScheduled {
+		_, err = tx.Exec(`
+			INSERT INTO playlist_songs (playlist_id, song_id, position, added_at, added_by, note)
+			SELECT ?, song_id, position, added_at, added_by, note FROM playlist_songs WHERE playlist_id = ?`,
+			frozenId,
+			playlistId,
+		)
+	} else {
+		_, err = insertRuleResults(tx, int(frozenId), frozen.UserId, smartRules)
+	}
+	if err != nil {
+		s.log.Error("REPOSITORY: frozen playlist tracks not saved: ", err)
+		return 0, err
+	}
+
+	if err := checkTrackQuota(tx, int(frozenId), limits); err != nil {
+		s.log.Error("REPOSITORY: track quota: ", err)
+		return 0, err
+	}
+
+	snapshotId, err := createSnapshot(tx, frozen.UserId, int(frozenId), models.ActionCreate)
+	if err != nil {
+		s.log.Error("REPOSITORY: snapshot not created: ", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log.Error("REPOSITORY: commit freeze smart playlist: ", err)
+		return 0, err
+	}
+	frozen.SnapshotId = snapshotId
+
+	s.log.Info("REPOSITORY: smart playlist frozen: ", playlistId, frozenId)
+	return frozenId, nil
+}
+
+func getSmartRules(q querier, userId, playlistId int) (*models.SmartRules, error) {
+	var owner int
+	var kind string
+	var encoded []byte
+	err := q.QueryRow(`SELECT user_id, kind, rules FROM playlists WHERE id = ? AND deleted_at IS NULL`, playlistId).
+		Scan(&owner, &kind, &encoded)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, playlistNotFound
+		}
+		return nil, err
+	}
+
+	if owner != userId {
+		return nil, permissionDenied
+	}
+
+	if kind != models.PlaylistKindSmart || len(encoded) == 0 {
+		return nil, notSmartPlaylist
+	}
+
+	smartRules := new(models.SmartRules)
+	if err := json.Unmarshal(encoded, smartRules); err != nil {
+		return nil, err
+	}
+	return smartRules, nil
+}
+
+// refreshRuleResults replaces the stored tracks of the playlist with what its rules
+// match right now.
+func refreshRuleResults(tx *sql.Tx, playlistId, userId int, smartRules *models.SmartRules) (int64, error) {
+	if _, err := tx.Exec(`DELETE FROM playlist_songs WHERE playlist_id = ?`, playlistId); err != nil {
+		return 0, err
+	}
+
+	count, err := insertRuleResults(tx, playlistId, userId, smartRules)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`UPDATE playlists SET refreshed_at = CURRENT_TIMESTAMP WHERE id = ?`, playlistId); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func insertRuleResults(tx *sql.Tx, playlistId, userId int, smartRules *models.SmartRules) (int64, error) {
+	query, err := rules.Compile(smartRules, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	args := append([]interface{}{playlistId, userId}, query.Args...)
+	result, err := tx.Exec(fmt.Sprintf(`
+		INSERT INTO playlist_songs (playlist_id, song_id, position, added_by)
+		SELECT ?, s.id, ROW_NUMBER() OVER (ORDER BY %s), ?
+		FROM songs s
+		WHERE %s
+		ORDER BY %s
+		LIMIT ?
+	`, query.OrderBy, query.Where, query.OrderBy), append(args, query.Limit)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"testing"
+)
+
+const testSmartRules = `{"match":{"field":"artist","op":"=","value":"Test Artist"},"limit":10}`
+
+func TestSmartRepository_EvaluateRules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSmartRepository(db, logging.NewLogger())
+
+	smartRules := &models.SmartRules{
+		Match: models.RuleNode{Field: "artist", Op: "=", Value: []byte(`"Test Artist"`)},
+		Limit: 10,
+	}
+
+	mock.ExpectQuery(`^SELECT s\.id, .* FROM songs s WHERE \(s\.id IN \( SELECT sa\.song_id FROM song_artists sa JOIN artists a ON sa\.artist_id = a\.id WHERE a\.name = \?\)\) AND s\.id IN \( SELECT ps\.song_id FROM playlist_songs ps .* p\.deleted_at IS NULL\) ORDER BY s\.popularity DESC, s\.id LIMIT \?$`).
+		WithArgs("Test Artist", 1, 10).
+		WillReturnRows(sqlmock.NewRows(songRowColumns).
+			AddRow("song123", "Test Song", "Test Artist", "", "", 200, "", 80, "", "", "album1", true, 3, 1, "day"))
+
+	songs, err := repo.EvaluateRules(1, smartRules)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Song{
+		{
+			ID: "song123", Title: "Test Song", Artist: "Test Artist", AlbumId: "album1", Duration: 200, ReleaseDatePrecision: "day",
+			Popularity: 80, Explicit: true, TrackNumber: 3, DiscNumber: 1,
+		},
+	}, songs)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSmartRepository_RefreshSmartPlaylist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSmartRepository(db, logging.NewLogger())
+
+	testCases := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+		expectedCount int64
+	}{
+		{
+			name: "successful refresh",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectQuery(`^SELECT user_id, kind, rules FROM playlists WHERE id = \? AND deleted_at IS NULL$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "kind", "rules"}).AddRow(1, "smart", testSmartRules))
+				mock.ExpectExec(`^DELETE FROM playlist_songs WHERE playlist_id = \?$`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(`^INSERT INTO playlist_songs \(playlist_id, song_id, position, added_by\) SELECT \?, s\.id, ROW_NUMBER\(\) .* LIMIT \?$`).
+					WithArgs(1, 1, "Test Artist", 1, 10).
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec(`^UPDATE playlists SET refreshed_at = CURRENT_TIMESTAMP WHERE id = \?$`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedCount: 4,
+		},
+		{
+			name: "not a smart playlist",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+				mock.ExpectQuery(`^SELECT user_id, kind, rules FROM playlists WHERE id = \? AND deleted_at IS NULL$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "kind", "rules"}).AddRow(1, "manual", nil))
+				mock.ExpectRollback()
+			},
+			expectedError: notSmartPlaylist,
+		},
+		{
+			name: "playlist not found",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT user_id, version FROM playlists WHERE id = \? AND deleted_at IS NULL FOR UPDATE$`).
+					WithArgs(1).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: playlistNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			count, err := repo.RefreshSmartPlaylist(&models.PlaylistChange{UserId: 1, PlaylistId: 1})
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedCount, count)
+			}
+
+			err = mock.ExpectationsWereMet()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSmartRepository_UpdateSmartRules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSmartRepository(db, logging.NewLogger())
+
+	smartRules := &models.SmartRules{
+		Match: models.RuleNode{Field: "artist", Op: "=", Value: []byte(`"Test Artist"`)},
+		Limit: 10,
+	}
+
+	mock.ExpectBegin()
+	expectClaimVersion(mock, 1, 1, 2)
+	mock.ExpectQuery(`^SELECT user_id, kind, rules FROM playlists WHERE id = \? AND deleted_at IS NULL$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "kind", "rules"}).AddRow(1, "smart", testSmartRules))
+	mock.ExpectExec(`^UPDATE playlists SET rules = \?, refresh_mode = COALESCE\(NULLIF\(\?, ''\), refresh_mode\) WHERE user_id = \? AND id = \?$`).
+		WithArgs(sqlmock.AnyArg(), "", 1, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^DELETE FROM playlist_songs WHERE playlist_id = \?$`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`^INSERT INTO playlist_songs \(playlist_id, song_id, position, added_by\)`).
+		WithArgs(1, 1, "Test Artist", 1, 10).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(`^UPDATE playlists SET refreshed_at = CURRENT_TIMESTAMP WHERE id = \?$`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectSnapshot(mock, 1, 1, models.ActionUpdate, 6)
+	mock.ExpectCommit()
+
+	change := &models.PlaylistChange{UserId: 1, PlaylistId: 1}
+	err = repo.UpdateSmartRules(change, smartRules, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), change.SnapshotId)
+	assert.Equal(t, 3, change.Version)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSmartRepository_FreezeSmartPlaylist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSmartRepository(db, logging.NewLogger())
+
+	testCases := []struct {
+		name        string
+		refreshMode string
+		expectCopy  func()
+	}{
+		{
+			name:        "live playlist is evaluated now",
+			refreshMode: models.RefreshOnRead,
+			expectCopy: func() {
+				mock.ExpectExec(`^INSERT INTO playlist_songs \(playlist_id, song_id, position, added_by\)`).
+					WithArgs(5, 1, "Test Artist", 1, 10).
+					WillReturnResult(sqlmock.NewResult(0, 4))
+			},
+		},
+		{
+			name:        "scheduled playlist keeps its stored tracks",
+			refreshMode: models.RefreshScheduled,
+			expectCopy: func() {
+				mock.ExpectExec(`^INSERT INTO playlist_songs \(playlist_id, song_id, position, added_at, added_by, note\) SELECT \?, song_id, position, added_at, added_by, note FROM playlist_songs WHERE playlist_id = \?$`).
+					WithArgs(5, 1).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(`^SELECT user_id, kind, rules FROM playlists WHERE id = \? AND deleted_at IS NULL$`).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"user_id", "kind", "rules"}).AddRow(1, "smart", testSmartRules))
+			mock.ExpectQuery(`^SELECT refresh_mode FROM playlists WHERE id = \?$`).
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"refresh_mode"}).AddRow(tt.refreshMode))
+			mock.ExpectExec(`^INSERT INTO playlists \(name, user_id, duplicate_policy\) VALUES \(\?, \?, \?\)$`).
+				WithArgs("Frozen", 1, models.DuplicatePolicyAllow).
+				WillReturnResult(sqlmock.NewResult(5, 1))
+			tt.expectCopy()
+			expectSnapshot(mock, 1, 5, models.ActionCreate, 8)
+			mock.ExpectCommit()
+
+			frozen := &models.Playlist{Name: "Frozen", UserId: 1}
+			id, err := repo.FreezeSmartPlaylist(1, frozen, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), id)
+			assert.Equal(t, int64(8), frozen.SnapshotId)
+
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
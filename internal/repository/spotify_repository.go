@@ -5,12 +5,19 @@ import (
 	"errors"
 	"music-service/internal/models"
 	"music-service/pkg/logging"
+	"strings"
+	"time"
 )
 
 var (
 	musicNotFound    = errors.New("no rows in result set")
 	playlistNotFound = errors.New("playlist not found")
 	permissionDenied = errors.New("user does not own this playlist")
+
+	smartPlaylistReadOnly = errors.New("smart playlist tracks are computed from its rules")
+
+	ErrDuplicateSong     = errors.New("track is already in the playlist")
+	ErrSongNotInPlaylist = errors.New("track is not in the playlist")
 )
 
 type SpotifyRepository struct {
@@ -28,17 +35,52 @@ func NewSpotifyRepository(
 	}
 }
 
-func (s *SpotifyRepository) GetAllSongsFromPlaylist(userId, playlistId int) ([]*models.Song, error) {
-	var songs []*models.Song
+func (s *SpotifyRepository) GetAllSongsFromPlaylist(userId, playlistId int, filter *models.EntryFilter) ([]*models.PlaylistEntry, error) {
+	var entries []*models.PlaylistEntry
 	query := `
-		SELECT s.id, s.title, s.artist, s.album, s.album_cover, s.duration, 
-		       s.release_date, s.popularity, s.preview_url, s.external_url 
+		SELECT ` + entryColumns + `
 		FROM playlist_songs ps
 		JOIN songs s ON ps.song_id = s.id
 		JOIN playlists p ON ps.playlist_id = p.id
-		WHERE p.id = ? AND p.user_id = ?
-	`
-	rows, err := s.storage.Query(query, playlistId, userId)
+		LEFT JOIN audio_features af ON af.song_id = s.id
+		WHERE p.id = ? AND p.user_id = ?`
+	args := []interface{}{playlistId, userId}
+
+	if filter != nil && filter.AddedAfter != nil {
+		query += " AND ps.added_at >= ?"
+		args = append(args, *filter.AddedAfter)
+	}
+	if filter != nil && filter.AddedBefore != nil {
+		query += " AND ps.added_at < ?"
+		args = append(args, *filter.AddedBefore)
+	}
+	if filter != nil && filter.ArtistId != "" {
+		query += " AND EXISTS (SELECT 1 FROM song_artists sa WHERE sa.song_id = s.id AND sa.artist_id = ?)"
+		args = append(args, filter.ArtistId)
+	}
+	if filter != nil {
+		for _, bound := range filter.Features {
+			if bound.Min != nil {
+				query += " AND " + featureColumns[bound.Feature] + " >= ?"
+				args = append(args, *bound.Min)
+			}
+			if bound.Max != nil {
+				query += " AND " + featureColumns[bound.Feature] + " <= ?"
+				args = append(args, *bound.Max)
+			}
+		}
+		if filter.Key != nil {
+			query += " AND af.musical_key = ?"
+			args = append(args, *filter.Key)
+		}
+		if filter.Mode != nil {
+			query += " AND af.mode = ?"
+			args = append(args, *filter.Mode)
+		}
+	}
+	query += " ORDER BY " + entryOrderBy(filter)
+
+	rows, err := s.storage.Query(query, args...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			s.log.Error("REPOSITORY: no songs in playlist")
@@ -50,83 +92,174 @@ func (s *SpotifyRepository) GetAllSongsFromPlaylist(userId, playlistId int) ([]*
 	defer rows.Close()
 
 	for rows.Next() {
-		song, err := scanRowsIntoSong(rows)
+		entry, err := scanRowsIntoEntry(rows)
 		if err != nil {
 			return nil, err
 		}
-		songs = append(songs, song)
+		entries = append(entries, entry)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	rows.Close()
+
+	if err := loadEntryArtists(s.storage, entries); err != nil {
+		s.log.Error("REPOSITORY: unsuccessful get track artists:", err)
+		return nil, err
+	}
+
+	if err := loadEntryFeatures(s.storage, entries); err != nil {
+		s.log.Error("REPOSITORY: unsuccessful get audio features:", err)
+		return nil, err
+	}
 
-	s.log.Info("REPOSITORY: get list of tracks from playlist:", len(songs))
-	return songs, nil
+	s.log.Info("REPOSITORY: get list of tracks from playlist:", len(entries))
+	return entries, nil
 }
 
-func (s *SpotifyRepository) CreateSong(userId, playlistId int, song *models.Song) (string, error) {
-	var playlistOwner int
-	query := `SELECT user_id FROM playlists WHERE id = ?`
-	err := s.storage.QueryRow(query, playlistId).Scan(&playlistOwner)
+func (s *SpotifyRepository) CreateSong(change *models.PlaylistChange, song *models.Song) (string, error) {
+	report, err := s.CreateSongs(change, []models.Song{*song})
 	if err != nil {
-		s.log.Error("REPOSITORY: get playlist owner:", err)
-		if errors.Is(err, sql.ErrNoRows) {
-			s.log.Error("REPOSITORY: playlist not found:", err)
-			return "", playlistNotFound
-		}
 		return "", err
 	}
 
-	if playlistOwner != userId {
-		s.log.Error("REPOSITORY: permitting denied:", err)
-		return "", permissionDenied
+	if len(report.Rejected) > 0 {
+		return "", ErrDuplicateSong
 	}
+	return song.ID, nil
+}
 
-	_, err = s.storage.Exec(`
-		INSERT INTO songs (id, title, artist, album, album_cover, duration, release_date, popularity, preview_url, external_url)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON DUPLICATE KEY UPDATE id=id
-	`, song.ID, song.Title, song.Artist, song.Album, song.AlbumCover, song.Duration,
-		song.ReleaseDate, song.Popularity, song.PreviewURL, song.ExternalURL)
+// CreateSongs appends the songs in order within one transaction, so a failure leaves
+// the playlist as it was. Songs the duplicate policy rejects are listed in the report.
+func (s *SpotifyRepository) CreateSongs(change *models.PlaylistChange, songs []models.Song) (*models.AddTracksReport, error) {
+	userId, playlistId := change.UserId, change.PlaylistId
+	tx, err := s.storage.Begin()
+	if err != nil {
+		s.log.Error("REPOSITORY: begin create tracks:", err)
+		return nil, err
+	}
+	defer tx.Rollback()
 
+	// The playlist row stays locked until the commit, so concurrent inserts into the
+	// same playlist can't both pass the duplicate check or take the same position.
+	version, err := claimPlaylistVersion(tx, change)
 	if err != nil {
-		s.log.Error("REPOSITORY: track not created:", err)
-		return "", err
+		s.log.Error("REPOSITORY: claim playlist version:", err)
+		return nil, err
 	}
 
-	_, err = s.storage.Exec(`
-		INSERT INTO playlist_songs (playlist_id, song_id) 
-		VALUES (?, ?)
-	`, playlistId, song.ID)
+	var duplicatePolicy, kind string
+	query := `SELECT duplicate_policy, kind FROM playlists WHERE id = ?`
+	if err := tx.QueryRow(query, playlistId).Scan(&duplicatePolicy, &kind); err != nil {
+		s.log.Error("REPOSITORY: get playlist duplicate policy:", err)
+		return nil, err
+	}
+
+	if kind == models.PlaylistKindSmart {
+		s.log.Error("REPOSITORY: track not added to smart playlist:", playlistId)
+		return nil, smartPlaylistReadOnly
+	}
+
+	var position int
+	query = `SELECT COALESCE(MAX(position), 0) FROM playlist_songs WHERE playlist_id = ?`
+	if err := tx.QueryRow(query, playlistId).Scan(&position); err != nil {
+		s.log.Error("REPOSITORY: get last track position:", err)
+		return nil, err
+	}
+
+	inserted := 0
+	report := &models.AddTracksReport{Songs: make([]models.Song, 0, len(songs))}
+	for index := range songs {
+		song := &songs[index]
+
+		if duplicatePolicy != models.DuplicatePolicyAllow {
+			var exists bool
+			query = `SELECT EXISTS(SELECT 1 FROM playlist_songs WHERE playlist_id = ? AND song_id = ?)`
+			err = tx.QueryRow(query, playlistId, song.ID).Scan(&exists)
+			if err != nil {
+				s.log.Error("REPOSITORY: check duplicate track:", err)
+				return nil, err
+			}
+
+			if exists && duplicatePolicy == models.DuplicatePolicyReject {
+				s.log.Error("REPOSITORY: duplicate track rejected:", song.ID)
+				report.Rejected = append(report.Rejected, song.ID)
+				continue
+			}
+
+			if exists {
+				s.log.Info("REPOSITORY: duplicate track skipped:", song.ID)
+				report.Songs = append(report.Songs, *song)
+				continue
+			}
+		}
+
+		err = insertSong(tx, song, false)
+		if err != nil {
+			s.log.Error("REPOSITORY: track not created:", err)
+			return nil, err
+		}
+
+		position++
+		_, err = tx.Exec(`
+			INSERT INTO playlist_songs (playlist_id, song_id, position, added_by)
+			VALUES (?, ?, ?, ?)
+		`, playlistId, song.ID, position, userId)
 
+		if err != nil {
+			s.log.Error("REPOSITORY: track not added to playlist_songs:", err)
+			return nil, err
+		}
+		inserted++
+		report.Songs = append(report.Songs, *song)
+	}
+
+	// Nothing was written, rolling back keeps the version as it was.
+	if inserted == 0 {
+		s.log.Info("REPOSITORY: no tracks created:", len(report.Songs), len(report.Rejected))
+		return report, nil
+	}
+
+	if err := checkTrackQuota(tx, playlistId, change.Limits); err != nil {
+		s.log.Error("REPOSITORY: track quota:", err)
+		return nil, err
+	}
+
+	snapshotId, err := createSnapshot(tx, userId, playlistId, models.ActionAdd)
 	if err != nil {
-		s.log.Error("REPOSITORY: track not added to playlist_songs:", err)
-		return "", err
+		s.log.Error("REPOSITORY: snapshot not created:", err)
+		return nil, err
 	}
 
-	s.log.Info("REPOSITORY: track created successfully:", song.ID)
-	return song.ID, nil
+	if err := tx.Commit(); err != nil {
+		s.log.Error("REPOSITORY: commit create tracks:", err)
+		return nil, err
+	}
+	change.Version = version
+	change.SnapshotId = snapshotId
+	report.SnapshotId = snapshotId
+
+	s.log.Info("REPOSITORY: tracks created successfully:", len(report.Songs), len(report.Rejected))
+	return report, nil
 }
 
-func (s *SpotifyRepository) DeleteSongFromPlaylist(userId, playlistId int, songId string) error {
-	var playlistOwner int
-	query := `SELECT user_id FROM playlists WHERE id = ?`
-	err := s.storage.QueryRow(query, playlistId).Scan(&playlistOwner)
+func (s *SpotifyRepository) DeleteSongFromPlaylist(change *models.PlaylistChange, songId string) error {
+	userId, playlistId := change.UserId, change.PlaylistId
+	tx, err := s.storage.Begin()
 	if err != nil {
-		s.log.Error("REPOSITORY: get playlist owner:", err)
-		if errors.Is(err, sql.ErrNoRows) {
-			return playlistNotFound
-		}
+		s.log.Error("REPOSITORY: begin remove track:", err)
 		return err
 	}
+	defer tx.Rollback()
 
-	if playlistOwner != userId {
-		s.log.Error("REPOSITORY: permitting denied:", err)
-		return permissionDenied
+	version, err := claimPlaylistVersion(tx, change)
+	if err != nil {
+		s.log.Error("REPOSITORY: claim playlist version:", err)
+		return err
 	}
 
-	_, err = s.storage.Exec(`
+	result, err := tx.Exec(`
 		DELETE FROM playlist_songs 
 		WHERE playlist_id = ? AND song_id = ?
 	`, playlistId, songId)
@@ -135,13 +268,212 @@ func (s *SpotifyRepository) DeleteSongFromPlaylist(userId, playlistId int, songI
 		return err
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		s.log.Error("REPOSITORY: track not removed from playlist_songs:", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		s.log.Error("REPOSITORY: track not in playlist:", songId)
+		return ErrSongNotInPlaylist
+	}
+
+	if err := removeOrphanSongTags(tx, userId); err != nil {
+		s.log.Error("REPOSITORY: orphan track tags not removed:", err)
+		return err
+	}
+
+	snapshotId, err := createSnapshot(tx, userId, playlistId, models.ActionRemove)
+	if err != nil {
+		s.log.Error("REPOSITORY: snapshot not created:", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log.Error("REPOSITORY: commit remove track:", err)
+		return err
+	}
+	change.Version = version
+	change.SnapshotId = snapshotId
+
 	s.log.Info("REPOSITORY: track removed successfully:", songId)
 	return nil
 }
 
-func scanRowsIntoSong(rows *sql.Rows) (*models.Song, error) {
+// GetCachedTrack returns the stored copy of a track and when it was last fetched
+// from Spotify. A track that was never stored is no error, song is nil then.
+func (s *SpotifyRepository) GetCachedTrack(trackId string) (*models.Song, time.Time, error) {
 	var song models.Song
-	err := rows.Scan(
+	var albumId sql.NullString
+	var fetchedAt time.Time
+	err := s.storage.QueryRow(`
+		SELECT `+songColumns+`, s.fetched_at
+		FROM songs s
+		WHERE s.id = ?
+	`, trackId).Scan(append(songDest(&song, &albumId), &fetchedAt)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, time.Time{}, nil
+		}
+		s.log.Error("REPOSITORY: cached track not loaded:", err)
+		return nil, time.Time{}, err
+	}
+	song.AlbumId = albumId.String
+
+	if err := loadSongArtists(s.storage, &song); err != nil {
+		s.log.Error("REPOSITORY: cached track artists not loaded:", err)
+		return nil, time.Time{}, err
+	}
+	return &song, fetchedAt, nil
+}
+
+// SaveCachedTrack stores the track as just fetched from Spotify, replacing the
+// metadata and artists of an existing copy.
+func (s *SpotifyRepository) SaveCachedTrack(song *models.Song) error {
+	tx, err := s.storage.Begin()
+	if err != nil {
+		s.log.Error("REPOSITORY: begin save cached track:", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertSong(tx, song, true); err != nil {
+		s.log.Error("REPOSITORY: cached track not saved:", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log.Error("REPOSITORY: commit save cached track:", err)
+		return err
+	}
+	return nil
+}
+
+// GetSongsWithoutArtists returns up to limit ids of songs stored before artists
+// were tracked. Unavailable songs are left out, the catalog no longer serves them.
+func (s *SpotifyRepository) GetSongsWithoutArtists(limit int) ([]string, error) {
+	rows, err := s.storage.Query(`
+		SELECT s.id
+		FROM songs s
+		WHERE NOT s.artists_resolved AND NOT s.unavailable
+		ORDER BY s.id
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful get songs without artists:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// HasSongsWithoutArtists tells whether the playlist holds songs GetSongsWithoutArtists
+// still returns.
+func (s *SpotifyRepository) HasSongsWithoutArtists(playlistId int) (bool, error) {
+	var pending bool
+	err := s.storage.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM playlist_songs ps
+			JOIN songs s ON ps.song_id = s.id
+			WHERE ps.playlist_id = ? AND NOT s.artists_resolved AND NOT s.unavailable
+		)
+	`, playlistId).Scan(&pending)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful check songs without artists:", err)
+		return false, err
+	}
+	return pending, nil
+}
+
+// GetStaleSongs returns up to limit ids of songs last fetched longer than olderThan
+// ago by the database clock, the longest unrefreshed first.
+func (s *SpotifyRepository) GetStaleSongs(olderThan time.Duration, limit int) ([]string, error) {
+	rows, err := s.storage.Query(`
+		SELECT id
+		FROM songs
+		WHERE fetched_at < NOW() - INTERVAL ? SECOND
+		ORDER BY fetched_at, id
+		LIMIT ?
+	`, int64(olderThan/time.Second), limit)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful get stale songs:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// RecordPopularity adds the current popularity of the songs to their history.
+func (s *SpotifyRepository) RecordPopularity(songs []models.Song) error {
+	if len(songs) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(songs)*2)
+	for _, song := range songs {
+		args = append(args, song.ID, song.Popularity)
+	}
+
+	_, err := s.storage.Exec(`INSERT INTO song_popularity (song_id, popularity) VALUES `+
+		valuesPlaceholders(len(songs), 2)+` ON DUPLICATE KEY UPDATE popularity = VALUES(popularity)`, args...)
+	if err != nil {
+		s.log.Error("REPOSITORY: popularity not recorded:", err)
+		return err
+	}
+	return nil
+}
+
+// MarkSongsUnavailable flags songs Spotify no longer serves. They count as fetched,
+// so the refresher only checks them again once they are stale.
+func (s *SpotifyRepository) MarkSongsUnavailable(songIds []string) error {
+	if len(songIds) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(songIds))
+	for _, songId := range songIds {
+		args = append(args, songId)
+	}
+
+	_, err := s.storage.Exec(`UPDATE songs SET unavailable = TRUE, fetched_at = CURRENT_TIMESTAMP WHERE id IN (`+
+		tagPlaceholders(len(songIds))+`)`, args...)
+	if err != nil {
+		s.log.Error("REPOSITORY: songs not marked unavailable:", err)
+		return err
+	}
+
+	s.log.Info("REPOSITORY: songs marked unavailable:", len(songIds))
+	return nil
+}
+
+// songColumns are read by scanRowsIntoSong, in this order.
+const songColumns = `s.id, s.title, s.artist, s.album, s.album_cover, s.duration, s.release_date, s.popularity,
+		       s.preview_url, s.external_url, s.album_id, s.explicit, s.track_number, s.disc_number, s.release_date_precision`
+
+// songDest lists the scan destinations of songColumns. The album id is NULL for
+// songs stored before albums were tracked, so it goes through albumId.
+func songDest(song *models.Song, albumId *sql.NullString) []any {
+	return []any{
 		&song.ID,
 		&song.Title,
 		&song.Artist,
@@ -152,9 +484,163 @@ func scanRowsIntoSong(rows *sql.Rows) (*models.Song, error) {
 		&song.Popularity,
 		&song.PreviewURL,
 		&song.ExternalURL,
-	)
-	if err != nil {
+		albumId,
+		&song.Explicit,
+		&song.TrackNumber,
+		&song.DiscNumber,
+		&song.ReleaseDatePrecision,
+	}
+}
+
+func scanRowsIntoSong(rows *sql.Rows) (*models.Song, error) {
+	var song models.Song
+	var albumId sql.NullString
+	if err := rows.Scan(songDest(&song, &albumId)...); err != nil {
 		return nil, err
 	}
+	song.AlbumId = albumId.String
 	return &song, nil
 }
+
+// insertSong stores the song together with its album and artists. Without refresh
+// stored songs, albums and artists are left as they are, the first copy wins; with
+// refresh their metadata and the song's artists are replaced.
+func insertSong(e execer, song *models.Song, refresh bool) error {
+	if song.AlbumId != "" {
+		onDuplicate := `id=id`
+		if refresh {
+			onDuplicate = `name = VALUES(name), album_cover = VALUES(album_cover),
+				release_date = VALUES(release_date), release_date_precision = VALUES(release_date_precision)`
+		}
+		_, err := e.Exec(`
+			INSERT INTO albums (id, name, album_cover, release_date, release_date_precision)
+			VALUES (?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE `+onDuplicate,
+			song.AlbumId, song.Album, song.AlbumCover, song.ReleaseDate, song.ReleaseDatePrecision)
+		if err != nil {
+			return err
+		}
+	}
+
+	onDuplicate := `id=id`
+	if refresh {
+		onDuplicate = `title = VALUES(title), artist = VALUES(artist), album = VALUES(album),
+			album_id = VALUES(album_id), album_cover = VALUES(album_cover), duration = VALUES(duration),
+			release_date = VALUES(release_date), release_date_precision = VALUES(release_date_precision),
+			popularity = VALUES(popularity), preview_url = VALUES(preview_url), external_url = VALUES(external_url),
+			explicit = VALUES(explicit), track_number = VALUES(track_number), disc_number = VALUES(disc_number),
+			artists_resolved = TRUE, unavailable = FALSE, fetched_at = CURRENT_TIMESTAMP`
+	}
+	result, err := e.Exec(`
+		INSERT INTO songs (id, title, artist, album, album_id, album_cover, duration, release_date, release_date_precision,
+			popularity, preview_url, external_url, explicit, track_number, disc_number, artists_resolved)
+		VALUES (?, ?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, TRUE)
+		ON DUPLICATE KEY UPDATE `+onDuplicate,
+		song.ID, song.Title, song.Artist, song.Album, song.AlbumId, song.AlbumCover, song.Duration, song.ReleaseDate,
+		song.ReleaseDatePrecision, song.Popularity, song.PreviewURL, song.ExternalURL, song.Explicit, song.TrackNumber,
+		song.DiscNumber)
+	if err != nil {
+		return err
+	}
+
+	if len(song.Artists) == 0 {
+		return nil
+	}
+	if !refresh {
+		// Nothing changed for a song that was already stored, its artists are either
+		// linked or left to the backfill.
+		if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+			return err
+		}
+	} else if _, err := e.Exec(`DELETE FROM song_artists WHERE song_id = ?`, song.ID); err != nil {
+		return err
+	}
+
+	return insertSongArtists(e, song, refresh)
+}
+
+func insertSongArtists(e execer, song *models.Song, refresh bool) error {
+	seen := make(map[string]bool, len(song.Artists))
+	artistArgs := make([]any, 0, 2*len(song.Artists))
+	linkArgs := make([]any, 0, 3*len(song.Artists))
+	for _, artist := range song.Artists {
+		if seen[artist.ID] {
+			continue
+		}
+		seen[artist.ID] = true
+		artistArgs = append(artistArgs, artist.ID, artist.Name)
+		linkArgs = append(linkArgs, song.ID, artist.ID, len(seen))
+	}
+
+	onDuplicate := `id=id`
+	if refresh {
+		onDuplicate = `name = VALUES(name)`
+	}
+	_, err := e.Exec(`INSERT INTO artists (id, name) VALUES `+valuesPlaceholders(len(seen), 2)+`
+		ON DUPLICATE KEY UPDATE `+onDuplicate, artistArgs...)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.Exec(`INSERT INTO song_artists (song_id, artist_id, position) VALUES `+valuesPlaceholders(len(seen), 3), linkArgs...)
+	return err
+}
+
+// valuesPlaceholders builds the VALUES list for rows of columns placeholders each.
+func valuesPlaceholders(rows, columns int) string {
+	row := "(" + tagPlaceholders(columns) + ")"
+	return strings.TrimSuffix(strings.Repeat(row+", ", rows), ", ")
+}
+
+func loadEntryArtists(q rowsQuerier, entries []*models.PlaylistEntry) error {
+	songs := make([]*models.Song, 0, len(entries))
+	for _, entry := range entries {
+		songs = append(songs, &entry.Song)
+	}
+	return loadSongArtists(q, songs...)
+}
+
+type rowsQuerier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// loadSongArtists fills in the artists of the songs in one query. Songs stored
+// before artists were tracked keep an empty list.
+func loadSongArtists(q rowsQuerier, songs ...*models.Song) error {
+	if len(songs) == 0 {
+		return nil
+	}
+
+	bySong := make(map[string][]*models.Song, len(songs))
+	args := make([]any, 0, len(songs))
+	for _, song := range songs {
+		if _, ok := bySong[song.ID]; !ok {
+			args = append(args, song.ID)
+		}
+		bySong[song.ID] = append(bySong[song.ID], song)
+	}
+
+	rows, err := q.Query(`
+		SELECT sa.song_id, a.id, a.name
+		FROM song_artists sa
+		JOIN artists a ON sa.artist_id = a.id
+		WHERE sa.song_id IN (`+tagPlaceholders(len(args))+`)
+		ORDER BY sa.song_id, sa.position
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var songId string
+		var artist models.SongArtist
+		if err := rows.Scan(&songId, &artist.ID, &artist.Name); err != nil {
+			return err
+		}
+		for _, song := range bySong[songId] {
+			song.Artists = append(song.Artists, artist)
+		}
+	}
+	return rows.Err()
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -9,8 +10,16 @@ import (
 	"music-service/internal/models"
 	"music-service/pkg/logging"
 	"testing"
+	"time"
 )
 
+// songArgs are the arguments insertSong stores a song with.
+func songArgs(song *models.Song) []driver.Value {
+	return []driver.Value{song.ID, song.Title, song.Artist, song.Album, song.AlbumId, song.AlbumCover, song.Duration,
+		song.ReleaseDate, song.ReleaseDatePrecision, song.Popularity, song.PreviewURL, song.ExternalURL, song.Explicit,
+		song.TrackNumber, song.DiscNumber}
+}
+
 func TestSpotifyRepository_CreateSong(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -34,6 +43,11 @@ func TestSpotifyRepository_CreateSong(t *testing.T) {
 		PreviewURL:  "preview_url",
 		ExternalURL: "external_url",
 	}
+	credited := &models.Song{
+		ID: "song456", Title: "Test Song", Artist: "Test Artist", Album: "Test Album", AlbumId: "album1", AlbumCover: "cover_url",
+		ReleaseDate: "2024-09-26", ReleaseDatePrecision: "day",
+		Artists: []models.SongArtist{{ID: "artist1", Name: "Test Artist"}, {ID: "artist2", Name: "Featured Artist"}},
+	}
 
 	testCases := []struct {
 		name          string
@@ -50,28 +64,74 @@ func TestSpotifyRepository_CreateSong(t *testing.T) {
 			playlistId: 1,
 			song:       song,
 			mockSetup: func() {
-				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \?$`).
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 1)
+				mock.ExpectQuery(`^SELECT duplicate_policy, kind FROM playlists WHERE id = \?$`).
 					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+					WillReturnRows(sqlmock.NewRows([]string{"duplicate_policy", "kind"}).AddRow("allow", "manual"))
+
+				mock.ExpectQuery(`^SELECT COALESCE\(MAX\(position\), 0\) FROM playlist_songs WHERE playlist_id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(0))
 
 				mock.ExpectExec(`^INSERT INTO songs .* ON DUPLICATE KEY UPDATE id=id$`).
-					WithArgs(song.ID, song.Title, song.Artist, song.Album, song.AlbumCover, song.Duration, song.ReleaseDate, song.Popularity, song.PreviewURL, song.ExternalURL).
+					WithArgs(songArgs(song)...).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectExec(`^INSERT INTO playlist_songs .*`).
-					WithArgs(1, song.ID).
+					WithArgs(1, song.ID, 1, 1).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectSnapshot(mock, 1, 1, models.ActionAdd, 5)
+				mock.ExpectCommit()
 			},
 			expectedError: nil,
 			expectedID:    song.ID,
 		},
+		{
+			name:       "song with album and credited artists",
+			userId:     1,
+			playlistId: 1,
+			song:       credited,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 1)
+				mock.ExpectQuery(`^SELECT duplicate_policy, kind FROM playlists WHERE id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"duplicate_policy", "kind"}).AddRow("allow", "manual"))
+
+				mock.ExpectQuery(`^SELECT COALESCE\(MAX\(position\), 0\) FROM playlist_songs WHERE playlist_id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(0))
+
+				mock.ExpectExec(`^INSERT INTO albums .* ON DUPLICATE KEY UPDATE id=id$`).
+					WithArgs("album1", "Test Album", "cover_url", "2024-09-26", "day").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`^INSERT INTO songs .* ON DUPLICATE KEY UPDATE id=id$`).
+					WithArgs(songArgs(credited)...).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`^INSERT INTO artists \(id, name\) VALUES \(\?, \?\), \(\?, \?\) ON DUPLICATE KEY UPDATE id=id$`).
+					WithArgs("artist1", "Test Artist", "artist2", "Featured Artist").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`^INSERT INTO song_artists \(song_id, artist_id, position\) VALUES`).
+					WithArgs("song456", "artist1", 1, "song456", "artist2", 2).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+
+				mock.ExpectExec(`^INSERT INTO playlist_songs .*`).
+					WithArgs(1, credited.ID, 1, 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectSnapshot(mock, 1, 1, models.ActionAdd, 5)
+				mock.ExpectCommit()
+			},
+			expectedID: credited.ID,
+		},
 		{
 			name:       "playlist not found",
 			userId:     1,
 			playlistId: 2,
 			song:       song,
 			mockSetup: func() {
-				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \?$`).
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT user_id, version FROM playlists WHERE id = \? AND deleted_at IS NULL FOR UPDATE$`).
 					WithArgs(2).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -84,9 +144,10 @@ func TestSpotifyRepository_CreateSong(t *testing.T) {
 			playlistId: 3,
 			song:       song,
 			mockSetup: func() {
-				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \?$`).
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT user_id, version FROM playlists WHERE id = \? AND deleted_at IS NULL FOR UPDATE$`).
 					WithArgs(3).
-					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(2))
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "version"}).AddRow(2, 1))
 			},
 			expectedError: permissionDenied,
 			expectedID:    "",
@@ -97,12 +158,18 @@ func TestSpotifyRepository_CreateSong(t *testing.T) {
 			playlistId: 1,
 			song:       song,
 			mockSetup: func() {
-				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \?$`).
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 1)
+				mock.ExpectQuery(`^SELECT duplicate_policy, kind FROM playlists WHERE id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"duplicate_policy", "kind"}).AddRow("allow", "manual"))
+
+				mock.ExpectQuery(`^SELECT COALESCE\(MAX\(position\), 0\) FROM playlist_songs WHERE playlist_id = \?$`).
 					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(0))
 
 				mock.ExpectExec(`^INSERT INTO songs .* ON DUPLICATE KEY UPDATE id=id$`).
-					WithArgs(song.ID, song.Title, song.Artist, song.Album, song.AlbumCover, song.Duration, song.ReleaseDate, song.Popularity, song.PreviewURL, song.ExternalURL).
+					WithArgs(songArgs(song)...).
 					WillReturnError(errors.New("failed to insert song"))
 			},
 			expectedError: errors.New("failed to insert song"),
@@ -114,28 +181,84 @@ func TestSpotifyRepository_CreateSong(t *testing.T) {
 			playlistId: 1,
 			song:       song,
 			mockSetup: func() {
-				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \?$`).
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 1)
+				mock.ExpectQuery(`^SELECT duplicate_policy, kind FROM playlists WHERE id = \?$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"duplicate_policy", "kind"}).AddRow("allow", "manual"))
+
+				mock.ExpectQuery(`^SELECT COALESCE\(MAX\(position\), 0\) FROM playlist_songs WHERE playlist_id = \?$`).
 					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(7))
 
 				mock.ExpectExec(`^INSERT INTO songs .* ON DUPLICATE KEY UPDATE id=id$`).
-					WithArgs(song.ID, song.Title, song.Artist, song.Album, song.AlbumCover, song.Duration, song.ReleaseDate, song.Popularity, song.PreviewURL, song.ExternalURL).
+					WithArgs(songArgs(song)...).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectExec(`^INSERT INTO playlist_songs .*`).
-					WithArgs(1, song.ID).
+					WithArgs(1, song.ID, 8, 1).
 					WillReturnError(errors.New("failed to add song to playlist"))
+				mock.ExpectRollback()
 			},
 			expectedError: errors.New("failed to add song to playlist"),
 			expectedID:    "",
 		},
+		{
+			name:       "duplicate rejected",
+			userId:     1,
+			playlistId: 4,
+			song:       song,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 4, 1, 1)
+				mock.ExpectQuery(`^SELECT duplicate_policy, kind FROM playlists WHERE id = \?$`).
+					WithArgs(4).
+					WillReturnRows(sqlmock.NewRows([]string{"duplicate_policy", "kind"}).AddRow("reject", "manual"))
+
+				mock.ExpectQuery(`^SELECT COALESCE\(MAX\(position\), 0\) FROM playlist_songs WHERE playlist_id = \?$`).
+					WithArgs(4).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(3))
+
+				mock.ExpectQuery(`^SELECT EXISTS\(SELECT 1 FROM playlist_songs WHERE playlist_id = \? AND song_id = \?\)$`).
+					WithArgs(4, song.ID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedError: ErrDuplicateSong,
+			expectedID:    "",
+		},
+		{
+			name:       "duplicate skipped",
+			userId:     1,
+			playlistId: 5,
+			song:       song,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 5, 1, 1)
+				mock.ExpectQuery(`^SELECT duplicate_policy, kind FROM playlists WHERE id = \?$`).
+					WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"duplicate_policy", "kind"}).AddRow("skip", "manual"))
+
+				mock.ExpectQuery(`^SELECT COALESCE\(MAX\(position\), 0\) FROM playlist_songs WHERE playlist_id = \?$`).
+					WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(3))
+
+				mock.ExpectQuery(`^SELECT EXISTS\(SELECT 1 FROM playlist_songs WHERE playlist_id = \? AND song_id = \?\)$`).
+					WithArgs(5, song.ID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedError: nil,
+			expectedID:    song.ID,
+		},
 	}
 
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			id, err := storage.CreateSong(tt.userId, tt.playlistId, tt.song)
+			change := &models.PlaylistChange{UserId: tt.userId, PlaylistId: tt.playlistId}
+			id, err := storage.CreateSong(change, tt.song)
 
 			if tt.expectedError != nil {
 				require.Error(t, err)
@@ -152,6 +275,78 @@ func TestSpotifyRepository_CreateSong(t *testing.T) {
 	}
 }
 
+func TestSpotifyRepository_CreateSongs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %s", err)
+	}
+	defer db.Close()
+
+	storage := NewSpotifyRepository(db, logging.NewLogger())
+
+	first := models.Song{ID: "song1", Title: "First"}
+	second := models.Song{ID: "song2", Title: "Second"}
+	third := models.Song{ID: "song3", Title: "Third"}
+
+	expectLock := func(policy string) {
+		mock.ExpectBegin()
+		expectClaimVersion(mock, 1, 1, 1)
+		mock.ExpectQuery(`^SELECT duplicate_policy, kind FROM playlists WHERE id = \?$`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"duplicate_policy", "kind"}).AddRow(policy, "manual"))
+		mock.ExpectQuery(`^SELECT COALESCE\(MAX\(position\), 0\) FROM playlist_songs WHERE playlist_id = \?$`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(4))
+	}
+	expectExists := func(song models.Song, exists bool) {
+		mock.ExpectQuery(`^SELECT EXISTS\(SELECT 1 FROM playlist_songs WHERE playlist_id = \? AND song_id = \?\)$`).
+			WithArgs(1, song.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(exists))
+	}
+	expectInsert := func(song models.Song, position int) {
+		mock.ExpectExec(`^INSERT INTO songs .* ON DUPLICATE KEY UPDATE id=id$`).
+			WithArgs(songArgs(&song)...).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`^INSERT INTO playlist_songs .*`).
+			WithArgs(1, song.ID, position, 1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	t.Run("appends in order and lists rejected duplicates", func(t *testing.T) {
+		expectLock(models.DuplicatePolicyReject)
+		expectExists(first, false)
+		expectInsert(first, 5)
+		expectExists(second, true)
+		expectExists(third, false)
+		expectInsert(third, 6)
+		expectSnapshot(mock, 1, 1, models.ActionAdd, 9)
+		mock.ExpectCommit()
+
+		change := &models.PlaylistChange{UserId: 1, PlaylistId: 1}
+		report, err := storage.CreateSongs(change, []models.Song{first, second, third})
+		require.NoError(t, err)
+		assert.Equal(t, []models.Song{first, third}, report.Songs)
+		assert.Equal(t, []string{"song2"}, report.Rejected)
+		assert.Equal(t, int64(9), report.SnapshotId)
+		assert.Equal(t, int64(9), change.SnapshotId)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a failing insert rolls back the whole batch", func(t *testing.T) {
+		expectLock(models.DuplicatePolicyAllow)
+		expectInsert(first, 5)
+		mock.ExpectExec(`^INSERT INTO songs .* ON DUPLICATE KEY UPDATE id=id$`).
+			WithArgs(songArgs(&second)...).
+			WillReturnError(errors.New("failed to insert song"))
+		mock.ExpectRollback()
+
+		report, err := storage.CreateSongs(&models.PlaylistChange{UserId: 1, PlaylistId: 1}, []models.Song{first, second, third})
+		assert.EqualError(t, err, "failed to insert song")
+		assert.Nil(t, report)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestSpotifyRepository_GetAllSongsFromPlaylist(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -160,7 +355,7 @@ func TestSpotifyRepository_GetAllSongsFromPlaylist(t *testing.T) {
 	logger := logging.NewLogger()
 	storage := NewSpotifyRepository(db, logger)
 
-	song := &models.Song{
+	song := models.Song{
 		ID:          "song123",
 		Title:       "Test Song",
 		Artist:      "Test Artist",
@@ -172,49 +367,132 @@ func TestSpotifyRepository_GetAllSongsFromPlaylist(t *testing.T) {
 		PreviewURL:  "preview_url",
 		ExternalURL: "external_url",
 	}
+	addedAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	addedBy := 1
+	entry := &models.PlaylistEntry{EntryId: 10, Position: 1, AddedAt: &addedAt, AddedBy: &addedBy, Note: "opener", Song: song}
+	credited := &models.PlaylistEntry{EntryId: 10, Position: 1, AddedAt: &addedAt, AddedBy: &addedBy, Note: "opener", Song: song}
+	credited.Song.Artists = []models.SongArtist{{ID: "artist0", Name: "Test Artist"}, {ID: "artist1", Name: "Featured Artist"}}
+	credited.Song.AlbumId = "album1"
+	credited.Song.Explicit = true
+	credited.Song.TrackNumber = 2
+	credited.Song.DiscNumber = 1
+	credited.Song.ReleaseDatePrecision = "day"
+	analysed := &models.PlaylistEntry{EntryId: 10, Position: 1, AddedAt: &addedAt, AddedBy: &addedBy, Note: "opener", Song: song}
+	analysed.Song.AudioFeatures = &models.AudioFeatures{SongId: song.ID, Tempo: 128, Energy: 0.8, Danceability: 0.7, Valence: 0.6, Key: 9, Mode: 0, Loudness: -5.5}
+	minTempo, maxTempo, minEnergy := 120.0, 140.0, 0.7
+	key, mode := 9, 0
 
 	testCases := []struct {
-		name          string
-		userId        int
-		playlistId    int
-		mockSetup     func()
-		expectedError error
-		expectedSongs []*models.Song
+		name            string
+		userId          int
+		playlistId      int
+		filter          *models.EntryFilter
+		mockSetup       func()
+		expectedError   error
+		expectedEntries []*models.PlaylistEntry
 	}{
 		{
 			name:       "successful get all songs from playlist",
 			userId:     1,
 			playlistId: 1,
 			mockSetup: func() {
-				mock.ExpectQuery(`^SELECT s\.id, s\.title, s\.artist, s\.album, s\.album_cover, s\.duration, 
-		                   s\.release_date, s\.popularity, s\.preview_url, s\.external_url
+				mock.ExpectQuery(`^SELECT ps\.id, ps\.position, ps\.added_at, ps\.added_by, ps\.note, s\.id, .*
 		                   FROM playlist_songs ps
 		                   JOIN songs s ON ps.song_id = s.id
 		                   JOIN playlists p ON ps.playlist_id = p.id
-		                   WHERE p.id = \? AND p.user_id = \?$`).
+		                   LEFT JOIN audio_features af ON af.song_id = s.id
+		                   WHERE p.id = \? AND p.user_id = \? ORDER BY ps.position, ps.id$`).
 					WithArgs(1, 1).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "title", "artist", "album", "album_cover", "duration", "release_date", "popularity", "preview_url", "external_url"}).
-						AddRow(song.ID, song.Title, song.Artist, song.Album, song.AlbumCover, song.Duration, song.ReleaseDate, song.Popularity, song.PreviewURL, song.ExternalURL))
+					WillReturnRows(sqlmock.NewRows(entryRowColumns).
+						AddRow(10, 1, addedAt, 1, "opener", song.ID, song.Title, song.Artist, song.Album, song.AlbumCover, song.Duration, song.ReleaseDate, song.Popularity, song.PreviewURL, song.ExternalURL, nil, false, 0, 0, ""))
+				mock.ExpectQuery(`^SELECT sa\.song_id, a\.id, a\.name FROM song_artists sa`).
+					WithArgs(song.ID).
+					WillReturnRows(sqlmock.NewRows(songArtistColumns))
+				mock.ExpectQuery(`^SELECT song_id, tempo, .* FROM audio_features WHERE song_id IN \(\?\) AND tempo IS NOT NULL$`).
+					WithArgs(song.ID).
+					WillReturnRows(sqlmock.NewRows(featureRowColumns))
 			},
-			expectedError: nil,
-			expectedSongs: []*models.Song{song},
+			expectedError:   nil,
+			expectedEntries: []*models.PlaylistEntry{entry},
+		},
+		{
+			name:       "filtered and sorted by added_at",
+			userId:     1,
+			playlistId: 1,
+			filter:     &models.EntryFilter{AddedAfter: &addedAt, AddedBefore: &addedAt, Sort: models.EntrySortAddedAtDesc},
+			mockSetup: func() {
+				mock.ExpectQuery(`WHERE p.id = \? AND p.user_id = \? AND ps.added_at >= \? AND ps.added_at < \? ORDER BY ps.added_at DESC, ps.id DESC$`).
+					WithArgs(1, 1, addedAt, addedAt).
+					WillReturnRows(sqlmock.NewRows(entryRowColumns).
+						AddRow(10, 1, addedAt, 1, "opener", song.ID, song.Title, song.Artist, song.Album, song.AlbumCover, song.Duration, song.ReleaseDate, song.Popularity, song.PreviewURL, song.ExternalURL, nil, false, 0, 0, ""))
+				mock.ExpectQuery(`^SELECT sa\.song_id, a\.id, a\.name FROM song_artists sa`).
+					WithArgs(song.ID).
+					WillReturnRows(sqlmock.NewRows(songArtistColumns))
+				mock.ExpectQuery(`^SELECT song_id, tempo, .* FROM audio_features WHERE song_id IN \(\?\) AND tempo IS NOT NULL$`).
+					WithArgs(song.ID).
+					WillReturnRows(sqlmock.NewRows(featureRowColumns))
+			},
+			expectedError:   nil,
+			expectedEntries: []*models.PlaylistEntry{entry},
+		},
+		{
+			name:       "filtered by artist id",
+			userId:     1,
+			playlistId: 1,
+			filter:     &models.EntryFilter{ArtistId: "artist1"},
+			mockSetup: func() {
+				mock.ExpectQuery(`WHERE p.id = \? AND p.user_id = \? AND EXISTS \(SELECT 1 FROM song_artists sa WHERE sa.song_id = s.id AND sa.artist_id = \?\) ORDER BY ps.position, ps.id$`).
+					WithArgs(1, 1, "artist1").
+					WillReturnRows(sqlmock.NewRows(entryRowColumns).
+						AddRow(10, 1, addedAt, 1, "opener", song.ID, song.Title, song.Artist, song.Album, song.AlbumCover, song.Duration, song.ReleaseDate, song.Popularity, song.PreviewURL, song.ExternalURL, "album1", true, 2, 1, "day"))
+				mock.ExpectQuery(`^SELECT sa\.song_id, a\.id, a\.name FROM song_artists sa`).
+					WithArgs(song.ID).
+					WillReturnRows(sqlmock.NewRows(songArtistColumns).
+						AddRow(song.ID, "artist0", "Test Artist").
+						AddRow(song.ID, "artist1", "Featured Artist"))
+				mock.ExpectQuery(`^SELECT song_id, tempo, .* FROM audio_features`).
+					WithArgs(song.ID).
+					WillReturnRows(sqlmock.NewRows(featureRowColumns))
+			},
+			expectedError:   nil,
+			expectedEntries: []*models.PlaylistEntry{credited},
+		},
+		{
+			name:       "filtered and sorted by audio features",
+			userId:     1,
+			playlistId: 1,
+			filter: &models.EntryFilter{
+				Features: []models.FeatureRange{{Feature: models.FeatureTempo, Min: &minTempo, Max: &maxTempo}, {Feature: models.FeatureEnergy, Min: &minEnergy}},
+				Key:      &key,
+				Mode:     &mode,
+				Sort:     "-" + models.FeatureEnergy,
+			},
+			mockSetup: func() {
+				mock.ExpectQuery(`WHERE p.id = \? AND p.user_id = \? AND af.tempo >= \? AND af.tempo <= \? AND af.energy >= \? AND af.musical_key = \? AND af.mode = \? ORDER BY af.energy IS NULL, af.energy DESC, ps.position, ps.id$`).
+					WithArgs(1, 1, minTempo, maxTempo, minEnergy, key, mode).
+					WillReturnRows(sqlmock.NewRows(entryRowColumns).
+						AddRow(10, 1, addedAt, 1, "opener", song.ID, song.Title, song.Artist, song.Album, song.AlbumCover, song.Duration, song.ReleaseDate, song.Popularity, song.PreviewURL, song.ExternalURL, nil, false, 0, 0, ""))
+				mock.ExpectQuery(`^SELECT sa\.song_id, a\.id, a\.name FROM song_artists sa`).
+					WithArgs(song.ID).
+					WillReturnRows(sqlmock.NewRows(songArtistColumns))
+				mock.ExpectQuery(`^SELECT song_id, tempo, .* FROM audio_features`).
+					WithArgs(song.ID).
+					WillReturnRows(sqlmock.NewRows(featureRowColumns).AddRow(song.ID, 128.0, 0.8, 0.7, 0.6, 9, 0, -5.5))
+			},
+			expectedError:   nil,
+			expectedEntries: []*models.PlaylistEntry{analysed},
 		},
 		{
 			name:       "error getting all songs from playlist",
 			userId:     1,
 			playlistId: 1,
 			mockSetup: func() {
-				mock.ExpectQuery(`^SELECT s\.id, s\.title, s\.artist, s\.album, s\.album_cover, s\.duration, 
-		                   s\.release_date, s\.popularity, s\.preview_url, s\.external_url
-		                   FROM playlist_songs ps
-		                   JOIN songs s ON ps.song_id = s.id
-		                   JOIN playlists p ON ps.playlist_id = p.id
-		                   WHERE p.id = \? AND p.user_id = \?$`).
+				mock.ExpectQuery(`^SELECT ps\.id, .* FROM playlist_songs ps`).
 					WithArgs(1, 1).
 					WillReturnError(errors.New("failed to get all songs from playlist"))
 			},
-			expectedError: errors.New("failed to get all songs from playlist"),
-			expectedSongs: nil,
+			expectedError:   errors.New("failed to get all songs from playlist"),
+			expectedEntries: nil,
 		},
 	}
 
@@ -222,15 +500,15 @@ func TestSpotifyRepository_GetAllSongsFromPlaylist(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			songs, err := storage.GetAllSongsFromPlaylist(tt.userId, tt.playlistId)
+			entries, err := storage.GetAllSongsFromPlaylist(tt.userId, tt.playlistId, tt.filter)
 
 			if tt.expectedError != nil {
 				require.Error(t, err)
 				assert.Equal(t, tt.expectedError, err)
-				assert.Equal(t, tt.expectedSongs, songs)
+				assert.Equal(t, tt.expectedEntries, entries)
 			} else {
 				require.NoError(t, err)
-				assert.Equal(t, tt.expectedSongs, songs)
+				assert.Equal(t, tt.expectedEntries, entries)
 			}
 
 			err = mock.ExpectationsWereMet()
@@ -261,30 +539,51 @@ func TestSpotifyRepository_DeleteSongFromPlaylist(t *testing.T) {
 			playlistId: 1,
 			songId:     "song123",
 			mockSetup: func() {
-				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \?$`).
-					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
 
 				mock.ExpectExec(`^DELETE FROM playlist_songs WHERE playlist_id = \? AND song_id = \?$`).
 					WithArgs(1, "song123").
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`^DELETE FROM song_tags WHERE user_id = \? AND song_id NOT IN`).
+					WithArgs(1, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				expectSnapshot(mock, 1, 1, models.ActionRemove, 5)
+				mock.ExpectCommit()
 			},
 			expectedError: nil,
 		},
 
+		{
+			name:       "song not in playlist",
+			userId:     1,
+			playlistId: 1,
+			songId:     "song123",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
+
+				mock.ExpectExec(`^DELETE FROM playlist_songs WHERE playlist_id = \? AND song_id = \?$`).
+					WithArgs(1, "song123").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedError: ErrSongNotInPlaylist,
+		},
+
 		{
 			name:       "error deleting song from playlist",
 			userId:     1,
 			playlistId: 1,
 			songId:     "song123",
 			mockSetup: func() {
-				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \?$`).
-					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+				mock.ExpectBegin()
+				expectClaimVersion(mock, 1, 1, 2)
 
 				mock.ExpectExec(`^DELETE FROM playlist_songs WHERE playlist_id = \? AND song_id = \?$`).
 					WithArgs(1, "song123").
 					WillReturnError(errors.New("failed to delete song from playlist"))
+				mock.ExpectRollback()
 			},
 			expectedError: errors.New("failed to delete song from playlist"),
 		},
@@ -295,10 +594,11 @@ func TestSpotifyRepository_DeleteSongFromPlaylist(t *testing.T) {
 			playlistId: 1,
 			songId:     "song123",
 			mockSetup: func() {
-				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \?$`).
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT user_id, version FROM playlists WHERE id = \? AND deleted_at IS NULL FOR UPDATE$`).
 					WithArgs(1).
 					WillReturnError(errors.New("user does not own playlist"))
-
+				mock.ExpectRollback()
 			},
 			expectedError: errors.New("user does not own playlist"),
 		},
@@ -308,7 +608,8 @@ func TestSpotifyRepository_DeleteSongFromPlaylist(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			err := storage.DeleteSongFromPlaylist(tt.userId, tt.playlistId, tt.songId)
+			change := &models.PlaylistChange{UserId: tt.userId, PlaylistId: tt.playlistId}
+			err := storage.DeleteSongFromPlaylist(change, tt.songId)
 
 			if tt.expectedError != nil {
 				require.Error(t, err)
@@ -322,3 +623,199 @@ func TestSpotifyRepository_DeleteSongFromPlaylist(t *testing.T) {
 		})
 	}
 }
+
+func TestSpotifyRepository_GetCachedTrack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	storage := NewSpotifyRepository(db, logging.NewLogger())
+	fetchedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	columns := append(append([]string{}, songRowColumns...), "fetched_at")
+
+	testCases := []struct {
+		name              string
+		mockSetup         func()
+		expectedSong      *models.Song
+		expectedFetchedAt time.Time
+		expectedError     error
+	}{
+		{
+			name: "stored track",
+			mockSetup: func() {
+				mock.ExpectQuery(`^SELECT s\.id, s\.title, s\.artist, .*, s\.fetched_at FROM songs s WHERE s\.id = \?$`).
+					WithArgs("song123").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("song123", "Test Song", "Test Artist", "Test Album", "cover_url", 200, "2024-09-26", 80, "preview_url", "external_url",
+							"album1", false, 4, 1, "day", fetchedAt))
+				mock.ExpectQuery(`^SELECT sa\.song_id, a\.id, a\.name FROM song_artists sa`).
+					WithArgs("song123").
+					WillReturnRows(sqlmock.NewRows(songArtistColumns).AddRow("song123", "artist1", "Test Artist"))
+			},
+			expectedSong: &models.Song{
+				ID: "song123", Title: "Test Song", Artist: "Test Artist", Artists: []models.SongArtist{{ID: "artist1", Name: "Test Artist"}},
+				Album: "Test Album", AlbumId: "album1", AlbumCover: "cover_url", Duration: 200, ReleaseDate: "2024-09-26",
+				ReleaseDatePrecision: "day", Popularity: 80, PreviewURL: "preview_url", ExternalURL: "external_url", TrackNumber: 4, DiscNumber: 1,
+			},
+			expectedFetchedAt: fetchedAt,
+		},
+		{
+			name: "track never stored",
+			mockSetup: func() {
+				mock.ExpectQuery(`^SELECT s\.id, .* FROM songs s WHERE s\.id = \?$`).
+					WithArgs("song123").
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				mock.ExpectQuery(`^SELECT s\.id, .* FROM songs s WHERE s\.id = \?$`).
+					WithArgs("song123").
+					WillReturnError(errors.New("database error"))
+			},
+			expectedError: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			song, fetched, err := storage.GetCachedTrack("song123")
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedSong, song)
+			assert.Equal(t, tt.expectedFetchedAt, fetched)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSpotifyRepository_SaveCachedTrack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	storage := NewSpotifyRepository(db, logging.NewLogger())
+	song := &models.Song{
+		ID: "song123", Title: "Test Song", Artist: "Test Artist", Album: "Test Album", AlbumId: "album1", Duration: 200,
+		ReleaseDate: "2024", ReleaseDatePrecision: "year",
+		Artists: []models.SongArtist{{ID: "artist1", Name: "Test Artist"}, {ID: "artist2", Name: "Featured Artist"}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^INSERT INTO albums \(id, name, album_cover, release_date, release_date_precision\) VALUES \(\?, \?, \?, \?, \?\) ON DUPLICATE KEY UPDATE name = VALUES\(name\)`).
+		WithArgs("album1", "Test Album", "", "2024", "year").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^INSERT INTO songs .* ON DUPLICATE KEY UPDATE title = VALUES\(title\), .* fetched_at = CURRENT_TIMESTAMP$`).
+		WithArgs(songArgs(song)...).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`^DELETE FROM song_artists WHERE song_id = \?$`).
+		WithArgs("song123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^INSERT INTO artists \(id, name\) VALUES \(\?, \?\), \(\?, \?\) ON DUPLICATE KEY UPDATE name = VALUES\(name\)$`).
+		WithArgs("artist1", "Test Artist", "artist2", "Featured Artist").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`^INSERT INTO song_artists \(song_id, artist_id, position\) VALUES \(\?, \?, \?\), \(\?, \?, \?\)$`).
+		WithArgs("song123", "artist1", 1, "song123", "artist2", 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	require.NoError(t, storage.SaveCachedTrack(song))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^INSERT INTO albums`).WillReturnError(errors.New("database error"))
+	mock.ExpectRollback()
+	assert.Error(t, storage.SaveCachedTrack(song))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSpotifyRepository_GetSongsWithoutArtists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	storage := NewSpotifyRepository(db, logging.NewLogger())
+
+	mock.ExpectQuery(`^SELECT s\.id FROM songs s WHERE NOT s\.artists_resolved AND NOT s\.unavailable ORDER BY s\.id LIMIT \?$`).
+		WithArgs(50).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a1").AddRow("b2"))
+
+	ids, err := storage.GetSongsWithoutArtists(50)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "b2"}, ids)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSpotifyRepository_HasSongsWithoutArtists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	storage := NewSpotifyRepository(db, logging.NewLogger())
+
+	mock.ExpectQuery(`^SELECT EXISTS \( SELECT 1 FROM playlist_songs ps JOIN songs s ON ps\.song_id = s\.id WHERE ps\.playlist_id = \? AND NOT s\.artists_resolved AND NOT s\.unavailable \)$`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"pending"}).AddRow(true))
+
+	pending, err := storage.HasSongsWithoutArtists(7)
+	require.NoError(t, err)
+	assert.True(t, pending)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSpotifyRepository_GetStaleSongs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	storage := NewSpotifyRepository(db, logging.NewLogger())
+
+	mock.ExpectQuery(`^SELECT id FROM songs WHERE fetched_at < NOW\(\) - INTERVAL \? SECOND ORDER BY fetched_at, id LIMIT \?$`).
+		WithArgs(int64(24*60*60), 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("old1").AddRow("old2"))
+
+	ids, err := storage.GetStaleSongs(24*time.Hour, 50)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old1", "old2"}, ids)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSpotifyRepository_RecordPopularity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	storage := NewSpotifyRepository(db, logging.NewLogger())
+
+	mock.ExpectExec(`^INSERT INTO song_popularity \(song_id, popularity\) VALUES \(\?, \?\), \(\?, \?\) ON DUPLICATE KEY UPDATE popularity = VALUES\(popularity\)$`).
+		WithArgs("song1", 70, "song2", 35).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	require.NoError(t, storage.RecordPopularity([]models.Song{{ID: "song1", Popularity: 70}, {ID: "song2", Popularity: 35}}))
+
+	require.NoError(t, storage.RecordPopularity(nil))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSpotifyRepository_MarkSongsUnavailable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	storage := NewSpotifyRepository(db, logging.NewLogger())
+
+	mock.ExpectExec(`^UPDATE songs SET unavailable = TRUE, fetched_at = CURRENT_TIMESTAMP WHERE id IN \(\?, \?\)$`).
+		WithArgs("gone1", "gone2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	require.NoError(t, storage.MarkSongsUnavailable([]string{"gone1", "gone2"}))
+
+	mock.ExpectExec(`^UPDATE songs SET unavailable = TRUE`).WillReturnError(errors.New("database error"))
+	assert.Error(t, storage.MarkSongsUnavailable([]string{"gone1"}))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"sort"
+)
+
+const (
+	topStatsLimit = 10
+)
+
+var pitchClasses = []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+
+type StatsRepository struct {
+	storage *sql.DB
+	log     *logging.LogrusLogger
+}
+
+func NewStatsRepository(
+	storage *sql.DB,
+	log *logging.LogrusLogger,
+) *StatsRepository {
+	return &StatsRepository{
+		storage: storage,
+		log:     log,
+	}
+}
+
+// GetStatsRevision returns a value that changes whenever the playlist tracks change:
+// every change records a snapshot, and smart playlists also stamp refreshed_at.
+// Audio features arrive after the tracks, so the number of analysed tracks counts too.
+func (s *StatsRepository) GetStatsRevision(userId, playlistId int) (string, error) {
+	var owner int
+	var version, refreshedAt, analysed int64
+	err := s.storage.QueryRow(`
+		SELECT p.user_id,
+		       (SELECT COALESCE(MAX(version), 0) FROM playlist_snapshots WHERE playlist_id = p.id),
+		       COALESCE(UNIX_TIMESTAMP(p.refreshed_at), 0),
+		       (SELECT COUNT(*) FROM playlist_songs ps JOIN audio_features af ON af.song_id = ps.song_id WHERE ps.playlist_id = p.id)
+		FROM playlists p
+		WHERE p.id = ? AND p.deleted_at IS NULL
+	`, playlistId).Scan(&owner, &version, &refreshedAt, &analysed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", playlistNotFound
+		}
+		s.log.Error("REPOSITORY: unsuccessful get stats revision: ", err)
+		return "", err
+	}
+
+	if owner != userId {
+		return "", permissionDenied
+	}
+
+	return fmt.Sprintf("%d-%d-%d", version, refreshedAt, analysed), nil
+}
+
+func (s *StatsRepository) GetPlaylistStats(userId, playlistId int) (*models.PlaylistStats, error) {
+	if err := checkPlaylistOwner(s.storage, userId, playlistId); err != nil {
+		s.log.Error("REPOSITORY: check playlist owner: ", err)
+		return nil, err
+	}
+
+	stats := &models.PlaylistStats{PlaylistId: playlistId}
+
+	err := s.storage.QueryRow(`
+		SELECT COUNT(*),
+		       COALESCE(SUM(s.duration), 0),
+		       COALESCE(AVG(s.popularity), 0),
+		       COALESCE(SUM(s.preview_url IS NULL OR s.preview_url = ''), 0)
+		FROM playlist_songs ps
+		JOIN songs s ON ps.song_id = s.id
+		WHERE ps.playlist_id = ?
+	`, playlistId).Scan(&stats.TrackCount, &stats.TotalDuration, &stats.AveragePopularity, &stats.MissingPreviews)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful get playlist totals: ", err)
+		return nil, err
+	}
+
+	err = s.storage.QueryRow(`
+		SELECT COALESCE(AVG(popularity), 0)
+		FROM (
+			SELECT s.popularity,
+			       ROW_NUMBER() OVER (ORDER BY s.popularity) AS rn,
+			       COUNT(*) OVER () AS total
+			FROM playlist_songs ps
+			JOIN songs s ON ps.song_id = s.id
+			WHERE ps.playlist_id = ?
+		) ranked
+		WHERE rn IN (FLOOR((total + 1) / 2), CEIL((total + 1) / 2))
+	`, playlistId).Scan(&stats.MedianPopularity)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful get median popularity: ", err)
+		return nil, err
+	}
+
+	stats.TopArtists, err = s.getTopCounts("s.artist", playlistId)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful get top artists: ", err)
+		return nil, err
+	}
+
+	stats.TopAlbums, err = s.getTopCounts("s.album", playlistId)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful get top albums: ", err)
+		return nil, err
+	}
+
+	stats.ReleaseYears, err = s.getReleaseYears(playlistId)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful get release years: ", err)
+		return nil, err
+	}
+
+	features, err := s.getAudioFeatures(playlistId)
+	if err != nil {
+		s.log.Error("REPOSITORY: unsuccessful get audio features: ", err)
+		return nil, err
+	}
+	stats.AudioFeatures = audioFeatureStats(features)
+
+	s.log.Info("REPOSITORY: playlist stats computed: ", playlistId, stats.TrackCount)
+	return stats, nil
+}
+
+func (s *StatsRepository) getTopCounts(column string, playlistId int) ([]*models.StatCount, error) {
+	rows, err := s.storage.Query(fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*) AS total
+		FROM playlist_songs ps
+		JOIN songs s ON ps.song_id = s.id
+		WHERE ps.playlist_id = ?
+		GROUP BY %[1]s
+		ORDER BY total DESC, %[1]s
+		LIMIT ?
+	`, column), playlistId, topStatsLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []*models.StatCount{}
+	for rows.Next() {
+		var count models.StatCount
+		if err := rows.Scan(&count.Name, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, &count)
+	}
+
+	return counts, rows.Err()
+}
+
+func (s *StatsRepository) getReleaseYears(playlistId int) ([]*models.YearCount, error) {
+	rows, err := s.storage.Query(`
+		SELECT YEAR(s.release_date) AS year, COUNT(*)
+		FROM playlist_songs ps
+		JOIN songs s ON ps.song_id = s.id
+		WHERE ps.playlist_id = ? AND s.release_date IS NOT NULL
+		GROUP BY year
+		ORDER BY year
+	`, playlistId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	years := []*models.YearCount{}
+	for rows.Next() {
+		var year models.YearCount
+		if err := rows.Scan(&year.Year, &year.Count); err != nil {
+			return nil, err
+		}
+		years = append(years, &year)
+	}
+
+	return years, rows.Err()
+}
+
+func (s *StatsRepository) getAudioFeatures(playlistId int) ([]models.AudioFeatures, error) {
+	rows, err := s.storage.Query(`
+		SELECT af.tempo, af.energy, af.danceability, af.valence, af.musical_key, af.mode, af.loudness
+		FROM playlist_songs ps
+		JOIN audio_features af ON af.song_id = ps.song_id
+		WHERE ps.playlist_id = ? AND af.tempo IS NOT NULL
+	`, playlistId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	features := []models.AudioFeatures{}
+	for rows.Next() {
+		var feature models.AudioFeatures
+		err := rows.Scan(&feature.Tempo, &feature.Energy, &feature.Danceability, &feature.Valence,
+			&feature.Key, &feature.Mode, &feature.Loudness)
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, feature)
+	}
+
+	return features, rows.Err()
+}
+
+// audioFeatureStats buckets tempo by 10 BPM, loudness by 5 dB and the 0 to 1
+// features by tenths. Keys are counted by name, e.g. "A minor".
+func audioFeatureStats(features []models.AudioFeatures) *models.AudioFeatureStats {
+	stats := &models.AudioFeatureStats{TrackCount: len(features), Keys: []*models.StatCount{}}
+	if len(features) == 0 {
+		return stats
+	}
+
+	values := func(value func(models.AudioFeatures) float64) []float64 {
+		result := make([]float64, 0, len(features))
+		for _, feature := range features {
+			result = append(result, value(feature))
+		}
+		return result
+	}
+	stats.Tempo = distribution(values(func(f models.AudioFeatures) float64 { return f.Tempo }), 10, math.Inf(1))
+	stats.Energy = distribution(values(func(f models.AudioFeatures) float64 { return f.Energy }), 0.1, 1)
+	stats.Danceability = distribution(values(func(f models.AudioFeatures) float64 { return f.Danceability }), 0.1, 1)
+	stats.Valence = distribution(values(func(f models.AudioFeatures) float64 { return f.Valence }), 0.1, 1)
+	stats.Loudness = distribution(values(func(f models.AudioFeatures) float64 { return f.Loudness }), 5, 0)
+
+	keys := make(map[string]int)
+	for _, feature := range features {
+		if feature.Key < 0 || feature.Key >= len(pitchClasses) {
+			continue
+		}
+		name := pitchClasses[feature.Key] + " minor"
+		if feature.Mode == 1 {
+			name = pitchClasses[feature.Key] + " major"
+		}
+		keys[name]++
+	}
+	for name, count := range keys {
+		stats.Keys = append(stats.Keys, &models.StatCount{Name: name, Count: count})
+	}
+	sort.Slice(stats.Keys, func(i, j int) bool {
+		if stats.Keys[i].Count != stats.Keys[j].Count {
+			return stats.Keys[i].Count > stats.Keys[j].Count
+		}
+		return stats.Keys[i].Name < stats.Keys[j].Name
+	})
+
+	return stats
+}
+
+// distribution counts values into buckets of the given width. A value at the upper
+// bound goes into the last bucket below it instead of a bucket of its own.
+func distribution(values []float64, width, upper float64) *models.Distribution {
+	result := &models.Distribution{Min: values[0], Max: values[0], Buckets: []*models.Bucket{}}
+	counts := make(map[int]int)
+	var sum float64
+	for _, value := range values {
+		result.Min = math.Min(result.Min, value)
+		result.Max = math.Max(result.Max, value)
+		sum += value
+
+		// The epsilon keeps 0.3 / 0.1 from landing just below 3.
+		index := int(math.Floor(value/width + 1e-9))
+		if value >= upper {
+			index = int(math.Ceil(upper/width)) - 1
+		}
+		counts[index]++
+	}
+	result.Average = sum / float64(len(values))
+
+	indexes := make([]int, 0, len(counts))
+	for index := range counts {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		result.Buckets = append(result.Buckets, &models.Bucket{
+			From:  roundBound(float64(index) * width),
+			To:    roundBound(float64(index+1) * width),
+			Count: counts[index],
+		})
+	}
+
+	return result
+}
+
+// roundBound drops the float noise of multiplying by a width like 0.1.
+func roundBound(bound float64) float64 {
+	return math.Round(bound*1000) / 1000
+}
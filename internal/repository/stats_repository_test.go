@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"testing"
+)
+
+func TestStatsRepository_GetStatsRevision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStatsRepository(db, logging.NewLogger())
+
+	mock.ExpectQuery(`^SELECT p\.user_id, .* FROM playlists p WHERE p\.id = \? AND p\.deleted_at IS NULL$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "version", "refreshed_at", "analysed"}).AddRow(1, 4, 0, 2))
+
+	revision, err := repo.GetStatsRevision(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "4-0-2", revision)
+
+	mock.ExpectQuery(`^SELECT p\.user_id, .* FROM playlists p WHERE p\.id = \? AND p\.deleted_at IS NULL$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "version", "refreshed_at", "analysed"}).AddRow(2, 4, 0, 2))
+
+	_, err = repo.GetStatsRevision(1, 1)
+	assert.Equal(t, permissionDenied, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatsRepository_GetPlaylistStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStatsRepository(db, logging.NewLogger())
+
+	mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \? AND deleted_at IS NULL$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	mock.ExpectQuery(`^SELECT COUNT\(\*\), .* WHERE ps\.playlist_id = \?$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "duration", "average", "missing"}).AddRow(3, 600000, "56.6667", 1))
+	mock.ExpectQuery(`^SELECT COALESCE\(AVG\(popularity\), 0\) FROM \( .* \) ranked WHERE rn IN`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"median"}).AddRow("60.0000"))
+	mock.ExpectQuery(`^SELECT s\.artist, COUNT\(\*\) AS total .* GROUP BY s\.artist ORDER BY total DESC, s\.artist LIMIT \?$`).
+		WithArgs(1, topStatsLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"artist", "total"}).AddRow("Artist A", 2).AddRow("Artist B", 1))
+	mock.ExpectQuery(`^SELECT s\.album, COUNT\(\*\) AS total .* GROUP BY s\.album ORDER BY total DESC, s\.album LIMIT \?$`).
+		WithArgs(1, topStatsLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"album", "total"}).AddRow("Album A", 3))
+	mock.ExpectQuery(`^SELECT YEAR\(s\.release_date\) AS year, COUNT\(\*\) .* GROUP BY year ORDER BY year$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"year", "count"}).AddRow(2019, 1).AddRow(2020, 2))
+	mock.ExpectQuery(`^SELECT af\.tempo, .* FROM playlist_songs ps JOIN audio_features af ON af\.song_id = ps\.song_id WHERE ps\.playlist_id = \? AND af\.tempo IS NOT NULL$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(featureRowColumns[1:]))
+
+	stats, err := repo.GetPlaylistStats(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &models.PlaylistStats{
+		PlaylistId:        1,
+		TrackCount:        3,
+		TotalDuration:     600000,
+		AveragePopularity: 56.6667,
+		MedianPopularity:  60,
+		MissingPreviews:   1,
+		TopArtists:        []*models.StatCount{{Name: "Artist A", Count: 2}, {Name: "Artist B", Count: 1}},
+		TopAlbums:         []*models.StatCount{{Name: "Album A", Count: 3}},
+		ReleaseYears:      []*models.YearCount{{Year: 2019, Count: 1}, {Year: 2020, Count: 2}},
+		AudioFeatures:     &models.AudioFeatureStats{Keys: []*models.StatCount{}},
+	}, stats)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAudioFeatureStats(t *testing.T) {
+	stats := audioFeatureStats([]models.AudioFeatures{
+		{Tempo: 124, Energy: 0.3, Danceability: 1, Valence: 0.25, Key: 9, Mode: 0, Loudness: -6},
+		{Tempo: 128, Energy: 0.35, Danceability: 0.95, Valence: 0.75, Key: 9, Mode: 0, Loudness: -4},
+		{Tempo: 174, Energy: 0.9, Danceability: 0.5, Valence: 0.5, Key: 0, Mode: 1, Loudness: 0.5},
+		{Tempo: 90, Energy: 0.1, Danceability: 0.4, Valence: 0.5, Key: -1, Mode: 1, Loudness: -12},
+	})
+
+	assert.Equal(t, 4, stats.TrackCount)
+	assert.Equal(t, &models.Distribution{Min: 90, Max: 174, Average: 129, Buckets: []*models.Bucket{
+		{From: 90, To: 100, Count: 1},
+		{From: 120, To: 130, Count: 2},
+		{From: 170, To: 180, Count: 1},
+	}}, stats.Tempo)
+	assert.Equal(t, []*models.Bucket{
+		{From: 0.1, To: 0.2, Count: 1},
+		{From: 0.3, To: 0.4, Count: 2},
+		{From: 0.9, To: 1, Count: 1},
+	}, stats.Energy.Buckets)
+	assert.Equal(t, []*models.Bucket{
+		{From: 0.4, To: 0.5, Count: 1},
+		{From: 0.5, To: 0.6, Count: 1},
+		{From: 0.9, To: 1, Count: 2},
+	}, stats.Danceability.Buckets)
+	assert.Equal(t, []*models.Bucket{
+		{From: -15, To: -10, Count: 1},
+		{From: -10, To: -5, Count: 1},
+		{From: -5, To: 0, Count: 2},
+	}, stats.Loudness.Buckets)
+	assert.Equal(t, []*models.StatCount{{Name: "A minor", Count: 2}, {Name: "C major", Count: 1}}, stats.Keys)
+}
@@ -0,0 +1,407 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"strings"
+)
+
+const (
+	tagAutocompleteLimit = 10
+)
+
+var (
+	ErrTagExists = errors.New("a tag with this name already exists")
+
+	tagNotFound      = errors.New("tag not found")
+	songNotInLibrary = errors.New("song is not in any of the user's playlists")
+)
+
+type TagRepository struct {
+	storage *sql.DB
+	log     *logging.LogrusLogger
+}
+
+func NewTagRepository(
+	storage *sql.DB,
+	log *logging.LogrusLogger,
+) *TagRepository {
+	return &TagRepository{
+		storage: storage,
+		log:     log,
+	}
+}
+
+func (t *TagRepository) CreateTag(userId int, name string) (int64, error) {
+	tagId, err := upsertTag(t.storage, userId, name)
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful create tag: ", err)
+		return 0, err
+	}
+
+	t.log.Info("REPOSITORY: create tag: ", tagId)
+	return tagId, nil
+}
+
+// GetTags lists the user's tags with their usage, most used first. A non-empty
+// prefix turns it into an autocomplete lookup.
+func (t *TagRepository) GetTags(userId int, prefix string) ([]*models.Tag, error) {
+	query := `
+		SELECT t.id, t.name,
+		       (SELECT COUNT(*) FROM playlist_tags pt WHERE pt.tag_id = t.id) +
+		       (SELECT COUNT(*) FROM song_tags st WHERE st.tag_id = t.id) AS usage_count
+		FROM tags t
+		WHERE t.user_id = ?`
+	args := []interface{}{userId}
+
+	if prefix != "" {
+		query += ` AND t.name LIKE ? ORDER BY usage_count DESC, t.name LIMIT ?`
+		args = append(args, escapeLikePattern(prefix)+"%", tagAutocompleteLimit)
+	} else {
+		query += ` ORDER BY usage_count DESC, t.name`
+	}
+
+	rows, err := t.storage.Query(query, args...)
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful get tags: ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Usage); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	t.log.Info("REPOSITORY: get list of tags: ", len(tags))
+	return tags, nil
+}
+
+func (t *TagRepository) RenameTag(userId, tagId int, name string) error {
+	if err := checkTagOwner(t.storage, userId, tagId); err != nil {
+		t.log.Error("REPOSITORY: check tag owner: ", err)
+		return err
+	}
+
+	var taken bool
+	query := `SELECT EXISTS(SELECT 1 FROM tags WHERE user_id = ? AND name = ? AND id <> ?)`
+	if err := t.storage.QueryRow(query, userId, name, tagId).Scan(&taken); err != nil {
+		t.log.Error("REPOSITORY: check tag name: ", err)
+		return err
+	}
+
+	if taken {
+		t.log.Error("REPOSITORY: tag name taken: ", name)
+		return ErrTagExists
+	}
+
+	if _, err := t.storage.Exec(`UPDATE tags SET name = ? WHERE id = ?`, name, tagId); err != nil {
+		t.log.Error("REPOSITORY: unsuccessful rename tag: ", err)
+		return err
+	}
+
+	t.log.Info("REPOSITORY: tag renamed: ", tagId)
+	return nil
+}
+
+func (t *TagRepository) DeleteTag(userId, tagId int) error {
+	result, err := t.storage.Exec(`DELETE FROM tags WHERE user_id = ? AND id = ?`, userId, tagId)
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful delete tag: ", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful delete tag: ", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		t.log.Error("REPOSITORY: tag not found: ", tagId)
+		return tagNotFound
+	}
+
+	t.log.Info("REPOSITORY: tag deleted: ", tagId)
+	return nil
+}
+
+func (t *TagRepository) TagPlaylist(userId, playlistId int, name string) (int64, error) {
+	tx, err := t.storage.Begin()
+	if err != nil {
+		t.log.Error("REPOSITORY: begin tag playlist: ", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := checkPlaylistOwner(tx, userId, playlistId); err != nil {
+		t.log.Error("REPOSITORY: check playlist owner: ", err)
+		return 0, err
+	}
+
+	tagId, err := upsertTag(tx, userId, name)
+	if err != nil {
+		t.log.Error("REPOSITORY: tag not created: ", err)
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`INSERT IGNORE INTO playlist_tags (playlist_id, tag_id) VALUES (?, ?)`, playlistId, tagId); err != nil {
+		t.log.Error("REPOSITORY: playlist not tagged: ", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.log.Error("REPOSITORY: commit tag playlist: ", err)
+		return 0, err
+	}
+
+	t.log.Info("REPOSITORY: playlist tagged: ", playlistId, tagId)
+	return tagId, nil
+}
+
+func (t *TagRepository) UntagPlaylist(userId, playlistId, tagId int) error {
+	if err := checkPlaylistOwner(t.storage, userId, playlistId); err != nil {
+		t.log.Error("REPOSITORY: check playlist owner: ", err)
+		return err
+	}
+
+	if _, err := t.storage.Exec(`DELETE FROM playlist_tags WHERE playlist_id = ? AND tag_id = ?`, playlistId, tagId); err != nil {
+		t.log.Error("REPOSITORY: playlist tag not removed: ", err)
+		return err
+	}
+
+	t.log.Info("REPOSITORY: playlist untagged: ", playlistId, tagId)
+	return nil
+}
+
+func (t *TagRepository) GetPlaylistTags(userId, playlistId int) ([]*models.Tag, error) {
+	if err := checkPlaylistOwner(t.storage, userId, playlistId); err != nil {
+		t.log.Error("REPOSITORY: check playlist owner: ", err)
+		return nil, err
+	}
+
+	return t.queryTags(`
+		SELECT t.id, t.name FROM tags t
+		JOIN playlist_tags pt ON pt.tag_id = t.id
+		WHERE pt.playlist_id = ?
+		ORDER BY t.name
+	`, playlistId)
+}
+
+func (t *TagRepository) TagSong(userId int, songId string, name string) (int64, error) {
+	tx, err := t.storage.Begin()
+	if err != nil {
+		t.log.Error("REPOSITORY: begin tag song: ", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var inLibrary bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM playlist_songs ps
+			JOIN playlists p ON ps.playlist_id = p.id
+			WHERE p.user_id = ? AND ps.song_id = ? AND p.deleted_at IS NULL)
+	`, userId, songId).Scan(&inLibrary)
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful check song in library: ", err)
+		return 0, err
+	}
+
+	if !inLibrary {
+		t.log.Error("REPOSITORY: song not in library: ", songId)
+		return 0, songNotInLibrary
+	}
+
+	tagId, err := upsertTag(tx, userId, name)
+	if err != nil {
+		t.log.Error("REPOSITORY: tag not created: ", err)
+		return 0, err
+	}
+
+	_, err = tx.Exec(`INSERT IGNORE INTO song_tags (user_id, song_id, tag_id) VALUES (?, ?, ?)`, userId, songId, tagId)
+	if err != nil {
+		t.log.Error("REPOSITORY: song not tagged: ", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.log.Error("REPOSITORY: commit tag song: ", err)
+		return 0, err
+	}
+
+	t.log.Info("REPOSITORY: song tagged: ", songId, tagId)
+	return tagId, nil
+}
+
+func (t *TagRepository) UntagSong(userId int, songId string, tagId int) error {
+	_, err := t.storage.Exec(
+		`DELETE FROM song_tags WHERE user_id = ? AND song_id = ? AND tag_id = ?`,
+		userId,
+		songId,
+		tagId,
+	)
+	if err != nil {
+		t.log.Error("REPOSITORY: song tag not removed: ", err)
+		return err
+	}
+
+	t.log.Info("REPOSITORY: song untagged: ", songId, tagId)
+	return nil
+}
+
+func (t *TagRepository) GetSongTags(userId int, songId string) ([]*models.Tag, error) {
+	return t.queryTags(`
+		SELECT t.id, t.name FROM tags t
+		JOIN song_tags st ON st.tag_id = t.id
+		WHERE st.user_id = ? AND st.song_id = ?
+		ORDER BY t.name
+	`, userId, songId)
+}
+
+// GetTaggedPlaylistIds returns the user's playlists that carry every one of the given tags.
+func (t *TagRepository) GetTaggedPlaylistIds(userId int, tags []string) (map[int]bool, error) {
+	rows, err := t.storage.Query(fmt.Sprintf(`
+		SELECT pt.playlist_id FROM playlist_tags pt
+		JOIN tags t ON pt.tag_id = t.id
+		WHERE t.user_id = ? AND t.name IN (%s)
+		GROUP BY pt.playlist_id
+		HAVING COUNT(DISTINCT t.id) = ?
+	`, tagPlaceholders(len(tags))), tagArgs(userId, tags)...)
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful get tagged playlists: ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+
+	return ids, rows.Err()
+}
+
+// GetTaggedSongIds returns the songs in the user's library that carry every one of the given tags.
+func (t *TagRepository) GetTaggedSongIds(userId int, tags []string) (map[string]bool, error) {
+	rows, err := t.storage.Query(fmt.Sprintf(`
+		SELECT st.song_id FROM song_tags st
+		JOIN tags t ON st.tag_id = t.id
+		WHERE t.user_id = ? AND t.name IN (%s)
+		GROUP BY st.song_id
+		HAVING COUNT(DISTINCT t.id) = ?
+	`, tagPlaceholders(len(tags))), tagArgs(userId, tags)...)
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful get tagged songs: ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+
+	return ids, rows.Err()
+}
+
+func (t *TagRepository) queryTags(query string, args ...interface{}) ([]*models.Tag, error) {
+	rows, err := t.storage.Query(query, args...)
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful get tags: ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+
+	return tags, rows.Err()
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// upsertTag returns the id of the user's tag with this name, creating it when missing.
+func upsertTag(e execer, userId int, name string) (int64, error) {
+	result, err := e.Exec(
+		`INSERT INTO tags (user_id, name) VALUES (?, ?) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)`,
+		userId,
+		name,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// removeOrphanSongTags drops track tags for songs that are no longer in any of the user's playlists.
+func removeOrphanSongTags(e execer, userId int) error {
+	_, err := e.Exec(`
+		DELETE FROM song_tags
+		WHERE user_id = ? AND song_id NOT IN (
+			SELECT ps.song_id FROM playlist_songs ps
+			JOIN playlists p ON ps.playlist_id = p.id
+			WHERE p.user_id = ?)
+	`, userId, userId)
+	return err
+}
+
+func checkTagOwner(q querier, userId, tagId int) error {
+	var owner int
+	err := q.QueryRow(`SELECT user_id FROM tags WHERE id = ?`, tagId).Scan(&owner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return tagNotFound
+		}
+		return err
+	}
+
+	if owner != userId {
+		return tagNotFound
+	}
+	return nil
+}
+
+func tagPlaceholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func tagArgs(userId int, tags []string) []interface{} {
+	args := make([]interface{}, 0, len(tags)+2)
+	args = append(args, userId)
+	for _, tag := range tags {
+		args = append(args, tag)
+	}
+	return append(args, len(tags))
+}
+
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
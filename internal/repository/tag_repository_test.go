@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"testing"
+)
+
+func TestTagRepository_GetTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTagRepository(db, logging.NewLogger())
+
+	mock.ExpectQuery(`^SELECT t\.id, t\.name, .* FROM tags t WHERE t\.user_id = \? AND t\.name LIKE \? ORDER BY usage_count DESC, t\.name LIMIT \?$`).
+		WithArgs(1, `wo\_rk%`, tagAutocompleteLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "usage_count"}).AddRow(3, "wo_rkout", 4))
+
+	tags, err := repo.GetTags(1, "wo_rk")
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Tag{{ID: 3, Name: "wo_rkout", Usage: 4}}, tags)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTagRepository_TagPlaylist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTagRepository(db, logging.NewLogger())
+
+	testCases := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+		expectedTagId int64
+	}{
+		{
+			name: "successful tag",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \? AND deleted_at IS NULL$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+				mock.ExpectExec(`^INSERT INTO tags \(user_id, name\) VALUES \(\?, \?\) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID\(id\)$`).
+					WithArgs(1, "workout").
+					WillReturnResult(sqlmock.NewResult(7, 1))
+				mock.ExpectExec(`^INSERT IGNORE INTO playlist_tags \(playlist_id, tag_id\) VALUES \(\?, \?\)$`).
+					WithArgs(1, int64(7)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedTagId: 7,
+		},
+		{
+			name: "playlist of another user",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT user_id FROM playlists WHERE id = \? AND deleted_at IS NULL$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(2))
+				mock.ExpectRollback()
+			},
+			expectedError: permissionDenied,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			tagId, err := repo.TagPlaylist(1, 1, "workout")
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedTagId, tagId)
+			}
+
+			err = mock.ExpectationsWereMet()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTagRepository_TagSong_NotInLibrary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTagRepository(db, logging.NewLogger())
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT EXISTS\( SELECT 1 FROM playlist_songs ps .*\)$`).
+		WithArgs(1, "song123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+
+	_, err = repo.TagSong(1, "song123", "focus")
+	assert.Equal(t, songNotInLibrary, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTagRepository_GetTaggedPlaylistIds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTagRepository(db, logging.NewLogger())
+
+	mock.ExpectQuery(`^SELECT pt\.playlist_id FROM playlist_tags pt .* t\.name IN \(\?, \?\) GROUP BY pt\.playlist_id HAVING COUNT\(DISTINCT t\.id\) = \?$`).
+		WithArgs(1, "workout", "focus", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"playlist_id"}).AddRow(4).AddRow(9))
+
+	ids, err := repo.GetTaggedPlaylistIds(1, []string{"workout", "focus"})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]bool{4: true, 9: true}, ids)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTagRepository_RenameTag_NameTaken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTagRepository(db, logging.NewLogger())
+
+	mock.ExpectQuery(`^SELECT user_id FROM tags WHERE id = \?$`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	mock.ExpectQuery(`^SELECT EXISTS\(SELECT 1 FROM tags WHERE user_id = \? AND name = \? AND id <> \?\)$`).
+		WithArgs(1, "focus", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	err = repo.RenameTag(1, 3, "focus")
+	assert.Equal(t, ErrTagExists, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"time"
+)
+
+var (
+	trashNotFound = errors.New("playlist not found in trash")
+)
+
+type TrashRepository struct {
+	storage *sql.DB
+	log     *logging.LogrusLogger
+}
+
+func NewTrashRepository(
+	storage *sql.DB,
+	log *logging.LogrusLogger,
+) *TrashRepository {
+	return &TrashRepository{
+		storage: storage,
+		log:     log,
+	}
+}
+
+func (t *TrashRepository) GetTrash(userId int) ([]*models.TrashedPlaylist, error) {
+	rows, err := t.storage.Query(
+		"SELECT id, name, kind, deleted_at FROM playlists WHERE user_id = ? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC, id",
+		userId,
+	)
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful get trash: ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	playlists := []*models.TrashedPlaylist{}
+	for rows.Next() {
+		var playlist models.TrashedPlaylist
+		if err := rows.Scan(&playlist.ID, &playlist.Name, &playlist.Kind, &playlist.DeletedAt); err != nil {
+			return nil, err
+		}
+		playlists = append(playlists, &playlist)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	t.log.Info("REPOSITORY: get list of trashed playlists: ", len(playlists))
+	return playlists, nil
+}
+
+func (t *TrashRepository) RestorePlaylist(userId, playlistId int, limits *models.QuotaLimits) error {
+	tx, err := t.storage.Begin()
+	if err != nil {
+		t.log.Error("REPOSITORY: begin restore playlist: ", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkPlaylistQuota(tx, userId, 1, limits); err != nil {
+		t.log.Error("REPOSITORY: playlist quota: ", err)
+		return err
+	}
+
+	result, err := tx.Exec(
+		"UPDATE playlists SET deleted_at = NULL WHERE user_id = ? AND id = ? AND deleted_at IS NOT NULL",
+		userId,
+		playlistId,
+	)
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful restore playlist: ", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful restore playlist: ", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		t.log.Error("REPOSITORY: playlist not in trash: ", playlistId)
+		return trashNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.log.Error("REPOSITORY: commit restore playlist: ", err)
+		return err
+	}
+
+	t.log.Info("REPOSITORY: playlist restored: ", playlistId)
+	return nil
+}
+
+// PurgeDeletedPlaylists hard-deletes playlists trashed longer than olderThan ago, by
+// the database clock. Their
+// tracks, snapshots and tags go with them through ON DELETE CASCADE; track tags that
+// no longer belong to any playlist of their owner are removed as well.
+func (t *TrashRepository) PurgeDeletedPlaylists(olderThan time.Duration) (int64, error) {
+	tx, err := t.storage.Begin()
+	if err != nil {
+		t.log.Error("REPOSITORY: begin purge trash: ", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"DELETE FROM playlists WHERE deleted_at IS NOT NULL AND deleted_at < NOW() - INTERVAL ? SECOND",
+		int64(olderThan/time.Second),
+	)
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful purge trash: ", err)
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		t.log.Error("REPOSITORY: unsuccessful purge trash: ", err)
+		return 0, err
+	}
+
+	if purged > 0 {
+		_, err := tx.Exec(`
+			DELETE FROM song_tags
+			WHERE NOT EXISTS (
+				SELECT 1 FROM playlist_songs ps
+				JOIN playlists p ON ps.playlist_id = p.id
+				WHERE p.user_id = song_tags.user_id AND ps.song_id = song_tags.song_id)
+		`)
+		if err != nil {
+			t.log.Error("REPOSITORY: orphan track tags not removed: ", err)
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.log.Error("REPOSITORY: commit purge trash: ", err)
+		return 0, err
+	}
+
+	t.log.Info("REPOSITORY: trash purged: ", purged)
+	return purged, nil
+}
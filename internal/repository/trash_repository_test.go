@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/pkg/logging"
+	"testing"
+	"time"
+)
+
+func TestTrashRepository_GetTrash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTrashRepository(db, logging.NewLogger())
+
+	deletedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`^SELECT id, name, kind, deleted_at FROM playlists WHERE user_id = \? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC, id$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "kind", "deleted_at"}).AddRow(3, "Old mix", "manual", deletedAt))
+
+	playlists, err := repo.GetTrash(1)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.TrashedPlaylist{{ID: 3, Name: "Old mix", Kind: "manual", DeletedAt: deletedAt}}, playlists)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTrashRepository_RestorePlaylist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTrashRepository(db, logging.NewLogger())
+
+	testCases := []struct {
+		name          string
+		limits        *models.QuotaLimits
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "successful restore",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`^UPDATE playlists SET deleted_at = NULL WHERE user_id = \? AND id = \? AND deleted_at IS NOT NULL$`).
+					WithArgs(1, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "not in trash",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`^UPDATE playlists SET deleted_at = NULL WHERE user_id = \? AND id = \? AND deleted_at IS NOT NULL$`).
+					WithArgs(1, 3).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedError: trashNotFound,
+		},
+		{
+			name:   "playlist quota exceeded",
+			limits: &models.QuotaLimits{MaxPlaylists: 2},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`^SELECT id FROM users WHERE id = \? FOR UPDATE$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM playlists WHERE user_id = \? AND deleted_at IS NULL$`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectRollback()
+			},
+			expectedError: fmt.Errorf("%w: at most 2 playlists allowed", ErrQuotaExceeded),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.RestorePlaylist(1, 3, tt.limits)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			err = mock.ExpectationsWereMet()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTrashRepository_PurgeDeletedPlaylists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTrashRepository(db, logging.NewLogger())
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^DELETE FROM playlists WHERE deleted_at IS NOT NULL AND deleted_at < NOW\(\) - INTERVAL \? SECOND$`).
+		WithArgs(int64(30 * 24 * 60 * 60)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`^DELETE FROM song_tags WHERE NOT EXISTS`).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectCommit()
+
+	purged, err := repo.PurgeDeletedPlaylists(30 * 24 * time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
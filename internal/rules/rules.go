@@ -0,0 +1,341 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"music-service/internal/models"
+	"strings"
+)
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 500
+
+	maxDepth      = 5
+	maxConditions = 50
+)
+
+// libraryScope keeps rule matches to the songs in the user's manual playlists.
+const libraryScope = ` AND s.id IN (
+			SELECT ps.song_id FROM playlist_songs ps
+			JOIN playlists p ON ps.playlist_id = p.id
+			WHERE p.user_id = ? AND p.kind = 'manual' AND p.deleted_at IS NULL)`
+
+var (
+	errEmptyRule      = errors.New("rule must define a condition or a group")
+	errMixedRule      = errors.New("rule can't be a condition and a group at the same time")
+	errTooDeep        = fmt.Errorf("rules can't be nested deeper than %d levels", maxDepth)
+	errTooManyRules   = fmt.Errorf("rules can't have more than %d conditions", maxConditions)
+	errInvalidLimit   = fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+	errInvalidSource  = errors.New("source must be catalog or library")
+	errInvalidOrder   = errors.New("sort order must be asc or desc")
+	errInvalidBetween = errors.New("between expects exactly two values")
+)
+
+type valueKind int
+
+const (
+	textValue valueKind = iota
+	numberValue
+	playlistValue
+)
+
+type field struct {
+	column string
+	kind   valueKind
+	// credited fields match any of the song's credited artists, column is only sorted on.
+	credited bool
+}
+
+var fields = map[string]field{
+	"title":        {column: "s.title", kind: textValue},
+	"artist":       {column: "s.artist", kind: textValue, credited: true},
+	"album":        {column: "s.album", kind: textValue},
+	"release_date": {column: "s.release_date", kind: textValue},
+	"release_year": {column: "YEAR(s.release_date)", kind: numberValue},
+	"duration":     {column: "s.duration", kind: numberValue},
+	"popularity":   {column: "s.popularity", kind: numberValue},
+	"playlist":     {kind: playlistValue},
+}
+
+// negations map the negative operators to the positive ones credited fields are
+// matched with before the whole match is negated.
+var negations = map[string]string{
+	"!=":     "=",
+	"not_in": "in",
+}
+
+var comparisons = map[string]string{
+	"=":  "=",
+	"!=": "<>",
+	">":  ">",
+	">=": ">=",
+	"<":  "<",
+	"<=": "<=",
+}
+
+// Query is a compiled rule tree, ready to be placed into a SELECT over songs aliased as s.
+type Query struct {
+	Where   string
+	Args    []interface{}
+	OrderBy string
+	Limit   int
+}
+
+// Validate checks the rule tree without producing SQL.
+func Validate(r *models.SmartRules) error {
+	_, err := Compile(r, 0)
+	return err
+}
+
+// Compile turns the rule tree into a parameterized WHERE clause. Only whitelisted
+// fields and operators reach the SQL text; every value is passed as an argument.
+// Rules only match songs in the user's own manual playlists, whatever the source.
+func Compile(r *models.SmartRules, userId int) (*Query, error) {
+	c := &compiler{userId: userId}
+
+	where, err := c.node(r.Match, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch r.Source {
+	case "", models.RuleSourceCatalog, models.RuleSourceLibrary:
+	default:
+		return nil, errInvalidSource
+	}
+
+	where = "(" + where + ")" + libraryScope
+	c.args = append(c.args, userId)
+
+	limit := r.Limit
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit < 0 || limit > MaxLimit {
+		return nil, errInvalidLimit
+	}
+
+	orderBy, err := orderBy(r.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Query{
+		Where:   where,
+		Args:    c.args,
+		OrderBy: orderBy,
+		Limit:   limit,
+	}, nil
+}
+
+type compiler struct {
+	userId     int
+	args       []interface{}
+	conditions int
+}
+
+func (c *compiler) node(n models.RuleNode, depth int) (string, error) {
+	if depth > maxDepth {
+		return "", errTooDeep
+	}
+
+	isGroup := len(n.All) > 0 || len(n.Any) > 0
+	isCondition := n.Field != ""
+
+	switch {
+	case isGroup && isCondition, len(n.All) > 0 && len(n.Any) > 0:
+		return "", errMixedRule
+	case len(n.All) > 0:
+		return c.group(n.All, " AND ", depth)
+	case len(n.Any) > 0:
+		return c.group(n.Any, " OR ", depth)
+	case isCondition:
+		return c.condition(n)
+	default:
+		return "", errEmptyRule
+	}
+}
+
+func (c *compiler) group(nodes []models.RuleNode, joiner string, depth int) (string, error) {
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		part, err := c.node(n, depth+1)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+	return "(" + strings.Join(parts, joiner) + ")", nil
+}
+
+func (c *compiler) condition(n models.RuleNode) (string, error) {
+	c.conditions++
+	if c.conditions > maxConditions {
+		return "", errTooManyRules
+	}
+
+	f, ok := fields[n.Field]
+	if !ok {
+		return "", fmt.Errorf("unknown rule field %q", n.Field)
+	}
+
+	if f.kind == playlistValue {
+		return c.playlistCondition(n)
+	}
+
+	if f.credited {
+		return c.creditCondition(n, f)
+	}
+	return c.predicate(n.Field, f.column, f.kind, n.Op, n.Value)
+}
+
+// creditCondition matches songs by their credited artists, so a negative operator
+// excludes a song when any of its artists matches.
+func (c *compiler) creditCondition(n models.RuleNode, f field) (string, error) {
+	op, in := n.Op, "IN"
+	if positive, ok := negations[n.Op]; ok {
+		op, in = positive, "NOT IN"
+	}
+
+	predicate, err := c.predicate(n.Field, "a.name", f.kind, op, n.Value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`s.id %s (
+			SELECT sa.song_id FROM song_artists sa
+			JOIN artists a ON sa.artist_id = a.id
+			WHERE %s)`, in, predicate), nil
+}
+
+func (c *compiler) predicate(name, column string, kind valueKind, op string, raw json.RawMessage) (string, error) {
+	if comparison, ok := comparisons[op]; ok {
+		value, err := decodeScalar(raw, kind)
+		if err != nil {
+			return "", fmt.Errorf("field %q: %w", name, err)
+		}
+		c.args = append(c.args, value)
+		return fmt.Sprintf("%s %s ?", column, comparison), nil
+	}
+
+	switch op {
+	case "in", "not_in":
+		values, err := decodeList(raw, kind)
+		if err != nil {
+			return "", fmt.Errorf("field %q: %w", name, err)
+		}
+		c.args = append(c.args, values...)
+		return fmt.Sprintf("%s %s (%s)", column, sqlIn(op), placeholders(len(values))), nil
+	case "between":
+		values, err := decodeList(raw, kind)
+		if err != nil {
+			return "", fmt.Errorf("field %q: %w", name, err)
+		}
+		if len(values) != 2 {
+			return "", errInvalidBetween
+		}
+		c.args = append(c.args, values...)
+		return fmt.Sprintf("%s BETWEEN ? AND ?", column), nil
+	case "contains":
+		if kind != textValue {
+			return "", fmt.Errorf("field %q doesn't support contains", name)
+		}
+		value, err := decodeScalar(raw, textValue)
+		if err != nil {
+			return "", fmt.Errorf("field %q: %w", name, err)
+		}
+		c.args = append(c.args, "%"+escapeLike(value.(string))+"%")
+		return fmt.Sprintf("%s LIKE ?", column), nil
+	default:
+		return "", fmt.Errorf("unknown rule operator %q", op)
+	}
+}
+
+func (c *compiler) playlistCondition(n models.RuleNode) (string, error) {
+	if n.Op != "in" && n.Op != "not_in" {
+		return "", fmt.Errorf("field \"playlist\" supports only in and not_in")
+	}
+
+	values, err := decodeList(n.Value, numberValue)
+	if err != nil {
+		return "", fmt.Errorf("field \"playlist\": %w", err)
+	}
+
+	c.args = append(c.args, values...)
+	c.args = append(c.args, c.userId)
+	return fmt.Sprintf(`s.id %s (
+			SELECT ps.song_id FROM playlist_songs ps
+			JOIN playlists p ON ps.playlist_id = p.id
+			WHERE ps.playlist_id IN (%s) AND p.user_id = ? AND p.deleted_at IS NULL)`, sqlIn(n.Op), placeholders(len(values))), nil
+}
+
+func orderBy(sort *models.RuleSort) (string, error) {
+	if sort == nil {
+		return "s.popularity DESC, s.id", nil
+	}
+
+	f, ok := fields[sort.Field]
+	if !ok || f.kind == playlistValue {
+		return "", fmt.Errorf("unknown sort field %q", sort.Field)
+	}
+
+	direction := "ASC"
+	switch strings.ToLower(sort.Order) {
+	case "", "asc":
+	case "desc":
+		direction = "DESC"
+	default:
+		return "", errInvalidOrder
+	}
+
+	return fmt.Sprintf("%s %s, s.id", f.column, direction), nil
+}
+
+func decodeScalar(raw json.RawMessage, kind valueKind) (interface{}, error) {
+	if kind == textValue {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, errors.New("expected a string value")
+		}
+		return s, nil
+	}
+
+	var f float64
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, errors.New("expected a numeric value")
+	}
+	return f, nil
+}
+
+func decodeList(raw json.RawMessage, kind valueKind) ([]interface{}, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil || len(items) == 0 {
+		return nil, errors.New("expected a non-empty list of values")
+	}
+
+	values := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		value, err := decodeScalar(item, kind)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func sqlIn(op string) string {
+	if op == "not_in" {
+		return "NOT IN"
+	}
+	return "IN"
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
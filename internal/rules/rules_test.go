@@ -0,0 +1,107 @@
+package rules
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"testing"
+)
+
+func parseRules(t *testing.T, raw string) *models.SmartRules {
+	t.Helper()
+	var r models.SmartRules
+	require.NoError(t, json.Unmarshal([]byte(raw), &r))
+	return &r
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name            string
+		rules           string
+		expectedWhere   string
+		expectedArgs    []interface{}
+		expectedOrderBy string
+		expectedLimit   int
+	}{
+		{
+			name: "artist, popularity and release years",
+			rules: `{
+				"match": {"all": [
+					{"field": "artist", "op": "in", "value": ["X", "Y"]},
+					{"field": "popularity", "op": ">", "value": 60},
+					{"field": "release_year", "op": "between", "value": [2015, 2020]}
+				]},
+				"limit": 50,
+				"sort": {"field": "popularity", "order": "desc"}
+			}`,
+			expectedWhere: "((s.id IN (\n\t\t\tSELECT sa.song_id FROM song_artists sa\n\t\t\tJOIN artists a ON sa.artist_id = a.id\n\t\t\tWHERE a.name IN (?, ?))" +
+				" AND s.popularity > ? AND YEAR(s.release_date) BETWEEN ? AND ?))" + libraryScope,
+			expectedArgs:    []interface{}{"X", "Y", float64(60), float64(2015), float64(2020), 1},
+			expectedOrderBy: "s.popularity DESC, s.id",
+			expectedLimit:   50,
+		},
+		{
+			name:            "nested any group with contains",
+			rules:           `{"match": {"any": [{"field": "title", "op": "contains", "value": "50%"}, {"field": "duration", "op": "<=", "value": 180}]}}`,
+			expectedWhere:   "((s.title LIKE ? OR s.duration <= ?))" + libraryScope,
+			expectedArgs:    []interface{}{`%50\%%`, float64(180), 1},
+			expectedOrderBy: "s.popularity DESC, s.id",
+			expectedLimit:   DefaultLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := Compile(parseRules(t, tt.rules), 1)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedWhere, query.Where)
+			assert.Equal(t, tt.expectedArgs, query.Args)
+			assert.Equal(t, tt.expectedOrderBy, query.OrderBy)
+			assert.Equal(t, tt.expectedLimit, query.Limit)
+		})
+	}
+}
+
+func TestCompile_PlaylistAndLibrary(t *testing.T) {
+	query, err := Compile(parseRules(t, `{
+		"match": {"field": "playlist", "op": "not_in", "value": [3]},
+		"source": "library"
+	}`), 7)
+	require.NoError(t, err)
+	assert.Contains(t, query.Where, "s.id NOT IN (")
+	assert.Contains(t, query.Where, "p.kind = 'manual'")
+	assert.Equal(t, []interface{}{float64(3), 7, 7}, query.Args)
+}
+
+func TestValidate_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{name: "empty rule", rules: `{"match": {}}`},
+		{name: "unknown field", rules: `{"match": {"field": "id; DROP TABLE songs", "op": "=", "value": "x"}}`},
+		{name: "unknown operator", rules: `{"match": {"field": "artist", "op": "~", "value": "x"}}`},
+		{name: "wrong value type", rules: `{"match": {"field": "popularity", "op": ">", "value": "high"}}`},
+		{name: "between needs two values", rules: `{"match": {"field": "popularity", "op": "between", "value": [1]}}`},
+		{name: "limit too large", rules: `{"match": {"field": "artist", "op": "=", "value": "x"}, "limit": 100000}`},
+		{name: "unknown source", rules: `{"match": {"field": "artist", "op": "=", "value": "x"}, "source": "web"}`},
+		{name: "unknown sort field", rules: `{"match": {"field": "artist", "op": "=", "value": "x"}, "sort": {"field": "rand()"}}`},
+		{name: "mixed node", rules: `{"match": {"field": "artist", "op": "=", "value": "x", "all": [{"field": "album", "op": "=", "value": "y"}]}}`},
+		{name: "too deep", rules: `{"match": {"all": [{"all": [{"all": [{"all": [{"all": [{"field": "artist", "op": "=", "value": "x"}]}]}]}]}]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, Validate(parseRules(t, tt.rules)))
+		})
+	}
+}
+
+func TestCompile_ArtistExclusion(t *testing.T) {
+	query, err := Compile(parseRules(t, `{"match": {"field": "artist", "op": "!=", "value": "X"}}`), 7)
+	require.NoError(t, err)
+	assert.Contains(t, query.Where, "s.id NOT IN (")
+	assert.Contains(t, query.Where, "WHERE a.name = ?)")
+	assert.Equal(t, []interface{}{"X", 7}, query.Args)
+}
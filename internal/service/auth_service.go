@@ -103,3 +103,7 @@ func (a *AuthService) InvalidateToken(userID int) error {
 func (a *AuthService) IsTokenValid(token string) (bool, error) {
 	return a.tokenRepo.IsTokenValid(token)
 }
+
+func (a *AuthService) IsAdmin(userId int) (bool, error) {
+	return a.authRepo.IsAdmin(userId)
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"fmt"
+	"music-service/internal/backup"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/repository"
+	"music-service/pkg/utils"
+	"time"
+)
+
+type BackupService struct {
+	repo         repository.Backup
+	authRepo     repository.Authorization
+	playlistRepo repository.PlayList
+	entryRepo    repository.Entry
+	smartRepo    repository.SmartPlaylist
+	quotaRepo    repository.Quota
+	catalog      provider.MusicProvider
+	quota        Quota
+}
+
+func NewBackupService(
+	repo repository.Backup,
+	authRepo repository.Authorization,
+	playlistRepo repository.PlayList,
+	entryRepo repository.Entry,
+	smartRepo repository.SmartPlaylist,
+	quotaRepo repository.Quota,
+	catalog provider.MusicProvider,
+	quota Quota,
+) *BackupService {
+	return &BackupService{
+		repo:         repo,
+		authRepo:     authRepo,
+		playlistRepo: playlistRepo,
+		entryRepo:    entryRepo,
+		smartRepo:    smartRepo,
+		quotaRepo:    quotaRepo,
+		catalog:      catalog,
+		quota:        quota,
+	}
+}
+
+// ExportBackup saves smart playlists with their rules and current tracks, live ones
+// are evaluated for it.
+func (b *BackupService) ExportBackup(userId int) (*backup.Document, error) {
+	user, err := b.authRepo.GetUserByID(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	playlists, err := b.playlistRepo.GetAllPlaylists(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	document := backup.New(backupOrigin(user), time.Now().UTC())
+	for _, playlist := range playlists {
+		entries, err := b.exportEntries(userId, playlist)
+		if err != nil {
+			return nil, err
+		}
+		document.Add(playlist, entries)
+	}
+
+	return document, nil
+}
+
+func (b *BackupService) exportEntries(userId int, playlist *models.Playlist) ([]*models.PlaylistEntry, error) {
+	if playlist.Kind != models.PlaylistKindSmart || playlist.RefreshMode == models.RefreshScheduled {
+		return b.entryRepo.GetPlaylistEntries(userId, playlist.ID)
+	}
+
+	songs, err := b.smartRepo.EvaluateRules(userId, playlist.Rules)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*models.PlaylistEntry, 0, len(songs))
+	for index, song := range songs {
+		entries = append(entries, &models.PlaylistEntry{Position: index + 1, Song: *song})
+	}
+	return entries, nil
+}
+
+// RestoreBackup skips playlists an earlier restore of the document created, so a
+// failed run can be repeated. Only the track ids of the document are trusted, the
+// songs are taken from the catalog. Smart playlists come back with their rules and
+// take their tracks from this library. Who added an entry is kept only when the
+// document was exported by the same account.
+func (b *BackupService) RestoreBackup(userId int, document *backup.Document) (*models.RestoreReport, error) {
+	limits, err := b.quota.GetLimits(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := b.authRepo.GetUserByID(userId)
+	if err != nil {
+		return nil, err
+	}
+	sameAccount := document.Origin == backupOrigin(user)
+
+	restoredBefore, err := b.repo.GetRestoredPlaylists(userId, document.Origin)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkRestoreQuota(userId, document, restoredBefore, limits); err != nil {
+		return nil, err
+	}
+
+	songs, err := b.catalogSongs(document, restoredBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.RestoreReport{Playlists: make([]*models.RestoredPlaylist, 0, len(document.Playlists))}
+
+	for _, source := range document.Playlists {
+		playlist := &models.Playlist{
+			Name:            source.Name,
+			UserId:          userId,
+			DuplicatePolicy: source.DuplicatePolicy,
+			Kind:            source.Kind,
+			Rules:           source.Rules,
+			RefreshMode:     source.RefreshMode,
+		}
+		if playlist.DuplicatePolicy == "" {
+			playlist.DuplicatePolicy = models.DuplicatePolicyAllow
+		}
+		if playlist.Kind == "" {
+			playlist.Kind = models.PlaylistKindManual
+		}
+
+		var entries []*models.PlaylistEntry
+		missing := 0
+		if playlist.Kind == models.PlaylistKindManual {
+			entries, missing = catalogEntries(document.Entries(source), songs)
+		} else if playlist.RefreshMode == "" {
+			playlist.RefreshMode = models.RefreshOnRead
+		}
+		if !sameAccount {
+			for _, entry := range entries {
+				entry.AddedBy = nil
+			}
+		}
+		playlistId, created, err := b.repo.RestoreBackupPlaylist(document.Origin, source.ID, playlist, entries, limits)
+		if err != nil {
+			return nil, err
+		}
+
+		restored := &models.RestoredPlaylist{
+			SourceId:   source.ID,
+			PlaylistId: playlistId,
+			Name:       source.Name,
+			Status:     models.RestoreStatusSkipped,
+		}
+		if created {
+			restored.Status = models.RestoreStatusCreated
+			restored.MissingTracks = missing
+			report.Created++
+		} else {
+			report.Skipped++
+		}
+		report.Playlists = append(report.Playlists, restored)
+	}
+
+	return report, nil
+}
+
+// checkRestoreQuota refuses the whole document up front.
+func (b *BackupService) checkRestoreQuota(userId int, document *backup.Document, restored map[int]bool, limits *models.QuotaLimits) error {
+	adding := 0
+	for _, source := range document.Playlists {
+		if restored[source.ID] {
+			continue
+		}
+		adding++
+		if source.Kind == models.PlaylistKindSmart {
+			continue
+		}
+		if limits.MaxTracksPerPlaylist > 0 && len(source.Entries) > limits.MaxTracksPerPlaylist {
+			return fmt.Errorf("%w: at most %d tracks per playlist allowed", repository.ErrQuotaExceeded, limits.MaxTracksPerPlaylist)
+		}
+	}
+
+	if limits.MaxPlaylists == 0 || adding == 0 {
+		return nil
+	}
+	count, err := b.quotaRepo.CountPlaylists(userId)
+	if err != nil {
+		return err
+	}
+	if count+adding > limits.MaxPlaylists {
+		return fmt.Errorf("%w: at most %d playlists allowed", repository.ErrQuotaExceeded, limits.MaxPlaylists)
+	}
+	return nil
+}
+
+// catalogSongs looks up the tracks of the manual playlists the restore is going to
+// create.
+func (b *BackupService) catalogSongs(document *backup.Document, restored map[int]bool) (map[string]*models.Song, error) {
+	seen := make(map[string]bool)
+	var trackIds []string
+	for _, source := range document.Playlists {
+		if restored[source.ID] || source.Kind == models.PlaylistKindSmart {
+			continue
+		}
+		for _, entry := range source.Entries {
+			if !seen[entry.SongId] && utils.IsSpotifyID(entry.SongId) {
+				seen[entry.SongId] = true
+				trackIds = append(trackIds, entry.SongId)
+			}
+		}
+	}
+	if len(trackIds) == 0 {
+		return map[string]*models.Song{}, nil
+	}
+
+	found, err := b.catalog.GetTracks(trackIds...)
+	if err != nil {
+		return nil, err
+	}
+	songs := make(map[string]*models.Song, len(found))
+	for index, song := range found {
+		if song != nil {
+			songs[trackIds[index]] = song
+		}
+	}
+	return songs, nil
+}
+
+// catalogEntries swaps the songs of the document for the catalog's and leaves out
+// the entries the catalog has no track for, returning how many were left out.
+func catalogEntries(entries []*models.PlaylistEntry, songs map[string]*models.Song) ([]*models.PlaylistEntry, int) {
+	kept := make([]*models.PlaylistEntry, 0, len(entries))
+	for _, entry := range entries {
+		song, ok := songs[entry.ID]
+		if !ok {
+			continue
+		}
+		entry.Song = *song
+		entry.Position = len(kept) + 1
+		kept = append(kept, entry)
+	}
+	return kept, len(entries) - len(kept)
+}
+
+// backupOrigin tells apart accounts sharing an id in different environments.
+func backupOrigin(user *models.User) string {
+	return fmt.Sprintf("%d-%d", user.ID, user.CreatedAt.Unix())
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/backup"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/repository"
+	"testing"
+	"time"
+)
+
+// fakeBackupRepo restores into memory. Playlists in restored are skipped, as they
+// are by the repository.
+type fakeBackupRepo struct {
+	restored  map[int]bool
+	written   []int
+	playlists map[int]*models.Playlist
+	entries   map[int][]*models.PlaylistEntry
+}
+
+func (f *fakeBackupRepo) RestoreBackupPlaylist(origin string, sourceId int, playlist *models.Playlist, entries []*models.PlaylistEntry, limits *models.QuotaLimits) (int64, bool, error) {
+	if f.restored[sourceId] {
+		return int64(sourceId + 100), false, nil
+	}
+	f.written = append(f.written, sourceId)
+	if f.entries == nil {
+		f.playlists = make(map[int]*models.Playlist)
+		f.entries = make(map[int][]*models.PlaylistEntry)
+	}
+	f.playlists[sourceId] = playlist
+	f.entries[sourceId] = entries
+	return int64(sourceId + 100), true, nil
+}
+
+func (f *fakeBackupRepo) GetRestoredPlaylists(userId int, origin string) (map[int]bool, error) {
+	return f.restored, nil
+}
+
+// fakeQuotaRepo only counts playlists.
+type fakeQuotaRepo struct {
+	repository.Quota
+	playlists int
+}
+
+func (f *fakeQuotaRepo) CountPlaylists(userId int) (int, error) {
+	return f.playlists, nil
+}
+
+// fakeAuthRepo only knows the restoring user.
+type fakeAuthRepo struct {
+	repository.Authorization
+	user models.User
+}
+
+func (f *fakeAuthRepo) GetUserByID(id int) (*models.User, error) {
+	return &f.user, nil
+}
+
+func TestBackupService_RestoreBackupQuota(t *testing.T) {
+	document := backup.New("3-1790000000", time.Now())
+	document.Add(&models.Playlist{ID: 1, Name: "Road trip"}, []*models.PlaylistEntry{
+		{Song: models.Song{ID: fakeTrackId(0)}}, {Song: models.Song{ID: fakeTrackId(1)}}, {Song: models.Song{ID: fakeTrackId(2)}},
+	})
+	document.Add(&models.Playlist{ID: 2, Name: "Focus"}, []*models.PlaylistEntry{{Song: models.Song{ID: fakeTrackId(0)}}})
+	catalog := provider.NewFake(
+		models.Song{ID: fakeTrackId(0)}, models.Song{ID: fakeTrackId(1)}, models.Song{ID: fakeTrackId(2)},
+	)
+
+	tests := []struct {
+		name            string
+		restored        map[int]bool
+		limits          models.QuotaLimits
+		expectedWritten []int
+		expectedError   string
+	}{
+		{
+			name:            "both playlists fit",
+			limits:          models.QuotaLimits{MaxPlaylists: 3, MaxTracksPerPlaylist: 3},
+			expectedWritten: []int{1, 2},
+		},
+		{
+			name:          "one playlist too many",
+			limits:        models.QuotaLimits{MaxPlaylists: 2},
+			expectedError: "quota exceeded: at most 2 playlists allowed",
+		},
+		{
+			name:            "playlists restored earlier don't count",
+			restored:        map[int]bool{1: true},
+			limits:          models.QuotaLimits{MaxPlaylists: 2, MaxTracksPerPlaylist: 1},
+			expectedWritten: []int{2},
+		},
+		{
+			name:          "playlist over the track limit",
+			limits:        models.QuotaLimits{MaxTracksPerPlaylist: 2},
+			expectedError: "quota exceeded: at most 2 tracks per playlist allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBackupRepo{restored: tt.restored}
+			service := NewBackupService(repo, &fakeAuthRepo{}, nil, nil, nil, &fakeQuotaRepo{playlists: 1}, catalog, &fakeQuota{limits: tt.limits})
+
+			report, err := service.RestoreBackup(1, document)
+			if tt.expectedError != "" {
+				assert.ErrorIs(t, err, repository.ErrQuotaExceeded)
+				assert.EqualError(t, err, tt.expectedError)
+				assert.Empty(t, repo.written, "nothing is written when the document doesn't fit")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedWritten, repo.written)
+			assert.Len(t, report.Playlists, 2)
+		})
+	}
+}
+
+func TestBackupService_RestoreBackupTakesSongsFromCatalog(t *testing.T) {
+	document := backup.New("3-1790000000", time.Now())
+	document.Add(&models.Playlist{ID: 1, Name: "Road trip"}, []*models.PlaylistEntry{
+		{Song: models.Song{ID: fakeTrackId(0), Title: "Renamed by the uploader", PreviewURL: "https://example.com/x.mp3"}},
+		{Song: models.Song{ID: fakeTrackId(1), Title: "Made up"}},
+		{Song: models.Song{ID: "not-a-track", Title: "Local file"}},
+		{Song: models.Song{ID: fakeTrackId(2)}},
+	})
+	catalog := provider.NewFake(models.Song{ID: fakeTrackId(0), Title: "Teardrop"}, models.Song{ID: fakeTrackId(2), Title: "Angel"})
+
+	repo := &fakeBackupRepo{}
+	service := NewBackupService(repo, &fakeAuthRepo{}, nil, nil, nil, &fakeQuotaRepo{}, catalog, &fakeQuota{})
+
+	report, err := service.RestoreBackup(1, document)
+	require.NoError(t, err)
+
+	require.Len(t, repo.entries[1], 2)
+	assert.Equal(t, models.Song{ID: fakeTrackId(0), Title: "Teardrop"}, repo.entries[1][0].Song)
+	assert.Equal(t, 1, repo.entries[1][0].Position)
+	assert.Equal(t, "Angel", repo.entries[1][1].Title)
+	assert.Equal(t, 2, repo.entries[1][1].Position)
+	assert.Equal(t, 2, report.Playlists[0].MissingTracks)
+}
+
+func TestBackupService_RestoreBackupSmartPlaylist(t *testing.T) {
+	rules := &models.SmartRules{Match: models.RuleNode{Field: "popularity", Op: ">", Value: []byte(`60`)}}
+	document := backup.New("3-1790000000", time.Now())
+	document.Add(&models.Playlist{ID: 1, Name: "Hits", Kind: models.PlaylistKindSmart, Rules: rules},
+		[]*models.PlaylistEntry{{Song: models.Song{ID: fakeTrackId(0)}}})
+
+	repo := &fakeBackupRepo{}
+	service := NewBackupService(repo, &fakeAuthRepo{}, nil, nil, nil, &fakeQuotaRepo{}, provider.NewFake(), &fakeQuota{})
+
+	report, err := service.RestoreBackup(1, document)
+	require.NoError(t, err)
+
+	restored := repo.playlists[1]
+	require.NotNil(t, restored)
+	assert.Equal(t, models.PlaylistKindSmart, restored.Kind)
+	assert.Equal(t, rules, restored.Rules)
+	assert.Equal(t, models.RefreshOnRead, restored.RefreshMode)
+	assert.Empty(t, repo.entries[1], "smart playlists are rebuilt from their rules")
+	assert.Zero(t, report.Playlists[0].MissingTracks)
+}
+
+func TestBackupService_RestoreBackupAddedBy(t *testing.T) {
+	createdAt := time.Unix(1790000000, 0)
+	addedBy := 3
+	catalog := provider.NewFake(models.Song{ID: fakeTrackId(0)})
+
+	tests := []struct {
+		name     string
+		user     models.User
+		expected *int
+	}{
+		{name: "same account keeps added_by", user: models.User{ID: 3, CreatedAt: createdAt}, expected: &addedBy},
+		{name: "other account drops added_by", user: models.User{ID: 9, CreatedAt: createdAt}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := backup.New("3-1790000000", time.Now())
+			document.Add(&models.Playlist{ID: 1, Name: "Road trip"},
+				[]*models.PlaylistEntry{{AddedBy: &addedBy, Song: models.Song{ID: fakeTrackId(0)}}})
+
+			repo := &fakeBackupRepo{}
+			service := NewBackupService(repo, &fakeAuthRepo{user: tt.user}, nil, nil, nil, &fakeQuotaRepo{}, catalog, &fakeQuota{})
+
+			_, err := service.RestoreBackup(tt.user.ID, document)
+			require.NoError(t, err)
+
+			require.Len(t, repo.entries[1], 1)
+			assert.Equal(t, tt.expected, repo.entries[1][0].AddedBy)
+		})
+	}
+}
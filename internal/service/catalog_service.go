@@ -0,0 +1,22 @@
+package service
+
+import (
+	"music-service/internal/models"
+	"music-service/internal/provider"
+)
+
+type CatalogService struct {
+	catalog provider.MusicProvider
+}
+
+func NewCatalogService(catalog provider.MusicProvider) *CatalogService {
+	return &CatalogService{
+		catalog: catalog,
+	}
+}
+
+// Search runs one catalog search for all requested types. Limit and offset apply to
+// each type on its own, as they do on Spotify.
+func (c *CatalogService) Search(query *models.SearchQuery) (*models.SearchResult, error) {
+	return c.catalog.Search(query)
+}
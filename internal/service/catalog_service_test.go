@@ -0,0 +1,112 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"net/http"
+	"testing"
+)
+
+func TestCatalogService_Search(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/search", func(writer http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+		assert.Equal(t, "teardrop", query.Get("q"))
+		assert.Equal(t, "10", query.Get("limit"))
+		assert.Equal(t, "20", query.Get("offset"))
+
+		result := map[string]any{}
+		switch query.Get("type") {
+		case "track":
+			assert.Empty(t, query.Get("market"))
+			result["tracks"] = map[string]any{
+				"items": []map[string]any{fakeTrack("67Hna13dNDkZvBpTXRIaOJ", "Teardrop", "Massive Attack")},
+				"total": 31, "limit": 10, "offset": 20,
+			}
+		case "album,artist":
+			assert.Equal(t, "GB", query.Get("market"))
+			result["albums"] = map[string]any{
+				"items": []map[string]any{fakeAlbum("49MNmJhZQewjt06rpwp6QR", "Mezzanine", "Massive Attack")},
+				"total": 21, "limit": 10, "offset": 20,
+			}
+			result["artists"] = map[string]any{
+				"items": []map[string]any{fakeArtist("6FXMGgJwohJLUSr5nVlf9X", "Massive Attack", "trip hop")},
+				"total": 1, "limit": 10, "offset": 20,
+			}
+		default:
+			writeFakeError(writer, http.StatusBadRequest, "unexpected type "+query.Get("type"))
+			return
+		}
+		writeFakeJSON(writer, http.StatusOK, result)
+	})
+
+	service := NewCatalogService(provider.NewSpotify(fake.client()))
+
+	tracks, err := service.Search(&models.SearchQuery{Query: "teardrop", Types: []string{models.SearchTypeTrack}, Limit: 10, Offset: 20})
+	require.NoError(t, err)
+	assert.Nil(t, tracks.Albums)
+	assert.Nil(t, tracks.Artists)
+	require.NotNil(t, tracks.Tracks)
+	assert.Equal(t, 31, tracks.Tracks.Total)
+	assert.Equal(t, 10, tracks.Tracks.Limit)
+	assert.Equal(t, 20, tracks.Tracks.Offset)
+	assert.Equal(t, []models.Song{{
+		ID:          "67Hna13dNDkZvBpTXRIaOJ",
+		Title:       "Teardrop",
+		Artist:      "Massive Attack",
+		Album:       "Album Teardrop",
+		AlbumCover:  "https://i.scdn.co/67Hna13dNDkZvBpTXRIaOJ",
+		Duration:    180,
+		Popularity:  50,
+		ExternalURL: "https://open.spotify.com/track/67Hna13dNDkZvBpTXRIaOJ",
+	}}, tracks.Tracks.Items)
+
+	result, err := service.Search(&models.SearchQuery{
+		Query:  "teardrop",
+		Types:  []string{models.SearchTypeArtist, models.SearchTypeAlbum},
+		Limit:  10,
+		Offset: 20,
+		Market: "GB",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, result.Tracks)
+	require.NotNil(t, result.Albums)
+	assert.Equal(t, &models.AlbumPage{
+		Items: []models.Album{{
+			ID:          "49MNmJhZQewjt06rpwp6QR",
+			Name:        "Mezzanine",
+			Artist:      "Massive Attack",
+			AlbumType:   "album",
+			AlbumCover:  "https://i.scdn.co/49MNmJhZQewjt06rpwp6QR",
+			ReleaseDate: "1998-04-20",
+			ExternalURL: "https://open.spotify.com/album/49MNmJhZQewjt06rpwp6QR",
+		}},
+		Total: 21, Limit: 10, Offset: 20,
+	}, result.Albums)
+	assert.Equal(t, &models.ArtistPage{
+		Items: []models.Artist{{
+			ID:          "6FXMGgJwohJLUSr5nVlf9X",
+			Name:        "Massive Attack",
+			Genres:      []string{"trip hop"},
+			Popularity:  70,
+			Image:       "https://i.scdn.co/6FXMGgJwohJLUSr5nVlf9X",
+			ExternalURL: "https://open.spotify.com/artist/6FXMGgJwohJLUSr5nVlf9X",
+		}},
+		Total: 1, Limit: 10, Offset: 20,
+	}, result.Artists)
+}
+
+func TestCatalogService_SearchFails(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/search", func(writer http.ResponseWriter, request *http.Request) {
+		writeFakeError(writer, http.StatusServiceUnavailable, "service unavailable")
+	})
+
+	service := NewCatalogService(provider.NewSpotify(fake.client()))
+
+	_, err := service.Search(&models.SearchQuery{Query: "teardrop", Types: []string{models.SearchTypeTrack}, Limit: 20})
+	assert.ErrorIs(t, err, provider.ErrUnavailable)
+	assert.EqualError(t, err, "catalog is unavailable: service unavailable")
+}
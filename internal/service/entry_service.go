@@ -0,0 +1,43 @@
+package service
+
+import (
+	"music-service/internal/models"
+	"music-service/internal/repository"
+	"strings"
+)
+
+type EntryService struct {
+	repo repository.Entry
+}
+
+func NewEntryService(
+	repo repository.Entry,
+) *EntryService {
+	return &EntryService{
+		repo: repo,
+	}
+}
+
+func (e *EntryService) GetPlaylistEntries(userId, playlistId int) ([]*models.PlaylistEntry, error) {
+	return e.repo.GetPlaylistEntries(userId, playlistId)
+}
+
+func (e *EntryService) MovePlaylistEntry(change *models.PlaylistChange, entryId, position int) error {
+	return e.repo.MovePlaylistEntry(change, entryId, position)
+}
+
+func (e *EntryService) DeletePlaylistEntry(change *models.PlaylistChange, entryId int) error {
+	return e.repo.DeletePlaylistEntry(change, entryId)
+}
+
+func (e *EntryService) UpdateEntryNote(change *models.PlaylistChange, entryId int, note string) error {
+	return e.repo.UpdateEntryNote(change, entryId, strings.TrimSpace(note))
+}
+
+func (e *EntryService) GetDuplicateEntries(userId, playlistId int) ([]*models.DuplicateGroup, error) {
+	return e.repo.GetDuplicateEntries(userId, playlistId)
+}
+
+func (e *EntryService) CollapseDuplicateEntries(change *models.PlaylistChange) (int64, error) {
+	return e.repo.CollapseDuplicateEntries(change)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"music-service/internal/models"
+	"music-service/internal/repository"
+)
+
+// featuresBatchSize is how many songs one provider call analyses.
+const featuresBatchSize = 100
+
+// AudioFeatureProvider leaves out tracks it has no analysis for.
+type AudioFeatureProvider interface {
+	GetAudioFeatures(trackIds ...string) ([]models.AudioFeatures, error)
+}
+
+type FeatureService struct {
+	repo     repository.AudioFeatures
+	provider AudioFeatureProvider
+}
+
+func NewFeatureService(repo repository.AudioFeatures, provider AudioFeatureProvider) *FeatureService {
+	return &FeatureService{
+		repo:     repo,
+		provider: provider,
+	}
+}
+
+// StoreAudioFeatures stores tracks without an analysis as unavailable.
+func (f *FeatureService) StoreAudioFeatures(trackIds ...string) error {
+	if len(trackIds) == 0 {
+		return nil
+	}
+
+	features, err := f.provider.GetAudioFeatures(trackIds...)
+	if err != nil {
+		return err
+	}
+
+	analysed := make(map[string]bool, len(features))
+	for _, feature := range features {
+		analysed[feature.SongId] = true
+	}
+	var unavailable []string
+	for _, trackId := range trackIds {
+		if !analysed[trackId] {
+			unavailable = append(unavailable, trackId)
+		}
+	}
+
+	return f.repo.SaveAudioFeatures(features, unavailable)
+}
+
+// BackfillAudioFeatures analyses the songs stored without features batch by batch
+// and returns how many it went through.
+func (f *FeatureService) BackfillAudioFeatures() (int, error) {
+	stored := 0
+	for {
+		trackIds, err := f.repo.GetSongsWithoutAudioFeatures(featuresBatchSize)
+		if err != nil {
+			return stored, err
+		}
+
+		if err := f.StoreAudioFeatures(trackIds...); err != nil {
+			return stored, err
+		}
+		stored += len(trackIds)
+
+		if len(trackIds) < featuresBatchSize {
+			return stored, nil
+		}
+	}
+}
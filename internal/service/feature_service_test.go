@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"testing"
+)
+
+// fakeFeatureProvider analyses every track except the unknown ones, deriving the
+// features from the track id so repeated runs agree.
+type fakeFeatureProvider struct {
+	unknown map[string]bool
+	err     error
+	calls   [][]string
+}
+
+func fakeFeatures(trackId string) models.AudioFeatures {
+	n := float64(len(trackId))
+	return models.AudioFeatures{
+		SongId:       trackId,
+		Tempo:        100 + n,
+		Energy:       n / 10,
+		Danceability: n / 20,
+		Valence:      0.5,
+		Key:          len(trackId) % 12,
+		Mode:         len(trackId) % 2,
+		Loudness:     -n,
+	}
+}
+
+func (p *fakeFeatureProvider) GetAudioFeatures(trackIds ...string) ([]models.AudioFeatures, error) {
+	p.calls = append(p.calls, trackIds)
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	var features []models.AudioFeatures
+	for _, trackId := range trackIds {
+		if !p.unknown[trackId] {
+			features = append(features, fakeFeatures(trackId))
+		}
+	}
+	return features, nil
+}
+
+type fakeFeatureRepo struct {
+	pending     []string
+	limit       int
+	saved       []models.AudioFeatures
+	unavailable []string
+}
+
+func (r *fakeFeatureRepo) SaveAudioFeatures(features []models.AudioFeatures, unavailable []string) error {
+	r.saved = append(r.saved, features...)
+	r.unavailable = append(r.unavailable, unavailable...)
+	return nil
+}
+
+// GetSongsWithoutAudioFeatures hands out the pending songs a batch at a time, as
+// if the previous batch had been saved.
+func (r *fakeFeatureRepo) GetSongsWithoutAudioFeatures(limit int) ([]string, error) {
+	r.limit = limit
+	batch := r.pending[:min(limit, len(r.pending))]
+	r.pending = r.pending[len(batch):]
+	return batch, nil
+}
+
+func TestFeatureService_BackfillAudioFeatures(t *testing.T) {
+	repo := &fakeFeatureRepo{pending: []string{"track1", "track22", "gone"}}
+	provider := &fakeFeatureProvider{unknown: map[string]bool{"gone": true}}
+
+	stored, err := NewFeatureService(repo, provider).BackfillAudioFeatures()
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, stored)
+	assert.Equal(t, featuresBatchSize, repo.limit)
+	assert.Equal(t, [][]string{{"track1", "track22", "gone"}}, provider.calls)
+	assert.Equal(t, []models.AudioFeatures{fakeFeatures("track1"), fakeFeatures("track22")}, repo.saved)
+	assert.Equal(t, []string{"gone"}, repo.unavailable)
+}
+
+func TestFeatureService_BackfillNothingPending(t *testing.T) {
+	provider := &fakeFeatureProvider{}
+
+	stored, err := NewFeatureService(&fakeFeatureRepo{}, provider).BackfillAudioFeatures()
+	require.NoError(t, err)
+
+	assert.Zero(t, stored)
+	assert.Empty(t, provider.calls)
+}
+
+func TestFeatureService_BackfillAllBatches(t *testing.T) {
+	pending := make([]string, featuresBatchSize+20)
+	for i := range pending {
+		pending[i] = fmt.Sprintf("track%d", i)
+	}
+	repo := &fakeFeatureRepo{pending: pending}
+	provider := &fakeFeatureProvider{}
+
+	stored, err := NewFeatureService(repo, provider).BackfillAudioFeatures()
+	require.NoError(t, err)
+
+	assert.Equal(t, len(pending), stored)
+	assert.Len(t, provider.calls, 2)
+	assert.Len(t, repo.saved, len(pending))
+}
+
+func TestFeatureService_BackfillProviderDown(t *testing.T) {
+	repo := &fakeFeatureRepo{pending: []string{"track1"}}
+	provider := &fakeFeatureProvider{err: errors.New("spotify is down")}
+
+	stored, err := NewFeatureService(repo, provider).BackfillAudioFeatures()
+
+	assert.EqualError(t, err, "spotify is down")
+	assert.Zero(t, stored)
+}
+
+func TestFeatureService_StoreAudioFeaturesProviderDown(t *testing.T) {
+	repo := &fakeFeatureRepo{}
+	provider := &fakeFeatureProvider{err: errors.New("spotify is down")}
+
+	err := NewFeatureService(repo, provider).StoreAudioFeatures("track1")
+
+	assert.EqualError(t, err, "spotify is down")
+	assert.Empty(t, repo.saved)
+	assert.Empty(t, repo.unavailable)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"music-service/internal/models"
+	"music-service/internal/repository"
+)
+
+type FolderService struct {
+	repo      repository.Folder
+	playlists repository.PlayList
+}
+
+func NewFolderService(
+	repo repository.Folder,
+	playlists repository.PlayList,
+) *FolderService {
+	return &FolderService{
+		repo:      repo,
+		playlists: playlists,
+	}
+}
+
+func (f *FolderService) CreateFolder(folder *models.Folder) (int64, error) {
+	return f.repo.CreateFolder(folder)
+}
+
+func (f *FolderService) RenameFolder(userId, folderId int, name string) error {
+	return f.repo.RenameFolder(userId, folderId, name)
+}
+
+func (f *FolderService) MoveFolder(userId, folderId int, parentId *int) error {
+	return f.repo.MoveFolder(userId, folderId, parentId)
+}
+
+func (f *FolderService) DeleteFolder(userId, folderId int, mode string) error {
+	if mode == "" {
+		mode = models.FolderDeleteMoveUp
+	}
+	return f.repo.DeleteFolder(userId, folderId, mode)
+}
+
+func (f *FolderService) MovePlaylist(userId, playlistId int, folderId *int) error {
+	return f.repo.MovePlaylist(userId, playlistId, folderId)
+}
+
+// GetPlaylistTree nests folders under their parents and places every playlist
+// into its folder. Both lists come from the repository already ordered by position.
+func (f *FolderService) GetPlaylistTree(userId int) (*models.PlaylistTree, error) {
+	folders, err := f.repo.GetFolders(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	playlists, err := f.playlists.GetAllPlaylists(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &models.PlaylistTree{
+		Folders:   []*models.Folder{},
+		Playlists: []*models.Playlist{},
+	}
+
+	byId := make(map[int]*models.Folder, len(folders))
+	for _, folder := range folders {
+		byId[folder.ID] = folder
+	}
+
+	for _, folder := range folders {
+		if parent, ok := byId[derefId(folder.ParentId)]; ok {
+			parent.Folders = append(parent.Folders, folder)
+			continue
+		}
+		tree.Folders = append(tree.Folders, folder)
+	}
+
+	for _, playlist := range playlists {
+		if folder, ok := byId[derefId(playlist.FolderId)]; ok {
+			folder.Playlists = append(folder.Playlists, playlist)
+			continue
+		}
+		tree.Playlists = append(tree.Playlists, playlist)
+	}
+
+	return tree, nil
+}
+
+func derefId(id *int) int {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
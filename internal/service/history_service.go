@@ -0,0 +1,38 @@
+package service
+
+import (
+	"music-service/internal/models"
+	"music-service/internal/repository"
+)
+
+type HistoryService struct {
+	repo  repository.History
+	quota Quota
+}
+
+func NewHistoryService(
+	repo repository.History,
+	quota Quota,
+) *HistoryService {
+	return &HistoryService{
+		repo:  repo,
+		quota: quota,
+	}
+}
+
+func (h *HistoryService) GetHistory(userId, playlistId int) ([]*models.PlaylistSnapshot, error) {
+	return h.repo.GetHistory(userId, playlistId)
+}
+
+func (h *HistoryService) GetSnapshot(userId, playlistId, snapshotId int) (*models.PlaylistSnapshot, error) {
+	return h.repo.GetSnapshot(userId, playlistId, snapshotId)
+}
+
+func (h *HistoryService) RevertToSnapshot(change *models.PlaylistChange, snapshotId int) error {
+	limits, err := h.quota.GetLimits(change.UserId)
+	if err != nil {
+		return err
+	}
+	change.Limits = limits
+	return h.repo.RevertToSnapshot(change, snapshotId)
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/repository"
+	"music-service/internal/tracklist"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Playlists fitting into one Spotify page are imported while the request waits.
+const (
+	spotifyPageSize     = 100
+	maxJobErrorLength   = 500
+	tracklistCandidates = 3
+)
+
+var (
+	errImportStopped = errors.New("import stopped by server shutdown")
+	errJobNotSaved   = errors.New("import job not saved")
+)
+
+// ImportService stops background imports once ctx is done.
+type ImportService struct {
+	ctx     context.Context
+	repo    repository.Import
+	catalog provider.MusicProvider
+	quota   Quota
+	running sync.WaitGroup
+}
+
+func NewImportService(
+	ctx context.Context,
+	repo repository.Import,
+	catalog provider.MusicProvider,
+	quota Quota,
+) *ImportService {
+	return &ImportService{
+		ctx:     ctx,
+		repo:    repo,
+		catalog: catalog,
+		quota:   quota,
+	}
+}
+
+// MatchItems returns the matched songs in order and the items nothing was found for.
+func (i *ImportService) MatchItems(items []*models.ImportItem) ([]models.Song, []*models.ImportItem, error) {
+	songs := make([]models.Song, 0, len(items))
+	unmatched := make([]*models.ImportItem, 0)
+
+	for _, item := range items {
+		if item.Error != "" {
+			unmatched = append(unmatched, item)
+			continue
+		}
+
+		song, err := i.matchItem(item)
+		if err != nil {
+			return nil, nil, err
+		}
+		if song == nil {
+			unmatched = append(unmatched, item)
+			continue
+		}
+		songs = append(songs, *song)
+	}
+
+	return songs, unmatched, nil
+}
+
+// PreviewTracklist retries lines the filtered search misses with a plain search.
+func (i *ImportService) PreviewTracklist(lines []*models.TracklistLine) ([]*models.TracklistLine, error) {
+	for _, line := range lines {
+		if line.Error != "" {
+			continue
+		}
+
+		songs, err := i.searchTracks(searchQuery(&models.ImportItem{Title: line.Title, Artist: line.Artist}), tracklistCandidates)
+		if err != nil {
+			return nil, err
+		}
+		if len(songs) == 0 {
+			songs, err = i.searchTracks(strings.TrimSpace(line.Artist+" "+line.Title), tracklistCandidates)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, song := range songs {
+			line.Candidates = append(line.Candidates, &models.TrackCandidate{
+				Confidence: tracklist.Confidence(line.Artist, line.Title, song),
+				Song:       song,
+			})
+		}
+		sort.SliceStable(line.Candidates, func(a, b int) bool {
+			return line.Candidates[a].Confidence > line.Candidates[b].Confidence
+		})
+	}
+
+	return lines, nil
+}
+
+func (i *ImportService) ImportPlaylist(playlist *models.Playlist) (int64, error) {
+	limits, err := i.quota.GetLimits(playlist.UserId)
+	if err != nil {
+		return 0, err
+	}
+	return i.repo.ImportPlaylist(playlist, limits)
+}
+
+func (i *ImportService) GetSpotifyPlaylist(playlistId string) (*models.RemotePlaylist, error) {
+	return i.catalog.GetPlaylist(playlistId)
+}
+
+// StartSpotifyImport finishes small playlists before it returns, larger ones run in
+// the background.
+func (i *ImportService) StartSpotifyImport(userId int, playlist *models.RemotePlaylist, name string) (*models.ImportJob, error) {
+	if name == "" {
+		name = playlist.Name
+	}
+
+	// Fails early, the write checks the quota again.
+	limits, err := i.quota.GetLimits(userId)
+	if err != nil {
+		return nil, err
+	}
+	if limits.MaxTracksPerPlaylist > 0 && playlist.Total > limits.MaxTracksPerPlaylist {
+		return nil, fmt.Errorf("%w: at most %d tracks per playlist allowed", repository.ErrQuotaExceeded, limits.MaxTracksPerPlaylist)
+	}
+
+	job := &models.ImportJob{
+		UserId: userId,
+		Source: playlist.ID,
+		Status: models.ImportJobRunning,
+		Total:  playlist.Total,
+	}
+
+	id, err := i.repo.CreateImportJob(job)
+	if err != nil {
+		return nil, err
+	}
+	job.ID = id
+
+	// Other failures are reported through the job.
+	if job.Total <= spotifyPageSize {
+		err := i.runSpotifyImport(job, playlist.ID, name)
+		if errors.Is(err, repository.ErrQuotaExceeded) || errors.Is(err, errJobNotSaved) {
+			return nil, err
+		}
+		return job, nil
+	}
+
+	started := *job
+	i.running.Add(1)
+	go func() {
+		defer i.running.Done()
+		_ = i.runSpotifyImport(job, playlist.ID, name)
+	}()
+	return &started, nil
+}
+
+// WaitForImports blocks until the background imports have finished their jobs.
+func (i *ImportService) WaitForImports() {
+	i.running.Wait()
+}
+
+func (i *ImportService) GetImportJob(userId, jobId int) (*models.ImportJob, error) {
+	return i.repo.GetImportJob(userId, jobId)
+}
+
+// runSpotifyImport records the outcome on the job, a panic included.
+func (i *ImportService) runSpotifyImport(job *models.ImportJob, playlistId string, name string) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("import failed: %v", recovered)
+		}
+		err = errors.Join(err, i.finishImportJob(job, err))
+	}()
+
+	songs, err := i.fetchSpotifyTracks(job, playlistId)
+	if err != nil {
+		return err
+	}
+
+	importedId, err := i.ImportPlaylist(&models.Playlist{
+		Name:            name,
+		UserId:          job.UserId,
+		DuplicatePolicy: models.DuplicatePolicyAllow,
+		Songs:           songs,
+	})
+	if err != nil {
+		return err
+	}
+	job.PlaylistId = &importedId
+	return nil
+}
+
+func (i *ImportService) finishImportJob(job *models.ImportJob, failure error) error {
+	job.Status = models.ImportJobDone
+	if failure != nil {
+		job.Status = models.ImportJobFailed
+		job.Error = failure.Error()
+		if len(job.Error) > maxJobErrorLength {
+			job.Error = job.Error[:maxJobErrorLength]
+		}
+	}
+
+	if err := i.repo.UpdateImportJob(job); err != nil {
+		return fmt.Errorf("%w: %w", errJobNotSaved, err)
+	}
+	return nil
+}
+
+// fetchSpotifyTracks skips local files and saves the progress after every page.
+func (i *ImportService) fetchSpotifyTracks(job *models.ImportJob, playlistId string) ([]models.Song, error) {
+	songs := make([]models.Song, 0, job.Total)
+	for offset := 0; ; offset += spotifyPageSize {
+		page, err := i.catalog.GetPlaylistTracks(playlistId, offset, spotifyPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, song := range page.Items {
+			job.Processed++
+			if song == nil {
+				job.Skipped++
+				continue
+			}
+			songs = append(songs, *song)
+		}
+		if err := i.repo.UpdateImportJob(job); err != nil {
+			return nil, fmt.Errorf("%w: %w", errJobNotSaved, err)
+		}
+		if i.ctx.Err() != nil {
+			return nil, errImportStopped
+		}
+
+		if len(page.Items) == 0 || offset+len(page.Items) >= page.Total {
+			return songs, nil
+		}
+	}
+}
+
+// matchItem returns nil without an error when nothing matches.
+func (i *ImportService) matchItem(item *models.ImportItem) (*models.Song, error) {
+	if item.SpotifyId != "" {
+		song, err := i.catalog.GetTrack(item.SpotifyId)
+		if err == nil {
+			return song, nil
+		}
+		if !errors.Is(err, provider.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	query := searchQuery(item)
+	if query == "" {
+		return nil, nil
+	}
+
+	songs, err := i.searchTracks(query, 1)
+	if err != nil || len(songs) == 0 {
+		return nil, err
+	}
+	return &songs[0], nil
+}
+
+func (i *ImportService) searchTracks(query string, limit int) ([]models.Song, error) {
+	result, err := i.catalog.Search(&models.SearchQuery{
+		Query: query,
+		Types: []string{models.SearchTypeTrack},
+		Limit: limit,
+	})
+	if err != nil || result.Tracks == nil {
+		return nil, err
+	}
+	return result.Tracks.Items, nil
+}
+
+// searchQuery drops quotes, they would end the field filter early.
+func searchQuery(item *models.ImportItem) string {
+	title := strings.TrimSpace(strings.ReplaceAll(item.Title, `"`, ""))
+	if title == "" {
+		return ""
+	}
+
+	query := `track:"` + title + `"`
+	if artist := strings.TrimSpace(strings.ReplaceAll(item.Artist, `"`, "")); artist != "" {
+		query += ` artist:"` + artist + `"`
+	}
+	return query
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/repository"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeImportRepo keeps jobs and imported playlists in memory. It is shared with the
+// background import, hence the lock.
+type fakeImportRepo struct {
+	mu        sync.Mutex
+	jobs      map[int64]models.ImportJob
+	playlists []*models.Playlist
+	panics    bool
+	updateErr error
+}
+
+func newFakeImportRepo() *fakeImportRepo {
+	return &fakeImportRepo{jobs: make(map[int64]models.ImportJob)}
+}
+
+func (f *fakeImportRepo) ImportPlaylist(playlist *models.Playlist, limits *models.QuotaLimits) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.panics {
+		panic("playlist lost")
+	}
+	f.playlists = append(f.playlists, playlist)
+	return int64(len(f.playlists)), nil
+}
+
+func (f *fakeImportRepo) CreateImportJob(job *models.ImportJob) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := int64(len(f.jobs) + 1)
+	stored := *job
+	stored.ID = id
+	f.jobs[id] = stored
+	return id, nil
+}
+
+func (f *fakeImportRepo) UpdateImportJob(job *models.ImportJob) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.updateErr != nil {
+		return f.updateErr
+	}
+	f.jobs[job.ID] = *job
+	return nil
+}
+
+func (f *fakeImportRepo) GetImportJob(userId, jobId int) (*models.ImportJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job, ok := f.jobs[int64(jobId)]
+	if !ok || job.UserId != userId {
+		return nil, errors.New("import job not found")
+	}
+	return &job, nil
+}
+
+// fakeQuota hands out the same limits to every user.
+type fakeQuota struct {
+	limits models.QuotaLimits
+}
+
+func (f *fakeQuota) GetLimits(userId int) (*models.QuotaLimits, error) {
+	limits := f.limits
+	return &limits, nil
+}
+
+func (f *fakeQuota) SetQuotaOverride(userId int, override *models.QuotaOverride) error {
+	return nil
+}
+
+func (f *fakeQuota) GetUsage(userId int) (*models.Usage, error) {
+	return &models.Usage{}, nil
+}
+
+func (f *fakeQuota) CountRequest(userId int) error {
+	return nil
+}
+
+func (f *fakeQuota) PurgeRequestCounts() (int64, error) {
+	return 0, nil
+}
+
+func TestImportService_MatchItems(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/tracks/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		if request.PathValue("id") != "4uLU6hMCjMI75M1A2tKUQC" {
+			writeFakeError(writer, http.StatusNotFound, "non existing id")
+			return
+		}
+		writeFakeJSON(writer, http.StatusOK, fakeTrack("4uLU6hMCjMI75M1A2tKUQC", "Song A", "Artist X"))
+	})
+	fake.handle("GET /v1/search", func(writer http.ResponseWriter, request *http.Request) {
+		var items []map[string]any
+		if request.URL.Query().Get("q") == `track:"Song B" artist:"Artist Y"` {
+			items = append(items, fakeTrack("6rqhFgbbKwnb9MLmUQDhG6", "Song B", "Artist Y"))
+		}
+		writeFakeJSON(writer, http.StatusOK, map[string]any{"tracks": map[string]any{"items": items, "total": len(items)}})
+	})
+
+	service := NewImportService(context.Background(), newFakeImportRepo(), provider.NewSpotify(fake.client()), &fakeQuota{})
+
+	items := []*models.ImportItem{
+		{Title: "Song A", SpotifyId: "4uLU6hMCjMI75M1A2tKUQC"},
+		{Title: "Song B", Artist: "Artist Y", SpotifyId: "0000000000000000000000"},
+		{Title: "Song C", Artist: "Artist Z"},
+		{Row: 5, Error: "row has neither a Spotify id nor a title"},
+	}
+
+	songs, unmatched, err := service.MatchItems(items)
+	require.NoError(t, err)
+
+	require.Len(t, songs, 2)
+	assert.Equal(t, "4uLU6hMCjMI75M1A2tKUQC", songs[0].ID)
+	assert.Equal(t, "Artist X", songs[0].Artist)
+	assert.Equal(t, 180, songs[0].Duration)
+	assert.Equal(t, "6rqhFgbbKwnb9MLmUQDhG6", songs[1].ID, "unknown id falls back to search")
+	assert.Equal(t, []*models.ImportItem{items[2], items[3]}, unmatched)
+}
+
+func TestImportService_MatchItemsCatalogDown(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/search", func(writer http.ResponseWriter, request *http.Request) {
+		writeFakeError(writer, http.StatusInternalServerError, "server error")
+	})
+
+	service := NewImportService(context.Background(), newFakeImportRepo(), provider.NewSpotify(fake.client()), &fakeQuota{})
+
+	_, _, err := service.MatchItems([]*models.ImportItem{{Title: "Song A"}})
+	assert.ErrorIs(t, err, provider.ErrUnavailable)
+	assert.EqualError(t, err, "catalog is unavailable: server error")
+}
+
+func TestImportService_StartSpotifyImport(t *testing.T) {
+	const playlistId = "37i9dQZF1DXcBWIGoYBM5M"
+
+	tests := []struct {
+		name           string
+		total          int
+		local          map[int]bool
+		startedStatus  string
+		expectedTracks int
+		expectedSkip   int
+	}{
+		{
+			name:           "small playlist finishes in the request",
+			total:          3,
+			local:          map[int]bool{1: true},
+			startedStatus:  models.ImportJobDone,
+			expectedTracks: 2,
+			expectedSkip:   1,
+		},
+		{
+			name:           "large playlist runs in the background",
+			total:          250,
+			startedStatus:  models.ImportJobRunning,
+			expectedTracks: 250,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeSpotify(t)
+			fake.handle("GET /v1/playlists/{id}", func(writer http.ResponseWriter, request *http.Request) {
+				writeFakeJSON(writer, http.StatusOK, map[string]any{
+					"id": request.PathValue("id"), "name": "Today's Top Hits", "tracks": map[string]any{"total": tt.total},
+				})
+			})
+			fake.handle("GET /v1/playlists/{id}/tracks", servePlaylistTracks(tt.total, tt.local))
+
+			repo := newFakeImportRepo()
+			service := NewImportService(context.Background(), repo, provider.NewSpotify(fake.client()), &fakeQuota{})
+
+			playlist, err := service.GetSpotifyPlaylist(playlistId)
+			require.NoError(t, err)
+			assert.Equal(t, "Today's Top Hits", playlist.Name)
+
+			job, err := service.StartSpotifyImport(1, playlist, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.startedStatus, job.Status)
+			assert.Equal(t, tt.total, job.Total)
+
+			require.Eventually(t, func() bool {
+				stored, err := service.GetImportJob(1, int(job.ID))
+				return err == nil && stored.Status == models.ImportJobDone
+			}, 5*time.Second, 10*time.Millisecond)
+
+			stored, err := service.GetImportJob(1, int(job.ID))
+			require.NoError(t, err)
+			assert.Equal(t, tt.total, stored.Processed)
+			assert.Equal(t, tt.expectedSkip, stored.Skipped)
+			require.NotNil(t, stored.PlaylistId)
+
+			repo.mu.Lock()
+			defer repo.mu.Unlock()
+			require.Len(t, repo.playlists, 1)
+			imported := repo.playlists[0]
+			assert.Equal(t, "Today's Top Hits", imported.Name)
+			assert.Equal(t, models.DuplicatePolicyAllow, imported.DuplicatePolicy)
+			require.Len(t, imported.Songs, tt.expectedTracks)
+			assert.Equal(t, fakeTrackId(0), imported.Songs[0].ID)
+			assert.Equal(t, "Song 0", imported.Songs[0].Title)
+		})
+	}
+}
+
+func TestImportService_StartSpotifyImportFails(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/playlists/{id}/tracks", func(writer http.ResponseWriter, request *http.Request) {
+		writeFakeError(writer, http.StatusBadGateway, "upstream unavailable")
+	})
+
+	repo := newFakeImportRepo()
+	service := NewImportService(context.Background(), repo, provider.NewSpotify(fake.client()), &fakeQuota{})
+
+	playlist := &models.RemotePlaylist{ID: "37i9dQZF1DXcBWIGoYBM5M", Name: "Mix", Total: 3}
+
+	job, err := service.StartSpotifyImport(1, playlist, "Copy")
+	require.NoError(t, err)
+
+	assert.Equal(t, models.ImportJobFailed, job.Status)
+	assert.Equal(t, "catalog is unavailable: upstream unavailable", job.Error)
+	assert.Empty(t, repo.playlists)
+}
+
+func TestImportService_StartSpotifyImportPanics(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/playlists/{id}/tracks", servePlaylistTracks(3, nil))
+
+	repo := newFakeImportRepo()
+	repo.panics = true
+	service := NewImportService(context.Background(), repo, provider.NewSpotify(fake.client()), &fakeQuota{})
+
+	job, err := service.StartSpotifyImport(1, &models.RemotePlaylist{ID: "37i9dQZF1DXcBWIGoYBM5M", Total: 3}, "Copy")
+	require.NoError(t, err)
+
+	stored, err := service.GetImportJob(1, int(job.ID))
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportJobFailed, stored.Status)
+	assert.Equal(t, "import failed: playlist lost", stored.Error)
+}
+
+func TestImportService_StartSpotifyImportNotSaved(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/playlists/{id}/tracks", servePlaylistTracks(3, nil))
+
+	repo := newFakeImportRepo()
+	repo.updateErr = errors.New("connection lost")
+	service := NewImportService(context.Background(), repo, provider.NewSpotify(fake.client()), &fakeQuota{})
+
+	_, err := service.StartSpotifyImport(1, &models.RemotePlaylist{ID: "37i9dQZF1DXcBWIGoYBM5M", Total: 3}, "Copy")
+	assert.ErrorIs(t, err, errJobNotSaved)
+}
+
+func TestImportService_StartSpotifyImportShutdown(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/playlists/{id}/tracks", servePlaylistTracks(250, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	repo := newFakeImportRepo()
+	service := NewImportService(ctx, repo, provider.NewSpotify(fake.client()), &fakeQuota{})
+
+	job, err := service.StartSpotifyImport(1, &models.RemotePlaylist{ID: "37i9dQZF1DXcBWIGoYBM5M", Total: 250}, "Copy")
+	require.NoError(t, err)
+	service.WaitForImports()
+
+	stored, err := service.GetImportJob(1, int(job.ID))
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportJobFailed, stored.Status)
+	assert.Equal(t, "import stopped by server shutdown", stored.Error)
+	assert.Equal(t, 100, stored.Processed, "the page being fetched is finished")
+	assert.Empty(t, repo.playlists)
+}
+
+func TestImportService_StartSpotifyImportOverQuota(t *testing.T) {
+	repo := newFakeImportRepo()
+	service := NewImportService(context.Background(), repo, provider.NewSpotify(newFakeSpotify(t).client()), &fakeQuota{
+		limits: models.QuotaLimits{MaxTracksPerPlaylist: 100},
+	})
+
+	playlist := &models.RemotePlaylist{ID: "37i9dQZF1DXcBWIGoYBM5M", Name: "Mix", Total: 250}
+
+	_, err := service.StartSpotifyImport(1, playlist, "")
+	assert.ErrorIs(t, err, repository.ErrQuotaExceeded)
+	assert.Empty(t, repo.jobs, "no job is started for a playlist that can't fit")
+}
+
+func TestImportService_PreviewTracklist(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/search", func(writer http.ResponseWriter, request *http.Request) {
+		var items []map[string]any
+		switch request.URL.Query().Get("q") {
+		case `track:"Teardrop" artist:"Massive Attack"`:
+			items = append(items,
+				fakeTrack("1ZL3A1iKSCYYRiQzOVeOSB", "Teardrop - Live", "Massive Attack"),
+				fakeTrack("67Hna13dNDkZvBpTXRIaOJ", "Teardrop", "Massive Attack"))
+		case "Masive Atack Tear drop":
+			items = append(items, fakeTrack("67Hna13dNDkZvBpTXRIaOJ", "Teardrop", "Massive Attack"))
+		}
+		writeFakeJSON(writer, http.StatusOK, map[string]any{"tracks": map[string]any{"items": items, "total": len(items)}})
+	})
+
+	service := NewImportService(context.Background(), newFakeImportRepo(), provider.NewSpotify(fake.client()), &fakeQuota{})
+
+	lines, err := service.PreviewTracklist([]*models.TracklistLine{
+		{Row: 1, Artist: "Massive Attack", Title: "Teardrop", Candidates: []*models.TrackCandidate{}},
+		{Row: 2, Artist: "Masive Atack", Title: "Tear drop", Candidates: []*models.TrackCandidate{}},
+		{Row: 3, Artist: "Nobody", Title: "Nothing", Candidates: []*models.TrackCandidate{}},
+		{Row: 4, Error: `line is not in "Artist - Title" form`, Candidates: []*models.TrackCandidate{}},
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 4)
+
+	require.Len(t, lines[0].Candidates, 2)
+	assert.Equal(t, "Teardrop - Live", lines[0].Candidates[0].Song.Title, "equal scores keep the catalog order")
+	assert.Equal(t, 1.0, lines[0].Candidates[0].Confidence)
+	assert.Equal(t, 1.0, lines[0].Candidates[1].Confidence)
+
+	require.Len(t, lines[1].Candidates, 1, "plain search after the filtered one found nothing")
+	assert.Equal(t, "67Hna13dNDkZvBpTXRIaOJ", lines[1].Candidates[0].Song.ID)
+	assert.Less(t, lines[1].Candidates[0].Confidence, 1.0)
+
+	assert.Empty(t, lines[2].Candidates)
+	assert.Empty(t, lines[3].Candidates)
+}
@@ -5,11 +5,11 @@
 package mock_service
 
 import (
+	backup "music-service/internal/backup"
 	models "music-service/internal/models"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
-	spotify "github.com/zmb3/spotify"
 )
 
 // MockAuthorization is a mock of Authorization interface.
@@ -93,6 +93,21 @@ func (mr *MockAuthorizationMockRecorder) InvalidateToken(userID interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateToken", reflect.TypeOf((*MockAuthorization)(nil).InvalidateToken), userID)
 }
 
+// IsAdmin mocks base method.
+func (m *MockAuthorization) IsAdmin(userId int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAdmin", userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAdmin indicates an expected call of IsAdmin.
+func (mr *MockAuthorizationMockRecorder) IsAdmin(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAdmin", reflect.TypeOf((*MockAuthorization)(nil).IsAdmin), userId)
+}
+
 // IsTokenValid mocks base method.
 func (m *MockAuthorization) IsTokenValid(token string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -162,17 +177,17 @@ func (mr *MockPlayListMockRecorder) CreatePlaylist(playlist interface{}) *gomock
 }
 
 // DeletePlaylistById mocks base method.
-func (m *MockPlayList) DeletePlaylistById(userId, playlistId int) error {
+func (m *MockPlayList) DeletePlaylistById(change *models.PlaylistChange) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePlaylistById", userId, playlistId)
+	ret := m.ctrl.Call(m, "DeletePlaylistById", change)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeletePlaylistById indicates an expected call of DeletePlaylistById.
-func (mr *MockPlayListMockRecorder) DeletePlaylistById(userId, playlistId interface{}) *gomock.Call {
+func (mr *MockPlayListMockRecorder) DeletePlaylistById(change interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlaylistById", reflect.TypeOf((*MockPlayList)(nil).DeletePlaylistById), userId, playlistId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlaylistById", reflect.TypeOf((*MockPlayList)(nil).DeletePlaylistById), change)
 }
 
 // GetAllPlaylists mocks base method.
@@ -206,17 +221,17 @@ func (mr *MockPlayListMockRecorder) GetPlaylistById(userId, playlistId interface
 }
 
 // UpdatePlaylistById mocks base method.
-func (m *MockPlayList) UpdatePlaylistById(userId int, playlist *models.Playlist) error {
+func (m *MockPlayList) UpdatePlaylistById(change *models.PlaylistChange, playlist *models.Playlist) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePlaylistById", userId, playlist)
+	ret := m.ctrl.Call(m, "UpdatePlaylistById", change, playlist)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdatePlaylistById indicates an expected call of UpdatePlaylistById.
-func (mr *MockPlayListMockRecorder) UpdatePlaylistById(userId, playlist interface{}) *gomock.Call {
+func (mr *MockPlayListMockRecorder) UpdatePlaylistById(change, playlist interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePlaylistById", reflect.TypeOf((*MockPlayList)(nil).UpdatePlaylistById), userId, playlist)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePlaylistById", reflect.TypeOf((*MockPlayList)(nil).UpdatePlaylistById), change, playlist)
 }
 
 // MockSong is a mock of Song interface.
@@ -242,55 +257,144 @@ func (m *MockSong) EXPECT() *MockSongMockRecorder {
 	return m.recorder
 }
 
+// BackfillSongArtists mocks base method.
+func (m *MockSong) BackfillSongArtists() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackfillSongArtists")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackfillSongArtists indicates an expected call of BackfillSongArtists.
+func (mr *MockSongMockRecorder) BackfillSongArtists() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackfillSongArtists", reflect.TypeOf((*MockSong)(nil).BackfillSongArtists))
+}
+
 // CreateSong mocks base method.
-func (m *MockSong) CreateSong(userId, playlistId int, song *models.Song) (string, error) {
+func (m *MockSong) CreateSong(change *models.PlaylistChange, song *models.Song) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSong", userId, playlistId, song)
+	ret := m.ctrl.Call(m, "CreateSong", change, song)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateSong indicates an expected call of CreateSong.
-func (mr *MockSongMockRecorder) CreateSong(userId, playlistId, song interface{}) *gomock.Call {
+func (mr *MockSongMockRecorder) CreateSong(change, song interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSong", reflect.TypeOf((*MockSong)(nil).CreateSong), change, song)
+}
+
+// CreateSongs mocks base method.
+func (m *MockSong) CreateSongs(change *models.PlaylistChange, songs []models.Song) (*models.AddTracksReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSongs", change, songs)
+	ret0, _ := ret[0].(*models.AddTracksReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSongs indicates an expected call of CreateSongs.
+func (mr *MockSongMockRecorder) CreateSongs(change, songs interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSong", reflect.TypeOf((*MockSong)(nil).CreateSong), userId, playlistId, song)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSongs", reflect.TypeOf((*MockSong)(nil).CreateSongs), change, songs)
 }
 
 // DeleteSongFromPlaylist mocks base method.
-func (m *MockSong) DeleteSongFromPlaylist(userId, playlistId int, songId string) error {
+func (m *MockSong) DeleteSongFromPlaylist(change *models.PlaylistChange, songId string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteSongFromPlaylist", userId, playlistId, songId)
+	ret := m.ctrl.Call(m, "DeleteSongFromPlaylist", change, songId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteSongFromPlaylist indicates an expected call of DeleteSongFromPlaylist.
-func (mr *MockSongMockRecorder) DeleteSongFromPlaylist(userId, playlistId, songId interface{}) *gomock.Call {
+func (mr *MockSongMockRecorder) DeleteSongFromPlaylist(change, songId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSongFromPlaylist", reflect.TypeOf((*MockSong)(nil).DeleteSongFromPlaylist), change, songId)
+}
+
+// GetAlbum mocks base method.
+func (m *MockSong) GetAlbum(albumId string) (*models.AlbumDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlbum", albumId)
+	ret0, _ := ret[0].(*models.AlbumDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlbum indicates an expected call of GetAlbum.
+func (mr *MockSongMockRecorder) GetAlbum(albumId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSongFromPlaylist", reflect.TypeOf((*MockSong)(nil).DeleteSongFromPlaylist), userId, playlistId, songId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlbum", reflect.TypeOf((*MockSong)(nil).GetAlbum), albumId)
 }
 
 // GetAllSongsFromPlaylist mocks base method.
-func (m *MockSong) GetAllSongsFromPlaylist(userId, playlistId int) ([]*models.Song, error) {
+func (m *MockSong) GetAllSongsFromPlaylist(userId, playlistId int, filter *models.EntryFilter) ([]*models.PlaylistEntry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllSongsFromPlaylist", userId, playlistId)
-	ret0, _ := ret[0].([]*models.Song)
+	ret := m.ctrl.Call(m, "GetAllSongsFromPlaylist", userId, playlistId, filter)
+	ret0, _ := ret[0].([]*models.PlaylistEntry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllSongsFromPlaylist indicates an expected call of GetAllSongsFromPlaylist.
-func (mr *MockSongMockRecorder) GetAllSongsFromPlaylist(userId, playlistId interface{}) *gomock.Call {
+func (mr *MockSongMockRecorder) GetAllSongsFromPlaylist(userId, playlistId, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllSongsFromPlaylist", reflect.TypeOf((*MockSong)(nil).GetAllSongsFromPlaylist), userId, playlistId, filter)
+}
+
+// GetArtist mocks base method.
+func (m *MockSong) GetArtist(artistId, market string) (*models.ArtistDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArtist", artistId, market)
+	ret0, _ := ret[0].(*models.ArtistDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArtist indicates an expected call of GetArtist.
+func (mr *MockSongMockRecorder) GetArtist(artistId, market interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArtist", reflect.TypeOf((*MockSong)(nil).GetArtist), artistId, market)
+}
+
+// GetCacheStats mocks base method.
+func (m *MockSong) GetCacheStats() models.CacheStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCacheStats")
+	ret0, _ := ret[0].(models.CacheStats)
+	return ret0
+}
+
+// GetCacheStats indicates an expected call of GetCacheStats.
+func (mr *MockSongMockRecorder) GetCacheStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCacheStats", reflect.TypeOf((*MockSong)(nil).GetCacheStats))
+}
+
+// GetRecommendations mocks base method.
+func (m *MockSong) GetRecommendations(userId, playlistId int, query *models.RecommendationQuery) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecommendations", userId, playlistId, query)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecommendations indicates an expected call of GetRecommendations.
+func (mr *MockSongMockRecorder) GetRecommendations(userId, playlistId, query interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllSongsFromPlaylist", reflect.TypeOf((*MockSong)(nil).GetAllSongsFromPlaylist), userId, playlistId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecommendations", reflect.TypeOf((*MockSong)(nil).GetRecommendations), userId, playlistId, query)
 }
 
 // GetTrackByID mocks base method.
-func (m *MockSong) GetTrackByID(trackID string) (*spotify.FullTrack, error) {
+func (m *MockSong) GetTrackByID(trackID string) (*models.Song, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetTrackByID", trackID)
-	ret0, _ := ret[0].(*spotify.FullTrack)
+	ret0, _ := ret[0].(*models.Song)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -300,3 +404,1112 @@ func (mr *MockSongMockRecorder) GetTrackByID(trackID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrackByID", reflect.TypeOf((*MockSong)(nil).GetTrackByID), trackID)
 }
+
+// GetTracksByIDs mocks base method.
+func (m *MockSong) GetTracksByIDs(trackIds []string) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTracksByIDs", trackIds)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTracksByIDs indicates an expected call of GetTracksByIDs.
+func (mr *MockSongMockRecorder) GetTracksByIDs(trackIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTracksByIDs", reflect.TypeOf((*MockSong)(nil).GetTracksByIDs), trackIds)
+}
+
+// MockEntry is a mock of Entry interface.
+type MockEntry struct {
+	ctrl     *gomock.Controller
+	recorder *MockEntryMockRecorder
+}
+
+// MockEntryMockRecorder is the mock recorder for MockEntry.
+type MockEntryMockRecorder struct {
+	mock *MockEntry
+}
+
+// NewMockEntry creates a new mock instance.
+func NewMockEntry(ctrl *gomock.Controller) *MockEntry {
+	mock := &MockEntry{ctrl: ctrl}
+	mock.recorder = &MockEntryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEntry) EXPECT() *MockEntryMockRecorder {
+	return m.recorder
+}
+
+// CollapseDuplicateEntries mocks base method.
+func (m *MockEntry) CollapseDuplicateEntries(change *models.PlaylistChange) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CollapseDuplicateEntries", change)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CollapseDuplicateEntries indicates an expected call of CollapseDuplicateEntries.
+func (mr *MockEntryMockRecorder) CollapseDuplicateEntries(change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollapseDuplicateEntries", reflect.TypeOf((*MockEntry)(nil).CollapseDuplicateEntries), change)
+}
+
+// DeletePlaylistEntry mocks base method.
+func (m *MockEntry) DeletePlaylistEntry(change *models.PlaylistChange, entryId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePlaylistEntry", change, entryId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePlaylistEntry indicates an expected call of DeletePlaylistEntry.
+func (mr *MockEntryMockRecorder) DeletePlaylistEntry(change, entryId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlaylistEntry", reflect.TypeOf((*MockEntry)(nil).DeletePlaylistEntry), change, entryId)
+}
+
+// GetDuplicateEntries mocks base method.
+func (m *MockEntry) GetDuplicateEntries(userId, playlistId int) ([]*models.DuplicateGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDuplicateEntries", userId, playlistId)
+	ret0, _ := ret[0].([]*models.DuplicateGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDuplicateEntries indicates an expected call of GetDuplicateEntries.
+func (mr *MockEntryMockRecorder) GetDuplicateEntries(userId, playlistId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDuplicateEntries", reflect.TypeOf((*MockEntry)(nil).GetDuplicateEntries), userId, playlistId)
+}
+
+// GetPlaylistEntries mocks base method.
+func (m *MockEntry) GetPlaylistEntries(userId, playlistId int) ([]*models.PlaylistEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlaylistEntries", userId, playlistId)
+	ret0, _ := ret[0].([]*models.PlaylistEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlaylistEntries indicates an expected call of GetPlaylistEntries.
+func (mr *MockEntryMockRecorder) GetPlaylistEntries(userId, playlistId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaylistEntries", reflect.TypeOf((*MockEntry)(nil).GetPlaylistEntries), userId, playlistId)
+}
+
+// MovePlaylistEntry mocks base method.
+func (m *MockEntry) MovePlaylistEntry(change *models.PlaylistChange, entryId, position int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MovePlaylistEntry", change, entryId, position)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MovePlaylistEntry indicates an expected call of MovePlaylistEntry.
+func (mr *MockEntryMockRecorder) MovePlaylistEntry(change, entryId, position interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MovePlaylistEntry", reflect.TypeOf((*MockEntry)(nil).MovePlaylistEntry), change, entryId, position)
+}
+
+// UpdateEntryNote mocks base method.
+func (m *MockEntry) UpdateEntryNote(change *models.PlaylistChange, entryId int, note string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEntryNote", change, entryId, note)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEntryNote indicates an expected call of UpdateEntryNote.
+func (mr *MockEntryMockRecorder) UpdateEntryNote(change, entryId, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEntryNote", reflect.TypeOf((*MockEntry)(nil).UpdateEntryNote), change, entryId, note)
+}
+
+// MockHistory is a mock of History interface.
+type MockHistory struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryMockRecorder
+}
+
+// MockHistoryMockRecorder is the mock recorder for MockHistory.
+type MockHistoryMockRecorder struct {
+	mock *MockHistory
+}
+
+// NewMockHistory creates a new mock instance.
+func NewMockHistory(ctrl *gomock.Controller) *MockHistory {
+	mock := &MockHistory{ctrl: ctrl}
+	mock.recorder = &MockHistoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistory) EXPECT() *MockHistoryMockRecorder {
+	return m.recorder
+}
+
+// GetHistory mocks base method.
+func (m *MockHistory) GetHistory(userId, playlistId int) ([]*models.PlaylistSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", userId, playlistId)
+	ret0, _ := ret[0].([]*models.PlaylistSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockHistoryMockRecorder) GetHistory(userId, playlistId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockHistory)(nil).GetHistory), userId, playlistId)
+}
+
+// GetSnapshot mocks base method.
+func (m *MockHistory) GetSnapshot(userId, playlistId, snapshotId int) (*models.PlaylistSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSnapshot", userId, playlistId, snapshotId)
+	ret0, _ := ret[0].(*models.PlaylistSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSnapshot indicates an expected call of GetSnapshot.
+func (mr *MockHistoryMockRecorder) GetSnapshot(userId, playlistId, snapshotId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSnapshot", reflect.TypeOf((*MockHistory)(nil).GetSnapshot), userId, playlistId, snapshotId)
+}
+
+// RevertToSnapshot mocks base method.
+func (m *MockHistory) RevertToSnapshot(change *models.PlaylistChange, snapshotId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevertToSnapshot", change, snapshotId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevertToSnapshot indicates an expected call of RevertToSnapshot.
+func (mr *MockHistoryMockRecorder) RevertToSnapshot(change, snapshotId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertToSnapshot", reflect.TypeOf((*MockHistory)(nil).RevertToSnapshot), change, snapshotId)
+}
+
+// MockSmartPlaylist is a mock of SmartPlaylist interface.
+type MockSmartPlaylist struct {
+	ctrl     *gomock.Controller
+	recorder *MockSmartPlaylistMockRecorder
+}
+
+// MockSmartPlaylistMockRecorder is the mock recorder for MockSmartPlaylist.
+type MockSmartPlaylistMockRecorder struct {
+	mock *MockSmartPlaylist
+}
+
+// NewMockSmartPlaylist creates a new mock instance.
+func NewMockSmartPlaylist(ctrl *gomock.Controller) *MockSmartPlaylist {
+	mock := &MockSmartPlaylist{ctrl: ctrl}
+	mock.recorder = &MockSmartPlaylistMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSmartPlaylist) EXPECT() *MockSmartPlaylistMockRecorder {
+	return m.recorder
+}
+
+// CreateSmartPlaylist mocks base method.
+func (m *MockSmartPlaylist) CreateSmartPlaylist(playlist *models.Playlist) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSmartPlaylist", playlist)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSmartPlaylist indicates an expected call of CreateSmartPlaylist.
+func (mr *MockSmartPlaylistMockRecorder) CreateSmartPlaylist(playlist interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSmartPlaylist", reflect.TypeOf((*MockSmartPlaylist)(nil).CreateSmartPlaylist), playlist)
+}
+
+// FreezeSmartPlaylist mocks base method.
+func (m *MockSmartPlaylist) FreezeSmartPlaylist(playlistId int, frozen *models.Playlist) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FreezeSmartPlaylist", playlistId, frozen)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FreezeSmartPlaylist indicates an expected call of FreezeSmartPlaylist.
+func (mr *MockSmartPlaylistMockRecorder) FreezeSmartPlaylist(playlistId, frozen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FreezeSmartPlaylist", reflect.TypeOf((*MockSmartPlaylist)(nil).FreezeSmartPlaylist), playlistId, frozen)
+}
+
+// RefreshScheduledPlaylists mocks base method.
+func (m *MockSmartPlaylist) RefreshScheduledPlaylists() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshScheduledPlaylists")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshScheduledPlaylists indicates an expected call of RefreshScheduledPlaylists.
+func (mr *MockSmartPlaylistMockRecorder) RefreshScheduledPlaylists() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshScheduledPlaylists", reflect.TypeOf((*MockSmartPlaylist)(nil).RefreshScheduledPlaylists))
+}
+
+// RefreshSmartPlaylist mocks base method.
+func (m *MockSmartPlaylist) RefreshSmartPlaylist(change *models.PlaylistChange) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSmartPlaylist", change)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshSmartPlaylist indicates an expected call of RefreshSmartPlaylist.
+func (mr *MockSmartPlaylistMockRecorder) RefreshSmartPlaylist(change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSmartPlaylist", reflect.TypeOf((*MockSmartPlaylist)(nil).RefreshSmartPlaylist), change)
+}
+
+// UpdateSmartRules mocks base method.
+func (m *MockSmartPlaylist) UpdateSmartRules(change *models.PlaylistChange, rules *models.SmartRules, refreshMode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSmartRules", change, rules, refreshMode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSmartRules indicates an expected call of UpdateSmartRules.
+func (mr *MockSmartPlaylistMockRecorder) UpdateSmartRules(change, rules, refreshMode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSmartRules", reflect.TypeOf((*MockSmartPlaylist)(nil).UpdateSmartRules), change, rules, refreshMode)
+}
+
+// MockFolder is a mock of Folder interface.
+type MockFolder struct {
+	ctrl     *gomock.Controller
+	recorder *MockFolderMockRecorder
+}
+
+// MockFolderMockRecorder is the mock recorder for MockFolder.
+type MockFolderMockRecorder struct {
+	mock *MockFolder
+}
+
+// NewMockFolder creates a new mock instance.
+func NewMockFolder(ctrl *gomock.Controller) *MockFolder {
+	mock := &MockFolder{ctrl: ctrl}
+	mock.recorder = &MockFolderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFolder) EXPECT() *MockFolderMockRecorder {
+	return m.recorder
+}
+
+// CreateFolder mocks base method.
+func (m *MockFolder) CreateFolder(folder *models.Folder) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFolder", folder)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFolder indicates an expected call of CreateFolder.
+func (mr *MockFolderMockRecorder) CreateFolder(folder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFolder", reflect.TypeOf((*MockFolder)(nil).CreateFolder), folder)
+}
+
+// DeleteFolder mocks base method.
+func (m *MockFolder) DeleteFolder(userId, folderId int, mode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFolder", userId, folderId, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFolder indicates an expected call of DeleteFolder.
+func (mr *MockFolderMockRecorder) DeleteFolder(userId, folderId, mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFolder", reflect.TypeOf((*MockFolder)(nil).DeleteFolder), userId, folderId, mode)
+}
+
+// GetPlaylistTree mocks base method.
+func (m *MockFolder) GetPlaylistTree(userId int) (*models.PlaylistTree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlaylistTree", userId)
+	ret0, _ := ret[0].(*models.PlaylistTree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlaylistTree indicates an expected call of GetPlaylistTree.
+func (mr *MockFolderMockRecorder) GetPlaylistTree(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaylistTree", reflect.TypeOf((*MockFolder)(nil).GetPlaylistTree), userId)
+}
+
+// MoveFolder mocks base method.
+func (m *MockFolder) MoveFolder(userId, folderId int, parentId *int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFolder", userId, folderId, parentId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveFolder indicates an expected call of MoveFolder.
+func (mr *MockFolderMockRecorder) MoveFolder(userId, folderId, parentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFolder", reflect.TypeOf((*MockFolder)(nil).MoveFolder), userId, folderId, parentId)
+}
+
+// MovePlaylist mocks base method.
+func (m *MockFolder) MovePlaylist(userId, playlistId int, folderId *int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MovePlaylist", userId, playlistId, folderId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MovePlaylist indicates an expected call of MovePlaylist.
+func (mr *MockFolderMockRecorder) MovePlaylist(userId, playlistId, folderId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MovePlaylist", reflect.TypeOf((*MockFolder)(nil).MovePlaylist), userId, playlistId, folderId)
+}
+
+// RenameFolder mocks base method.
+func (m *MockFolder) RenameFolder(userId, folderId int, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameFolder", userId, folderId, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameFolder indicates an expected call of RenameFolder.
+func (mr *MockFolderMockRecorder) RenameFolder(userId, folderId, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameFolder", reflect.TypeOf((*MockFolder)(nil).RenameFolder), userId, folderId, name)
+}
+
+// MockStats is a mock of Stats interface.
+type MockStats struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsMockRecorder
+}
+
+// MockStatsMockRecorder is the mock recorder for MockStats.
+type MockStatsMockRecorder struct {
+	mock *MockStats
+}
+
+// NewMockStats creates a new mock instance.
+func NewMockStats(ctrl *gomock.Controller) *MockStats {
+	mock := &MockStats{ctrl: ctrl}
+	mock.recorder = &MockStatsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStats) EXPECT() *MockStatsMockRecorder {
+	return m.recorder
+}
+
+// GetPlaylistStats mocks base method.
+func (m *MockStats) GetPlaylistStats(userId, playlistId int) (*models.PlaylistStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlaylistStats", userId, playlistId)
+	ret0, _ := ret[0].(*models.PlaylistStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlaylistStats indicates an expected call of GetPlaylistStats.
+func (mr *MockStatsMockRecorder) GetPlaylistStats(userId, playlistId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaylistStats", reflect.TypeOf((*MockStats)(nil).GetPlaylistStats), userId, playlistId)
+}
+
+// MockTag is a mock of Tag interface.
+type MockTag struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagMockRecorder
+}
+
+// MockTagMockRecorder is the mock recorder for MockTag.
+type MockTagMockRecorder struct {
+	mock *MockTag
+}
+
+// NewMockTag creates a new mock instance.
+func NewMockTag(ctrl *gomock.Controller) *MockTag {
+	mock := &MockTag{ctrl: ctrl}
+	mock.recorder = &MockTagMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTag) EXPECT() *MockTagMockRecorder {
+	return m.recorder
+}
+
+// CreateTag mocks base method.
+func (m *MockTag) CreateTag(userId int, name string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", userId, name)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockTagMockRecorder) CreateTag(userId, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockTag)(nil).CreateTag), userId, name)
+}
+
+// DeleteTag mocks base method.
+func (m *MockTag) DeleteTag(userId, tagId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", userId, tagId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MockTagMockRecorder) DeleteTag(userId, tagId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockTag)(nil).DeleteTag), userId, tagId)
+}
+
+// FilterPlaylistsByTags mocks base method.
+func (m *MockTag) FilterPlaylistsByTags(userId int, playlists []*models.Playlist, tags []string) ([]*models.Playlist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterPlaylistsByTags", userId, playlists, tags)
+	ret0, _ := ret[0].([]*models.Playlist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterPlaylistsByTags indicates an expected call of FilterPlaylistsByTags.
+func (mr *MockTagMockRecorder) FilterPlaylistsByTags(userId, playlists, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterPlaylistsByTags", reflect.TypeOf((*MockTag)(nil).FilterPlaylistsByTags), userId, playlists, tags)
+}
+
+// FilterSongsByTags mocks base method.
+func (m *MockTag) FilterSongsByTags(userId int, entries []*models.PlaylistEntry, tags []string) ([]*models.PlaylistEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterSongsByTags", userId, entries, tags)
+	ret0, _ := ret[0].([]*models.PlaylistEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterSongsByTags indicates an expected call of FilterSongsByTags.
+func (mr *MockTagMockRecorder) FilterSongsByTags(userId, entries, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterSongsByTags", reflect.TypeOf((*MockTag)(nil).FilterSongsByTags), userId, entries, tags)
+}
+
+// GetPlaylistTags mocks base method.
+func (m *MockTag) GetPlaylistTags(userId, playlistId int) ([]*models.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlaylistTags", userId, playlistId)
+	ret0, _ := ret[0].([]*models.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlaylistTags indicates an expected call of GetPlaylistTags.
+func (mr *MockTagMockRecorder) GetPlaylistTags(userId, playlistId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaylistTags", reflect.TypeOf((*MockTag)(nil).GetPlaylistTags), userId, playlistId)
+}
+
+// GetSongTags mocks base method.
+func (m *MockTag) GetSongTags(userId int, songId string) ([]*models.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongTags", userId, songId)
+	ret0, _ := ret[0].([]*models.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongTags indicates an expected call of GetSongTags.
+func (mr *MockTagMockRecorder) GetSongTags(userId, songId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongTags", reflect.TypeOf((*MockTag)(nil).GetSongTags), userId, songId)
+}
+
+// GetTags mocks base method.
+func (m *MockTag) GetTags(userId int, prefix string) ([]*models.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags", userId, prefix)
+	ret0, _ := ret[0].([]*models.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockTagMockRecorder) GetTags(userId, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockTag)(nil).GetTags), userId, prefix)
+}
+
+// RenameTag mocks base method.
+func (m *MockTag) RenameTag(userId, tagId int, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameTag", userId, tagId, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameTag indicates an expected call of RenameTag.
+func (mr *MockTagMockRecorder) RenameTag(userId, tagId, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameTag", reflect.TypeOf((*MockTag)(nil).RenameTag), userId, tagId, name)
+}
+
+// TagPlaylist mocks base method.
+func (m *MockTag) TagPlaylist(userId, playlistId int, name string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagPlaylist", userId, playlistId, name)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagPlaylist indicates an expected call of TagPlaylist.
+func (mr *MockTagMockRecorder) TagPlaylist(userId, playlistId, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagPlaylist", reflect.TypeOf((*MockTag)(nil).TagPlaylist), userId, playlistId, name)
+}
+
+// TagSong mocks base method.
+func (m *MockTag) TagSong(userId int, songId, name string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagSong", userId, songId, name)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagSong indicates an expected call of TagSong.
+func (mr *MockTagMockRecorder) TagSong(userId, songId, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagSong", reflect.TypeOf((*MockTag)(nil).TagSong), userId, songId, name)
+}
+
+// UntagPlaylist mocks base method.
+func (m *MockTag) UntagPlaylist(userId, playlistId, tagId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UntagPlaylist", userId, playlistId, tagId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UntagPlaylist indicates an expected call of UntagPlaylist.
+func (mr *MockTagMockRecorder) UntagPlaylist(userId, playlistId, tagId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UntagPlaylist", reflect.TypeOf((*MockTag)(nil).UntagPlaylist), userId, playlistId, tagId)
+}
+
+// UntagSong mocks base method.
+func (m *MockTag) UntagSong(userId int, songId string, tagId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UntagSong", userId, songId, tagId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UntagSong indicates an expected call of UntagSong.
+func (mr *MockTagMockRecorder) UntagSong(userId, songId, tagId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UntagSong", reflect.TypeOf((*MockTag)(nil).UntagSong), userId, songId, tagId)
+}
+
+// MockTrash is a mock of Trash interface.
+type MockTrash struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrashMockRecorder
+}
+
+// MockTrashMockRecorder is the mock recorder for MockTrash.
+type MockTrashMockRecorder struct {
+	mock *MockTrash
+}
+
+// NewMockTrash creates a new mock instance.
+func NewMockTrash(ctrl *gomock.Controller) *MockTrash {
+	mock := &MockTrash{ctrl: ctrl}
+	mock.recorder = &MockTrashMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrash) EXPECT() *MockTrashMockRecorder {
+	return m.recorder
+}
+
+// GetTrash mocks base method.
+func (m *MockTrash) GetTrash(userId int) ([]*models.TrashedPlaylist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrash", userId)
+	ret0, _ := ret[0].([]*models.TrashedPlaylist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrash indicates an expected call of GetTrash.
+func (mr *MockTrashMockRecorder) GetTrash(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrash", reflect.TypeOf((*MockTrash)(nil).GetTrash), userId)
+}
+
+// PurgeExpired mocks base method.
+func (m *MockTrash) PurgeExpired() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpired")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpired indicates an expected call of PurgeExpired.
+func (mr *MockTrashMockRecorder) PurgeExpired() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpired", reflect.TypeOf((*MockTrash)(nil).PurgeExpired))
+}
+
+// RestorePlaylist mocks base method.
+func (m *MockTrash) RestorePlaylist(userId, playlistId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePlaylist", userId, playlistId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestorePlaylist indicates an expected call of RestorePlaylist.
+func (mr *MockTrashMockRecorder) RestorePlaylist(userId, playlistId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePlaylist", reflect.TypeOf((*MockTrash)(nil).RestorePlaylist), userId, playlistId)
+}
+
+// MockQuota is a mock of Quota interface.
+type MockQuota struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuotaMockRecorder
+}
+
+// MockQuotaMockRecorder is the mock recorder for MockQuota.
+type MockQuotaMockRecorder struct {
+	mock *MockQuota
+}
+
+// NewMockQuota creates a new mock instance.
+func NewMockQuota(ctrl *gomock.Controller) *MockQuota {
+	mock := &MockQuota{ctrl: ctrl}
+	mock.recorder = &MockQuotaMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuota) EXPECT() *MockQuotaMockRecorder {
+	return m.recorder
+}
+
+// CountRequest mocks base method.
+func (m *MockQuota) CountRequest(userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRequest", userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CountRequest indicates an expected call of CountRequest.
+func (mr *MockQuotaMockRecorder) CountRequest(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRequest", reflect.TypeOf((*MockQuota)(nil).CountRequest), userId)
+}
+
+// GetLimits mocks base method.
+func (m *MockQuota) GetLimits(userId int) (*models.QuotaLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLimits", userId)
+	ret0, _ := ret[0].(*models.QuotaLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLimits indicates an expected call of GetLimits.
+func (mr *MockQuotaMockRecorder) GetLimits(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimits", reflect.TypeOf((*MockQuota)(nil).GetLimits), userId)
+}
+
+// GetUsage mocks base method.
+func (m *MockQuota) GetUsage(userId int) (*models.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", userId)
+	ret0, _ := ret[0].(*models.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage.
+func (mr *MockQuotaMockRecorder) GetUsage(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockQuota)(nil).GetUsage), userId)
+}
+
+// PurgeRequestCounts mocks base method.
+func (m *MockQuota) PurgeRequestCounts() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeRequestCounts")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeRequestCounts indicates an expected call of PurgeRequestCounts.
+func (mr *MockQuotaMockRecorder) PurgeRequestCounts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeRequestCounts", reflect.TypeOf((*MockQuota)(nil).PurgeRequestCounts))
+}
+
+// SetQuotaOverride mocks base method.
+func (m *MockQuota) SetQuotaOverride(userId int, override *models.QuotaOverride) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetQuotaOverride", userId, override)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetQuotaOverride indicates an expected call of SetQuotaOverride.
+func (mr *MockQuotaMockRecorder) SetQuotaOverride(userId, override interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetQuotaOverride", reflect.TypeOf((*MockQuota)(nil).SetQuotaOverride), userId, override)
+}
+
+// MockImport is a mock of Import interface.
+type MockImport struct {
+	ctrl     *gomock.Controller
+	recorder *MockImportMockRecorder
+}
+
+// MockImportMockRecorder is the mock recorder for MockImport.
+type MockImportMockRecorder struct {
+	mock *MockImport
+}
+
+// NewMockImport creates a new mock instance.
+func NewMockImport(ctrl *gomock.Controller) *MockImport {
+	mock := &MockImport{ctrl: ctrl}
+	mock.recorder = &MockImportMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImport) EXPECT() *MockImportMockRecorder {
+	return m.recorder
+}
+
+// GetImportJob mocks base method.
+func (m *MockImport) GetImportJob(userId, jobId int) (*models.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImportJob", userId, jobId)
+	ret0, _ := ret[0].(*models.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImportJob indicates an expected call of GetImportJob.
+func (mr *MockImportMockRecorder) GetImportJob(userId, jobId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImportJob", reflect.TypeOf((*MockImport)(nil).GetImportJob), userId, jobId)
+}
+
+// GetSpotifyPlaylist mocks base method.
+func (m *MockImport) GetSpotifyPlaylist(playlistId string) (*models.RemotePlaylist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSpotifyPlaylist", playlistId)
+	ret0, _ := ret[0].(*models.RemotePlaylist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSpotifyPlaylist indicates an expected call of GetSpotifyPlaylist.
+func (mr *MockImportMockRecorder) GetSpotifyPlaylist(playlistId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpotifyPlaylist", reflect.TypeOf((*MockImport)(nil).GetSpotifyPlaylist), playlistId)
+}
+
+// ImportPlaylist mocks base method.
+func (m *MockImport) ImportPlaylist(playlist *models.Playlist) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportPlaylist", playlist)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportPlaylist indicates an expected call of ImportPlaylist.
+func (mr *MockImportMockRecorder) ImportPlaylist(playlist interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportPlaylist", reflect.TypeOf((*MockImport)(nil).ImportPlaylist), playlist)
+}
+
+// MatchItems mocks base method.
+func (m *MockImport) MatchItems(items []*models.ImportItem) ([]models.Song, []*models.ImportItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MatchItems", items)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].([]*models.ImportItem)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MatchItems indicates an expected call of MatchItems.
+func (mr *MockImportMockRecorder) MatchItems(items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MatchItems", reflect.TypeOf((*MockImport)(nil).MatchItems), items)
+}
+
+// PreviewTracklist mocks base method.
+func (m *MockImport) PreviewTracklist(lines []*models.TracklistLine) ([]*models.TracklistLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewTracklist", lines)
+	ret0, _ := ret[0].([]*models.TracklistLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewTracklist indicates an expected call of PreviewTracklist.
+func (mr *MockImportMockRecorder) PreviewTracklist(lines interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewTracklist", reflect.TypeOf((*MockImport)(nil).PreviewTracklist), lines)
+}
+
+// StartSpotifyImport mocks base method.
+func (m *MockImport) StartSpotifyImport(userId int, playlist *models.RemotePlaylist, name string) (*models.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartSpotifyImport", userId, playlist, name)
+	ret0, _ := ret[0].(*models.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartSpotifyImport indicates an expected call of StartSpotifyImport.
+func (mr *MockImportMockRecorder) StartSpotifyImport(userId, playlist, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartSpotifyImport", reflect.TypeOf((*MockImport)(nil).StartSpotifyImport), userId, playlist, name)
+}
+
+// WaitForImports mocks base method.
+func (m *MockImport) WaitForImports() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "WaitForImports")
+}
+
+// WaitForImports indicates an expected call of WaitForImports.
+func (mr *MockImportMockRecorder) WaitForImports() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForImports", reflect.TypeOf((*MockImport)(nil).WaitForImports))
+}
+
+// MockBackup is a mock of Backup interface.
+type MockBackup struct {
+	ctrl     *gomock.Controller
+	recorder *MockBackupMockRecorder
+}
+
+// MockBackupMockRecorder is the mock recorder for MockBackup.
+type MockBackupMockRecorder struct {
+	mock *MockBackup
+}
+
+// NewMockBackup creates a new mock instance.
+func NewMockBackup(ctrl *gomock.Controller) *MockBackup {
+	mock := &MockBackup{ctrl: ctrl}
+	mock.recorder = &MockBackupMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBackup) EXPECT() *MockBackupMockRecorder {
+	return m.recorder
+}
+
+// ExportBackup mocks base method.
+func (m *MockBackup) ExportBackup(userId int) (*backup.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportBackup", userId)
+	ret0, _ := ret[0].(*backup.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportBackup indicates an expected call of ExportBackup.
+func (mr *MockBackupMockRecorder) ExportBackup(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportBackup", reflect.TypeOf((*MockBackup)(nil).ExportBackup), userId)
+}
+
+// RestoreBackup mocks base method.
+func (m *MockBackup) RestoreBackup(userId int, document *backup.Document) (*models.RestoreReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreBackup", userId, document)
+	ret0, _ := ret[0].(*models.RestoreReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreBackup indicates an expected call of RestoreBackup.
+func (mr *MockBackupMockRecorder) RestoreBackup(userId, document interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreBackup", reflect.TypeOf((*MockBackup)(nil).RestoreBackup), userId, document)
+}
+
+// MockCatalog is a mock of Catalog interface.
+type MockCatalog struct {
+	ctrl     *gomock.Controller
+	recorder *MockCatalogMockRecorder
+}
+
+// MockCatalogMockRecorder is the mock recorder for MockCatalog.
+type MockCatalogMockRecorder struct {
+	mock *MockCatalog
+}
+
+// NewMockCatalog creates a new mock instance.
+func NewMockCatalog(ctrl *gomock.Controller) *MockCatalog {
+	mock := &MockCatalog{ctrl: ctrl}
+	mock.recorder = &MockCatalogMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCatalog) EXPECT() *MockCatalogMockRecorder {
+	return m.recorder
+}
+
+// Search mocks base method.
+func (m *MockCatalog) Search(query *models.SearchQuery) (*models.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", query)
+	ret0, _ := ret[0].(*models.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockCatalogMockRecorder) Search(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockCatalog)(nil).Search), query)
+}
+
+// MockRefresh is a mock of Refresh interface.
+type MockRefresh struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefreshMockRecorder
+}
+
+// MockRefreshMockRecorder is the mock recorder for MockRefresh.
+type MockRefreshMockRecorder struct {
+	mock *MockRefresh
+}
+
+// NewMockRefresh creates a new mock instance.
+func NewMockRefresh(ctrl *gomock.Controller) *MockRefresh {
+	mock := &MockRefresh{ctrl: ctrl}
+	mock.recorder = &MockRefreshMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefresh) EXPECT() *MockRefreshMockRecorder {
+	return m.recorder
+}
+
+// RefreshStaleSongs mocks base method.
+func (m *MockRefresh) RefreshStaleSongs() (*models.RefreshReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshStaleSongs")
+	ret0, _ := ret[0].(*models.RefreshReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshStaleSongs indicates an expected call of RefreshStaleSongs.
+func (mr *MockRefreshMockRecorder) RefreshStaleSongs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshStaleSongs", reflect.TypeOf((*MockRefresh)(nil).RefreshStaleSongs))
+}
+
+// MockFeature is a mock of Feature interface.
+type MockFeature struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeatureMockRecorder
+}
+
+// MockFeatureMockRecorder is the mock recorder for MockFeature.
+type MockFeatureMockRecorder struct {
+	mock *MockFeature
+}
+
+// NewMockFeature creates a new mock instance.
+func NewMockFeature(ctrl *gomock.Controller) *MockFeature {
+	mock := &MockFeature{ctrl: ctrl}
+	mock.recorder = &MockFeatureMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeature) EXPECT() *MockFeatureMockRecorder {
+	return m.recorder
+}
+
+// BackfillAudioFeatures mocks base method.
+func (m *MockFeature) BackfillAudioFeatures() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackfillAudioFeatures")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackfillAudioFeatures indicates an expected call of BackfillAudioFeatures.
+func (mr *MockFeatureMockRecorder) BackfillAudioFeatures() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackfillAudioFeatures", reflect.TypeOf((*MockFeature)(nil).BackfillAudioFeatures))
+}
+
+// StoreAudioFeatures mocks base method.
+func (m *MockFeature) StoreAudioFeatures(trackIds ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range trackIds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StoreAudioFeatures", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreAudioFeatures indicates an expected call of StoreAudioFeatures.
+func (mr *MockFeatureMockRecorder) StoreAudioFeatures(trackIds ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreAudioFeatures", reflect.TypeOf((*MockFeature)(nil).StoreAudioFeatures), trackIds...)
+}
@@ -6,19 +6,30 @@ import (
 )
 
 type PlaylistService struct {
-	repo repository.PlayList
+	repo  repository.PlayList
+	quota Quota
 }
 
 func NewPlaylistService(
 	repo repository.PlayList,
+	quota Quota,
 ) *PlaylistService {
 	return &PlaylistService{
-		repo: repo,
+		repo:  repo,
+		quota: quota,
 	}
 }
 
 func (p *PlaylistService) CreatePlaylist(playlist *models.Playlist) (int64, error) {
-	return p.repo.CreatePlaylist(playlist)
+	if playlist.DuplicatePolicy == "" {
+		playlist.DuplicatePolicy = models.DuplicatePolicyAllow
+	}
+
+	limits, err := p.quota.GetLimits(playlist.UserId)
+	if err != nil {
+		return 0, err
+	}
+	return p.repo.CreatePlaylist(playlist, limits)
 }
 
 func (p *PlaylistService) GetAllPlaylists(userId int) ([]*models.Playlist, error) {
@@ -29,10 +40,10 @@ func (p *PlaylistService) GetPlaylistById(userId int, playlistId int) (*models.P
 	return p.repo.GetPlaylistById(userId, playlistId)
 }
 
-func (p *PlaylistService) UpdatePlaylistById(userId int, playlist *models.Playlist) error {
-	return p.repo.UpdatePlaylistById(userId, playlist)
+func (p *PlaylistService) UpdatePlaylistById(change *models.PlaylistChange, playlist *models.Playlist) error {
+	return p.repo.UpdatePlaylistById(change, playlist)
 }
 
-func (p *PlaylistService) DeletePlaylistById(userId int, playlistId int) error {
-	return p.repo.DeletePlaylistById(userId, playlistId)
+func (p *PlaylistService) DeletePlaylistById(change *models.PlaylistChange) error {
+	return p.repo.DeletePlaylistById(change)
 }
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"fmt"
+	"music-service/internal/models"
+	"music-service/internal/repository"
+	"time"
+)
+
+const requestDayLayout = "2006-01-02"
+
+type QuotaService struct {
+	repo     repository.Quota
+	defaults models.QuotaLimits
+}
+
+func NewQuotaService(
+	repo repository.Quota,
+	defaults models.QuotaLimits,
+) *QuotaService {
+	return &QuotaService{
+		repo:     repo,
+		defaults: defaults,
+	}
+}
+
+// GetLimits applies the user's overrides on top of the configured defaults.
+func (q *QuotaService) GetLimits(userId int) (*models.QuotaLimits, error) {
+	override, err := q.repo.GetQuotaOverride(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := q.defaults
+	if override.MaxPlaylists != nil {
+		limits.MaxPlaylists = *override.MaxPlaylists
+	}
+	if override.MaxTracksPerPlaylist != nil {
+		limits.MaxTracksPerPlaylist = *override.MaxTracksPerPlaylist
+	}
+	if override.MaxRequestsPerDay != nil {
+		limits.MaxRequestsPerDay = *override.MaxRequestsPerDay
+	}
+	return &limits, nil
+}
+
+func (q *QuotaService) SetQuotaOverride(userId int, override *models.QuotaOverride) error {
+	return q.repo.SetQuotaOverride(userId, override)
+}
+
+func (q *QuotaService) GetUsage(userId int) (*models.Usage, error) {
+	limits, err := q.GetLimits(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	playlists, err := q.repo.CountPlaylists(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	largest, err := q.repo.GetLargestPlaylistSize(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := q.repo.GetRequestCount(userId, today())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Usage{
+		Playlists:       models.UsageCount{Used: playlists, Limit: limits.MaxPlaylists},
+		LargestPlaylist: models.UsageCount{Used: largest, Limit: limits.MaxTracksPerPlaylist},
+		RequestsToday:   models.UsageCount{Used: requests, Limit: limits.MaxRequestsPerDay},
+	}, nil
+}
+
+// CountRequest records one request of the user for today and fails once the daily
+// limit is used up.
+func (q *QuotaService) CountRequest(userId int) error {
+	limits, err := q.GetLimits(userId)
+	if err != nil {
+		return err
+	}
+
+	count, err := q.repo.IncrementRequestCount(userId, today())
+	if err != nil {
+		return err
+	}
+
+	if limits.MaxRequestsPerDay != 0 && count > limits.MaxRequestsPerDay {
+		return fmt.Errorf("%w: at most %d requests per day allowed", repository.ErrQuotaExceeded, limits.MaxRequestsPerDay)
+	}
+	return nil
+}
+
+// PurgeRequestCounts deletes the request counts of past days, only today's count
+// is ever read.
+func (q *QuotaService) PurgeRequestCounts() (int64, error) {
+	return q.repo.PurgeRequestCounts(today())
+}
+
+func today() string {
+	return time.Now().UTC().Format(requestDayLayout)
+}
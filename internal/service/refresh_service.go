@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/repository"
+	"sync"
+	"time"
+)
+
+var ErrRefreshRunning = errors.New("a metadata refresh is already running")
+
+type RefreshService struct {
+	repo     repository.Song
+	catalog  provider.MusicProvider
+	settings models.RefreshSettings
+
+	running sync.Mutex
+}
+
+func NewRefreshService(repo repository.Song, catalog provider.MusicProvider, settings models.RefreshSettings) *RefreshService {
+	if settings.BatchSize <= 0 || settings.BatchSize > tracksBatchSize {
+		settings.BatchSize = tracksBatchSize
+	}
+	return &RefreshService{
+		repo:     repo,
+		catalog:  catalog,
+		settings: settings,
+	}
+}
+
+// RefreshStaleSongs stops early without an error when rate limited.
+func (r *RefreshService) RefreshStaleSongs() (*models.RefreshReport, error) {
+	if !r.running.TryLock() {
+		return nil, ErrRefreshRunning
+	}
+	defer r.running.Unlock()
+
+	report := &models.RefreshReport{Unavailable: []string{}}
+
+	var throttle <-chan time.Time
+	if r.settings.RequestsPerSecond > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / r.settings.RequestsPerSecond))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	for batch := 0; batch < r.settings.Batches; batch++ {
+		trackIds, err := r.repo.GetStaleSongs(r.settings.MaxAge, r.settings.BatchSize)
+		if err != nil {
+			return report, err
+		}
+		if len(trackIds) == 0 {
+			break
+		}
+
+		if batch > 0 && throttle != nil {
+			<-throttle
+		}
+		if err := r.refreshBatch(trackIds, report); err != nil {
+			if errors.Is(err, provider.ErrRateLimited) {
+				report.RateLimited = true
+				return report, nil
+			}
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+func (r *RefreshService) refreshBatch(trackIds []string, report *models.RefreshReport) error {
+	songs, err := r.catalog.GetTracks(trackIds...)
+	if err != nil {
+		return err
+	}
+	report.Checked += len(trackIds)
+
+	var unavailable []string
+	updated := make([]models.Song, 0, len(songs))
+	for index, song := range songs {
+		if song == nil {
+			unavailable = append(unavailable, trackIds[index])
+			continue
+		}
+
+		if err := r.repo.SaveCachedTrack(song); err != nil {
+			return err
+		}
+		updated = append(updated, *song)
+	}
+
+	if err := r.repo.RecordPopularity(updated); err != nil {
+		return err
+	}
+	if err := r.repo.MarkSongsUnavailable(unavailable); err != nil {
+		return err
+	}
+
+	report.Updated += len(updated)
+	report.Unavailable = append(report.Unavailable, unavailable...)
+	return nil
+}
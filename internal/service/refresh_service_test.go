@@ -0,0 +1,112 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/repository"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// refreshRepoStub serves stale songs until they are saved or marked unavailable.
+type refreshRepoStub struct {
+	repository.Song
+	stale       []string
+	olderThan   time.Duration
+	saved       []models.Song
+	popularity  []models.Song
+	unavailable []string
+}
+
+func (r *refreshRepoStub) GetStaleSongs(olderThan time.Duration, limit int) ([]string, error) {
+	r.olderThan = olderThan
+	return r.stale[:min(limit, len(r.stale))], nil
+}
+
+func (r *refreshRepoStub) SaveCachedTrack(song *models.Song) error {
+	r.saved = append(r.saved, *song)
+	r.stale = slices.DeleteFunc(r.stale, func(id string) bool { return id == song.ID })
+	return nil
+}
+
+func (r *refreshRepoStub) RecordPopularity(songs []models.Song) error {
+	r.popularity = append(r.popularity, songs...)
+	return nil
+}
+
+func (r *refreshRepoStub) MarkSongsUnavailable(songIds []string) error {
+	r.unavailable = append(r.unavailable, songIds...)
+	r.stale = slices.DeleteFunc(r.stale, func(id string) bool { return slices.Contains(songIds, id) })
+	return nil
+}
+
+var refreshSettings = models.RefreshSettings{MaxAge: 24 * time.Hour, BatchSize: 2, Batches: 5, RequestsPerSecond: 1000}
+
+func TestRefreshService_RefreshStaleSongs(t *testing.T) {
+	repo := &refreshRepoStub{stale: []string{fakeTrackId(0), fakeTrackId(1), fakeTrackId(2), fakeTrackId(3)}}
+
+	fake := newFakeSpotify(t)
+	var requested []string
+	fake.handle("GET /v1/tracks", func(writer http.ResponseWriter, request *http.Request) {
+		ids := request.URL.Query().Get("ids")
+		requested = append(requested, ids)
+
+		var tracks []any
+		for _, id := range strings.Split(ids, ",") {
+			switch id {
+			case fakeTrackId(2):
+				tracks = append(tracks, nil)
+			case fakeTrackId(3):
+				track := fakeTrack(id, "Withdrawn", "Massive Attack")
+				track["available_markets"] = []string{}
+				tracks = append(tracks, track)
+			default:
+				track := fakeTrack(id, "Teardrop", "Massive Attack")
+				track["popularity"] = 77
+				tracks = append(tracks, track)
+			}
+		}
+		writeFakeJSON(writer, http.StatusOK, map[string]any{"tracks": tracks})
+	})
+
+	report, err := NewRefreshService(repo, provider.NewSpotify(fake.client()), refreshSettings).RefreshStaleSongs()
+	require.NoError(t, err)
+
+	assert.Equal(t, &models.RefreshReport{Checked: 4, Updated: 2, Unavailable: []string{fakeTrackId(2), fakeTrackId(3)}}, report)
+	assert.Equal(t, []string{fakeTrackId(0) + "," + fakeTrackId(1), fakeTrackId(2) + "," + fakeTrackId(3)}, requested)
+	assert.Equal(t, 24*time.Hour, repo.olderThan)
+	require.Len(t, repo.popularity, 2)
+	assert.Equal(t, 77, repo.popularity[0].Popularity)
+	assert.Equal(t, []string{fakeTrackId(2), fakeTrackId(3)}, repo.unavailable)
+	assert.Empty(t, repo.stale)
+}
+
+func TestRefreshService_RateLimited(t *testing.T) {
+	repo := &refreshRepoStub{stale: []string{fakeTrackId(0)}}
+
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/tracks", func(writer http.ResponseWriter, request *http.Request) {
+		writeFakeError(writer, http.StatusTooManyRequests, "API rate limit exceeded")
+	})
+
+	report, err := NewRefreshService(repo, provider.NewSpotify(fake.client()), refreshSettings).RefreshStaleSongs()
+	require.NoError(t, err)
+
+	assert.True(t, report.RateLimited)
+	assert.Zero(t, report.Checked)
+	assert.Empty(t, repo.saved)
+}
+
+func TestRefreshService_AlreadyRunning(t *testing.T) {
+	refresher := NewRefreshService(&refreshRepoStub{}, nil, refreshSettings)
+	refresher.running.Lock()
+	defer refresher.running.Unlock()
+
+	_, err := refresher.RefreshStaleSongs()
+	assert.ErrorIs(t, err, ErrRefreshRunning)
+}
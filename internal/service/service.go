@@ -1,15 +1,31 @@
 package service
 
 import (
-	"github.com/zmb3/spotify"
+	"context"
+	"music-service/internal/backup"
 	"music-service/internal/models"
+	"music-service/internal/provider"
 	"music-service/internal/repository"
+	"time"
 )
 
 type Service struct {
 	Authorization
 	PlayList
 	Song
+	Entry
+	History
+	SmartPlaylist
+	Folder
+	Stats
+	Tag
+	Trash
+	Quota
+	Import
+	Backup
+	Catalog
+	Feature
+	Refresh
 }
 
 //go:generate mockgen -source=service.go -destination=mocks/mock.go
@@ -21,27 +37,143 @@ type Authorization interface {
 	CreateToken(username, password string) (string, error)
 	InvalidateToken(userID int) error
 	IsTokenValid(token string) (bool, error)
+	IsAdmin(userId int) (bool, error)
 }
 
 type PlayList interface {
 	CreatePlaylist(playlist *models.Playlist) (int64, error)
 	GetAllPlaylists(userId int) ([]*models.Playlist, error)
 	GetPlaylistById(userId int, playlistId int) (*models.Playlist, error)
-	UpdatePlaylistById(userId int, playlist *models.Playlist) error
-	DeletePlaylistById(userId int, playlistId int) error
+	UpdatePlaylistById(change *models.PlaylistChange, playlist *models.Playlist) error
+	DeletePlaylistById(change *models.PlaylistChange) error
 }
 
 type Song interface {
-	GetAllSongsFromPlaylist(userId, playlistId int) ([]*models.Song, error)
-	CreateSong(userId, playlistId int, song *models.Song) (string, error)
-	DeleteSongFromPlaylist(userId, playlistId int, songId string) error
-	GetTrackByID(trackID string) (*spotify.FullTrack, error)
+	GetAllSongsFromPlaylist(userId, playlistId int, filter *models.EntryFilter) ([]*models.PlaylistEntry, error)
+	CreateSong(change *models.PlaylistChange, song *models.Song) (string, error)
+	CreateSongs(change *models.PlaylistChange, songs []models.Song) (*models.AddTracksReport, error)
+	DeleteSongFromPlaylist(change *models.PlaylistChange, songId string) error
+	GetTrackByID(trackID string) (*models.Song, error)
+	GetTracksByIDs(trackIds []string) ([]models.Song, error)
+	GetCacheStats() models.CacheStats
+	GetAlbum(albumId string) (*models.AlbumDetails, error)
+	GetArtist(artistId, market string) (*models.ArtistDetails, error)
+	BackfillSongArtists() (int, error)
+	GetRecommendations(userId, playlistId int, query *models.RecommendationQuery) ([]models.Song, error)
 }
 
-func NewService(repo *repository.Repository, client *spotify.Client, exp int64, secret string) *Service {
+type Entry interface {
+	GetPlaylistEntries(userId, playlistId int) ([]*models.PlaylistEntry, error)
+	MovePlaylistEntry(change *models.PlaylistChange, entryId, position int) error
+	DeletePlaylistEntry(change *models.PlaylistChange, entryId int) error
+	UpdateEntryNote(change *models.PlaylistChange, entryId int, note string) error
+	GetDuplicateEntries(userId, playlistId int) ([]*models.DuplicateGroup, error)
+	CollapseDuplicateEntries(change *models.PlaylistChange) (int64, error)
+}
+
+type History interface {
+	GetHistory(userId, playlistId int) ([]*models.PlaylistSnapshot, error)
+	GetSnapshot(userId, playlistId, snapshotId int) (*models.PlaylistSnapshot, error)
+	RevertToSnapshot(change *models.PlaylistChange, snapshotId int) error
+}
+
+type SmartPlaylist interface {
+	CreateSmartPlaylist(playlist *models.Playlist) (int64, error)
+	UpdateSmartRules(change *models.PlaylistChange, rules *models.SmartRules, refreshMode string) error
+	RefreshSmartPlaylist(change *models.PlaylistChange) (int64, error)
+	RefreshScheduledPlaylists() (int, error)
+	FreezeSmartPlaylist(playlistId int, frozen *models.Playlist) (int64, error)
+}
+
+type Folder interface {
+	CreateFolder(folder *models.Folder) (int64, error)
+	RenameFolder(userId, folderId int, name string) error
+	MoveFolder(userId, folderId int, parentId *int) error
+	DeleteFolder(userId, folderId int, mode string) error
+	MovePlaylist(userId, playlistId int, folderId *int) error
+	GetPlaylistTree(userId int) (*models.PlaylistTree, error)
+}
+
+type Stats interface {
+	GetPlaylistStats(userId, playlistId int) (*models.PlaylistStats, error)
+}
+
+type Tag interface {
+	CreateTag(userId int, name string) (int64, error)
+	GetTags(userId int, prefix string) ([]*models.Tag, error)
+	RenameTag(userId, tagId int, name string) error
+	DeleteTag(userId, tagId int) error
+	TagPlaylist(userId, playlistId int, name string) (int64, error)
+	UntagPlaylist(userId, playlistId, tagId int) error
+	GetPlaylistTags(userId, playlistId int) ([]*models.Tag, error)
+	TagSong(userId int, songId string, name string) (int64, error)
+	UntagSong(userId int, songId string, tagId int) error
+	GetSongTags(userId int, songId string) ([]*models.Tag, error)
+	FilterPlaylistsByTags(userId int, playlists []*models.Playlist, tags []string) ([]*models.Playlist, error)
+	FilterSongsByTags(userId int, entries []*models.PlaylistEntry, tags []string) ([]*models.PlaylistEntry, error)
+}
+
+type Trash interface {
+	GetTrash(userId int) ([]*models.TrashedPlaylist, error)
+	RestorePlaylist(userId, playlistId int) error
+	PurgeExpired() (int64, error)
+}
+
+type Quota interface {
+	GetLimits(userId int) (*models.QuotaLimits, error)
+	SetQuotaOverride(userId int, override *models.QuotaOverride) error
+	GetUsage(userId int) (*models.Usage, error)
+	CountRequest(userId int) error
+	PurgeRequestCounts() (int64, error)
+}
+
+type Import interface {
+	MatchItems(items []*models.ImportItem) ([]models.Song, []*models.ImportItem, error)
+	PreviewTracklist(lines []*models.TracklistLine) ([]*models.TracklistLine, error)
+	ImportPlaylist(playlist *models.Playlist) (int64, error)
+	GetSpotifyPlaylist(playlistId string) (*models.RemotePlaylist, error)
+	StartSpotifyImport(userId int, playlist *models.RemotePlaylist, name string) (*models.ImportJob, error)
+	GetImportJob(userId, jobId int) (*models.ImportJob, error)
+	WaitForImports()
+}
+
+type Backup interface {
+	ExportBackup(userId int) (*backup.Document, error)
+	RestoreBackup(userId int, document *backup.Document) (*models.RestoreReport, error)
+}
+
+type Catalog interface {
+	Search(query *models.SearchQuery) (*models.SearchResult, error)
+}
+
+type Refresh interface {
+	RefreshStaleSongs() (*models.RefreshReport, error)
+}
+
+type Feature interface {
+	StoreAudioFeatures(trackIds ...string) error
+	BackfillAudioFeatures() (int, error)
+}
+
+func NewService(ctx context.Context, repo *repository.Repository, catalog provider.MusicProvider, exp int64, secret string, trashRetention time.Duration, quotas models.QuotaLimits, cacheSettings models.CacheSettings, refreshSettings models.RefreshSettings) *Service {
+	features := NewFeatureService(repo.AudioFeatures, catalog)
+	quota := NewQuotaService(repo.Quota, quotas)
 	return &Service{
 		Authorization: NewAuthService(repo.Authorization, secret, exp, repo.Token),
-		PlayList:      NewPlaylistService(repo.PlayList),
-		Song:          NewSpotifyService(repo.Song, client),
+		PlayList:      NewPlaylistService(repo.PlayList, quota),
+		Song:          NewSpotifyService(repo.Song, repo.PlayList, repo.SmartPlaylist, catalog, cacheSettings, quota),
+		Entry:         NewEntryService(repo.Entry),
+		History:       NewHistoryService(repo.History, quota),
+		SmartPlaylist: NewSmartPlaylistService(repo.SmartPlaylist, quota),
+		Folder:        NewFolderService(repo.Folder, repo.PlayList),
+		Stats:         NewStatsService(repo.Stats),
+		Tag:           NewTagService(repo.Tag),
+		Trash:         NewTrashService(repo.Trash, quota, trashRetention),
+		Quota:         quota,
+		Import:        NewImportService(ctx, repo.Import, catalog, quota),
+		Backup:        NewBackupService(repo.Backup, repo.Authorization, repo.PlayList, repo.Entry, repo.SmartPlaylist, repo.Quota, catalog, quota),
+		Catalog:       NewCatalogService(catalog),
+		Feature:       features,
+		Refresh:       NewRefreshService(repo.Song, catalog, refreshSettings),
 	}
 }
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"music-service/internal/models"
+	"music-service/internal/repository"
+)
+
+type SmartPlaylistService struct {
+	repo  repository.SmartPlaylist
+	quota Quota
+}
+
+func NewSmartPlaylistService(
+	repo repository.SmartPlaylist,
+	quota Quota,
+) *SmartPlaylistService {
+	return &SmartPlaylistService{
+		repo:  repo,
+		quota: quota,
+	}
+}
+
+func (s *SmartPlaylistService) CreateSmartPlaylist(playlist *models.Playlist) (int64, error) {
+	if playlist.RefreshMode == "" {
+		playlist.RefreshMode = models.RefreshOnRead
+	}
+
+	limits, err := s.quota.GetLimits(playlist.UserId)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.CreateSmartPlaylist(playlist, limits)
+}
+
+func (s *SmartPlaylistService) UpdateSmartRules(change *models.PlaylistChange, rules *models.SmartRules, refreshMode string) error {
+	limits, err := s.quota.GetLimits(change.UserId)
+	if err != nil {
+		return err
+	}
+	change.Limits = limits
+	return s.repo.UpdateSmartRules(change, rules, refreshMode)
+}
+
+func (s *SmartPlaylistService) RefreshSmartPlaylist(change *models.PlaylistChange) (int64, error) {
+	limits, err := s.quota.GetLimits(change.UserId)
+	if err != nil {
+		return 0, err
+	}
+	change.Limits = limits
+	return s.repo.RefreshSmartPlaylist(change)
+}
+
+// RefreshScheduledPlaylists re-materializes every smart playlist in scheduled mode.
+// A failed playlist doesn't stop the others; all failures come back joined.
+func (s *SmartPlaylistService) RefreshScheduledPlaylists() (int, error) {
+	playlists, err := s.repo.GetScheduledSmartPlaylists()
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	var failures []error
+	for _, playlist := range playlists {
+		_, err := s.RefreshSmartPlaylist(&models.PlaylistChange{UserId: playlist.UserId, PlaylistId: playlist.ID})
+		if err != nil {
+			failures = append(failures, fmt.Errorf("playlist %d: %w", playlist.ID, err))
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, errors.Join(failures...)
+}
+
+func (s *SmartPlaylistService) FreezeSmartPlaylist(playlistId int, frozen *models.Playlist) (int64, error) {
+	limits, err := s.quota.GetLimits(frozen.UserId)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.FreezeSmartPlaylist(playlistId, frozen, limits)
+}
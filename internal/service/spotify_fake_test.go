@@ -0,0 +1,131 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/zmb3/spotify"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// fakeSpotify serves canned Web API responses from a local server. The client it
+// hands out sends every request there, whatever host the library puts in the URL,
+// so paging links can keep pointing at api.spotify.com.
+type fakeSpotify struct {
+	server *httptest.Server
+	mux    *http.ServeMux
+}
+
+func newFakeSpotify(t *testing.T) *fakeSpotify {
+	mux := http.NewServeMux()
+	fake := &fakeSpotify{
+		server: httptest.NewServer(mux),
+		mux:    mux,
+	}
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+func (f *fakeSpotify) handle(pattern string, handler http.HandlerFunc) {
+	f.mux.HandleFunc(pattern, handler)
+}
+
+func (f *fakeSpotify) client() *spotify.Client {
+	target, _ := url.Parse(f.server.URL)
+	client := spotify.NewClient(&http.Client{Transport: redirectTransport{target: target}})
+	return &client
+}
+
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (r redirectTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	request.URL.Scheme = r.target.Scheme
+	request.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func writeFakeJSON(writer http.ResponseWriter, status int, body any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(body)
+}
+
+func writeFakeError(writer http.ResponseWriter, status int, message string) {
+	writeFakeJSON(writer, status, map[string]any{
+		"error": map[string]any{"status": status, "message": message},
+	})
+}
+
+func fakeTrack(id, name, artist string) map[string]any {
+	return map[string]any{
+		"id":            id,
+		"name":          name,
+		"artists":       []map[string]any{{"name": artist}},
+		"album":         map[string]any{"name": "Album " + name, "images": []map[string]any{{"url": "https://i.scdn.co/" + id}}},
+		"duration_ms":   180000,
+		"popularity":    50,
+		"external_urls": map[string]string{"spotify": "https://open.spotify.com/track/" + id},
+	}
+}
+
+func fakeAlbum(id, name, artist string) map[string]any {
+	return map[string]any{
+		"id":            id,
+		"name":          name,
+		"album_type":    "album",
+		"artists":       []map[string]any{{"name": artist}},
+		"images":        []map[string]any{{"url": "https://i.scdn.co/" + id}},
+		"release_date":  "1998-04-20",
+		"external_urls": map[string]string{"spotify": "https://open.spotify.com/album/" + id},
+	}
+}
+
+func fakeArtist(id, name string, genres ...string) map[string]any {
+	return map[string]any{
+		"id":            id,
+		"name":          name,
+		"genres":        genres,
+		"popularity":    70,
+		"images":        []map[string]any{{"url": "https://i.scdn.co/" + id}},
+		"external_urls": map[string]string{"spotify": "https://open.spotify.com/artist/" + id},
+	}
+}
+
+// fakeTrackId builds a valid looking 22 character id for the n-th track.
+func fakeTrackId(n int) string {
+	return fmt.Sprintf("track%017d", n)
+}
+
+// servePlaylistTracks pages through total tracks the way Spotify does, with next
+// links until the last page.
+func servePlaylistTracks(total int, local map[int]bool) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		offset, _ := strconv.Atoi(request.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(request.URL.Query().Get("limit"))
+		if limit == 0 {
+			limit = 100
+		}
+
+		items := make([]map[string]any, 0, limit)
+		for n := offset; n < offset+limit && n < total; n++ {
+			if local[n] {
+				items = append(items, map[string]any{"is_local": true, "track": map[string]any{"name": "local file"}})
+				continue
+			}
+			items = append(items, map[string]any{"track": fakeTrack(fakeTrackId(n), fmt.Sprintf("Song %d", n), "Artist")})
+		}
+
+		page := map[string]any{"items": items, "total": total, "limit": limit, "offset": offset}
+		if offset+limit < total {
+			page["next"] = fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks?offset=%d&limit=%d",
+				request.PathValue("id"), offset+limit, limit)
+		}
+		writeFakeJSON(writer, http.StatusOK, page)
+	}
+}
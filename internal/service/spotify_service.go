@@ -1,43 +1,291 @@
 package service
 
 import (
-	"github.com/zmb3/spotify"
+	"errors"
+	"fmt"
 	"music-service/internal/models"
+	"music-service/internal/provider"
 	"music-service/internal/repository"
+	"music-service/internal/trackcache"
+	"music-service/pkg/utils"
+	"sort"
+)
+
+const (
+	// tracksBatchSize is the most tracks Spotify returns from a single several-tracks call.
+	tracksBatchSize = 50
+	// maxRecommendations is the most tracks Spotify recommends in one call.
+	maxRecommendations = 100
+	// seedArtists of the models.MaxRecommendationSeeds seeds go to the playlist's most
+	// frequent artists, the rest to its most popular tracks.
+	seedArtists = 2
+)
+
+var (
+	ErrNoRecommendationSeeds = errors.New("playlist has no Spotify tracks to seed recommendations from")
+	// ErrArtistsPending is returned for an artist filter on a playlist holding songs
+	// the artist backfill hasn't reached yet, the result would miss them.
+	ErrArtistsPending = errors.New("artist credits of this playlist are still being resolved")
 )
 
 type SpotifyService struct {
-	repo   repository.Song
-	client *spotify.Client
+	repo         repository.Song
+	playlistRepo repository.PlayList
+	smartRepo    repository.SmartPlaylist
+	catalog      provider.MusicProvider
+	cache        *trackcache.Cache
+	quota        Quota
 }
 
 func NewSpotifyService(
 	repo repository.Song,
-	client *spotify.Client,
+	playlistRepo repository.PlayList,
+	smartRepo repository.SmartPlaylist,
+	catalog provider.MusicProvider,
+	cacheSettings models.CacheSettings,
+	quota Quota,
 ) *SpotifyService {
-	return &SpotifyService{
-		repo:   repo,
-		client: client,
+	s := &SpotifyService{
+		repo:         repo,
+		playlistRepo: playlistRepo,
+		smartRepo:    smartRepo,
+		catalog:      catalog,
+		quota:        quota,
 	}
+	s.cache = trackcache.New(cacheSettings, repo, s.fetchTrack)
+	return s
 }
 
-func (s *SpotifyService) GetAllSongsFromPlaylist(userId, playlistId int) ([]*models.Song, error) {
-	return s.repo.GetAllSongsFromPlaylist(userId, playlistId)
+// GetAllSongsFromPlaylist returns the playlist entries. Live smart playlists are
+// evaluated on the fly, so their entries carry no added_at, added_by or note and
+// the filter doesn't apply to them.
+func (s *SpotifyService) GetAllSongsFromPlaylist(userId, playlistId int, filter *models.EntryFilter) ([]*models.PlaylistEntry, error) {
+	playlist, err := s.playlistRepo.GetPlaylistById(userId, playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	if playlist.Kind == models.PlaylistKindSmart && playlist.RefreshMode != models.RefreshScheduled {
+		songs, err := s.smartRepo.EvaluateRules(userId, playlist.Rules)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]*models.PlaylistEntry, 0, len(songs))
+		for i, song := range songs {
+			entries = append(entries, &models.PlaylistEntry{Position: i + 1, Song: *song})
+		}
+		return entries, nil
+	}
+
+	if filter != nil && filter.ArtistId != "" {
+		pending, err := s.repo.HasSongsWithoutArtists(playlistId)
+		if err != nil {
+			return nil, err
+		}
+		if pending {
+			return nil, ErrArtistsPending
+		}
+	}
+
+	return s.repo.GetAllSongsFromPlaylist(userId, playlistId, filter)
 }
 
-func (s *SpotifyService) CreateSong(userId, playlistId int, song *models.Song) (string, error) {
-	return s.repo.CreateSong(userId, playlistId, song)
+// CreateSong leaves the audio features of the track to the features backfill.
+func (s *SpotifyService) CreateSong(change *models.PlaylistChange, song *models.Song) (string, error) {
+	limits, err := s.quota.GetLimits(change.UserId)
+	if err != nil {
+		return "", err
+	}
+	change.Limits = limits
+
+	return s.repo.CreateSong(change, song)
 }
 
-func (s *SpotifyService) DeleteSongFromPlaylist(userId, playlistId int, songId string) error {
-	return s.repo.DeleteSongFromPlaylist(userId, playlistId, songId)
+func (s *SpotifyService) CreateSongs(change *models.PlaylistChange, songs []models.Song) (*models.AddTracksReport, error) {
+	limits, err := s.quota.GetLimits(change.UserId)
+	if err != nil {
+		return nil, err
+	}
+	change.Limits = limits
+
+	return s.repo.CreateSongs(change, songs)
 }
 
-func (s *SpotifyService) GetTrackByID(trackID string) (*spotify.FullTrack, error) {
-	spotifyID := spotify.ID(trackID)
-	track, err := s.client.GetTrack(spotifyID)
+func (s *SpotifyService) DeleteSongFromPlaylist(change *models.PlaylistChange, songId string) error {
+	return s.repo.DeleteSongFromPlaylist(change, songId)
+}
+
+// GetTrackByID goes through the catalog cache, Spotify is only asked when no fresh
+// copy of the track is held.
+func (s *SpotifyService) GetTrackByID(trackID string) (*models.Song, error) {
+	return s.cache.Get(trackID)
+}
+
+// GetTracksByIDs looks the tracks up in as few catalog calls as possible and returns
+// them in the order of the ids. It fails when any of them is unknown.
+func (s *SpotifyService) GetTracksByIDs(trackIds []string) ([]models.Song, error) {
+	found, err := s.catalog.GetTracks(trackIds...)
 	if err != nil {
 		return nil, err
 	}
-	return track, nil
+
+	songs := make([]models.Song, 0, len(found))
+	for index, song := range found {
+		if song == nil {
+			return nil, fmt.Errorf("%w: track %s", provider.ErrNotFound, trackIds[index])
+		}
+		songs = append(songs, *song)
+	}
+	return songs, nil
+}
+
+func (s *SpotifyService) GetCacheStats() models.CacheStats {
+	return s.cache.Stats()
+}
+
+func (s *SpotifyService) fetchTrack(trackID string) (*models.Song, error) {
+	return s.catalog.GetTrack(trackID)
+}
+
+// BackfillSongArtists re-fetches the songs stored before artists and albums had their
+// own tables, batch by batch until none is left, and returns how many it resolved.
+// Songs the catalog no longer serves are flagged unavailable. It stops at the first
+// error, the next run picks up where it stopped.
+func (s *SpotifyService) BackfillSongArtists() (int, error) {
+	resolved := 0
+	for {
+		trackIds, err := s.repo.GetSongsWithoutArtists(tracksBatchSize)
+		if err != nil || len(trackIds) == 0 {
+			return resolved, err
+		}
+
+		songs, err := s.catalog.GetTracks(trackIds...)
+		if err != nil {
+			return resolved, err
+		}
+
+		var unavailable []string
+		for index, song := range songs {
+			if song == nil {
+				unavailable = append(unavailable, trackIds[index])
+				continue
+			}
+			if err := s.repo.SaveCachedTrack(song); err != nil {
+				return resolved, err
+			}
+			resolved++
+		}
+		if err := s.repo.MarkSongsUnavailable(unavailable); err != nil {
+			return resolved, err
+		}
+		resolved += len(unavailable)
+
+		if len(trackIds) < tracksBatchSize {
+			return resolved, nil
+		}
+	}
+}
+
+func (s *SpotifyService) GetAlbum(albumId string) (*models.AlbumDetails, error) {
+	return s.catalog.GetAlbum(albumId)
+}
+
+func (s *SpotifyService) GetArtist(artistId, market string) (*models.ArtistDetails, error) {
+	return s.catalog.GetArtist(artistId, market)
+}
+
+// GetRecommendations asks Spotify for tracks like the ones in the playlist, leaving
+// out those already in it. Spotify only returns simplified tracks, so the picked
+// ones are fetched in full afterwards.
+func (s *SpotifyService) GetRecommendations(userId, playlistId int, query *models.RecommendationQuery) ([]models.Song, error) {
+	entries, err := s.GetAllSongsFromPlaylist(userId, playlistId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	seeds := recommendationSeeds(entries)
+	if len(seeds.Artists)+len(seeds.Tracks) == 0 {
+		return nil, ErrNoRecommendationSeeds
+	}
+
+	existing := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		existing[entry.ID] = true
+	}
+
+	// Ask for extra tracks so the limit still holds after dropping playlist tracks.
+	request := *query
+	request.Limit = min(query.Limit+len(existing), maxRecommendations)
+	recommended, err := s.catalog.GetRecommendations(seeds, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, query.Limit)
+	for _, trackId := range recommended {
+		if len(ids) == query.Limit {
+			break
+		}
+		if existing[trackId] {
+			continue
+		}
+		existing[trackId] = true
+		ids = append(ids, trackId)
+	}
+
+	found, err := s.catalog.GetTracks(ids...)
+	if err != nil {
+		return nil, err
+	}
+	songs := make([]models.Song, 0, len(found))
+	for _, song := range found {
+		if song != nil {
+			songs = append(songs, *song)
+		}
+	}
+	return songs, nil
+}
+
+// recommendationSeeds picks the most frequent primary artists and the most popular
+// tracks of the playlist. Ties go to the earlier entry, so the same playlist always
+// seeds the same way. Without a known artist every seed is a track.
+func recommendationSeeds(entries []*models.PlaylistEntry) *models.RecommendationSeeds {
+	seeds := &models.RecommendationSeeds{}
+
+	artistCounts := make(map[string]int)
+	var artists []string
+	for _, entry := range entries {
+		if len(entry.Artists) == 0 {
+			continue
+		}
+		artistId := entry.Artists[0].ID
+		if artistCounts[artistId] == 0 {
+			artists = append(artists, artistId)
+		}
+		artistCounts[artistId]++
+	}
+	sort.SliceStable(artists, func(i, j int) bool {
+		return artistCounts[artists[i]] > artistCounts[artists[j]]
+	})
+	for _, artistId := range artists[:min(seedArtists, len(artists))] {
+		seeds.Artists = append(seeds.Artists, artistId)
+	}
+
+	seen := make(map[string]bool, len(entries))
+	tracks := make([]*models.PlaylistEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !seen[entry.ID] && utils.IsSpotifyID(entry.ID) {
+			seen[entry.ID] = true
+			tracks = append(tracks, entry)
+		}
+	}
+	sort.SliceStable(tracks, func(i, j int) bool {
+		return tracks[i].Popularity > tracks[j].Popularity
+	})
+	for _, entry := range tracks[:min(models.MaxRecommendationSeeds-len(seeds.Artists), len(tracks))] {
+		seeds.Tracks = append(seeds.Tracks, entry.ID)
+	}
+
+	return seeds
 }
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zmb3/spotify"
+	"music-service/internal/models"
+	"music-service/internal/provider"
+	"music-service/internal/repository"
+	"net/http"
+	"testing"
+)
+
+const (
+	mezzanineId     = "49MNmJhZQewjt06rpwp6QR"
+	massiveAttackId = "6FXMGgJwohJLUSr5nVlf9X"
+)
+
+func albumTrack(n int) map[string]any {
+	return map[string]any{
+		"id":            fakeTrackId(n),
+		"name":          fmt.Sprintf("Song %d", n),
+		"artists":       []map[string]any{{"id": massiveAttackId, "name": "Massive Attack"}},
+		"duration_ms":   300000,
+		"explicit":      n == 1,
+		"track_number":  n + 1,
+		"disc_number":   1,
+		"external_urls": map[string]string{"spotify": "https://open.spotify.com/track/" + fakeTrackId(n)},
+	}
+}
+
+func TestSpotifyService_GetAlbum(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/albums/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		album := fakeAlbum(request.PathValue("id"), "Mezzanine", "Massive Attack")
+		album["release_date_precision"] = "day"
+		album["tracks"] = map[string]any{
+			"items":  []map[string]any{albumTrack(0), albumTrack(1)},
+			"total":  3,
+			"limit":  2,
+			"offset": 0,
+			"next":   "https://api.spotify.com/v1/albums/" + mezzanineId + "/tracks?offset=2&limit=2",
+		}
+		writeFakeJSON(writer, http.StatusOK, album)
+	})
+	fake.handle("GET /v1/albums/{id}/tracks", func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "2", request.URL.Query().Get("offset"))
+		writeFakeJSON(writer, http.StatusOK, map[string]any{
+			"items": []map[string]any{albumTrack(2)},
+			"total": 3, "limit": 2, "offset": 2,
+		})
+	})
+
+	spotifyService := NewSpotifyService(nil, nil, nil, provider.NewSpotify(fake.client()), models.CacheSettings{}, nil)
+	album, err := spotifyService.GetAlbum(mezzanineId)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Mezzanine", album.Name)
+	assert.Equal(t, 3, album.TotalTracks)
+	require.Len(t, album.Tracks, 3)
+	for n, song := range album.Tracks {
+		assert.Equal(t, models.Song{
+			ID:                   fakeTrackId(n),
+			Title:                fmt.Sprintf("Song %d", n),
+			Artist:               "Massive Attack",
+			Artists:              []models.SongArtist{{ID: massiveAttackId, Name: "Massive Attack"}},
+			Album:                "Mezzanine",
+			AlbumId:              mezzanineId,
+			AlbumCover:           "https://i.scdn.co/" + mezzanineId,
+			Duration:             300,
+			ReleaseDate:          "1998-04-20",
+			ReleaseDatePrecision: "day",
+			ExternalURL:          "https://open.spotify.com/track/" + fakeTrackId(n),
+			Explicit:             n == 1,
+			TrackNumber:          n + 1,
+			DiscNumber:           1,
+		}, song)
+	}
+}
+
+func TestSpotifyService_GetAlbumNotFound(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/albums/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		writeFakeError(writer, http.StatusNotFound, "non existing id")
+	})
+
+	spotifyService := NewSpotifyService(nil, nil, nil, provider.NewSpotify(fake.client()), models.CacheSettings{}, nil)
+	_, err := spotifyService.GetAlbum(mezzanineId)
+
+	var apiErr spotify.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.Status)
+}
+
+func TestSpotifyService_GetArtist(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/artists/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		writeFakeJSON(writer, http.StatusOK, fakeArtist(request.PathValue("id"), "Massive Attack", "trip hop"))
+	})
+	fake.handle("GET /v1/artists/{id}/top-tracks", func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "GB", request.URL.Query().Get("country"))
+		writeFakeJSON(writer, http.StatusOK, map[string]any{
+			"tracks": []map[string]any{fakeTrack("67Hna13dNDkZvBpTXRIaOJ", "Teardrop", "Massive Attack")},
+		})
+	})
+	fake.handle("GET /v1/artists/{id}/albums", func(writer http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+		assert.Equal(t, "GB", query.Get("market"))
+		assert.Equal(t, "album,single", query.Get("include_groups"))
+		assert.Equal(t, "50", query.Get("limit"))
+		writeFakeJSON(writer, http.StatusOK, map[string]any{
+			"items": []map[string]any{fakeAlbum(mezzanineId, "Mezzanine", "Massive Attack")},
+			"total": 24, "limit": 50, "offset": 0,
+		})
+	})
+
+	spotifyService := NewSpotifyService(nil, nil, nil, provider.NewSpotify(fake.client()), models.CacheSettings{}, nil)
+	artist, err := spotifyService.GetArtist(massiveAttackId, "GB")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Massive Attack", artist.Name)
+	assert.Equal(t, []string{"trip hop"}, artist.Genres)
+	require.Len(t, artist.TopTracks, 1)
+	assert.Equal(t, "Teardrop", artist.TopTracks[0].Title)
+	assert.Equal(t, 24, artist.Albums.Total)
+	require.Len(t, artist.Albums.Items, 1)
+	assert.Equal(t, "Mezzanine", artist.Albums.Items[0].Name)
+}
+
+// songRepoStub serves the playlist entries and the songs waiting for artist credits,
+// and records what got created and saved. Pending songs leave the backlog once served.
+type songRepoStub struct {
+	repository.Song
+	entries     []*models.PlaylistEntry
+	pending     []string
+	limit       int
+	created     []string
+	saved       []models.Song
+	unavailable []string
+	saveErr     error
+}
+
+func (r *songRepoStub) GetAllSongsFromPlaylist(userId, playlistId int, filter *models.EntryFilter) ([]*models.PlaylistEntry, error) {
+	return r.entries, nil
+}
+
+func (r *songRepoStub) CreateSong(change *models.PlaylistChange, song *models.Song) (string, error) {
+	r.created = append(r.created, song.ID)
+	return song.ID, nil
+}
+
+func (r *songRepoStub) GetSongsWithoutArtists(limit int) ([]string, error) {
+	r.limit = limit
+	batch := r.pending[:min(limit, len(r.pending))]
+	r.pending = r.pending[len(batch):]
+	return batch, nil
+}
+
+func (r *songRepoStub) HasSongsWithoutArtists(playlistId int) (bool, error) {
+	return len(r.pending) > 0, nil
+}
+
+func (r *songRepoStub) SaveCachedTrack(song *models.Song) error {
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+	r.saved = append(r.saved, *song)
+	return nil
+}
+
+func (r *songRepoStub) MarkSongsUnavailable(songIds []string) error {
+	r.unavailable = append(r.unavailable, songIds...)
+	return nil
+}
+
+func TestSpotifyService_BackfillSongArtists(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/tracks", func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, fakeTrackId(0)+","+fakeTrackId(1), request.URL.Query().Get("ids"))
+		track := fakeTrack(fakeTrackId(0), "Teardrop", "Massive Attack")
+		track["artists"] = []map[string]any{
+			{"id": massiveAttackId, "name": "Massive Attack"},
+			{"id": "2wLSoPCbMkCIdtZCasTGtI", "name": "Elizabeth Fraser"},
+		}
+		// Spotify answers null for a track it no longer knows.
+		writeFakeJSON(writer, http.StatusOK, map[string]any{"tracks": []any{track, nil}})
+	})
+
+	repo := &songRepoStub{pending: []string{fakeTrackId(0), fakeTrackId(1)}}
+	spotifyService := NewSpotifyService(repo, nil, nil, provider.NewSpotify(fake.client()), models.CacheSettings{}, nil)
+	resolved, err := spotifyService.BackfillSongArtists()
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, resolved)
+	assert.Equal(t, 50, repo.limit)
+	require.Len(t, repo.saved, 1)
+	assert.Equal(t, "Teardrop", repo.saved[0].Title)
+	assert.Equal(t, []models.SongArtist{
+		{ID: massiveAttackId, Name: "Massive Attack"},
+		{ID: "2wLSoPCbMkCIdtZCasTGtI", Name: "Elizabeth Fraser"},
+	}, repo.saved[0].Artists)
+	assert.Equal(t, []string{fakeTrackId(1)}, repo.unavailable, "a track Spotify dropped leaves the backlog")
+}
+
+func TestSpotifyService_BackfillSongArtistsNothingPending(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/tracks", func(writer http.ResponseWriter, request *http.Request) {
+		t.Error("no tracks should be fetched")
+	})
+
+	repo := &songRepoStub{}
+	resolved, err := NewSpotifyService(repo, nil, nil, provider.NewSpotify(fake.client()), models.CacheSettings{}, nil).BackfillSongArtists()
+	require.NoError(t, err)
+	assert.Zero(t, resolved)
+	assert.Empty(t, repo.saved)
+}
+
+func TestSpotifyService_BackfillSongArtistsAllBatches(t *testing.T) {
+	var songs []models.Song
+	var pending []string
+	for n := 0; n < 75; n++ {
+		// The catalog credits no artist, the songs are resolved all the same.
+		songs = append(songs, models.Song{ID: fakeTrackId(n)})
+		pending = append(pending, fakeTrackId(n))
+	}
+
+	repo := &songRepoStub{pending: pending}
+	resolved, err := NewSpotifyService(repo, nil, nil, provider.NewFake(songs...), models.CacheSettings{}, nil).BackfillSongArtists()
+	require.NoError(t, err)
+	assert.Equal(t, 75, resolved)
+	assert.Len(t, repo.saved, 75)
+	assert.Empty(t, repo.pending)
+}
+
+func TestSpotifyService_BackfillSongArtistsFails(t *testing.T) {
+	repo := &songRepoStub{pending: []string{fakeTrackId(0)}, saveErr: errors.New("database error")}
+	resolved, err := NewSpotifyService(repo, nil, nil, provider.NewFake(models.Song{ID: fakeTrackId(0)}), models.CacheSettings{}, nil).BackfillSongArtists()
+	assert.EqualError(t, err, "database error")
+	assert.Zero(t, resolved)
+}
+
+func TestSpotifyService_GetTracksByIDs(t *testing.T) {
+	catalog := provider.NewFake(models.Song{ID: "track1", Title: "Teardrop"}, models.Song{ID: "track2", Title: "Angel"})
+	spotifyService := NewSpotifyService(nil, nil, nil, catalog, models.CacheSettings{}, nil)
+
+	songs, err := spotifyService.GetTracksByIDs([]string{"track2", "track1"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.Song{{ID: "track2", Title: "Angel"}, {ID: "track1", Title: "Teardrop"}}, songs)
+
+	_, err = spotifyService.GetTracksByIDs([]string{"track1", "track3"})
+	assert.ErrorIs(t, err, provider.ErrNotFound)
+	assert.EqualError(t, err, "not found in the catalog: track track3")
+}
+
+type playlistRepoStub struct {
+	repository.PlayList
+}
+
+func (playlistRepoStub) GetPlaylistById(userId, playlistId int) (*models.Playlist, error) {
+	return &models.Playlist{ID: playlistId, Kind: models.PlaylistKindManual}, nil
+}
+
+func entryOf(trackId, artistId string, popularity int) *models.PlaylistEntry {
+	entry := &models.PlaylistEntry{Song: models.Song{ID: trackId, Popularity: popularity}}
+	if artistId != "" {
+		entry.Artists = []models.SongArtist{{ID: artistId}}
+	}
+	return entry
+}
+
+func TestSpotifyService_GetAllSongsFromPlaylistArtistsPending(t *testing.T) {
+	repo := &songRepoStub{pending: []string{fakeTrackId(0)}, entries: []*models.PlaylistEntry{entryOf(fakeTrackId(1), massiveAttackId, 40)}}
+	spotifyService := NewSpotifyService(repo, playlistRepoStub{}, nil, nil, models.CacheSettings{}, nil)
+
+	_, err := spotifyService.GetAllSongsFromPlaylist(1, 1, &models.EntryFilter{ArtistId: massiveAttackId})
+	assert.ErrorIs(t, err, ErrArtistsPending)
+
+	entries, err := spotifyService.GetAllSongsFromPlaylist(1, 1, &models.EntryFilter{})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "only the artist filter waits for the backfill")
+}
+
+func TestSpotifyService_GetRecommendations(t *testing.T) {
+	const portisheadId = "6liAMWkVf5LH7YR9yfFy1Y"
+	repo := &songRepoStub{entries: []*models.PlaylistEntry{
+		entryOf(fakeTrackId(0), massiveAttackId, 40),
+		entryOf(fakeTrackId(1), portisheadId, 70),
+		entryOf(fakeTrackId(2), massiveAttackId, 60),
+		entryOf("local-file", "", 90),
+	}}
+
+	fake := newFakeSpotify(t)
+	fake.handle("GET /v1/recommendations", func(writer http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+		assert.Equal(t, massiveAttackId+","+portisheadId, query.Get("seed_artists"))
+		assert.Equal(t, fakeTrackId(1)+","+fakeTrackId(2)+","+fakeTrackId(0), query.Get("seed_tracks"))
+		assert.Equal(t, "170", query.Get("target_tempo"))
+		assert.Equal(t, "GB", query.Get("market"))
+		assert.Equal(t, "6", query.Get("limit"))
+		writeFakeJSON(writer, http.StatusOK, map[string]any{"tracks": []map[string]any{
+			{"id": fakeTrackId(1), "name": "Already there"},
+			{"id": fakeTrackId(5), "name": "Roads"},
+			{"id": fakeTrackId(5), "name": "Roads"},
+			{"id": fakeTrackId(6), "name": "Glory Box"},
+			{"id": fakeTrackId(7), "name": "Over the limit"},
+		}})
+	})
+	fake.handle("GET /v1/tracks", func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, fakeTrackId(5)+","+fakeTrackId(6), request.URL.Query().Get("ids"))
+		writeFakeJSON(writer, http.StatusOK, map[string]any{"tracks": []any{
+			fakeTrack(fakeTrackId(5), "Roads", "Portishead"),
+			fakeTrack(fakeTrackId(6), "Glory Box", "Portishead"),
+		}})
+	})
+
+	spotifyService := NewSpotifyService(repo, playlistRepoStub{}, nil, provider.NewSpotify(fake.client()), models.CacheSettings{}, nil)
+	songs, err := spotifyService.GetRecommendations(1, 1, &models.RecommendationQuery{
+		Limit:   2,
+		Market:  "GB",
+		Targets: map[string]float64{models.FeatureTempo: 170},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, songs, 2)
+	assert.Equal(t, "Roads", songs[0].Title)
+	assert.Equal(t, "Glory Box", songs[1].Title)
+	assert.Equal(t, "Album Glory Box", songs[1].Album)
+}
+
+func TestSpotifyService_GetRecommendationsEmptyPlaylist(t *testing.T) {
+	repo := &songRepoStub{entries: []*models.PlaylistEntry{entryOf("local-file", "", 90)}}
+
+	spotifyService := NewSpotifyService(repo, playlistRepoStub{}, nil, nil, models.CacheSettings{}, nil)
+	_, err := spotifyService.GetRecommendations(1, 1, &models.RecommendationQuery{Limit: 20})
+
+	assert.ErrorIs(t, err, ErrNoRecommendationSeeds)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"music-service/internal/models"
+	"music-service/internal/repository"
+	"sync"
+)
+
+type cachedStats struct {
+	revision string
+	stats    *models.PlaylistStats
+}
+
+type StatsService struct {
+	repo repository.Stats
+
+	mu    sync.Mutex
+	cache map[int]cachedStats
+}
+
+func NewStatsService(
+	repo repository.Stats,
+) *StatsService {
+	return &StatsService{
+		repo:  repo,
+		cache: make(map[int]cachedStats),
+	}
+}
+
+// GetPlaylistStats serves stats from memory while the playlist revision is unchanged
+// and recomputes them in SQL otherwise.
+func (s *StatsService) GetPlaylistStats(userId, playlistId int) (*models.PlaylistStats, error) {
+	revision, err := s.repo.GetStatsRevision(userId, playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	cached, ok := s.cache[playlistId]
+	s.mu.Unlock()
+	if ok && cached.revision == revision {
+		return cached.stats, nil
+	}
+
+	stats, err := s.repo.GetPlaylistStats(userId, playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[playlistId] = cachedStats{revision: revision, stats: stats}
+	s.mu.Unlock()
+
+	return stats, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"music-service/internal/models"
+	"music-service/internal/repository"
+	"strings"
+)
+
+// ErrNoTags is returned when a tag filter has no name left after normalizing.
+var ErrNoTags = errors.New("tag filter needs at least one non-blank tag")
+
+type TagService struct {
+	repo repository.Tag
+}
+
+func NewTagService(
+	repo repository.Tag,
+) *TagService {
+	return &TagService{
+		repo: repo,
+	}
+}
+
+func (t *TagService) CreateTag(userId int, name string) (int64, error) {
+	return t.repo.CreateTag(userId, normalizeTag(name))
+}
+
+func (t *TagService) GetTags(userId int, prefix string) ([]*models.Tag, error) {
+	return t.repo.GetTags(userId, normalizeTag(prefix))
+}
+
+func (t *TagService) RenameTag(userId, tagId int, name string) error {
+	return t.repo.RenameTag(userId, tagId, normalizeTag(name))
+}
+
+func (t *TagService) DeleteTag(userId, tagId int) error {
+	return t.repo.DeleteTag(userId, tagId)
+}
+
+func (t *TagService) TagPlaylist(userId, playlistId int, name string) (int64, error) {
+	return t.repo.TagPlaylist(userId, playlistId, normalizeTag(name))
+}
+
+func (t *TagService) UntagPlaylist(userId, playlistId, tagId int) error {
+	return t.repo.UntagPlaylist(userId, playlistId, tagId)
+}
+
+func (t *TagService) GetPlaylistTags(userId, playlistId int) ([]*models.Tag, error) {
+	return t.repo.GetPlaylistTags(userId, playlistId)
+}
+
+func (t *TagService) TagSong(userId int, songId string, name string) (int64, error) {
+	return t.repo.TagSong(userId, songId, normalizeTag(name))
+}
+
+func (t *TagService) UntagSong(userId int, songId string, tagId int) error {
+	return t.repo.UntagSong(userId, songId, tagId)
+}
+
+func (t *TagService) GetSongTags(userId int, songId string) ([]*models.Tag, error) {
+	return t.repo.GetSongTags(userId, songId)
+}
+
+func (t *TagService) FilterPlaylistsByTags(userId int, playlists []*models.Playlist, tags []string) ([]*models.Playlist, error) {
+	names := normalizeTags(tags)
+	if len(names) == 0 {
+		return nil, ErrNoTags
+	}
+
+	ids, err := t.repo.GetTaggedPlaylistIds(userId, names)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []*models.Playlist{}
+	for _, playlist := range playlists {
+		if ids[playlist.ID] {
+			filtered = append(filtered, playlist)
+		}
+	}
+	return filtered, nil
+}
+
+func (t *TagService) FilterSongsByTags(userId int, entries []*models.PlaylistEntry, tags []string) ([]*models.PlaylistEntry, error) {
+	names := normalizeTags(tags)
+	if len(names) == 0 {
+		return nil, ErrNoTags
+	}
+
+	ids, err := t.repo.GetTaggedSongIds(userId, names)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []*models.PlaylistEntry{}
+	for _, entry := range entries {
+		if ids[entry.Song.ID] {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered, nil
+}
+
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func normalizeTags(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		tag := normalizeTag(name)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
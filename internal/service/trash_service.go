@@ -0,0 +1,51 @@
+package service
+
+import (
+	"music-service/internal/models"
+	"music-service/internal/repository"
+	"time"
+)
+
+type TrashService struct {
+	repo      repository.Trash
+	quota     Quota
+	retention time.Duration
+}
+
+func NewTrashService(
+	repo repository.Trash,
+	quota Quota,
+	retention time.Duration,
+) *TrashService {
+	return &TrashService{
+		repo:      repo,
+		quota:     quota,
+		retention: retention,
+	}
+}
+
+func (t *TrashService) GetTrash(userId int) ([]*models.TrashedPlaylist, error) {
+	playlists, err := t.repo.GetTrash(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, playlist := range playlists {
+		playlist.PurgeAt = playlist.DeletedAt.Add(t.retention)
+	}
+	return playlists, nil
+}
+
+func (t *TrashService) RestorePlaylist(userId, playlistId int) error {
+	limits, err := t.quota.GetLimits(userId)
+	if err != nil {
+		return err
+	}
+	return t.repo.RestorePlaylist(userId, playlistId, limits)
+}
+
+// PurgeExpired hard-deletes everything that stayed in the trash past the retention
+// and returns how many playlists went.
+func (t *TrashService) PurgeExpired() (int64, error) {
+	return t.repo.PurgeDeletedPlaylists(t.retention)
+}
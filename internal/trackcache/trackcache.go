@@ -0,0 +1,185 @@
+// Package trackcache puts a read-through cache in front of Spotify track lookups. A
+// bounded LRU in memory is backed by the songs table, so tracks survive restarts
+// and an outdated copy can stand in while Spotify is unreachable.
+package trackcache
+
+import (
+	"container/list"
+	"music-service/internal/models"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Store is the durable tier. GetCachedTrack returns nil without an error for a track
+// it doesn't hold.
+type Store interface {
+	GetCachedTrack(trackId string) (*models.Song, time.Time, error)
+	SaveCachedTrack(song *models.Song) error
+}
+
+// FetchFunc looks a track up at the source.
+type FetchFunc func(trackId string) (*models.Song, error)
+
+type entry struct {
+	trackId   string
+	song      models.Song
+	fetchedAt time.Time
+}
+
+type Cache struct {
+	settings models.CacheSettings
+	store    Store
+	fetch    FetchFunc
+	now      func() time.Time
+
+	mu           sync.Mutex
+	items        map[string]*list.Element
+	order        *list.List
+	revalidating map[string]bool
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	stale  atomic.Int64
+	errors atomic.Int64
+}
+
+func New(settings models.CacheSettings, store Store, fetch FetchFunc) *Cache {
+	return &Cache{
+		settings:     settings,
+		store:        store,
+		fetch:        fetch,
+		now:          time.Now,
+		items:        make(map[string]*list.Element),
+		order:        list.New(),
+		revalidating: make(map[string]bool),
+	}
+}
+
+// Get answers from memory, then from the store, and only asks the source when
+// neither holds a usable copy. A stale copy is returned right away while a
+// background refresh replaces it; when the source fails, any copy is better than
+// none and is returned instead of the error.
+func (c *Cache) Get(trackId string) (*models.Song, error) {
+	cached, found := c.lookup(trackId)
+	if !found {
+		// A failing store is no reason to fail the lookup, the source still answers.
+		song, fetchedAt, err := c.store.GetCachedTrack(trackId)
+		if err == nil && song != nil {
+			cached = c.put(trackId, song, fetchedAt)
+			found = true
+		}
+	}
+
+	if found {
+		age := c.now().Sub(cached.fetchedAt)
+		switch {
+		case age < c.settings.TTL:
+			c.hits.Add(1)
+			return &cached.song, nil
+		case age < c.settings.TTL+c.settings.StaleTTL:
+			c.stale.Add(1)
+			c.revalidate(trackId)
+			return &cached.song, nil
+		}
+	}
+
+	c.misses.Add(1)
+	song, err := c.refresh(trackId)
+	if err != nil {
+		if found {
+			c.stale.Add(1)
+			return &cached.song, nil
+		}
+		return nil, err
+	}
+	return song, nil
+}
+
+func (c *Cache) Stats() models.CacheStats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+
+	return models.CacheStats{
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Stale:    c.stale.Load(),
+		Errors:   c.errors.Load(),
+		Size:     size,
+		Capacity: c.settings.Size,
+	}
+}
+
+// refresh fetches the track and stores it in both tiers. The store only logs a
+// failed save, the track is still good to return.
+func (c *Cache) refresh(trackId string) (*models.Song, error) {
+	song, err := c.fetch(trackId)
+	if err != nil {
+		c.errors.Add(1)
+		return nil, err
+	}
+
+	_ = c.store.SaveCachedTrack(song)
+	c.put(trackId, song, c.now())
+	return song, nil
+}
+
+// revalidate refreshes the track in the background, once at a time per track.
+func (c *Cache) revalidate(trackId string) {
+	c.mu.Lock()
+	if c.revalidating[trackId] {
+		c.mu.Unlock()
+		return
+	}
+	c.revalidating[trackId] = true
+	c.mu.Unlock()
+
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.revalidating, trackId)
+			c.mu.Unlock()
+		}()
+		_, _ = c.refresh(trackId)
+	}()
+}
+
+func (c *Cache) lookup(trackId string) (entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[trackId]
+	if !ok {
+		return entry{}, false
+	}
+	c.order.MoveToFront(element)
+	return *element.Value.(*entry), true
+}
+
+// put keeps the track in memory and evicts the least recently used ones beyond the
+// configured size. A size of 0 keeps nothing in memory.
+func (c *Cache) put(trackId string, song *models.Song, fetchedAt time.Time) entry {
+	cached := entry{trackId: trackId, song: *song, fetchedAt: fetchedAt}
+	if c.settings.Size <= 0 {
+		return cached
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[trackId]; ok {
+		*element.Value.(*entry) = cached
+		c.order.MoveToFront(element)
+		return cached
+	}
+
+	stored := cached
+	c.items[trackId] = c.order.PushFront(&stored)
+	for c.order.Len() > c.settings.Size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry).trackId)
+	}
+	return cached
+}
@@ -0,0 +1,211 @@
+package trackcache
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errSpotifyDown = errors.New("spotify is down")
+
+type fakeStore struct {
+	mu     sync.Mutex
+	songs  map[string]models.Song
+	times  map[string]time.Time
+	saved  []string
+	loaded int
+	now    func() time.Time
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{songs: make(map[string]models.Song), times: make(map[string]time.Time)}
+}
+
+func (s *fakeStore) GetCachedTrack(trackId string) (*models.Song, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.loaded++
+	song, ok := s.songs[trackId]
+	if !ok {
+		return nil, time.Time{}, nil
+	}
+	return &song, s.times[trackId], nil
+}
+
+func (s *fakeStore) SaveCachedTrack(song *models.Song) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.songs[song.ID] = *song
+	s.times[song.ID] = s.now()
+	s.saved = append(s.saved, song.ID)
+	return nil
+}
+
+func (s *fakeStore) savedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.saved)
+}
+
+type fakeSource struct {
+	mu    sync.Mutex
+	title string
+	err   error
+	calls int
+}
+
+func (f *fakeSource) fetch(trackId string) (*models.Song, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.Song{ID: trackId, Title: f.title}, nil
+}
+
+func (f *fakeSource) set(title string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.title, f.err = title, err
+}
+
+func (f *fakeSource) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func newTestCache(size int) (*Cache, *fakeStore, *fakeSource, *time.Time) {
+	store := newFakeStore()
+	source := &fakeSource{title: "Fresh"}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	cache := New(models.CacheSettings{Size: size, TTL: time.Hour, StaleTTL: 24 * time.Hour}, store, source.fetch)
+	cache.now = func() time.Time { return now }
+	store.now = cache.now
+	return cache, store, source, &now
+}
+
+func TestCache_MissThenHit(t *testing.T) {
+	cache, store, source, _ := newTestCache(10)
+
+	song, err := cache.Get("track1")
+	require.NoError(t, err)
+	assert.Equal(t, "Fresh", song.Title)
+	assert.Equal(t, []string{"track1"}, store.saved)
+
+	song, err = cache.Get("track1")
+	require.NoError(t, err)
+	assert.Equal(t, "Fresh", song.Title)
+
+	assert.Equal(t, 1, source.callCount())
+	assert.Equal(t, 1, store.loaded)
+	assert.Equal(t, models.CacheStats{Hits: 1, Misses: 1, Size: 1, Capacity: 10}, cache.Stats())
+}
+
+func TestCache_StoreTier(t *testing.T) {
+	cache, store, source, now := newTestCache(10)
+	store.songs["track1"] = models.Song{ID: "track1", Title: "Stored"}
+	store.times["track1"] = now.Add(-30 * time.Minute)
+
+	song, err := cache.Get("track1")
+	require.NoError(t, err)
+	assert.Equal(t, "Stored", song.Title)
+	assert.Zero(t, source.callCount())
+
+	_, err = cache.Get("track1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.loaded)
+	assert.Equal(t, int64(2), cache.Stats().Hits)
+}
+
+func TestCache_StaleWhileRevalidate(t *testing.T) {
+	cache, store, source, now := newTestCache(10)
+	store.songs["track1"] = models.Song{ID: "track1", Title: "Stored"}
+	store.times["track1"] = now.Add(-2 * time.Hour)
+
+	song, err := cache.Get("track1")
+	require.NoError(t, err)
+	assert.Equal(t, "Stored", song.Title)
+
+	require.Eventually(t, func() bool { return store.savedCount() == 1 }, time.Second, time.Millisecond)
+	require.Eventually(t, func() bool {
+		song, err := cache.Get("track1")
+		return err == nil && song.Title == "Fresh"
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, 1, source.callCount())
+	stats := cache.Stats()
+	assert.GreaterOrEqual(t, stats.Stale, int64(1))
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Zero(t, stats.Misses)
+}
+
+func TestCache_ExpiredIsFetched(t *testing.T) {
+	cache, store, source, now := newTestCache(10)
+	store.songs["track1"] = models.Song{ID: "track1", Title: "Stored"}
+	store.times["track1"] = now.Add(-48 * time.Hour)
+
+	song, err := cache.Get("track1")
+	require.NoError(t, err)
+	assert.Equal(t, "Fresh", song.Title)
+	assert.Equal(t, 1, source.callCount())
+	assert.Equal(t, int64(1), cache.Stats().Misses)
+}
+
+func TestCache_SpotifyDown(t *testing.T) {
+	cache, store, source, now := newTestCache(10)
+	source.set("", errSpotifyDown)
+	store.songs["track1"] = models.Song{ID: "track1", Title: "Stored"}
+	store.times["track1"] = now.Add(-48 * time.Hour)
+
+	song, err := cache.Get("track1")
+	require.NoError(t, err)
+	assert.Equal(t, "Stored", song.Title)
+
+	_, err = cache.Get("track2")
+	assert.ErrorIs(t, err, errSpotifyDown)
+
+	assert.Equal(t, models.CacheStats{Misses: 2, Stale: 1, Errors: 2, Size: 1, Capacity: 10}, cache.Stats())
+}
+
+func TestCache_Eviction(t *testing.T) {
+	cache, store, source, _ := newTestCache(2)
+
+	for _, trackId := range []string{"track1", "track2", "track1", "track3"} {
+		_, err := cache.Get(trackId)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, source.callCount())
+	assert.Equal(t, 2, cache.Stats().Size)
+
+	// track2 was the least recently used, so it now comes from the store.
+	_, err := cache.Get("track2")
+	require.NoError(t, err)
+	assert.Equal(t, 3, source.callCount())
+	assert.Equal(t, 4, store.loaded)
+
+	_, err = cache.Get("track1")
+	require.NoError(t, err)
+	assert.Equal(t, 5, store.loaded)
+}
+
+func TestCache_NoMemoryTier(t *testing.T) {
+	cache, store, source, _ := newTestCache(0)
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.Get("track1")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, source.callCount())
+	assert.Equal(t, 2, store.loaded)
+	assert.Zero(t, cache.Stats().Size)
+}
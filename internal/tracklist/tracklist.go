@@ -0,0 +1,184 @@
+// Package tracklist reads plain text track lists with one "Artist - Title" per line,
+// as pasted from radio show notes or forum posts, and scores how well a catalog
+// track matches a line.
+package tracklist
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"music-service/internal/models"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const MaxLines = 200
+
+var (
+	ErrEmptyTracklist = errors.New("track list has no lines")
+	ErrTooManyLines   = fmt.Errorf("track list has more than %d lines", MaxLines)
+)
+
+// separators in order of preference. The line is split at the first one found, so a
+// title like "Song - Live" keeps its suffix.
+var separators = []string{" -- ", " - ", " – ", " — ", " ~ ", " | ", "\t"}
+
+var (
+	timestamp = regexp.MustCompile(`^[\[(]?\d{1,2}:\d{2}(?::\d{2})?[\])]?\s*(?:[-–—|]\s+)?`)
+	bullet    = regexp.MustCompile(`^[-–—*•·>]+\s+`)
+	numbering = regexp.MustCompile(`^\d{1,3}(?:\s*[.):\]]\s*|\s+[-–—]\s+)`)
+
+	bracketed = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+	featuring = regexp.MustCompile(`(?i)\s(?:feat\.?|ft\.?|featuring)\s.*$`)
+	coArtists = regexp.MustCompile(`(?i)\s*(?:,|&|\band\b|\bx\b|\bvs\.?)\s*`)
+)
+
+// Parse reads the text line by line, skipping blank lines. Track numbers, timestamps
+// and bullets in front of the artist are dropped. Lines without a separator keep
+// their text and an error instead of artist and title.
+func Parse(r io.Reader) ([]*models.TracklistLine, error) {
+	scanner := bufio.NewScanner(r)
+	lines := make([]*models.TracklistLine, 0)
+
+	row := 0
+	for scanner.Scan() {
+		row++
+		text := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\uFEFF"))
+		if text == "" {
+			continue
+		}
+		if len(lines) == MaxLines {
+			return nil, ErrTooManyLines
+		}
+
+		line := &models.TracklistLine{Row: row, Text: text, Candidates: []*models.TrackCandidate{}}
+		line.Artist, line.Title = splitLine(text)
+		if line.Title == "" {
+			line.Error = `line is not in "Artist - Title" form`
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 {
+		return nil, ErrEmptyTracklist
+	}
+	return lines, nil
+}
+
+func splitLine(text string) (string, string) {
+	text = strings.TrimSpace(bullet.ReplaceAllString(timestamp.ReplaceAllString(text, ""), ""))
+
+	// A leading number is only a track number when the rest still splits, otherwise
+	// it belongs to the artist, as in "311 - Amber".
+	if stripped := numbering.ReplaceAllString(text, ""); stripped != text {
+		if artist, title := split(stripped); title != "" {
+			return artist, title
+		}
+	}
+	return split(text)
+}
+
+func split(text string) (string, string) {
+	for _, separator := range separators {
+		if artist, title, found := strings.Cut(text, separator); found {
+			return clean(artist), clean(title)
+		}
+	}
+
+	// "Title by Artist" is common enough in forum posts to be worth reading.
+	if index := strings.LastIndex(strings.ToLower(text), " by "); index > 0 {
+		return clean(text[index+4:]), clean(text[:index])
+	}
+	return "", ""
+}
+
+func clean(value string) string {
+	return strings.Trim(strings.TrimSpace(value), `"'“”‘’`)
+}
+
+// Confidence rates the song against the artist and title read from a line, from 0
+// to 1 in steps of 0.01. The title weighs more than the artist; additions such as
+// "(Remastered)", "- Live" or featured artists are ignored on both sides.
+func Confidence(artist, title string, song models.Song) float64 {
+	score := similarity(normalizeTitle(title), normalizeTitle(song.Title))
+	if artist != "" {
+		artistScore := 0.0
+		catalogArtist := normalize(song.Artist)
+		for _, part := range append([]string{artist}, coArtists.Split(featuring.ReplaceAllString(artist, ""), -1)...) {
+			artistScore = math.Max(artistScore, similarity(normalize(part), catalogArtist))
+		}
+		score = 0.6*score + 0.4*artistScore
+	}
+	return math.Round(score*100) / 100
+}
+
+func normalizeTitle(title string) string {
+	if before, _, found := strings.Cut(title, " - "); found {
+		title = before
+	}
+	return normalize(featuring.ReplaceAllString(bracketed.ReplaceAllString(title, " "), ""))
+}
+
+// normalize lowercases the value and keeps only letters and digits, separated by
+// single spaces.
+func normalize(value string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(value) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+			continue
+		}
+		space = true
+	}
+	return b.String()
+}
+
+// similarity is the Dice coefficient of the character bigrams, which tolerates
+// typos and word order better than comparing words.
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	left := bigrams(a)
+	right := bigrams(b)
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int, len(left))
+	for _, bigram := range left {
+		counts[bigram]++
+	}
+	shared := 0
+	for _, bigram := range right {
+		if counts[bigram] > 0 {
+			counts[bigram]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(left)+len(right))
+}
+
+func bigrams(value string) []string {
+	runes := []rune(value)
+	result := make([]string, 0, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		result = append(result, string(runes[i:i+2]))
+	}
+	return result
+}
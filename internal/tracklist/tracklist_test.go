@@ -0,0 +1,118 @@
+package tracklist
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	text := "\uFEFFDaft Punk - One More Time\n" +
+		"\n" +
+		"2. Massive Attack – Teardrop\n" +
+		"03) Portishead — Glory Box\n" +
+		"[00:12:34] Bonobo ~ Kerala\n" +
+		"* Burial | Archangel\n" +
+		"Aphex Twin\tXtal\n" +
+		"311 - Amber\n" +
+		"4 - The Chemical Brothers - Galvanize - Radio Edit\n" +
+		"\"Windowlicker\" by Aphex Twin\n" +
+		"just some chatter\n"
+
+	lines, err := Parse(strings.NewReader(text))
+	require.NoError(t, err)
+
+	type parsed struct {
+		row           int
+		artist, title string
+		err           string
+	}
+	expected := []parsed{
+		{row: 1, artist: "Daft Punk", title: "One More Time"},
+		{row: 3, artist: "Massive Attack", title: "Teardrop"},
+		{row: 4, artist: "Portishead", title: "Glory Box"},
+		{row: 5, artist: "Bonobo", title: "Kerala"},
+		{row: 6, artist: "Burial", title: "Archangel"},
+		{row: 7, artist: "Aphex Twin", title: "Xtal"},
+		{row: 8, artist: "311", title: "Amber"},
+		{row: 9, artist: "The Chemical Brothers", title: "Galvanize - Radio Edit"},
+		{row: 10, artist: "Aphex Twin", title: "Windowlicker"},
+		{row: 11, err: `line is not in "Artist - Title" form`},
+	}
+
+	require.Len(t, lines, len(expected))
+	for i, line := range lines {
+		assert.Equal(t, expected[i], parsed{row: line.Row, artist: line.Artist, title: line.Title, err: line.Error}, line.Text)
+		assert.NotNil(t, line.Candidates)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	_, err := Parse(strings.NewReader("\n  \n"))
+	assert.ErrorIs(t, err, ErrEmptyTracklist)
+
+	_, err = Parse(strings.NewReader(strings.Repeat("Artist - Title\n", MaxLines+1)))
+	assert.ErrorIs(t, err, ErrTooManyLines)
+}
+
+func TestConfidence(t *testing.T) {
+	tests := []struct {
+		name          string
+		artist, title string
+		song          models.Song
+		min, max      float64
+	}{
+		{
+			name:   "same artist and title",
+			artist: "Daft Punk", title: "One More Time",
+			song: models.Song{Artist: "Daft Punk", Title: "One More Time"},
+			min:  1, max: 1,
+		},
+		{
+			name:   "catalog additions are ignored",
+			artist: "Queen", title: "Bohemian Rhapsody",
+			song: models.Song{Artist: "Queen", Title: "Bohemian Rhapsody - Remastered 2011"},
+			min:  1, max: 1,
+		},
+		{
+			name:   "featured and co-artists",
+			artist: "Calvin Harris feat. Rihanna", title: "This Is What You Came For (Official Video)",
+			song: models.Song{Artist: "Calvin Harris", Title: "This Is What You Came For"},
+			min:  1, max: 1,
+		},
+		{
+			name:   "typo",
+			artist: "Massive Atack", title: "Tear drop",
+			song: models.Song{Artist: "Massive Attack", Title: "Teardrop"},
+			min:  0.75, max: 0.95,
+		},
+		{
+			name:   "right title, other artist",
+			artist: "Portishead", title: "Glory Box",
+			song: models.Song{Artist: "Tom Jones", Title: "Glory Box"},
+			min:  0.6, max: 0.7,
+		},
+		{
+			name:   "unrelated",
+			artist: "Burial", title: "Archangel",
+			song: models.Song{Artist: "Daft Punk", Title: "One More Time"},
+			min:  0, max: 0.2,
+		},
+		{
+			name:  "title only",
+			title: "Xtal",
+			song:  models.Song{Artist: "Aphex Twin", Title: "Xtal"},
+			min:   1, max: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confidence := Confidence(tt.artist, tt.title, tt.song)
+			assert.GreaterOrEqual(t, confidence, tt.min)
+			assert.LessOrEqual(t, confidence, tt.max)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Package xspf reads and writes playlists in the XML Shareable Playlist Format
+// (https://xspf.org/spec, version 1).
+package xspf
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"music-service/internal/models"
+	"music-service/pkg/utils"
+	"strings"
+)
+
+const (
+	ContentType = "application/xspf+xml; charset=utf-8"
+	Extension   = ".xspf"
+
+	Namespace = "http://xspf.org/ns/0/"
+	Version   = "1"
+)
+
+var (
+	ErrInvalidDocument = errors.New("document is not an XSPF playlist")
+)
+
+// Playlist is the root element. Decoding ignores the namespace so documents
+// written by tools that leave it out are accepted as well.
+type Playlist struct {
+	XMLName xml.Name `xml:"playlist"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Version string   `xml:"version,attr"`
+	Title   string   `xml:"title,omitempty"`
+	Creator string   `xml:"creator,omitempty"`
+	Tracks  []Track  `xml:"trackList>track"`
+}
+
+// Track carries duration in milliseconds, as the spec requires.
+type Track struct {
+	Locations   []string `xml:"location,omitempty"`
+	Identifiers []string `xml:"identifier,omitempty"`
+	Title       string   `xml:"title,omitempty"`
+	Creator     string   `xml:"creator,omitempty"`
+	Album       string   `xml:"album,omitempty"`
+	Duration    int      `xml:"duration,omitempty"`
+	Image       string   `xml:"image,omitempty"`
+}
+
+// FromSongs builds a document for export. Location is the Spotify URL stored with the
+// track and the identifier its canonical open.spotify.com form, built from the id.
+func FromSongs(name string, songs []*models.Song) *Playlist {
+	playlist := &Playlist{
+		Xmlns:   Namespace,
+		Version: Version,
+		Title:   name,
+		Tracks:  make([]Track, 0, len(songs)),
+	}
+
+	for _, song := range songs {
+		track := Track{
+			Title:    song.Title,
+			Creator:  song.Artist,
+			Album:    song.Album,
+			Duration: song.Duration * 1000,
+			Image:    song.AlbumCover,
+		}
+		if song.ExternalURL != "" {
+			track.Locations = []string{song.ExternalURL}
+		}
+		if song.ID != "" {
+			track.Identifiers = []string{utils.SpotifyTrackURL + song.ID}
+		}
+		playlist.Tracks = append(playlist.Tracks, track)
+	}
+
+	return playlist
+}
+
+func Encode(w io.Writer, playlist *Playlist) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(playlist); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func Decode(r io.Reader) (*Playlist, error) {
+	var playlist Playlist
+	if err := xml.NewDecoder(r).Decode(&playlist); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrInvalidDocument
+		}
+		return nil, err
+	}
+
+	if playlist.XMLName.Space != "" && playlist.XMLName.Space != Namespace {
+		return nil, ErrInvalidDocument
+	}
+
+	return &playlist, nil
+}
+
+// Items turns the tracks into import candidates, keeping their order.
+func (p *Playlist) Items() []*models.ImportItem {
+	items := make([]*models.ImportItem, 0, len(p.Tracks))
+	for _, track := range p.Tracks {
+		items = append(items, &models.ImportItem{
+			Title:     strings.TrimSpace(track.Title),
+			Artist:    strings.TrimSpace(track.Creator),
+			Album:     strings.TrimSpace(track.Album),
+			Duration:  track.Duration / 1000,
+			SpotifyId: track.SpotifyID(),
+		})
+	}
+	return items
+}
+
+// SpotifyID looks for a Spotify track URL or URI among the identifiers first and
+// the locations second.
+func (t Track) SpotifyID() string {
+	for _, value := range append(append([]string{}, t.Identifiers...), t.Locations...) {
+		if id := utils.ParseSpotifyTrackID(value); id != "" {
+			return id
+		}
+	}
+	return ""
+}
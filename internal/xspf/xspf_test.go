@@ -0,0 +1,129 @@
+package xspf
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-service/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	songs := []*models.Song{
+		{ID: "4uLU6hMCjMI75M1A2tKUQC", Title: "Song A & B", Artist: "Artist X", Album: "Album Y", Duration: 215,
+			AlbumCover: "https://i.scdn.co/image/1", ExternalURL: "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, FromSongs("Road trip", songs)))
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<playlist xmlns="http://xspf.org/ns/0/" version="1">
+  <title>Road trip</title>
+  <trackList>
+    <track>
+      <location>https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC</location>
+      <identifier>https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC</identifier>
+      <title>Song A &amp; B</title>
+      <creator>Artist X</creator>
+      <album>Album Y</album>
+      <duration>215000</duration>
+      <image>https://i.scdn.co/image/1</image>
+    </track>
+  </trackList>
+</playlist>
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name          string
+		document      string
+		expectedTitle string
+		expectedItems []*models.ImportItem
+		expectedErr   bool
+	}{
+		{
+			name: "identifier, location and plain tracks",
+			document: `<?xml version="1.0" encoding="UTF-8"?>
+				<playlist version="1" xmlns="http://xspf.org/ns/0/">
+				  <title>Mix</title>
+				  <trackList>
+				    <track>
+				      <identifier>spotify:track:4uLU6hMCjMI75M1A2tKUQC</identifier>
+				      <title>Song A</title>
+				      <duration>215000</duration>
+				    </track>
+				    <track>
+				      <location>file:///music/b.mp3</location>
+				      <location>https://open.spotify.com/intl-de/track/6rqhFgbbKwnb9MLmUQDhG6?si=abc</location>
+				      <title>Song B</title>
+				    </track>
+				    <track>
+				      <location>file:///music/c.mp3</location>
+				      <title> Song C </title>
+				      <creator>Artist Z</creator>
+				      <album>Album Z</album>
+				    </track>
+				  </trackList>
+				</playlist>`,
+			expectedTitle: "Mix",
+			expectedItems: []*models.ImportItem{
+				{Title: "Song A", Duration: 215, SpotifyId: "4uLU6hMCjMI75M1A2tKUQC"},
+				{Title: "Song B", SpotifyId: "6rqhFgbbKwnb9MLmUQDhG6"},
+				{Title: "Song C", Artist: "Artist Z", Album: "Album Z"},
+			},
+		},
+		{
+			name:          "namespace left out",
+			document:      `<playlist version="1"><trackList><track><title>Song A</title></track></trackList></playlist>`,
+			expectedItems: []*models.ImportItem{{Title: "Song A"}},
+		},
+		{
+			name:        "foreign namespace",
+			document:    `<playlist xmlns="http://example.com/"><trackList/></playlist>`,
+			expectedErr: true,
+		},
+		{
+			name:        "not a playlist",
+			document:    `<html><body/></html>`,
+			expectedErr: true,
+		},
+		{
+			name:        "empty document",
+			document:    ``,
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playlist, err := Decode(strings.NewReader(tt.document))
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTitle, playlist.Title)
+			assert.Equal(t, tt.expectedItems, playlist.Items())
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	songs := []*models.Song{
+		{ID: "4uLU6hMCjMI75M1A2tKUQC", Title: "Song A", Artist: "Artist X", Duration: 215},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, FromSongs("Road trip", songs)))
+
+	playlist, err := Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.ImportItem{
+		{Title: "Song A", Artist: "Artist X", Duration: 215, SpotifyId: "4uLU6hMCjMI75M1A2tKUQC"},
+	}, playlist.Items())
+}
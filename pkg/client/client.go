@@ -3,17 +3,22 @@ package client
 import (
 	"context"
 	"github.com/zmb3/spotify"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
+	"net/http"
 )
 
-func NewSpotifyClient(clientID, clientSecret string) *spotify.Client {
+// NewSpotifyClient returns a client whose API and token calls both go through the
+// resilient Transport.
+func NewSpotifyClient(clientID, clientSecret string, settings Settings) *spotify.Client {
 	cfg := clientcredentials.Config{
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
 		TokenURL:     spotify.TokenURL,
 	}
 
-	client := cfg.Client(context.Background())
+	base := &http.Client{Transport: NewTransport(http.DefaultTransport, settings)}
+	client := cfg.Client(context.WithValue(context.Background(), oauth2.HTTPClient, base))
 	newClient := spotify.NewClient(client)
 	return &newClient
 }
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrUnavailable is returned without calling Spotify while the circuit breaker is open.
+var ErrUnavailable = errors.New("spotify is unavailable")
+
+// Settings configures the Transport. Idempotent requests answered with a 429 or
+// a 5xx are retried up to MaxRetries times, waiting for Retry-After when Spotify
+// sends one and a jittered exponential backoff between BaseBackoff and MaxBackoff
+// otherwise. A Retry-After longer than MaxBackoff is not waited for.
+//
+// RequestsPerSecond and Burst size the client-side token bucket, a non-positive
+// rate disables it. After FailureThreshold failed calls in a row the breaker opens
+// and fails fast for OpenTimeout, then lets one trial call through.
+type Settings struct {
+	MaxRetries        int
+	BaseBackoff       time.Duration
+	MaxBackoff        time.Duration
+	RequestsPerSecond float64
+	Burst             int
+	FailureThreshold  int
+	OpenTimeout       time.Duration
+}
+
+type Transport struct {
+	base     http.RoundTripper
+	settings Settings
+	bucket   *tokenBucket
+	breaker  *breaker
+
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+	jitter func(max time.Duration) time.Duration
+}
+
+func NewTransport(base http.RoundTripper, settings Settings) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &Transport{
+		base:     base,
+		settings: settings,
+		now:      time.Now,
+		sleep:    sleep,
+		jitter: func(max time.Duration) time.Duration {
+			return time.Duration(rand.Int63n(int64(max) + 1))
+		},
+	}
+	t.bucket = newTokenBucket(settings.RequestsPerSecond, settings.Burst, t.now)
+	t.breaker = newBreaker(settings.FailureThreshold, settings.OpenTimeout, t.now)
+	return t
+}
+
+func (t *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	ctx := request.Context()
+	for attempt := 0; ; attempt++ {
+		// Checked before taking a token, so calls failing fast don't drain the bucket.
+		// Every return before record releases the call, a half-open breaker waits for
+		// the outcome of its trial call.
+		if !t.breaker.allow() {
+			return nil, ErrUnavailable
+		}
+		if err := t.sleep(ctx, t.bucket.reserve()); err != nil {
+			t.breaker.release()
+			return nil, err
+		}
+
+		if attempt > 0 && request.Body != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				t.breaker.release()
+				return nil, err
+			}
+			request = request.Clone(ctx)
+			request.Body = body
+		}
+
+		response, err := t.base.RoundTrip(request)
+		// A call the caller gave up on says nothing about Spotify's health.
+		if ctx.Err() != nil {
+			t.breaker.release()
+			return response, err
+		}
+		retryable := err != nil || response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+		// A 429 is Spotify throttling this client, not Spotify being unhealthy.
+		t.breaker.record(err == nil && response.StatusCode < 500)
+
+		if !retryable || attempt >= t.settings.MaxRetries || !isIdempotent(request) {
+			return response, err
+		}
+
+		delay, ok := t.backoff(attempt, response)
+		if !ok {
+			return response, err
+		}
+		if response != nil {
+			io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns how long to wait before the next attempt, false when the wait
+// Spotify asks for is longer than the transport is willing to hold the call.
+func (t *Transport) backoff(attempt int, response *http.Response) (time.Duration, bool) {
+	if response != nil {
+		if wait, ok := retryAfter(response.Header.Get("Retry-After"), t.now()); ok {
+			return wait, wait <= t.settings.MaxBackoff
+		}
+	}
+
+	ceiling := t.settings.BaseBackoff << attempt
+	if ceiling > t.settings.MaxBackoff || ceiling <= 0 {
+		ceiling = t.settings.MaxBackoff
+	}
+	return t.jitter(ceiling), true
+}
+
+// retryAfter reads a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func isIdempotent(request *http.Request) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return request.Body == nil || request.GetBody != nil
+	default:
+		return false
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
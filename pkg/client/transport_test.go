@@ -0,0 +1,325 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// newTestTransport returns a transport that records its waits instead of sleeping.
+func newTestTransport(settings Settings) (*Transport, *fakeClock, *[]time.Duration) {
+	clock := &fakeClock{now: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+	waits := &[]time.Duration{}
+
+	t := NewTransport(http.DefaultTransport, settings)
+	t.now = clock.Now
+	t.bucket = newTokenBucket(settings.RequestsPerSecond, settings.Burst, clock.Now)
+	t.breaker = newBreaker(settings.FailureThreshold, settings.OpenTimeout, clock.Now)
+	t.jitter = func(max time.Duration) time.Duration { return max }
+	t.sleep = func(_ context.Context, d time.Duration) error {
+		if d > 0 {
+			*waits = append(*waits, d)
+			clock.now = clock.now.Add(d)
+		}
+		return nil
+	}
+	return t, clock, waits
+}
+
+func fakeServer(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(&calls, 1)) - 1
+		status := http.StatusOK
+		if call < len(statuses) {
+			status = statuses[call]
+		}
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", r.URL.Query().Get("retry_after"))
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, r.Method)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+var testSettings = Settings{MaxRetries: 3, BaseBackoff: 100 * time.Millisecond, MaxBackoff: 5 * time.Second}
+
+func TestTransport_RetriesServerErrors(t *testing.T) {
+	transport, _, waits := newTestTransport(testSettings)
+	server, calls := fakeServer(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+
+	response, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *waits)
+}
+
+func TestTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	transport, _, waits := newTestTransport(testSettings)
+	server, calls := fakeServer(t, 500, 500, 500, 500, 500)
+
+	response, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+	assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+	assert.Len(t, *waits, 3)
+}
+
+func TestTransport_HonorsRetryAfter(t *testing.T) {
+	transport, _, waits := newTestTransport(testSettings)
+	server, calls := fakeServer(t, http.StatusTooManyRequests)
+
+	response, err := (&http.Client{Transport: transport}).Get(server.URL + "?retry_after=2")
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+	assert.Equal(t, []time.Duration{2 * time.Second}, *waits)
+}
+
+func TestTransport_RetryAfterTooLong(t *testing.T) {
+	transport, _, waits := newTestTransport(testSettings)
+	server, calls := fakeServer(t, http.StatusTooManyRequests)
+
+	response, err := (&http.Client{Transport: transport}).Get(server.URL + "?retry_after=60")
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, "60", response.Header.Get("Retry-After"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	assert.Empty(t, *waits)
+}
+
+func TestTransport_DoesNotRetryPost(t *testing.T) {
+	transport, _, _ := newTestTransport(testSettings)
+	server, calls := fakeServer(t, http.StatusServiceUnavailable)
+
+	response, err := (&http.Client{Transport: transport}).Post(server.URL, "text/plain", strings.NewReader("body"))
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestTransport_RetriesPutWithBody(t *testing.T) {
+	transport, _, _ := newTestTransport(testSettings)
+	var bodies []string
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("payload"))
+	require.NoError(t, err)
+	response, err := (&http.Client{Transport: transport}).Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []string{"payload", "payload"}, bodies)
+}
+
+func TestTransport_CircuitBreaker(t *testing.T) {
+	settings := Settings{FailureThreshold: 2, OpenTimeout: 30 * time.Second}
+	transport, clock, _ := newTestTransport(settings)
+	server, calls := fakeServer(t, 500, 500, 500)
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 2; i++ {
+		response, err := client.Get(server.URL)
+		require.NoError(t, err)
+		response.Body.Close()
+	}
+
+	_, err := client.Get(server.URL)
+	assert.True(t, errors.Is(err, ErrUnavailable))
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+
+	// The trial call after the timeout fails and opens the breaker again.
+	clock.now = clock.now.Add(30 * time.Second)
+	response, err := client.Get(server.URL)
+	require.NoError(t, err)
+	response.Body.Close()
+	_, err = client.Get(server.URL)
+	assert.True(t, errors.Is(err, ErrUnavailable))
+
+	clock.now = clock.now.Add(30 * time.Second)
+	response, err = client.Get(server.URL)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, err = client.Get(server.URL)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, int32(5), atomic.LoadInt32(calls))
+}
+
+func TestTransport_RateLimitIsNotAFailure(t *testing.T) {
+	settings := Settings{FailureThreshold: 1, OpenTimeout: time.Minute}
+	transport, _, _ := newTestTransport(settings)
+	server, _ := fakeServer(t, http.StatusTooManyRequests)
+	client := &http.Client{Transport: transport}
+
+	response, err := client.Get(server.URL)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+
+	response, err = client.Get(server.URL)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestTransport_CanceledCallIsNotAFailure(t *testing.T) {
+	settings := Settings{FailureThreshold: 1, OpenTimeout: time.Minute}
+	transport, _, _ := newTestTransport(settings)
+	server, calls := fakeServer(t)
+	base := transport.base
+	transport.base = roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		if request.URL.Path == "/slow" {
+			<-request.Context().Done()
+			return nil, request.Context().Err()
+		}
+		return base.RoundTrip(request)
+	})
+	client := &http.Client{Transport: transport}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/slow", nil)
+	require.NoError(t, err)
+	cancel()
+	_, err = client.Do(request)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	response, err := client.Get(server.URL)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestTransport_OpenBreakerTakesNoToken(t *testing.T) {
+	settings := Settings{RequestsPerSecond: 1, Burst: 1, FailureThreshold: 1, OpenTimeout: time.Minute}
+	transport, clock, waits := newTestTransport(settings)
+	server, _ := fakeServer(t, 500)
+	client := &http.Client{Transport: transport}
+
+	response, err := client.Get(server.URL)
+	require.NoError(t, err)
+	response.Body.Close()
+
+	for i := 0; i < 3; i++ {
+		_, err = client.Get(server.URL)
+		assert.ErrorIs(t, err, ErrUnavailable)
+	}
+	assert.Empty(t, *waits)
+
+	// The trial call gives up waiting for its token, the next call becomes the trial.
+	clock.now = clock.now.Add(time.Minute)
+	transport.bucket = newTokenBucket(1, 1, clock.Now)
+	transport.bucket.reserve()
+	sleep := transport.sleep
+	transport.sleep = func(context.Context, time.Duration) error { return context.DeadlineExceeded }
+	_, err = client.Get(server.URL)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	transport.sleep = sleep
+	response, err = client.Get(server.URL)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestTransport_TokenBucket(t *testing.T) {
+	settings := Settings{RequestsPerSecond: 2, Burst: 2}
+	transport, _, waits := newTestTransport(settings)
+	server, calls := fakeServer(t)
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 4; i++ {
+		response, err := client.Get(server.URL)
+		require.NoError(t, err)
+		response.Body.Close()
+	}
+
+	assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, 500 * time.Millisecond}, *waits)
+}
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+	bucket := newTokenBucket(10, 1, clock.Now)
+
+	assert.Zero(t, bucket.reserve())
+	assert.Equal(t, 100*time.Millisecond, bucket.reserve())
+	assert.Equal(t, 200*time.Millisecond, bucket.reserve())
+
+	clock.now = clock.now.Add(time.Second)
+	assert.Zero(t, bucket.reserve())
+
+	assert.Zero(t, newTokenBucket(0, 0, clock.Now).reserve())
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{name: "Seconds", value: "3", want: 3 * time.Second, ok: true},
+		{name: "Date", value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second, ok: true},
+		{name: "Past date", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, ok: true},
+		{name: "Missing", value: "", ok: false},
+		{name: "Invalid", value: "soon", ok: false},
+		{name: "Negative", value: "-1", ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := retryAfter(test.value, now)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+// Every runs job on each tick of interval until ctx is done.
+// A non-positive interval disables the job.
+func Every(ctx context.Context, interval time.Duration, job func()) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job()
+		}
+	}
+}
@@ -3,11 +3,15 @@ package utils
 import (
 	"github.com/zmb3/spotify"
 	"music-service/internal/models"
+	"strings"
 )
 
 const (
-	unknownArtist = "Unknown Artist"
-	externalURL   = "spotify"
+	SpotifyTrackURL = "https://open.spotify.com/track/"
+
+	unknownArtist   = "Unknown Artist"
+	externalURL     = "spotify"
+	spotifyIDLength = 22
 )
 
 func MapTrackToSong(track *spotify.FullTrack) models.Song {
@@ -21,16 +25,129 @@ func MapTrackToSong(track *spotify.FullTrack) models.Song {
 		albumCover = track.Album.Images[0].URL
 	}
 
+	// Artists without an id, as on some local or podcast entries, can't be linked.
+	var artists []models.SongArtist
+	for _, credited := range track.Artists {
+		if credited.ID != "" {
+			artists = append(artists, models.SongArtist{ID: string(credited.ID), Name: credited.Name})
+		}
+	}
+
 	return models.Song{
-		ID:          string(track.ID),
-		Title:       track.Name,
+		ID:                   string(track.ID),
+		Title:                track.Name,
+		Artist:               artist,
+		Artists:              artists,
+		Album:                track.Album.Name,
+		AlbumId:              string(track.Album.ID),
+		AlbumCover:           albumCover,
+		Duration:             track.Duration / 1000,
+		ReleaseDate:          track.Album.ReleaseDate,
+		ReleaseDatePrecision: track.Album.ReleaseDatePrecision,
+		Popularity:           track.Popularity,
+		PreviewURL:           track.PreviewURL,
+		ExternalURL:          track.ExternalURLs[externalURL],
+		Explicit:             track.Explicit,
+		TrackNumber:          track.TrackNumber,
+		DiscNumber:           track.DiscNumber,
+	}
+}
+
+func MapAlbum(album *spotify.SimpleAlbum) models.Album {
+	artist := unknownArtist
+	if len(album.Artists) > 0 {
+		artist = album.Artists[0].Name
+	}
+
+	albumCover := ""
+	if len(album.Images) > 0 {
+		albumCover = album.Images[0].URL
+	}
+
+	return models.Album{
+		ID:          string(album.ID),
+		Name:        album.Name,
 		Artist:      artist,
-		Album:       track.Album.Name,
+		AlbumType:   album.AlbumType,
 		AlbumCover:  albumCover,
-		Duration:    track.Duration / 1000,
-		ReleaseDate: track.Album.ReleaseDate,
-		Popularity:  track.Popularity,
-		PreviewURL:  track.PreviewURL,
-		ExternalURL: track.ExternalURLs[externalURL],
+		ReleaseDate: album.ReleaseDate,
+		ExternalURL: album.ExternalURLs[externalURL],
+	}
+}
+
+func MapArtist(artist *spotify.FullArtist) models.Artist {
+	image := ""
+	if len(artist.Images) > 0 {
+		image = artist.Images[0].URL
+	}
+
+	genres := artist.Genres
+	if genres == nil {
+		genres = []string{}
+	}
+
+	return models.Artist{
+		ID:          string(artist.ID),
+		Name:        artist.Name,
+		Genres:      genres,
+		Popularity:  artist.Popularity,
+		Image:       image,
+		ExternalURL: artist.ExternalURLs[externalURL],
+	}
+}
+
+// ParseSpotifyTrackID extracts the track id from an open.spotify.com URL or a
+// spotify:track: URI. It returns an empty string for anything else.
+func ParseSpotifyTrackID(value string) string {
+	return parseSpotifyID(value, "track")
+}
+
+// ParseSpotifyPlaylistID accepts a playlist URL, a spotify:playlist: URI including the
+// older spotify:user:<name>:playlist: form, or a bare id.
+func ParseSpotifyPlaylistID(value string) string {
+	value = strings.TrimSpace(value)
+	if IsSpotifyID(value) {
+		return value
+	}
+	return parseSpotifyID(value, "playlist")
+}
+
+func parseSpotifyID(value, kind string) string {
+	value = strings.TrimSpace(value)
+
+	var id string
+	switch {
+	case strings.HasPrefix(value, "spotify:"):
+		index := strings.LastIndex(value, ":"+kind+":")
+		if index < 0 {
+			return ""
+		}
+		id = value[index+len(kind)+2:]
+	case strings.HasPrefix(value, "https://open.spotify.com/"), strings.HasPrefix(value, "http://open.spotify.com/"):
+		index := strings.Index(value, "/"+kind+"/")
+		if index < 0 {
+			return ""
+		}
+		id = value[index+len(kind)+2:]
+		if end := strings.IndexAny(id, "?#/"); end >= 0 {
+			id = id[:end]
+		}
+	default:
+		return ""
+	}
+
+	if !IsSpotifyID(id) {
+		return ""
+	}
+	return id
+}
+
+// IsSpotifyID reports whether value has the shape of a Spotify id, 22 base62 characters.
+func IsSpotifyID(value string) bool {
+	if len(value) != spotifyIDLength {
+		return false
 	}
+	return strings.IndexFunc(value, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) < 0
 }
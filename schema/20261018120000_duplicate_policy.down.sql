@@ -0,0 +1,7 @@
+DROP INDEX idx_playlist_songs_position ON playlist_songs;
+
+ALTER TABLE playlist_songs
+    DROP COLUMN position;
+
+ALTER TABLE playlists
+    DROP COLUMN duplicate_policy;
@@ -0,0 +1,9 @@
+ALTER TABLE playlists
+    ADD COLUMN duplicate_policy VARCHAR(10) NOT NULL DEFAULT 'allow';
+
+ALTER TABLE playlist_songs
+    ADD COLUMN position INT NOT NULL DEFAULT 0;
+
+UPDATE playlist_songs SET position = id;
+
+CREATE INDEX idx_playlist_songs_position ON playlist_songs (playlist_id, position);
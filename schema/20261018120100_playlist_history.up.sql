@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS playlist_snapshots (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    playlist_id INT NOT NULL,
+    version INT NOT NULL,
+    actor_id INT UNSIGNED NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY (playlist_id, version),
+    FOREIGN KEY (playlist_id) REFERENCES playlists(id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS playlist_snapshot_songs (
+    snapshot_id INT NOT NULL,
+    position INT NOT NULL,
+    song_id VARCHAR(255) NOT NULL,
+    PRIMARY KEY (snapshot_id, position),
+    FOREIGN KEY (snapshot_id) REFERENCES playlist_snapshots(id) ON DELETE CASCADE,
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
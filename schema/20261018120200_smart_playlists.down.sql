@@ -0,0 +1,5 @@
+ALTER TABLE playlists
+    DROP COLUMN refreshed_at,
+    DROP COLUMN refresh_mode,
+    DROP COLUMN rules,
+    DROP COLUMN kind;
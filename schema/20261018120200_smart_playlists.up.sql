@@ -0,0 +1,5 @@
+ALTER TABLE playlists
+    ADD COLUMN kind VARCHAR(10) NOT NULL DEFAULT 'manual',
+    ADD COLUMN rules JSON NULL,
+    ADD COLUMN refresh_mode VARCHAR(10) NULL,
+    ADD COLUMN refreshed_at TIMESTAMP NULL;
@@ -0,0 +1,6 @@
+ALTER TABLE playlists
+    DROP FOREIGN KEY fk_playlists_folder,
+    DROP COLUMN folder_id,
+    DROP COLUMN position;
+
+DROP TABLE IF EXISTS playlist_folders;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS playlist_folders (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT UNSIGNED NOT NULL,
+    parent_id INT NULL,
+    name VARCHAR(100) NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES playlist_folders(id) ON DELETE CASCADE
+);
+
+ALTER TABLE playlists
+    ADD COLUMN folder_id INT NULL,
+    ADD COLUMN position INT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT fk_playlists_folder FOREIGN KEY (folder_id) REFERENCES playlist_folders(id) ON DELETE SET NULL;
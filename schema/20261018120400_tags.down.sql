@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS song_tags;
+
+DROP TABLE IF EXISTS playlist_tags;
+
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,6 @@
+DELETE FROM playlists WHERE deleted_at IS NOT NULL;
+
+DROP INDEX idx_playlists_deleted_at ON playlists;
+
+ALTER TABLE playlists
+    DROP COLUMN deleted_at;
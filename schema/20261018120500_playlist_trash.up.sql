@@ -0,0 +1,4 @@
+ALTER TABLE playlists
+    ADD COLUMN deleted_at TIMESTAMP NULL;
+
+CREATE INDEX idx_playlists_deleted_at ON playlists (deleted_at);
@@ -0,0 +1,2 @@
+ALTER TABLE playlists
+    DROP COLUMN version;
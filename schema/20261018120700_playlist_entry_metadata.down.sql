@@ -0,0 +1,6 @@
+DROP INDEX idx_playlist_songs_added_at ON playlist_songs;
+
+ALTER TABLE playlist_songs
+    DROP COLUMN note,
+    DROP COLUMN added_by,
+    DROP COLUMN added_at;
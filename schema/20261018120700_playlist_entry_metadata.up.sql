@@ -0,0 +1,6 @@
+ALTER TABLE playlist_songs
+    ADD COLUMN added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN added_by INT NULL,
+    ADD COLUMN note VARCHAR(500) NULL;
+
+CREATE INDEX idx_playlist_songs_added_at ON playlist_songs (playlist_id, added_at);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_request_counts;
+
+DROP TABLE IF EXISTS user_quotas;
+
+DROP TABLE IF EXISTS admins;
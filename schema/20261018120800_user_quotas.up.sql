@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS admins (
+    user_id INT UNSIGNED PRIMARY KEY,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS user_quotas (
+    user_id INT UNSIGNED PRIMARY KEY,
+    max_playlists INT NULL,
+    max_tracks_per_playlist INT NULL,
+    max_requests_per_day INT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS user_request_counts (
+    user_id INT UNSIGNED NOT NULL,
+    day DATE NOT NULL,
+    count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, day),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS restored_playlists (
+    user_id INT UNSIGNED NOT NULL,
+    origin VARCHAR(64) NOT NULL,
+    source_id INT NOT NULL,
+    playlist_id INT NOT NULL,
+    PRIMARY KEY (user_id, origin, source_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (playlist_id) REFERENCES playlists(id) ON DELETE CASCADE
+);
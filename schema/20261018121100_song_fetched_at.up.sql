@@ -0,0 +1,2 @@
+ALTER TABLE songs
+    ADD COLUMN fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
@@ -0,0 +1,14 @@
+ALTER TABLE songs
+    DROP FOREIGN KEY fk_songs_album,
+    DROP COLUMN artists_resolved,
+    DROP COLUMN release_date_precision,
+    DROP COLUMN disc_number,
+    DROP COLUMN track_number,
+    DROP COLUMN explicit,
+    DROP COLUMN album_id;
+
+DROP TABLE IF EXISTS song_artists;
+
+DROP TABLE IF EXISTS albums;
+
+DROP TABLE IF EXISTS artists;
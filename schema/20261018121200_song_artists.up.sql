@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS artists (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS albums (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    album_cover VARCHAR(255),
+    release_date VARCHAR(10),
+    release_date_precision VARCHAR(5) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS song_artists (
+    song_id VARCHAR(255) NOT NULL,
+    artist_id VARCHAR(255) NOT NULL,
+    position INT NOT NULL,
+    PRIMARY KEY (song_id, artist_id),
+    INDEX idx_song_artists_artist (artist_id),
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE,
+    FOREIGN KEY (artist_id) REFERENCES artists(id) ON DELETE CASCADE
+);
+
+ALTER TABLE songs
+    ADD COLUMN album_id VARCHAR(255) NULL,
+    ADD COLUMN explicit BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN track_number INT NOT NULL DEFAULT 0,
+    ADD COLUMN disc_number INT NOT NULL DEFAULT 0,
+    ADD COLUMN release_date_precision VARCHAR(5) NOT NULL DEFAULT '',
+    ADD COLUMN artists_resolved BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD CONSTRAINT fk_songs_album FOREIGN KEY (album_id) REFERENCES albums(id) ON DELETE SET NULL;
+
+-- Existing rows only know their artist and album by name and start out with
+-- artists_resolved unset. The artist backfill runs once at startup and then on
+-- schedule: it re-fetches them, sets artists_resolved, and flags the ones Spotify
+-- no longer serves as unavailable. Until it has reached every song of a playlist,
+-- filtering that playlist by artist answers 503.
+UPDATE songs SET release_date_precision = 'day' WHERE release_date IS NOT NULL;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS audio_features (
+    song_id VARCHAR(255) PRIMARY KEY,
+    tempo FLOAT NULL,
+    energy FLOAT NULL,
+    danceability FLOAT NULL,
+    valence FLOAT NULL,
+    musical_key TINYINT NULL,
+    mode TINYINT NULL,
+    loudness FLOAT NULL,
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
+
+-- A row with NULL features marks a track Spotify has no analysis for, so the
+-- backfill doesn't ask for it again.
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS song_popularity;
+
+ALTER TABLE songs
+    DROP INDEX idx_songs_fetched_at,
+    DROP COLUMN unavailable;
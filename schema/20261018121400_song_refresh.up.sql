@@ -0,0 +1,11 @@
+ALTER TABLE songs
+    ADD COLUMN unavailable BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD INDEX idx_songs_fetched_at (fetched_at);
+
+CREATE TABLE IF NOT EXISTS song_popularity (
+    song_id VARCHAR(255) NOT NULL,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    popularity INT NOT NULL,
+    PRIMARY KEY (song_id, recorded_at),
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+ALTER TABLE playlist_snapshot_songs
+    DROP COLUMN note,
+    DROP COLUMN added_by,
+    DROP COLUMN added_at;
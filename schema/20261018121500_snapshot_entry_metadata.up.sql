@@ -0,0 +1,4 @@
+ALTER TABLE playlist_snapshot_songs
+    ADD COLUMN added_at TIMESTAMP NULL,
+    ADD COLUMN added_by INT NULL,
+    ADD COLUMN note VARCHAR(500) NULL;
@@ -0,0 +1,21 @@
+-- Delta snapshots can't stand on their own, so only full ones are kept.
+DELETE FROM playlist_snapshots WHERE base_version <> version;
+
+UPDATE playlist_snapshot_songs pss
+JOIN (
+    SELECT snapshot_id, entry_id, ROW_NUMBER() OVER (PARTITION BY snapshot_id ORDER BY position, entry_id) AS normalized
+    FROM playlist_snapshot_songs
+) numbered ON numbered.snapshot_id = pss.snapshot_id AND numbered.entry_id = pss.entry_id
+SET pss.position = numbered.normalized;
+
+ALTER TABLE playlist_snapshot_songs
+    DROP PRIMARY KEY,
+    MODIFY position INT NOT NULL,
+    MODIFY song_id VARCHAR(255) NOT NULL,
+    ADD PRIMARY KEY (snapshot_id, position),
+    DROP COLUMN removed,
+    DROP COLUMN entry_id;
+
+ALTER TABLE playlist_snapshots
+    DROP COLUMN track_count,
+    DROP COLUMN base_version;
@@ -0,0 +1,27 @@
+-- Snapshots store only the entries that changed since the previous snapshot, plus
+-- removal markers; every snapshot from base_version on is needed to rebuild one.
+-- Existing snapshots are full copies, so each becomes its own base.
+ALTER TABLE playlist_snapshots
+    ADD COLUMN base_version INT NULL,
+    ADD COLUMN track_count INT NOT NULL DEFAULT 0;
+
+UPDATE playlist_snapshots ps
+SET ps.base_version = ps.version,
+    ps.track_count = (SELECT COUNT(*) FROM playlist_snapshot_songs pss WHERE pss.snapshot_id = ps.id);
+
+ALTER TABLE playlist_snapshots
+    MODIFY base_version INT NOT NULL;
+
+ALTER TABLE playlist_snapshot_songs
+    ADD COLUMN entry_id INT NULL,
+    ADD COLUMN removed BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Copied rows never kept their entry, so they get ids no live entry can have.
+UPDATE playlist_snapshot_songs SET entry_id = -position;
+
+ALTER TABLE playlist_snapshot_songs
+    DROP PRIMARY KEY,
+    MODIFY entry_id INT NOT NULL,
+    MODIFY position INT NULL,
+    MODIFY song_id VARCHAR(255) NULL,
+    ADD PRIMARY KEY (snapshot_id, entry_id);